@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+)
+
+// Exit codes, so CI pipelines can tell the outcomes apart without parsing
+// output. When several apply after a benchmark, the highest one is used.
+const (
+	exitOK          = 0
+	exitFailure     = 1 // Runtime error: the run could not complete or its results could not be written
+	exitSLA         = 2 // -sla-* threshold missed
+	exitRegression  = 3 // -fail-on-regression threshold exceeded
+	exitHighFailure = 4 // More than maxFailureRate of the requests failed
+	exitConfig      = 5 // Invalid flags or unreadable input files
+)
+
+// maxFailureRate is the share of failed requests above which a benchmark
+// exits with exitHighFailure.
+const maxFailureRate = 0.5
+
+// printExitCodes writes the exit code table for -help.
+func printExitCodes(w io.Writer) {
+	fmt.Fprintf(w, "\nExit codes:\n")
+	fmt.Fprintf(w, "  %d  success\n", exitOK)
+	fmt.Fprintf(w, "  %d  runtime error\n", exitFailure)
+	fmt.Fprintf(w, "  %d  SLA violation (-sla-*)\n", exitSLA)
+	fmt.Fprintf(w, "  %d  regression against the baseline (-fail-on-regression)\n", exitRegression)
+	fmt.Fprintf(w, "  %d  more than %.0f%% of the requests failed\n", exitHighFailure, maxFailureRate*100)
+	fmt.Fprintf(w, "  %d  configuration error (invalid flags or input files)\n", exitConfig)
+	fmt.Fprintf(w, "  After a benchmark the highest applicable code is used.\n")
+}
+
+// fatalConfigf logs a configuration error and exits with exitConfig.
+func fatalConfigf(format string, args ...any) {
+	log.Printf(format, args...)
+	os.Exit(exitConfig)
+}
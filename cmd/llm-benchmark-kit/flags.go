@@ -0,0 +1,262 @@
+package main
+
+import (
+	"flag"
+
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/config"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/result"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/summarizer"
+)
+
+// options holds the values of every command-line flag. All flags are
+// registered on one flag set, fs; a subcommand parses a flag set of its own
+// that shares the values of just the flags it uses.
+type options struct {
+	fs  *flag.FlagSet
+	set map[string]bool // Flags given on the command line
+
+	cfg      *config.GlobalConfig
+	sla      result.SLA
+	baseline baselineCheck
+
+	tokenFile               *string
+	listModels              *bool
+	temperature             *float64
+	topP                    *float64
+	seed                    *int
+	baselineDir             *string
+	systemPromptFile        *string
+	jsonSchemaFile          *string
+	countTokensOnly         *bool
+	resumeDir               *string
+	transcriptFile          *string
+	chunkSize               *int
+	meetingTime             *string
+	streamSummary           *bool
+	verboseShort            *bool
+	veryVerbose             *bool
+	tui                     *bool
+	repeat                  *int
+	probeContext            *bool
+	probeContextMax         *int
+	probeCapabilities       *bool
+	prefixCacheTest         *bool
+	prefixCacheWords        *int
+	prefixCacheRepeats      *int
+	conversationTest        *bool
+	convSessions            *int
+	convTurns               *int
+	fullTest                *bool
+	summaryBench            *bool
+	summaryBenchConcurrency *int
+	summaryBenchRequests    *int
+	soakTest                *bool
+	soakDuration            *int
+	soakConcurrency         *int
+	soakWindow              *int
+	soakMetricsInterval     *int
+	soakLongConcurrency     *int
+	soakLongMaxTokens       *int
+	soakReportDir           *string
+	soakReportOutput        *string
+	replayFile              *string
+	leaderboardFile         *string
+	showVersion             *bool
+}
+
+// newOptions registers every flag on a new flag set and returns the options
+// bound to them.
+func newOptions() *options {
+	fs := flag.NewFlagSet("llm-benchmark-kit", flag.ContinueOnError)
+	cfg := config.DefaultConfig()
+	o := &options{fs: fs, set: map[string]bool{}, cfg: cfg}
+
+	// API Configuration
+	fs.StringVar(&cfg.URL, "url", "", "API endpoint URL (required)")
+	fs.StringVar(&cfg.ModelName, "model", "", "Model name to benchmark (required)")
+	fs.StringVar(&cfg.Token, "token", "", "API authentication token")
+	fs.Var((*stringList)(&cfg.APIKeys), "api-key", "API key to rotate across benchmark requests instead of -token (repeatable)")
+	o.tokenFile = fs.String("token-file", "", "File with API keys to rotate across benchmark requests, one per line (adds to -api-key)")
+	fs.Float64Var(&cfg.PerKeyRPS, "per-key-rps", 0, "Requests per second allowed per -api-key (0 = unlimited)")
+	o.listModels = fs.Bool("list-models", false, "List model IDs from {base}/models; with -model, warn if it is missing and continue")
+
+	// Benchmark Parameters
+	fs.IntVar(&cfg.Concurrency, "concurrency", cfg.Concurrency, "Number of concurrent workers")
+	fs.IntVar(&cfg.TotalRequests, "total-requests", cfg.TotalRequests, "Total number of requests to make")
+	fs.IntVar(&cfg.DurationSec, "duration", 0, "Duration in seconds (alternative to total-requests)")
+	fs.Float64Var(&cfg.RPS, "rps", 0, "Requests per second limit (0 = unlimited)")
+	fs.IntVar(&cfg.Retries, "retries", 0, "Retry failed requests that streamed nothing (connection errors, 5xx, 408, 429) up to this many times")
+	fs.IntVar(&cfg.RetryBackoffMs, "retry-backoff-ms", cfg.RetryBackoffMs, "Sleep before the first retry in ms; doubles on each further retry")
+	fs.BoolVar(&cfg.CountRetryLatency, "count-retry-latency", false, "Measure retried requests from the first attempt, including backoff (default: final attempt only)")
+	fs.IntVar(&cfg.AbortAfter, "abort-after-failures", 0, "Stop the run early after this many consecutive failed requests and mark the report as aborted (0 = never)")
+	fs.IntVar(&cfg.MaxInFlight, "max-in-flight", 0, "Max overlapping requests; above -concurrency each worker sends without waiting for its previous response (0 = -concurrency)")
+	fs.IntVar(&cfg.Warmup, "warmup", 0, "Number of warmup requests (excluded from stats)")
+	fs.Float64Var(&cfg.WarmupStablePct, "warmup-until-stable", 0, "Warm up until the P95 latency of two successive windows differs by at most this percent, e.g. 10 (0 = use -warmup)")
+	fs.IntVar(&cfg.WarmupWindow, "warmup-window", 0, "Completed requests per window for -warmup-until-stable (0 = max(20, concurrency))")
+	fs.IntVar(&cfg.WarmupMax, "warmup-max", 500, "Most warmup requests sent by -warmup-until-stable before measuring anyway")
+	fs.BoolVar(&cfg.WaitReady, "wait-ready", false, "Poll the endpoint with a 1-token request until it succeeds before benchmarking")
+	fs.IntVar(&cfg.WaitReadySec, "wait-ready-timeout", 300, "Seconds to wait for -wait-ready before giving up")
+	fs.IntVar(&cfg.MaxTokens, "max-tokens", cfg.MaxTokens, "Max tokens for response")
+	fs.IntVar(&cfg.N, "n", 0, "Completions per request, sent as n (openai provider; 0 = not sent)")
+
+	// Generation Parameters (only sent when explicitly set)
+	o.temperature = fs.Float64("temperature", 0, "Sampling temperature (omitted unless set; 0 is sent for deterministic decoding)")
+	o.topP = fs.Float64("top-p", 0, "Nucleus sampling top_p (omitted unless set)")
+	o.seed = fs.Int("seed", 0, "Random seed for reproducible sampling (omitted unless set)")
+	fs.Var((*stringList)(&cfg.Stop), "stop", "Stop sequence sent with every request (repeatable)")
+	fs.IntVar(&cfg.MinTokens, "min-tokens", 0, "Minimum completion tokens, sent as min_tokens (vLLM extension; 0 = not sent)")
+	fs.BoolVar(&cfg.IgnoreEOS, "ignore-eos", false, "Keep generating until max_tokens, sent as ignore_eos (vLLM extension)")
+	fs.StringVar(&cfg.ExtraBody, "extra-body", "", "JSON object merged into every request body, e.g. '{\"top_k\":40,\"repetition_penalty\":1.1}'")
+
+	// SLA Gating (benchmark mode exits non-zero when any threshold is violated)
+	fs.Int64Var(&o.sla.MaxP95LatencyMs, "sla-p95-latency-ms", 0, "Fail if P95 latency exceeds this many ms (0 = disabled)")
+	fs.Int64Var(&o.sla.MaxP99LatencyMs, "sla-p99-latency-ms", 0, "Fail if P99 latency exceeds this many ms (0 = disabled)")
+	fs.Int64Var(&o.sla.MaxP95TTFTMs, "sla-p95-ttft-ms", 0, "Fail if P95 TTFT exceeds this many ms (0 = disabled)")
+	fs.Int64Var(&o.sla.MaxP99TTFTMs, "sla-p99-ttft-ms", 0, "Fail if P99 TTFT exceeds this many ms (0 = disabled)")
+	fs.Float64Var(&o.sla.MinSuccessRate, "sla-success-rate", 0, "Fail if success rate is below this ratio, e.g. 0.99 (0 = disabled)")
+	fs.Float64Var(&o.sla.MinRPS, "sla-min-rps", 0, "Fail if RPS is below this value (0 = disabled)")
+
+	// Regression Comparison (benchmark mode compares against an earlier summary.json)
+	fs.StringVar(&o.baseline.path, "compare-baseline", "", "Compare the run with this earlier summary.json and print metric deltas")
+	o.baselineDir = fs.String("compare-baseline-dir", "", "Compare the run with the newest summary.json in subdirectories of this directory, e.g. output/")
+	fs.Float64Var(&o.baseline.failPct, "fail-on-regression", 0, "Fail if a metric is this many percent worse than the baseline (0 = report only)")
+
+	// Prompting
+	fs.StringVar(&cfg.SystemPrompt, "system-prompt", "", "System prompt prepended to every request without one")
+	o.systemPromptFile = fs.String("system-prompt-file", "", "Read the system prompt from a file (overrides -system-prompt)")
+
+	// Structured Output
+	fs.BoolVar(&cfg.JSONMode, "json-mode", false, "Request JSON output (response_format json_object) and report the valid JSON rate")
+	fs.Var((*stringList)(&cfg.Scorers), "score", "Score successful responses: nonempty, json, regex:<pattern> or length:<min>-<max> (repeatable)")
+	o.jsonSchemaFile = fs.String("json-schema", "", "JSON schema file for structured outputs (response_format json_schema; implies -json-mode)")
+
+	// Token Mode
+	fs.StringVar(&cfg.TokenMode, "token-mode", cfg.TokenMode, "Token counting mode: usage|chars|disabled")
+	fs.IntVar(&cfg.TTFTMinChars, "ttft-min-chars", 0, "Record TTFT at the delta where N non-whitespace chars have arrived, ignoring empty/role-only/whitespace deltas (0 = first content delta)")
+	fs.BoolVar(&cfg.ThinkTagFilter, "think-tag-filter", false, "Exclude inline <think>...</think> blocks from output chars and TTFT (fairer throughput for reasoning models)")
+	fs.Float64Var(&cfg.CharsPerToken, "chars-per-token", cfg.CharsPerToken, "Chars per token for estimating completion tokens when the server sends no usage (token-mode usage or chars; 0 = built-in tiktoken tokenizer)")
+
+	// Pricing
+	fs.Float64Var(&cfg.PriceInput, "price-input", 0, "Prompt token price in USD per million tokens, for the cost estimate (0 = free)")
+	fs.Float64Var(&cfg.PriceOutput, "price-output", 0, "Completion token price in USD per million tokens, for the cost estimate (0 = free)")
+
+	// Network Configuration
+	fs.IntVar(&cfg.TimeoutSec, "timeout", cfg.TimeoutSec, "Request timeout in seconds, covering the whole stream")
+	fs.IntVar(&cfg.ConnectTimeoutSec, "connect-timeout", 0, "Seconds allowed for TCP connect and TLS handshake (0 = only -timeout)")
+	fs.IntVar(&cfg.FirstByteTimeoutSec, "first-byte-timeout", 0, "Seconds allowed from sending a request to the first streamed data; failures are counted as first_byte_timeout (0 = only -timeout)")
+	fs.BoolVar(&cfg.InsecureTLS, "insecure", false, "Skip TLS verification")
+	fs.IntVar(&cfg.MaxIdleConns, "max-idle-conns", 0, "Idle connections kept for reuse (0 = max(100, concurrency))")
+	fs.IntVar(&cfg.MaxIdleConnsPerHost, "max-idle-conns-per-host", 0, "Idle connections kept per host (0 = -max-idle-conns)")
+	fs.IntVar(&cfg.MaxConnsPerHost, "max-conns-per-host", 0, "Cap on open connections per host; requests beyond it wait client-side for a free connection instead of failing (0 = unlimited)")
+	fs.IntVar(&cfg.MaxResponseChars, "max-response-chars", 0, "Abort a request as too_large once its streamed content exceeds this many chars (0 = unlimited)")
+	fs.BoolVar(&cfg.StrictSSE, "strict-sse", false, "Count SSE protocol violations per request (invalid UTF-8, frames without data:, missing blank-line boundaries, invalid JSON)")
+	fs.BoolVar(&cfg.NoKeepAlive, "no-keepalive", false, "Disable connection keep-alive (every request opens a new TCP/TLS connection)")
+	fs.StringVar(&cfg.CACertPath, "ca-cert", "", "Custom CA certificate path")
+
+	// Input/Output
+	fs.StringVar(&cfg.WorkloadFile, "workload-file", "", "Path to prompts file (each line a prompt or JSONL)")
+	o.countTokensOnly = fs.Bool("count-tokens-only", false, "Estimate the prompt tokens and cost of the workload without sending any requests")
+	fs.StringVar(&cfg.PromptTemplate, "prompt-template", "", "Prompt template using Go text/template syntax, e.g. \"Summarize {{.topic}} in {{.n}} words\"")
+	fs.StringVar(&cfg.PromptVars, "vars", "", "Template variables: name=v1|v2,name2=v (each combination becomes a workload)")
+	fs.BoolVar(&cfg.BustCache, "bust-cache", false, "Append a unique nonce to every prompt so a server that caches responses cannot answer from cache")
+	fs.StringVar(&cfg.MessagesFile, "messages-file", "", "JSON array of {role,content} sent as the base conversation of every request (workload prompts are appended as the next user turn)")
+	fs.StringVar(&cfg.VarsFile, "vars-file", "", "JSONL file with one template variable set per line (overrides -vars)")
+	fs.StringVar(&cfg.OutputDir, "out", cfg.OutputDir, "Output directory for results")
+	fs.BoolVar(&cfg.OutOverwrite, "out-overwrite", cfg.OutOverwrite, "Allow writing into an output directory that already contains results (false = exit instead)")
+	fs.StringVar(&cfg.RunName, "run-name", "", "Label for this run, added to the auto-generated output directory name and to reports")
+	fs.StringVar(&cfg.OutputFormat, "output-format", cfg.OutputFormat, "Report formats to write: comma list of json,md,html or all (results.jsonl is always written)")
+	fs.BoolVar(&cfg.PercentileCSV, "percentile-csv", false, "Also write percentiles.csv with TTFT and latency at P1-P99.9, for plotting with your own tools")
+	fs.BoolVar(&cfg.StreamingStats, "streaming-stats", false, "Estimate percentiles incrementally (bounded memory, no distribution charts)")
+	fs.BoolVar(&cfg.CDNCharts, "cdn-charts", false, "Load the chart library from a CDN in HTML reports instead of embedding it (~1MB smaller, needs internet to view)")
+	fs.BoolVar(&cfg.RecordAll, "record-all", false, "Write every request's messages and full response text to transcript.jsonl for diffing outputs between runs (large)")
+	fs.IntVar(&cfg.CheckpointSec, "checkpoint-interval", 30, "Seconds between checkpoint.json updates, so an interrupted benchmark can be continued with -resume (0 = only at the end)")
+	fs.IntVar(&cfg.SnapshotSec, "snapshot-interval", 0, "Rewrite summary.json/report.html from the results so far every N seconds during a benchmark, to watch long runs live (0 = only at the end)")
+	o.resumeDir = fs.String("resume", "", "Continue the interrupted benchmark in this output directory, sending only the requests missing from its results.jsonl")
+	fs.Float64Var(&cfg.SampleRate, "sample-rate", 0, "Fraction of requests (0-1, e.g. 0.01) whose prompt and full response text are stored in results.jsonl")
+	fs.IntVar(&cfg.SampleMiddleFrames, "sample-middle-frames", cfg.SampleMiddleFrames, "Raw content frames, evenly spaced over the stream, shown as samples in the report between the first and final frame (0 = none)")
+
+	// Provider
+	fs.StringVar(&cfg.ProviderType, "provider", cfg.ProviderType, "Provider type: openai, bedrock, aliyun, tgi, custom, websocket")
+	fs.StringVar(&cfg.Region, "region", "", "Cloud region for the provider (bedrock: defaults to AWS_REGION)")
+	fs.StringVar(&cfg.CustomCmd, "custom-cmd", "", "Command for -provider custom: reads request JSON on stdin, writes OpenAI-style SSE or NDJSON to stdout")
+
+	// Meeting Summary Mode
+	o.transcriptFile = fs.String("transcript-file", "", "Path to meeting transcript file (enables summary mode)")
+	o.chunkSize = fs.Int("chunk-size", 8000, "Maximum characters per chunk for transcript processing")
+	fs.IntVar(&cfg.ChunkTokens, "chunk-tokens", 0, "Maximum tokens per transcript chunk, counted with the built-in tokenizer; overrides -chunk-size (0 = use -chunk-size)")
+	fs.StringVar(&cfg.TranscriptEncoding, "transcript-encoding", summarizer.EncodingAuto, "Transcript file encoding: auto (UTF-8, UTF-16 by BOM, else GB18030), utf-8, utf-16le, utf-16be, gbk, gb18030; a UTF-8 BOM is always removed")
+	o.meetingTime = fs.String("meeting-time", "", "Meeting time for the summary header")
+	fs.BoolVar(&cfg.SummaryAutoExtend, "summary-auto-extend", false, "Retry chunks truncated by max_tokens (finish_reason=length) with doubled max_tokens")
+	fs.IntVar(&cfg.SummaryConcurrency, "summary-concurrency", 0, "Summarize transcript chunks independently with N parallel requests, then merge them in chunk order (map-reduce); 0 = rolling summary, one chunk at a time")
+	fs.BoolVar(&cfg.NoIntermediate, "no-intermediate", false, "Don't write intermediate/chunk_NN files in summary mode")
+	o.streamSummary = fs.Bool("stream-summary", false, "Stream the final chunk's summary to stdout as it is generated (summary mode)")
+	fs.StringVar(&cfg.IntermediateFormat, "intermediate-format", "md", "Intermediate chunk file format: md or json (json includes per-chunk token metrics)")
+
+	// Debug Options
+	fs.BoolVar(&cfg.Verbose, "verbose", false, "Enable verbose logging of LLM requests and responses")
+	o.verboseShort = fs.Bool("v", false, "Verbose output (same as -verbose)")
+	o.veryVerbose = fs.Bool("vv", false, "Very verbose output (-v plus raw SSE frames)")
+	fs.BoolVar(&cfg.SelfMonitor, "self-monitor", false, "Watch the tool's own dispatch loop (-rps schedule lag, busy workers, Go scheduler latency) and warn when the client, not the server, limits the run")
+	fs.BoolVar(&cfg.CPUProfile, "cpuprofile", false, "Profile the benchmark tool's own CPU use during the run and write cpu.pprof to the output directory")
+	fs.BoolVar(&cfg.MemProfile, "memprofile", false, "Write a heap profile of the benchmark tool (mem.pprof) to the output directory after the run")
+	fs.BoolVar(&cfg.Quiet, "quiet", false, "Suppress progress output and print only the final summary line")
+	fs.BoolVar(&cfg.LogSecrets, "log-secrets", false, "Show the first characters of the API token in logs (masked as *** by default)")
+	o.tui = fs.Bool("tui", false, "Show a live progress display (benchmark mode, TTY only)")
+	o.repeat = fs.Int("repeat", 1, "Run the benchmark N times with the same config and write an aggregate report")
+
+	// Model Behavior
+	fs.BoolVar(&cfg.DisableThinking, "no-thinking", false, "Disable thinking/reasoning mode (sends chat_template_kwargs.enable_thinking=false)")
+	fs.BoolVar(&cfg.Logprobs, "logprobs", false, "Request logprobs=true and report the average token logprob per response (openai provider)")
+
+	// Context Probe Mode
+	o.probeContext = fs.Bool("probe-context", false, "Binary-search the largest prompt the endpoint accepts before a context-length error")
+	o.probeContextMax = fs.Int("probe-context-max", 1048576, "Upper bound for -probe-context in approximate prompt tokens")
+	o.probeCapabilities = fs.Bool("probe", false, "Check which OpenAI API features the endpoint honors (streaming, stream usage, tools, JSON mode, logprobs, n, stop, max context) and write a capability matrix")
+	o.prefixCacheTest = fs.Bool("prefix-cache-test", false, "Send one long prompt prefix repeatedly and compare cold vs warm TTFT to measure prefix-cache speedup")
+	o.prefixCacheWords = fs.Int("prefix-cache-words", 4000, "Length of the shared -prefix-cache-test prefix in words (~1 token each)")
+	o.prefixCacheRepeats = fs.Int("prefix-cache-repeats", 5, "Requests sent by -prefix-cache-test, including the first (cold) one")
+
+	// Conversation Growth Mode
+	o.conversationTest = fs.Bool("conversation", false, "Simulate parallel chat sessions whose context grows every turn and report TTFT/latency per turn")
+	o.convSessions = fs.Int("conv-sessions", 4, "Parallel sessions in -conversation mode")
+	o.convTurns = fs.Int("conv-turns", 8, "Turns per session in -conversation mode; turn k resends the k-1 earlier exchanges")
+
+	// Full Test Mode
+	o.fullTest = fs.Bool("full-test", false, "Run complete test suite (benchmark + summary)")
+	fs.StringVar(&cfg.FullTestPhases, "phases", "", "Comma-separated full-test phases to run: perf, funccall, longctx, summary (default all)")
+	fs.IntVar(&cfg.TurnDelayMs, "turn-delay-ms", 0, "Think time between turns of the full-test multi-turn conversation and -conversation sessions")
+	fs.IntVar(&cfg.TurnJitterMs, "turn-jitter-ms", 0, "Random extra think time (0..N ms) added to -turn-delay-ms")
+	fs.StringVar(&cfg.ContextLadder, "context-ladder", "", "Comma-separated context lengths in characters for the full-test long context phase (default 1000,4000,8000,16000,32000)")
+	fs.StringVar(&cfg.ContextFillerFile, "context-filler-file", "", "Text file repeated to build full-test long contexts (default: built-in Chinese text)")
+	fs.IntVar(&cfg.ContextMaxTokens, "context-max-tokens", cfg.ContextMaxTokens, "Max output tokens of full-test long context requests")
+	fs.StringVar(&cfg.FunctionCallCasesFile, "fc-cases", "", "JSON file with extra function-call test cases ([{\"query\", \"expected_function\", \"expected_args\"}])")
+
+	// Summary Benchmark Mode
+	o.summaryBench = fs.Bool("summary-bench", false, "Run concurrent meeting summary benchmark")
+	o.summaryBenchConcurrency = fs.Int("sb-concurrency", 5, "Concurrency for summary benchmark")
+	o.summaryBenchRequests = fs.Int("sb-requests", 20, "Total requests for summary benchmark")
+
+	// Soak Test Mode
+	o.soakTest = fs.Bool("soak", false, "Run soak/endurance test (long-running stability test)")
+	o.soakDuration = fs.Int("soak-duration", 300, "Soak test duration in seconds")
+	o.soakConcurrency = fs.Int("soak-concurrency", 5, "Soak test concurrency")
+	o.soakWindow = fs.Int("soak-window", 30, "Soak test snapshot window interval in seconds")
+	o.soakMetricsInterval = fs.Int("soak-metrics-interval", 10, "System metrics collection interval in seconds")
+	o.soakLongConcurrency = fs.Int("soak-long-concurrency", 0, "Number of workers for long requests (0 = all short)")
+	o.soakLongMaxTokens = fs.Int("soak-long-max-tokens", 2048, "Max tokens for long request workers")
+
+	// Soak Report Rebuild Mode
+	o.soakReportDir = fs.String("soak-report", "", "Rebuild soak report from logs in the given directory (no server needed)")
+	o.soakReportOutput = fs.String("soak-report-output", "", "Output directory for rebuilt report (default: same as input)")
+
+	// Replay Mode
+	o.replayFile = fs.String("replay", "", "Rebuild summary/report files from an existing results.jsonl (no server needed)")
+
+	// Leaderboard Mode
+	o.leaderboardFile = fs.String("leaderboard", "", "Run the benchmark against every target in a JSON file and rank them")
+
+	// Version flag
+	o.showVersion = fs.Bool("version", false, "Show version information")
+
+	return o
+}
@@ -2,23 +2,39 @@
 package main
 
 import (
+	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/brianxiadong/llm-benchmark-kit/pkg/config"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/conversation"
 	"github.com/brianxiadong/llm-benchmark-kit/pkg/embedded"
 	"github.com/brianxiadong/llm-benchmark-kit/pkg/fulltest"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/probe"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/progress"
 	"github.com/brianxiadong/llm-benchmark-kit/pkg/provider"
-	_ "github.com/brianxiadong/llm-benchmark-kit/pkg/provider/openai" // Register OpenAI provider
+	_ "github.com/brianxiadong/llm-benchmark-kit/pkg/provider/aliyun"    // Register Aliyun DashScope provider
+	_ "github.com/brianxiadong/llm-benchmark-kit/pkg/provider/bedrock"   // Register Bedrock provider
+	_ "github.com/brianxiadong/llm-benchmark-kit/pkg/provider/custom"    // Register custom command provider
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/provider/openai"      // Also registers the OpenAI provider
+	_ "github.com/brianxiadong/llm-benchmark-kit/pkg/provider/tgi"       // Register TGI provider
+	_ "github.com/brianxiadong/llm-benchmark-kit/pkg/provider/websocket" // Register WebSocket provider
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/result"
 	"github.com/brianxiadong/llm-benchmark-kit/pkg/runner"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/scorer"
 	"github.com/brianxiadong/llm-benchmark-kit/pkg/soaktest"
 	"github.com/brianxiadong/llm-benchmark-kit/pkg/summarizer"
 	"github.com/brianxiadong/llm-benchmark-kit/pkg/summarybench"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/tokenizer"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/workload"
 )
 
 var (
@@ -28,263 +44,1344 @@ var (
 )
 
 func main() {
-	cfg := config.DefaultConfig()
-
-	// API Configuration
-	flag.StringVar(&cfg.URL, "url", "", "API endpoint URL (required)")
-	flag.StringVar(&cfg.ModelName, "model", "", "Model name to benchmark (required)")
-	flag.StringVar(&cfg.Token, "token", "", "API authentication token")
-
-	// Benchmark Parameters
-	flag.IntVar(&cfg.Concurrency, "concurrency", cfg.Concurrency, "Number of concurrent workers")
-	flag.IntVar(&cfg.TotalRequests, "total-requests", cfg.TotalRequests, "Total number of requests to make")
-	flag.IntVar(&cfg.DurationSec, "duration", 0, "Duration in seconds (alternative to total-requests)")
-	flag.Float64Var(&cfg.RPS, "rps", 0, "Requests per second limit (0 = unlimited)")
-	flag.IntVar(&cfg.Warmup, "warmup", 0, "Number of warmup requests (excluded from stats)")
-	flag.IntVar(&cfg.MaxTokens, "max-tokens", cfg.MaxTokens, "Max tokens for response")
-
-	// Token Mode
-	flag.StringVar(&cfg.TokenMode, "token-mode", cfg.TokenMode, "Token counting mode: usage|chars|disabled")
-
-	// Network Configuration
-	flag.IntVar(&cfg.TimeoutSec, "timeout", cfg.TimeoutSec, "Request timeout in seconds")
-	flag.BoolVar(&cfg.InsecureTLS, "insecure", false, "Skip TLS verification")
-	flag.StringVar(&cfg.CACertPath, "ca-cert", "", "Custom CA certificate path")
-
-	// Input/Output
-	flag.StringVar(&cfg.WorkloadFile, "workload-file", "", "Path to prompts file (each line a prompt or JSONL)")
-	flag.StringVar(&cfg.OutputDir, "out", cfg.OutputDir, "Output directory for results")
-
-	// Provider
-	flag.StringVar(&cfg.ProviderType, "provider", cfg.ProviderType, "Provider type: openai, aliyun, custom")
-
-	// Meeting Summary Mode
-	transcriptFile := flag.String("transcript-file", "", "Path to meeting transcript file (enables summary mode)")
-	chunkSize := flag.Int("chunk-size", 8000, "Maximum characters per chunk for transcript processing")
-	meetingTime := flag.String("meeting-time", "", "Meeting time for the summary header")
-
-	// Debug Options
-	flag.BoolVar(&cfg.Verbose, "verbose", false, "Enable verbose logging of LLM requests and responses")
-
-	// Model Behavior
-	flag.BoolVar(&cfg.DisableThinking, "no-thinking", false, "Disable thinking/reasoning mode (sends chat_template_kwargs.enable_thinking=false)")
-
-	// Full Test Mode
-	fullTest := flag.Bool("full-test", false, "Run complete test suite (benchmark + summary)")
-
-	// Summary Benchmark Mode
-	summaryBench := flag.Bool("summary-bench", false, "Run concurrent meeting summary benchmark")
-	summaryBenchConcurrency := flag.Int("sb-concurrency", 5, "Concurrency for summary benchmark")
-	summaryBenchRequests := flag.Int("sb-requests", 20, "Total requests for summary benchmark")
-
-	// Soak Test Mode
-	soakTest := flag.Bool("soak", false, "Run soak/endurance test (long-running stability test)")
-	soakDuration := flag.Int("soak-duration", 300, "Soak test duration in seconds")
-	soakConcurrency := flag.Int("soak-concurrency", 5, "Soak test concurrency")
-	soakWindow := flag.Int("soak-window", 30, "Soak test snapshot window interval in seconds")
-	soakMetricsInterval := flag.Int("soak-metrics-interval", 10, "System metrics collection interval in seconds")
-	soakLongConcurrency := flag.Int("soak-long-concurrency", 0, "Number of workers for long requests (0 = all short)")
-	soakLongMaxTokens := flag.Int("soak-long-max-tokens", 2048, "Max tokens for long request workers")
-
-	// Soak Report Rebuild Mode
-	soakReportDir := flag.String("soak-report", "", "Rebuild soak report from logs in the given directory (no server needed)")
-	soakReportOutput := flag.String("soak-report-output", "", "Output directory for rebuilt report (default: same as input)")
-
-	// Version flag
-	showVersion := flag.Bool("version", false, "Show version information")
-
-	flag.Usage = func() {
-		fmt.Fprintf(os.Stderr, "LLM Benchmark Kit - High-Performance LLM Benchmarking Tool\n\n")
-		fmt.Fprintf(os.Stderr, "Usage: %s [options]\n\n", os.Args[0])
-		fmt.Fprintf(os.Stderr, "Modes:\n")
-		fmt.Fprintf(os.Stderr, "  Benchmark Mode:      Run performance tests against LLM API\n")
-		fmt.Fprintf(os.Stderr, "  Summary Mode:        Summarize meeting transcripts (use -transcript-file)\n")
-		fmt.Fprintf(os.Stderr, "  Full Test Mode:      Run complete test suite (use -full-test)\n")
-		fmt.Fprintf(os.Stderr, "  Summary Bench Mode:  Concurrent meeting summary benchmark (use -summary-bench)\n")
-		fmt.Fprintf(os.Stderr, "  Soak Test Mode:      Long-running stability/endurance test (use -soak)\n")
-		fmt.Fprintf(os.Stderr, "  Soak Report Mode:    Rebuild report from soak test logs (use -soak-report)\n\n")
-		fmt.Fprintf(os.Stderr, "Options:\n")
-		flag.PrintDefaults()
-		fmt.Fprintf(os.Stderr, "\nExamples:\n")
-		fmt.Fprintf(os.Stderr, "  # Benchmark mode\n")
-		fmt.Fprintf(os.Stderr, "  %s -url https://api.openai.com/v1/chat/completions -model gpt-4 -token $OPENAI_API_KEY\n\n", os.Args[0])
-		fmt.Fprintf(os.Stderr, "  # Summary mode\n")
-		fmt.Fprintf(os.Stderr, "  %s -url http://localhost:8000/v1/chat/completions -model qwen -transcript-file meeting.txt\n\n", os.Args[0])
-		fmt.Fprintf(os.Stderr, "  # Full test mode\n")
-		fmt.Fprintf(os.Stderr, "  %s -full-test -url http://localhost:8000/v1/chat/completions -model qwen\n\n", os.Args[0])
-		fmt.Fprintf(os.Stderr, "  # Summary benchmark mode (concurrent meeting summary stress test)\n")
-		fmt.Fprintf(os.Stderr, "  %s -summary-bench -sb-concurrency 10 -sb-requests 50 -url http://localhost:8000/v1/chat/completions -model qwen\n\n", os.Args[0])
-		fmt.Fprintf(os.Stderr, "  # Soak test mode (long-running stability test with system metrics)\n")
-		fmt.Fprintf(os.Stderr, "  %s -soak -soak-duration 3600 -soak-concurrency 10 -soak-window 60 -url http://localhost:8000/v1/chat/completions -model qwen\n\n", os.Args[0])
-		fmt.Fprintf(os.Stderr, "  # Rebuild soak report from logs (download logs from server, generate report locally)\n")
-		fmt.Fprintf(os.Stderr, "  %s -soak-report ./output/soaktest_qwen_20260302_120000\n\n", os.Args[0])
-	}
-
-	flag.Parse()
-
-	if *showVersion {
+	o := newOptions()
+	// Parse errors exit with exitConfig rather than the flag package's 2, which means an SLA violation here
+	if err := o.parse(os.Args, os.Stderr); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			os.Exit(exitOK)
+		}
+		os.Exit(exitConfig)
+	}
+	cfg := o.cfg
+
+	if *o.showVersion {
 		fmt.Printf("llm-benchmark-kit version %s (commit: %s, built: %s)\n", version, commit, date)
 		os.Exit(0)
 	}
 
+	if *o.tokenFile != "" {
+		keys, err := readTokenFile(*o.tokenFile)
+		if err != nil {
+			fatalConfigf("Error: %v", err)
+		}
+		cfg.APIKeys = append(cfg.APIKeys, keys...)
+	}
+	// Modes that send with a single token use the first -api-key
+	if cfg.Token == "" && len(cfg.APIKeys) > 0 {
+		cfg.Token = cfg.APIKeys[0]
+	}
+
+	// Generation parameters are pointers so an explicit 0 is still sent
+	if o.set["temperature"] {
+		cfg.Temperature = o.temperature
+	}
+	if o.set["top-p"] {
+		cfg.TopP = o.topP
+	}
+	if o.set["seed"] {
+		cfg.Seed = o.seed
+	}
+
+	if *o.systemPromptFile != "" {
+		data, err := os.ReadFile(*o.systemPromptFile)
+		if err != nil {
+			fatalConfigf("Error: failed to read system prompt: %v", err)
+		}
+		cfg.SystemPrompt = strings.TrimSpace(string(data))
+	}
+
+	if *o.jsonSchemaFile != "" {
+		schema, err := os.ReadFile(*o.jsonSchemaFile)
+		if err != nil {
+			fatalConfigf("Error: failed to read JSON schema: %v", err)
+		}
+		if !json.Valid(schema) {
+			fatalConfigf("Error: JSON schema %s is not valid JSON", *o.jsonSchemaFile)
+		}
+		cfg.JSONSchema = string(schema)
+		cfg.JSONMode = true
+	}
+
+	if cfg.ExtraBody != "" {
+		var extra map[string]interface{}
+		if err := json.Unmarshal([]byte(cfg.ExtraBody), &extra); err != nil || extra == nil {
+			fatalConfigf("Error: -extra-body must be a JSON object, got %s", cfg.ExtraBody)
+		}
+	}
+
+	// Resolve verbosity level
+	switch {
+	case *o.veryVerbose:
+		cfg.Verbosity = 2
+	case *o.verboseShort || cfg.Verbose:
+		cfg.Verbosity = 1
+	}
+	cfg.Verbose = cfg.Verbosity > 0
+	if cfg.Quiet {
+		if cfg.Verbose {
+			fatalConfigf("Error: -quiet cannot be combined with -verbose/-v/-vv")
+		}
+	}
+
 	// Soak report rebuild mode does not require -url or -model
-	if *soakReportDir != "" {
-		runSoakReportRebuild(*soakReportDir, *soakReportOutput)
+	if *o.soakReportDir != "" {
+		runSoakReportRebuild(cfg, *o.soakReportDir, *o.soakReportOutput)
 		return
 	}
 
-	// Validate required flags
-	if cfg.URL == "" {
-		log.Fatal("Error: -url is required")
+	// Replay mode does not require -url or -model
+	if *o.replayFile != "" {
+		runReplay(cfg, *o.replayFile)
+		return
+	}
+
+	// Token counting sends no requests, so it needs neither -url nor -model
+	if *o.countTokensOnly {
+		runCountTokens(cfg)
+		return
+	}
+
+	// Leaderboard mode takes -url and -model from its targets file
+	if *o.leaderboardFile != "" {
+		runLeaderboard(cfg, *o.leaderboardFile)
+		return
+	}
+
+	// Validate required flags (bedrock derives its endpoint from -region, aliyun defaults to DashScope,
+	// custom passes -url through to its command)
+	switch cfg.ProviderType {
+	case "bedrock", "aliyun":
+	case "custom":
+		if cfg.CustomCmd == "" {
+			fatalConfigf("Error: -custom-cmd is required for -provider custom")
+		}
+	default:
+		if cfg.URL == "" {
+			fatalConfigf("Error: -url is required")
+		}
+	}
+	if *o.listModels {
+		runListModels(cfg)
+		if cfg.ModelName == "" {
+			return
+		}
 	}
 	if cfg.ModelName == "" {
-		log.Fatal("Error: -model is required")
+		fatalConfigf("Error: -model is required")
+	}
+	if cfg.IntermediateFormat != "md" && cfg.IntermediateFormat != "json" {
+		fatalConfigf("Error: -intermediate-format must be md or json, got %q", cfg.IntermediateFormat)
+	}
+	if cfg.SummaryConcurrency < 0 {
+		fatalConfigf("Error: -summary-concurrency must not be negative")
 	}
 
 	// Check if running in soak test mode
-	if *soakTest {
-		runSoakTest(cfg, *soakDuration, *soakConcurrency, *soakWindow, *soakMetricsInterval, *soakLongConcurrency, *soakLongMaxTokens)
+	if *o.soakTest {
+		runSoakTest(cfg, *o.soakDuration, *o.soakConcurrency, *o.soakWindow, *o.soakMetricsInterval, *o.soakLongConcurrency, *o.soakLongMaxTokens)
+		return
+	}
+
+	// Check if running in capability probe mode
+	if *o.probeCapabilities {
+		runProbeCapabilities(cfg)
+		return
+	}
+
+	// Check if running in context probe mode
+	if *o.probeContext {
+		runProbeContext(cfg, *o.probeContextMax)
+		return
+	}
+
+	// Check if running in prefix cache test mode
+	if *o.prefixCacheTest {
+		runPrefixCacheTest(cfg, *o.prefixCacheWords, *o.prefixCacheRepeats)
+		return
+	}
+
+	// Check if running in conversation growth mode
+	if *o.conversationTest {
+		runConversationTest(cfg, *o.convSessions, *o.convTurns)
 		return
 	}
 
 	// Check if running in full-test mode
-	if *fullTest {
+	if *o.fullTest {
 		runFullTest(cfg)
 		return
 	}
 
 	// Check if running in summary benchmark mode
-	if *summaryBench {
-		runSummaryBench(cfg, *transcriptFile, *chunkSize, *summaryBenchConcurrency, *summaryBenchRequests)
+	if *o.summaryBench {
+		// -concurrency/-total-requests apply too unless the -sb-* flags are given
+		if o.set["concurrency"] && !o.set["sb-concurrency"] {
+			*o.summaryBenchConcurrency = cfg.Concurrency
+		}
+		if o.set["total-requests"] && !o.set["sb-requests"] {
+			*o.summaryBenchRequests = cfg.TotalRequests
+		}
+		runSummaryBench(cfg, *o.transcriptFile, *o.chunkSize, *o.summaryBenchConcurrency, *o.summaryBenchRequests)
 		return
 	}
 
 	// Check if running in summary mode
-	if *transcriptFile != "" {
-		runSummaryMode(cfg, *transcriptFile, *chunkSize, *meetingTime)
+	if *o.transcriptFile != "" {
+		runSummaryMode(cfg, *o.transcriptFile, *o.chunkSize, *o.meetingTime, *o.streamSummary)
 		return
 	}
 
 	// Benchmark mode
-	runBenchmarkMode(cfg)
+	if *o.repeat < 1 {
+		fatalConfigf("Error: -repeat must be at least 1")
+	}
+	if *o.baselineDir != "" {
+		if o.baseline.path != "" {
+			fatalConfigf("Error: use either -compare-baseline or -compare-baseline-dir, not both")
+		}
+		// Resolved before the run so the new summary.json cannot be picked
+		path, err := runner.FindLatestSummary(*o.baselineDir)
+		if err != nil {
+			fatalConfigf("Error: %v", err)
+		}
+		o.baseline.path = path
+	}
+	if o.baseline.path != "" {
+		// Checked up front so a typo does not cost a whole benchmark run
+		if _, err := runner.LoadSummary(o.baseline.path); err != nil {
+			fatalConfigf("Error: invalid baseline: %v", err)
+		}
+	}
+	if o.baseline.failPct < 0 {
+		fatalConfigf("Error: -fail-on-regression must not be negative")
+	}
+	if o.baseline.path != "" && *o.repeat > 1 {
+		fatalConfigf("Error: -compare-baseline cannot be combined with -repeat")
+	}
+	if *o.resumeDir != "" {
+		if *o.repeat > 1 {
+			fatalConfigf("Error: -resume cannot be combined with -repeat")
+		}
+		cfg.OutputDir = *o.resumeDir
+		cfg.Resume = true
+	}
+	runBenchmarkMode(cfg, *o.tui, o.sla, o.baseline, *o.repeat)
 }
 
-func runSummaryMode(cfg *config.GlobalConfig, transcriptFile string, chunkSize int, meetingTime string) {
+func runSummaryMode(cfg *config.GlobalConfig, transcriptFile string, chunkSize int, meetingTime string, stream bool) {
 	// Set default meeting time if not provided
 	if meetingTime == "" {
 		meetingTime = time.Now().Format("2006-01-02 15:04")
 	}
 
 	// Auto-generate output directory
-	modelName := cfg.ModelName
-	modelName = strings.ReplaceAll(modelName, "/", "_")
-	modelName = strings.ReplaceAll(modelName, ":", "_")
-	timestamp := time.Now().Format("20060102_150405")
-	outputDir := filepath.Join("output", fmt.Sprintf("summary_%s_%s", modelName, timestamp))
-
-	fmt.Printf("Meeting Summary Mode\n")
-	fmt.Printf("====================\n")
-	fmt.Printf("URL:          %s\n", cfg.URL)
-	fmt.Printf("Model:        %s\n", cfg.ModelName)
-	fmt.Printf("Transcript:   %s\n", transcriptFile)
-	fmt.Printf("Chunk Size:   %d chars\n", chunkSize)
-	fmt.Printf("Meeting Time: %s\n", meetingTime)
-	fmt.Printf("Output:       %s\n", outputDir)
-	fmt.Println()
+	outputDir := autoOutputDir("summary", cfg)
+	checkOutputDir(cfg, outputDir)
+
+	fmt.Fprintf(cfg.Stdout(), "Meeting Summary Mode\n")
+	fmt.Fprintf(cfg.Stdout(), "====================\n")
+	fmt.Fprintf(cfg.Stdout(), "URL:          %s\n", cfg.URL)
+	fmt.Fprintf(cfg.Stdout(), "Model:        %s\n", cfg.ModelName)
+	fmt.Fprintf(cfg.Stdout(), "Transcript:   %s\n", transcriptFile)
+	if cfg.ChunkTokens > 0 {
+		fmt.Fprintf(cfg.Stdout(), "Chunk Size:   %d tokens (%s)\n", cfg.ChunkTokens, tokenizer.EncodingForModel(cfg.ModelName))
+	} else {
+		fmt.Fprintf(cfg.Stdout(), "Chunk Size:   %d chars\n", chunkSize)
+	}
+	fmt.Fprintf(cfg.Stdout(), "Meeting Time: %s\n", meetingTime)
+	fmt.Fprintf(cfg.Stdout(), "Output:       %s\n", outputDir)
+	fmt.Fprintln(cfg.Stdout())
 
 	sum := summarizer.NewSummarizer(cfg, chunkSize, meetingTime)
+	if stream {
+		sum.SetStreamWriter(cfg.Stdout())
+	}
 	_, err := sum.Run(transcriptFile, outputDir)
 	if err != nil {
 		log.Fatalf("Summarization failed: %v", err)
 	}
 
-	fmt.Printf("\n✅ Meeting summary complete!\n")
-	fmt.Printf("   Final summary:    %s/meeting_summary.md\n", outputDir)
-	fmt.Printf("   Intermediate:     %s/intermediate/\n", outputDir)
+	fmt.Fprintf(cfg.Stdout(), "\n✅ Meeting summary complete!\n")
+	fmt.Fprintf(cfg.Stdout(), "   Final summary:    %s/meeting_summary.md\n", outputDir)
+	if !cfg.NoIntermediate {
+		fmt.Fprintf(cfg.Stdout(), "   Intermediate:     %s/intermediate/\n", outputDir)
+	}
+	printQuietSummary(cfg, "summary: ok output=%s", outputDir)
 }
 
-func runBenchmarkMode(cfg *config.GlobalConfig) {
+// baselineCheck compares a benchmark run with an earlier run's summary.json.
+type baselineCheck struct {
+	path    string  // Baseline summary.json ("" = no comparison)
+	failPct float64 // Exit non-zero when a metric is this many percent worse (0 = report only)
+}
+
+// validateBenchmarkConfig exits if the benchmark flags in cfg are invalid.
+func validateBenchmarkConfig(cfg *config.GlobalConfig) {
 	// Validate token mode
 	switch cfg.TokenMode {
 	case "usage", "chars", "disabled":
 		// Valid
 	default:
-		log.Fatalf("Error: invalid token-mode '%s', must be one of: usage, chars, disabled", cfg.TokenMode)
+		fatalConfigf("Error: invalid token-mode '%s', must be one of: usage, chars, disabled", cfg.TokenMode)
+	}
+	if _, err := runner.ParseOutputFormat(cfg.OutputFormat); err != nil {
+		fatalConfigf("Error: invalid -output-format: %v", err)
+	}
+	if cfg.WaitReady && cfg.WaitReadySec <= 0 {
+		fatalConfigf("Error: -wait-ready-timeout must be positive")
+	}
+	if cfg.SampleRate < 0 || cfg.SampleRate > 1 {
+		fatalConfigf("Error: -sample-rate must be between 0 and 1, got %g", cfg.SampleRate)
+	}
+	if cfg.SampleMiddleFrames < 0 || cfg.SampleMiddleFrames > runner.MaxMiddleFrames {
+		fatalConfigf("Error: -sample-middle-frames must be between 0 and %d, got %d", runner.MaxMiddleFrames, cfg.SampleMiddleFrames)
+	}
+	if cfg.AbortAfter < 0 {
+		fatalConfigf("Error: -abort-after-failures must not be negative")
+	}
+	if cfg.PerKeyRPS < 0 {
+		fatalConfigf("Error: -per-key-rps must not be negative")
+	}
+	if cfg.PerKeyRPS > 0 && len(cfg.APIKeys) == 0 {
+		fatalConfigf("Error: -per-key-rps requires -api-key or -token-file")
 	}
+	if cfg.MaxResponseChars < 0 {
+		fatalConfigf("Error: -max-response-chars must not be negative")
+	}
+	if cfg.MaxConnsPerHost < 0 {
+		fatalConfigf("Error: -max-conns-per-host must not be negative")
+	}
+	if cfg.PriceInput < 0 || cfg.PriceOutput < 0 {
+		fatalConfigf("Error: -price-input and -price-output must not be negative")
+	}
+	if cfg.N < 0 {
+		fatalConfigf("Error: -n must not be negative")
+	}
+	if _, err := buildScorers(cfg.Scorers); err != nil {
+		fatalConfigf("Error: %v", err)
+	}
+	if cfg.N > 1 && cfg.ProviderType != "openai" && cfg.ProviderType != "websocket" {
+		fatalConfigf("Error: -n is only supported by the openai and websocket providers, got '%s'", cfg.ProviderType)
+	}
+	if cfg.MinTokens < 0 {
+		fatalConfigf("Error: -min-tokens must not be negative")
+	}
+	if cfg.MinTokens > cfg.MaxTokens {
+		fatalConfigf("Error: -min-tokens (%d) must not exceed -max-tokens (%d)", cfg.MinTokens, cfg.MaxTokens)
+	}
+	if (cfg.MinTokens > 0 || cfg.IgnoreEOS) && cfg.ProviderType != "openai" && cfg.ProviderType != "websocket" {
+		fatalConfigf("Error: -min-tokens and -ignore-eos are only supported by the openai and websocket providers, got '%s'", cfg.ProviderType)
+	}
+	if cfg.JSONMode && !sendsResponseFormat(cfg) {
+		// The other request formats have no response_format, so the valid JSON rate would measure nothing
+		fatalConfigf("Error: -json-mode and -json-schema are only supported by the openai and websocket providers (and aliyun with a /compatible-mode/ URL), got '%s'", cfg.ProviderType)
+	}
+	if cfg.Retries < 0 || cfg.RetryBackoffMs < 0 {
+		fatalConfigf("Error: -retries and -retry-backoff-ms must not be negative")
+	}
+	if cfg.CheckpointSec < 0 {
+		fatalConfigf("Error: -checkpoint-interval must not be negative")
+	}
+	if cfg.SnapshotSec < 0 {
+		fatalConfigf("Error: -snapshot-interval must not be negative")
+	}
+	if cfg.ConnectTimeoutSec < 0 || cfg.FirstByteTimeoutSec < 0 {
+		fatalConfigf("Error: -connect-timeout and -first-byte-timeout must not be negative")
+	}
+	if cfg.WarmupStablePct < 0 || cfg.WarmupWindow < 0 {
+		fatalConfigf("Error: -warmup-until-stable and -warmup-window must not be negative")
+	}
+	if cfg.WarmupStablePct > 0 {
+		if cfg.Warmup > 0 {
+			fatalConfigf("Error: use either -warmup or -warmup-until-stable, not both")
+		}
+		if cfg.WarmupMax <= 0 {
+			fatalConfigf("Error: -warmup-max must be positive with -warmup-until-stable")
+		}
+	}
+	if cfg.PercentileCSV && cfg.StreamingStats {
+		fatalConfigf("Error: -percentile-csv needs the full distributions, which -streaming-stats does not keep")
+	}
+}
 
-	// Auto-generate output directory if using default
-	if cfg.OutputDir == "./output" {
-		modelName := cfg.ModelName
-		modelName = strings.ReplaceAll(modelName, "/", "_")
-		modelName = strings.ReplaceAll(modelName, ":", "_")
-		modelName = strings.ReplaceAll(modelName, " ", "_")
-		timestamp := time.Now().Format("20060102_150405")
-		cfg.OutputDir = filepath.Join("output", fmt.Sprintf("%s_%s", modelName, timestamp))
+// sendsResponseFormat reports whether cfg's provider sends response_format,
+// which -json-mode and -json-schema rely on.
+func sendsResponseFormat(cfg *config.GlobalConfig) bool {
+	switch cfg.ProviderType {
+	case "openai", "websocket":
+		return true
+	case "aliyun":
+		return strings.Contains(cfg.URL, "/compatible-mode/")
+	}
+	return false
+}
+
+func runBenchmarkMode(cfg *config.GlobalConfig, tui bool, sla result.SLA, baseline baselineCheck, repeat int) {
+	validateBenchmarkConfig(cfg)
+
+	// Auto-generate output directory if using default; -resume writes into the given one
+	if !cfg.Resume {
+		if cfg.OutputDir == "./output" {
+			cfg.OutputDir = autoOutputDir("", cfg)
+		}
+		checkOutputDir(cfg, cfg.OutputDir)
 	}
 
 	// Get the provider
 	p, err := provider.Get(cfg.ProviderType)
 	if err != nil {
-		log.Fatalf("Error: %v\nAvailable providers: %v", err, provider.List())
-	}
-
-	fmt.Printf("LLM Benchmark Kit\n")
-	fmt.Printf("==================\n")
-	fmt.Printf("Provider:     %s\n", p.Name())
-	fmt.Printf("URL:          %s\n", cfg.URL)
-	fmt.Printf("Model:        %s\n", cfg.ModelName)
-	fmt.Printf("Concurrency:  %d\n", cfg.Concurrency)
-	fmt.Printf("Requests:     %d\n", cfg.TotalRequests)
-	fmt.Printf("Warmup:       %d\n", cfg.Warmup)
-	fmt.Printf("Token Mode:   %s\n", cfg.TokenMode)
-	fmt.Printf("Output:       %s\n", cfg.OutputDir)
-	fmt.Println()
-
-	// Run the benchmark
+		fatalConfigf("Error: %v\nAvailable providers: %v", err, provider.List())
+	}
+
+	fmt.Fprintf(cfg.Stdout(), "LLM Benchmark Kit\n")
+	fmt.Fprintf(cfg.Stdout(), "==================\n")
+	fmt.Fprintf(cfg.Stdout(), "Provider:     %s\n", p.Name())
+	fmt.Fprintf(cfg.Stdout(), "URL:          %s\n", cfg.URL)
+	fmt.Fprintf(cfg.Stdout(), "Model:        %s\n", cfg.ModelName)
+	if cfg.RunName != "" {
+		fmt.Fprintf(cfg.Stdout(), "Run Name:     %s\n", cfg.RunName)
+	}
+	fmt.Fprintf(cfg.Stdout(), "Concurrency:  %d\n", cfg.Concurrency)
+	if cfg.MaxInFlight > cfg.Concurrency {
+		fmt.Fprintf(cfg.Stdout(), "Max In-Flight: %d\n", cfg.MaxInFlight)
+	}
+	fmt.Fprintf(cfg.Stdout(), "Requests:     %d\n", cfg.TotalRequests)
+	if cfg.N > 1 {
+		fmt.Fprintf(cfg.Stdout(), "Completions:  %d per request (n)\n", cfg.N)
+	}
+	if cfg.WarmupStablePct > 0 {
+		fmt.Fprintf(cfg.Stdout(), "Warmup:       until P95 latency is stable within %g%% (at most %d)\n", cfg.WarmupStablePct, cfg.WarmupMax)
+	} else {
+		fmt.Fprintf(cfg.Stdout(), "Warmup:       %d\n", cfg.Warmup)
+	}
+	fmt.Fprintf(cfg.Stdout(), "Token Mode:   %s\n", cfg.TokenMode)
+	if cfg.NoKeepAlive {
+		fmt.Fprintf(cfg.Stdout(), "Keep-Alive:   disabled (new connection per request; latency includes TCP/TLS setup)\n")
+	}
+	if cfg.MaxConnsPerHost > 0 {
+		fmt.Fprintf(cfg.Stdout(), "Conns/Host:   %d", cfg.MaxConnsPerHost)
+		if cfg.MaxConnsPerHost < max(cfg.Concurrency, cfg.MaxInFlight) {
+			fmt.Fprintf(cfg.Stdout(), " (below concurrency: excess requests queue client-side and the wait counts toward TTFT)")
+		}
+		fmt.Fprintln(cfg.Stdout())
+	}
+	if repeat > 1 {
+		fmt.Fprintf(cfg.Stdout(), "Repeat:       %d\n", repeat)
+	}
+	fmt.Fprintf(cfg.Stdout(), "Output:       %s\n", cfg.OutputDir)
+	fmt.Fprintln(cfg.Stdout())
+
+	if repeat > 1 {
+		runRepeatedBenchmark(cfg, p, tui, sla, repeat)
+		return
+	}
+
+	report := runBenchmarkOnce(cfg, p, tui)
+
+	fmt.Fprintf(cfg.Stdout(), "\nBenchmark Complete!\n")
+	fmt.Fprintf(cfg.Stdout(), "==================\n")
+	if report.Aborted {
+		fmt.Fprintf(cfg.Stdout(), "⚠️  Aborted:   %s\n", report.AbortReason)
+	}
+	fmt.Fprintf(cfg.Stdout(), "Success Rate: %.2f%% (%d/%d)\n", report.SuccessRate*100, report.Success, report.TotalRequests)
+	if report.PartialCount > 0 {
+		fmt.Fprintf(cfg.Stdout(), "Partial:      %d (streamed content, then failed)\n", report.PartialCount)
+	}
+	if report.EmptyCount > 0 {
+		fmt.Fprintf(cfg.Stdout(), "Empty:        %d (ended without content)\n", report.EmptyCount)
+	}
+	if c := report.ClientStats; c != nil {
+		fmt.Fprintf(cfg.Stdout(), "Client:       dispatch %.2f req/s, lag %.0f ms (max %.0f), waited for a worker %.0f%%, sched p99 %.2f ms\n",
+			c.DispatchRPS, c.DispatchLagMs, c.MaxDispatchLagMs, c.WorkerWaitRatio*100, c.SchedLatencyP99Ms)
+		if c.Saturated {
+			fmt.Fprintf(cfg.Stdout(), "⚠️  Client saturated: %s\n", c.Reason)
+		}
+	}
+	if cfg.StrictSSE {
+		fmt.Fprintf(cfg.Stdout(), "SSE:          %d protocol violations in %d requests\n", report.SSEViolations, report.SSEViolationRequests)
+		if report.SSEViolationExample != "" {
+			fmt.Fprintf(cfg.Stdout(), "              first: %s\n", report.SSEViolationExample)
+		}
+	}
+	if report.RetriedRequests > 0 {
+		fmt.Fprintf(cfg.Stdout(), "Retried:      %d requests, %d extra attempts\n", report.RetriedRequests, report.RetryAttempts)
+	}
+	fmt.Fprintf(cfg.Stdout(), "Avg TTFT:     %.2f ms\n", report.AvgTTFTMs)
+	fmt.Fprintf(cfg.Stdout(), "Avg Latency:  %.2f ms\n", report.AvgLatencyMs)
+	fmt.Fprintf(cfg.Stdout(), "P50 TTFT:     %d ms\n", report.P50TTFTMs)
+	fmt.Fprintf(cfg.Stdout(), "P95 TTFT:     %d ms\n", report.P95TTFTMs)
+	fmt.Fprintf(cfg.Stdout(), "P99 TTFT:     %d ms\n", report.P99TTFTMs)
+	if report.AvgServerFirstByteMs > 0 {
+		fmt.Fprintf(cfg.Stdout(), "TTFT Split:   connect %.2f ms, TLS %.2f ms (%d new conns), write %.2f ms, server first byte %.2f ms\n",
+			report.AvgConnectMs, report.AvgTLSMs, report.NewConnections, report.AvgRequestWriteMs, report.AvgServerFirstByteMs)
+	}
+	fmt.Fprintf(cfg.Stdout(), "P50 Latency:  %d ms\n", report.P50LatencyMs)
+	fmt.Fprintf(cfg.Stdout(), "P95 Latency:  %d ms\n", report.P95LatencyMs)
+	fmt.Fprintf(cfg.Stdout(), "P99 Latency:  %d ms\n", report.P99LatencyMs)
+	if report.AvgTTLTMs > 0 {
+		fmt.Fprintf(cfg.Stdout(), "Avg TTLT:     %.2f ms (time to last token; stream tail after it avg %.2f ms, P95 %d ms)\n",
+			report.AvgTTLTMs, report.AvgStreamTailMs, report.P95StreamTailMs)
+	}
+	fmt.Fprintf(cfg.Stdout(), "RPS:          %.2f\n", report.RPS)
+	if cfg.TokenMode != "disabled" {
+		fmt.Fprintf(cfg.Stdout(), "Throughput:   %.2f %s/s\n", report.TokenThroughput, cfg.TokenMode)
+		if report.TotalPromptTokens > 0 || report.TotalCompletionTokens > 0 {
+			fmt.Fprintf(cfg.Stdout(), "Tokens:       %d prompt / %d completion\n", report.TotalPromptTokens, report.TotalCompletionTokens)
+		}
+		if report.EstimatedTokenCount > 0 {
+			method := fmt.Sprintf("with the built-in %s tokenizer", tokenizer.EncodingForModel(cfg.ModelName))
+			if cfg.CharsPerToken > 0 {
+				method = fmt.Sprintf("at %.1f chars/token", cfg.CharsPerToken)
+			}
+			fmt.Fprintf(cfg.Stdout(), "⚠️  No usage from server for %d requests; completion tokens estimated %s\n",
+				report.EstimatedTokenCount, method)
+		}
+		if report.ToolCallResponses > 0 {
+			fmt.Fprintf(cfg.Stdout(), "Tool Calls:   %d responses (tool-call arguments counted as output)\n", report.ToolCallResponses)
+		}
+		if report.EstimatedCostUSD != nil {
+			fmt.Fprintf(cfg.Stdout(), "Est. Cost:    $%.6f ($%.4f per 1K requests, %.0f completion tokens per $)\n",
+				*report.EstimatedCostUSD, report.CostPer1KRequestsUSD, report.CompletionTokensPerUSD)
+		}
+		if report.TotalPromptTokens > 0 {
+			fmt.Fprintf(cfg.Stdout(), "Prefill:      %.2f tokens/s (prompt tokens / TTFT)\n", report.PrefillSpeed)
+		}
+		if report.DecodeSpeed > 0 {
+			fmt.Fprintf(cfg.Stdout(), "Decode:       %.2f tokens/s (completion tokens / decode time)\n", report.DecodeSpeed)
+		}
+		if report.P50TokensPerSec > 0 {
+			fmt.Fprintf(cfg.Stdout(), "Per-request:  P50 %.2f / P95 %.2f / P99 %.2f %s/s\n",
+				report.P50TokensPerSec, report.P95TokensPerSec, report.P99TokensPerSec, report.TokenMode)
+			fmt.Fprintf(cfg.Stdout(), "              mean %.2f / geometric mean %.2f %s/s\n",
+				report.MeanTokensPerSec, report.GeoMeanTokensPerSec, report.TokenMode)
+		}
+	}
+	if len(report.HTTPStatusCounts) > 0 {
+		codes := make([]int, 0, len(report.HTTPStatusCounts))
+		for code := range report.HTTPStatusCounts {
+			codes = append(codes, code)
+		}
+		sort.Ints(codes)
+		parts := make([]string, len(codes))
+		for i, code := range codes {
+			count := report.HTTPStatusCounts[code]
+			parts[i] = fmt.Sprintf("%d×%d (%.1f%%)", code, count, float64(count)/float64(report.TotalRequests)*100)
+		}
+		fmt.Fprintf(cfg.Stdout(), "HTTP Errors:  %s\n", strings.Join(parts, ", "))
+	}
+	for i, b := range report.PromptBuckets {
+		label := "              "
+		if i == 0 {
+			label = "Prompt Len:   "
+		}
+		fmt.Fprintf(cfg.Stdout(), "%s%d–%d %s: %d requests, P50 TTFT %d ms, P50 latency %d ms\n",
+			label, b.MinLength, b.MaxLength, report.PromptLengthUnit, b.Requests, b.P50TTFTMs, b.P50LatencyMs)
+	}
+	for _, k := range report.APIKeys {
+		fmt.Fprintf(cfg.Stdout(), "API Key #%d:  %.2f%% success (%d requests, %d failed, %d × HTTP 429)\n",
+			k.Index, k.SuccessRate*100, k.Requests, k.Failures, k.RateLimited)
+	}
+	if len(report.FinishReasonCounts) > 0 {
+		reasons := make([]string, 0, len(report.FinishReasonCounts))
+		for reason, count := range report.FinishReasonCounts {
+			reasons = append(reasons, fmt.Sprintf("%s=%d", reason, count))
+		}
+		sort.Strings(reasons)
+		fmt.Fprintf(cfg.Stdout(), "Finish:       %s\n", strings.Join(reasons, ", "))
+	}
+	if report.ValidJSONRate != nil {
+		fmt.Fprintf(cfg.Stdout(), "Valid JSON:   %.2f%% (%d/%d)\n", *report.ValidJSONRate*100, report.ValidJSONCount, report.Success)
+	}
+	for _, s := range report.Scores {
+		fmt.Fprintf(cfg.Stdout(), "Score:        %s mean %.3f, pass %.2f%% (%d scored)\n", s.Name, s.Mean, s.PassRate*100, s.Scored)
+	}
+	if report.AvgLogprob != nil {
+		fmt.Fprintf(cfg.Stdout(), "Logprob:      avg %.4f per token (least confident response %.4f)\n", *report.AvgLogprob, *report.MinAvgLogprob)
+	}
+	if report.OutlierCount > 0 {
+		fmt.Fprintf(cfg.Stdout(), "Outliers:     %d beyond median ± 3·MAD (MAD %.2f ms, IQR %.2f ms), e.g. %s\n",
+			report.OutlierCount, report.LatencyMADMs, report.LatencyIQRMs, strings.Join(report.OutlierIDs, ", "))
+	}
+	atLeast := ""
+	if report.DistinctResponsesCapped {
+		atLeast = "≥ "
+	}
+	fmt.Fprintf(cfg.Stdout(), "Distinct:     %s%d responses (most common seen %d times)\n", atLeast, report.DistinctResponses, report.TopResponseCount)
+	if report.Success > 1 && report.DistinctResponses == 1 {
+		fmt.Fprintf(cfg.Stdout(), "⚠️  Every successful response was byte-identical; results may reflect caching or a canned reply\n")
+	}
+	if w := report.WarmupReport; w != nil && w.Success > 0 {
+		fmt.Fprintf(cfg.Stdout(), "Warmup:       avg TTFT %.2f ms, avg latency %.2f ms (%d/%d ok; steady state %.2f / %.2f ms)\n",
+			w.AvgTTFTMs, w.AvgLatencyMs, w.Success, w.TotalRequests, report.AvgTTFTMs, report.AvgLatencyMs)
+	}
+	if w := report.WarmupReport; w != nil && len(w.WarmupWindowP95Ms) > 0 {
+		state := "stable"
+		if !w.WarmupStable {
+			state = "not stable by -warmup-max"
+		}
+		fmt.Fprintf(cfg.Stdout(), "              %d warmup requests sent, %s (window P95s %v ms)\n", w.TotalRequests, state, w.WarmupWindowP95Ms)
+	}
+	fmt.Fprintf(cfg.Stdout(), "\nResults saved to: %s\n", cfg.OutputDir)
+	printQuietSummary(cfg, "benchmark: success=%.2f%% (%d/%d) avg_ttft=%.2fms p95_latency=%dms rps=%.2f aborted=%t output=%s",
+		report.SuccessRate*100, report.Success, report.TotalRequests, report.AvgTTFTMs, report.P95LatencyMs, report.RPS, report.Aborted, cfg.OutputDir)
+
+	// Every check runs and prints its outcome before the most severe one sets the exit code
+	code := exitOK
+	if baseline.path != "" && compareWithBaseline(cfg, report, baseline) {
+		code = exitRegression
+	}
+
+	if !sla.IsZero() {
+		violations := result.CheckSLA(report, sla)
+		if len(violations) > 0 {
+			fmt.Fprintf(os.Stderr, "\nSLA check FAILED (%d violation(s)):\n", len(violations))
+			for _, v := range violations {
+				fmt.Fprintf(os.Stderr, "  ✗ %s\n", v)
+			}
+			code = max(code, exitSLA)
+		} else {
+			fmt.Fprintf(cfg.Stdout(), "SLA check passed\n")
+		}
+	}
+
+	if checkFailureRate(report, "") {
+		code = max(code, exitHighFailure)
+	}
+	if code != exitOK {
+		os.Exit(code)
+	}
+}
+
+// checkFailureRate reports whether more than maxFailureRate of the requests
+// in report failed, printing a warning to stderr if so. label names the run.
+func checkFailureRate(report *result.BenchmarkReport, label string) bool {
+	if report.TotalRequests == 0 {
+		return false
+	}
+	failed := report.TotalRequests - report.Success
+	if float64(failed)/float64(report.TotalRequests) <= maxFailureRate {
+		return false
+	}
+	fmt.Fprintf(os.Stderr, "\nHealth check FAILED%s: %d of %d requests failed (more than %.0f%%)\n",
+		label, failed, report.TotalRequests, maxFailureRate*100)
+	return true
+}
+
+// compareWithBaseline prints the metric deltas against the baseline summary and
+// reports whether -fail-on-regression is set and a metric regressed.
+func compareWithBaseline(cfg *config.GlobalConfig, report *result.BenchmarkReport, baseline baselineCheck) bool {
+	base, err := runner.LoadSummary(baseline.path)
+	if err != nil {
+		fatalConfigf("Error: %v", err)
+	}
+	fmt.Fprintf(cfg.Stdout(), "\nBaseline:     %s (started %s)\n", baseline.path, base.StartedAt)
+	var regressions []result.MetricDelta
+	for _, d := range result.CompareReports(base, report, baseline.failPct) {
+		mark := " "
+		if d.Regressed {
+			mark = "✗"
+			regressions = append(regressions, d)
+		}
+		fmt.Fprintf(cfg.Stdout(), "  %s %-17s %12.2f -> %12.2f  %+7.1f%%\n", mark, d.Metric, d.Baseline, d.Current, d.ChangePct)
+	}
+	if baseline.failPct <= 0 {
+		return false
+	}
+	if len(regressions) > 0 {
+		fmt.Fprintf(os.Stderr, "\nRegression check FAILED (%d metric(s) more than %g%% worse than baseline):\n", len(regressions), baseline.failPct)
+		for _, d := range regressions {
+			fmt.Fprintf(os.Stderr, "  ✗ %s\n", d)
+		}
+		return true
+	}
+	fmt.Fprintf(cfg.Stdout(), "Regression check passed (threshold %g%%)\n", baseline.failPct)
+	return false
+}
+
+// runBenchmarkOnce runs a single benchmark with cfg and exits on failure.
+func runBenchmarkOnce(cfg *config.GlobalConfig, p provider.Provider, tui bool) *result.BenchmarkReport {
 	r := runner.New(cfg, p)
+	scorers, err := buildScorers(cfg.Scorers)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	for _, s := range scorers {
+		r.AddScorer(s)
+	}
+
+	if tui && !cfg.Quiet {
+		if progress.IsTerminal(os.Stdout) {
+			r.SetProgress(progress.NewDisplay(os.Stdout))
+		} else {
+			fmt.Fprintln(cfg.Stdout(), "Note: -tui requires a terminal, using plain output")
+		}
+	}
+	stopProfiles, err := startProfiles(cfg)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
 	report, err := r.Run()
+	if err := stopProfiles(); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+	if errors.Is(err, runner.ErrResumeMismatch) {
+		fatalConfigf("Error: %v", err)
+	}
 	if err != nil {
 		log.Fatalf("Benchmark failed: %v", err)
 	}
+	return report
+}
 
-	fmt.Printf("\nBenchmark Complete!\n")
-	fmt.Printf("==================\n")
-	fmt.Printf("Success Rate: %.2f%% (%d/%d)\n", report.SuccessRate*100, report.Success, report.TotalRequests)
-	fmt.Printf("Avg TTFT:     %.2f ms\n", report.AvgTTFTMs)
-	fmt.Printf("Avg Latency:  %.2f ms\n", report.AvgLatencyMs)
-	fmt.Printf("P50 TTFT:     %d ms\n", report.P50TTFTMs)
-	fmt.Printf("P95 TTFT:     %d ms\n", report.P95TTFTMs)
-	fmt.Printf("P99 TTFT:     %d ms\n", report.P99TTFTMs)
-	fmt.Printf("P50 Latency:  %d ms\n", report.P50LatencyMs)
-	fmt.Printf("P95 Latency:  %d ms\n", report.P95LatencyMs)
-	fmt.Printf("P99 Latency:  %d ms\n", report.P99LatencyMs)
-	fmt.Printf("RPS:          %.2f\n", report.RPS)
-	if cfg.TokenMode != "disabled" {
-		fmt.Printf("Throughput:   %.2f %s/s\n", report.TokenThroughput, cfg.TokenMode)
+// runRepeatedBenchmark runs the same benchmark repeat times, each into its own
+// run-N subdirectory, and writes aggregate.json/aggregate.md to cfg.OutputDir.
+func runRepeatedBenchmark(cfg *config.GlobalConfig, p provider.Provider, tui bool, sla result.SLA, repeat int) {
+	var reports []*result.BenchmarkReport
+	var outputDirs []string
+	for i := 1; i <= repeat; i++ {
+		fmt.Fprintf(cfg.Stdout(), "\n--- Run %d/%d ---\n", i, repeat)
+		runCfg := *cfg
+		runCfg.OutputDir = filepath.Join(cfg.OutputDir, fmt.Sprintf("run-%d", i))
+		report := runBenchmarkOnce(&runCfg, p, tui)
+		fmt.Fprintf(cfg.Stdout(), "Run %d: success=%.2f%% rps=%.2f avg_ttft=%.2fms p95_latency=%dms\n",
+			i, report.SuccessRate*100, report.RPS, report.AvgTTFTMs, report.P95LatencyMs)
+		reports = append(reports, report)
+		outputDirs = append(outputDirs, runCfg.OutputDir)
+	}
+
+	agg := runner.Aggregate(reports, outputDirs)
+	fmt.Fprintf(cfg.Stdout(), "\nRepeated Benchmark Complete! (%d runs)\n", repeat)
+	fmt.Fprintf(cfg.Stdout(), "==================\n")
+	fmt.Fprintf(cfg.Stdout(), "RPS:          %.2f ± %.2f\n", agg.RPS.Mean, agg.RPS.StdDev)
+	fmt.Fprintf(cfg.Stdout(), "Avg TTFT:     %.2f ± %.2f ms\n", agg.AvgTTFTMs.Mean, agg.AvgTTFTMs.StdDev)
+	fmt.Fprintf(cfg.Stdout(), "P95 TTFT:     %.2f ± %.2f ms\n", agg.P95TTFTMs.Mean, agg.P95TTFTMs.StdDev)
+	fmt.Fprintf(cfg.Stdout(), "P95 Latency:  %.2f ± %.2f ms\n", agg.P95LatencyMs.Mean, agg.P95LatencyMs.StdDev)
+	fmt.Fprintf(cfg.Stdout(), "Success Rate: %.2f%% ± %.2f%%\n", agg.SuccessRate.Mean*100, agg.SuccessRate.StdDev*100)
+	if err := runner.WriteAggregate(cfg.Stdout(), agg, cfg.OutputDir); err != nil {
+		log.Fatalf("Failed to write aggregate report: %v", err)
+	}
+	fmt.Fprintf(cfg.Stdout(), "\nResults saved to: %s\n", cfg.OutputDir)
+	printQuietSummary(cfg, "benchmark: runs=%d rps=%.2f±%.2f p95_latency=%.2f±%.2fms output=%s",
+		repeat, agg.RPS.Mean, agg.RPS.StdDev, agg.P95LatencyMs.Mean, agg.P95LatencyMs.StdDev, cfg.OutputDir)
+
+	// Every run must meet the SLAs
+	code := exitOK
+	if !sla.IsZero() {
+		failed := false
+		for i, report := range reports {
+			for _, v := range result.CheckSLA(report, sla) {
+				if !failed {
+					fmt.Fprintf(os.Stderr, "\nSLA check FAILED:\n")
+					failed = true
+				}
+				fmt.Fprintf(os.Stderr, "  ✗ run %d: %s\n", i+1, v)
+			}
+		}
+		if failed {
+			code = exitSLA
+		} else {
+			fmt.Fprintf(cfg.Stdout(), "SLA check passed for all runs\n")
+		}
+	}
+	for i, report := range reports {
+		if checkFailureRate(report, fmt.Sprintf(" for run %d", i+1)) {
+			code = exitHighFailure
+		}
+	}
+	if code != exitOK {
+		os.Exit(code)
+	}
+}
+
+// autoOutputDir returns output/<prefix>_<run name>_<model>_<timestamp>. The
+// timestamp has millisecond precision so back-to-back runs do not collide.
+func autoOutputDir(prefix string, cfg *config.GlobalConfig) string {
+	var parts []string
+	if prefix != "" {
+		parts = append(parts, prefix)
+	}
+	if cfg.RunName != "" {
+		parts = append(parts, sanitizeDirName(cfg.RunName))
+	}
+	if cfg.ModelName != "" {
+		parts = append(parts, sanitizeDirName(cfg.ModelName))
 	}
-	fmt.Printf("\nResults saved to: %s\n", cfg.OutputDir)
+	parts = append(parts, time.Now().Format("20060102_150405.000"))
+	return filepath.Join("output", strings.Join(parts, "_"))
+}
+
+// readTokenFile reads API keys from path, one per line. Blank lines and lines
+// starting with # are skipped.
+func readTokenFile(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read token file: %w", err)
+	}
+	var keys []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		keys = append(keys, line)
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("token file %s contains no keys", path)
+	}
+	return keys, nil
+}
+
+// buildScorers parses -score specs into scorers.
+func buildScorers(specs []string) ([]scorer.Scorer, error) {
+	scorers := make([]scorer.Scorer, 0, len(specs))
+	for _, spec := range specs {
+		s, err := scorer.Parse(spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid -score: %w", err)
+		}
+		scorers = append(scorers, s)
+	}
+	return scorers, nil
+}
+
+// stringList is a flag.Value that collects every occurrence of a repeatable flag.
+type stringList []string
+
+func (l *stringList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
+
+// sanitizeDirName replaces characters that are awkward in directory names.
+func sanitizeDirName(name string) string {
+	return strings.NewReplacer("/", "_", ":", "_", " ", "_").Replace(name)
+}
+
+// checkOutputDir exits if -out-overwrite=false and dir already holds files.
+func checkOutputDir(cfg *config.GlobalConfig, dir string) {
+	if cfg.OutOverwrite {
+		return
+	}
+	if entries, err := os.ReadDir(dir); err == nil && len(entries) > 0 {
+		fatalConfigf("Error: output directory %s already contains results (remove it, choose another -out, or pass -out-overwrite)", dir)
+	}
+}
+
+// runListModels prints the endpoint's models and warns if -model is not among them.
+func runListModels(cfg *config.GlobalConfig) {
+	if cfg.URL == "" {
+		fatalConfigf("Error: -list-models requires -url")
+	}
+	ids, err := openai.ListModels(cfg)
+	if err != nil {
+		log.Fatalf("Error: failed to list models from %s: %v", openai.ModelsURL(cfg.URL), err)
+	}
+
+	fmt.Fprintf(cfg.Stdout(), "Models at %s:\n", openai.ModelsURL(cfg.URL))
+	found := false
+	for _, id := range ids {
+		fmt.Fprintf(cfg.Stdout(), "  %s\n", id)
+		if id == cfg.ModelName {
+			found = true
+		}
+	}
+	fmt.Fprintln(cfg.Stdout())
+
+	if cfg.ModelName != "" && !found {
+		fmt.Fprintf(os.Stderr, "⚠️  Model %q is not in the endpoint's model list; requests may fail with 404\n", cfg.ModelName)
+	}
+}
+
+func runReplay(cfg *config.GlobalConfig, resultsPath string) {
+	// By default, regenerate the reports next to the results file
+	if cfg.OutputDir == "./output" {
+		cfg.OutputDir = filepath.Dir(resultsPath)
+	}
+	// Recover the model name from the original summary when not given
+	if cfg.ModelName == "" {
+		if data, err := os.ReadFile(filepath.Join(filepath.Dir(resultsPath), "summary.json")); err == nil {
+			var original result.BenchmarkReport
+			if json.Unmarshal(data, &original) == nil {
+				cfg.ModelName = original.Model
+			}
+		}
+	}
+
+	fmt.Fprintf(cfg.Stdout(), "Replay Mode\n")
+	fmt.Fprintf(cfg.Stdout(), "===========\n")
+	fmt.Fprintf(cfg.Stdout(), "Results:      %s\n", resultsPath)
+	fmt.Fprintf(cfg.Stdout(), "Output:       %s\n", cfg.OutputDir)
+	fmt.Fprintln(cfg.Stdout())
+
+	report, err := runner.Replay(cfg, resultsPath)
+	if err != nil {
+		log.Fatalf("Replay failed: %v", err)
+	}
+
+	fmt.Fprintf(cfg.Stdout(), "\n✅ Replay complete! %d requests, success rate %.2f%%, P95 latency %d ms\n",
+		report.TotalRequests, report.SuccessRate*100, report.P95LatencyMs)
+	printQuietSummary(cfg, "replay: success=%.2f%% (%d/%d) p95_latency=%dms output=%s",
+		report.SuccessRate*100, report.Success, report.TotalRequests, report.P95LatencyMs, cfg.OutputDir)
+}
+
+// runCountTokens estimates the prompt tokens and cost of the benchmark
+// workload (-workload-file, or the built-in prompts) for -total-requests
+// requests, without contacting the server.
+func runCountTokens(cfg *config.GlobalConfig) {
+	loader := workload.NewLoader()
+	var workloads []workload.WorkloadInput
+	source := "built-in prompts"
+	if cfg.WorkloadFile != "" {
+		var err error
+		workloads, err = loader.LoadFromFile(cfg.WorkloadFile, cfg.MaxTokens)
+		if err != nil {
+			fatalConfigf("Error: failed to load workloads: %v", err)
+		}
+		if len(workloads) == 0 {
+			fatalConfigf("Error: no prompts found in %s", cfg.WorkloadFile)
+		}
+		source = cfg.WorkloadFile
+	} else {
+		workloads = loader.GenerateDefault(cfg.TotalRequests, cfg.MaxTokens)
+	}
+	// The benchmark sends -messages-file before each workload, or alone without -workload-file
+	if cfg.MessagesFile != "" {
+		base, err := workload.LoadMessagesFile(cfg.MessagesFile)
+		if err != nil {
+			fatalConfigf("Error: %v", err)
+		}
+		for i := range workloads {
+			workloads[i] = workloads[i].WithBaseMessages(base, cfg.WorkloadFile != "")
+		}
+	}
+
+	file := workload.EstimateTokens(workloads, 0, cfg.SystemPrompt, cfg.ModelName)
+	run := workload.EstimateTokens(workloads, cfg.TotalRequests, cfg.SystemPrompt, cfg.ModelName)
+	pricing := result.Pricing{InputPerMillion: cfg.PriceInput, OutputPerMillion: cfg.PriceOutput}
+
+	fmt.Fprintf(cfg.Stdout(), "Token Count\n")
+	fmt.Fprintf(cfg.Stdout(), "===========\n")
+	fmt.Fprintf(cfg.Stdout(), "Workload:     %s (%d prompts)\n", source, file.Prompts)
+	fmt.Fprintf(cfg.Stdout(), "Encoding:     %s (estimated, no requests sent)\n", file.Encoding)
+	fmt.Fprintf(cfg.Stdout(), "Per prompt:   avg %.1f, max %d (%s) tokens\n", file.AvgTokens(), file.MaxTokens, file.MaxID)
+	fmt.Fprintf(cfg.Stdout(), "All prompts:  %d tokens\n", file.TotalTokens)
+	fmt.Fprintln(cfg.Stdout())
+	fmt.Fprintf(cfg.Stdout(), "For %d requests:\n", run.Prompts)
+	fmt.Fprintf(cfg.Stdout(), "Prompt:       %d tokens\n", run.TotalTokens)
+	fmt.Fprintf(cfg.Stdout(), "Completion:   up to %d tokens (max_tokens per request)\n", run.MaxOutput)
+	if !pricing.IsZero() {
+		fmt.Fprintf(cfg.Stdout(), "Input cost:   $%.4f\n", pricing.Cost(run.TotalTokens, 0))
+		fmt.Fprintf(cfg.Stdout(), "Max cost:     $%.4f (if every response reaches max_tokens)\n", pricing.Cost(run.TotalTokens, run.MaxOutput))
+	} else {
+		fmt.Fprintf(cfg.Stdout(), "Cost:         pass -price-input/-price-output for an estimate\n")
+	}
+	printQuietSummary(cfg, "count-tokens: prompts=%d prompt_tokens=%d avg=%.1f max=%d requests=%d run_prompt_tokens=%d input_cost=$%.4f",
+		file.Prompts, file.TotalTokens, file.AvgTokens(), file.MaxTokens, run.Prompts, run.TotalTokens, pricing.Cost(run.TotalTokens, 0))
+}
+
+// runLeaderboard runs the benchmark against each target in targetsPath, one
+// after another, and ranks them. Flags other than -provider, -url, -model and
+// -token apply to every target. A target that fails is reported as failed
+// rather than stopping the run.
+func runLeaderboard(cfg *config.GlobalConfig, targetsPath string) {
+	targets, err := runner.LoadLeaderboardTargets(targetsPath, cfg.ProviderType)
+	if err != nil {
+		fatalConfigf("Error: %v", err)
+	}
+
+	// Resolve and validate every target before running any of them
+	cfgs := make([]config.GlobalConfig, len(targets))
+	for i := range targets {
+		t := &targets[i]
+		tcfg := *cfg
+		if t.Provider != "" {
+			tcfg.ProviderType = t.Provider
+		}
+		if t.URL != "" {
+			tcfg.URL = t.URL
+		}
+		if t.Model != "" {
+			tcfg.ModelName = t.Model
+		}
+		switch {
+		case t.Token != "":
+			tcfg.Token = t.Token
+		case t.TokenEnv != "":
+			tcfg.Token = os.Getenv(t.TokenEnv)
+			if tcfg.Token == "" {
+				fatalConfigf("Error: leaderboard target %s: environment variable %s is not set", t.Name, t.TokenEnv)
+			}
+		}
+		if _, err := provider.Get(tcfg.ProviderType); err != nil {
+			fatalConfigf("Error: leaderboard target %s: %v\nAvailable providers: %v", t.Name, err, provider.List())
+		}
+		if tcfg.URL == "" && tcfg.ProviderType != "bedrock" && tcfg.ProviderType != "aliyun" && tcfg.ProviderType != "custom" {
+			fatalConfigf("Error: leaderboard target %s has no url", t.Name)
+		}
+		if tcfg.ModelName == "" {
+			fatalConfigf("Error: leaderboard target %s has no model", t.Name)
+		}
+		validateBenchmarkConfig(&tcfg)
+		t.Provider, t.URL, t.Model = tcfg.ProviderType, tcfg.URL, tcfg.ModelName
+		cfgs[i] = tcfg
+	}
+
+	if cfg.OutputDir == "./output" {
+		cfg.OutputDir = autoOutputDir("leaderboard", cfg)
+	}
+	checkOutputDir(cfg, cfg.OutputDir)
+
+	fmt.Fprintf(cfg.Stdout(), "Leaderboard Mode\n")
+	fmt.Fprintf(cfg.Stdout(), "================\n")
+	fmt.Fprintf(cfg.Stdout(), "Targets:      %d (%s)\n", len(targets), targetsPath)
+	fmt.Fprintf(cfg.Stdout(), "Concurrency:  %d\n", cfg.Concurrency)
+	fmt.Fprintf(cfg.Stdout(), "Requests:     %d per target\n", cfg.TotalRequests)
+	fmt.Fprintf(cfg.Stdout(), "Output:       %s\n", cfg.OutputDir)
+
+	scorers, err := buildScorers(cfg.Scorers)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	var entries []runner.LeaderboardEntry
+	for i, t := range targets {
+		tcfg := &cfgs[i]
+		tcfg.OutputDir = filepath.Join(cfg.OutputDir, fmt.Sprintf("%02d_%s", i+1, sanitizeDirName(t.Name)))
+		fmt.Fprintf(cfg.Stdout(), "\n--- Target %d/%d: %s (%s, %s) ---\n", i+1, len(targets), t.Name, t.Provider, t.Model)
+
+		p, _ := provider.Get(tcfg.ProviderType)
+		r := runner.New(tcfg, p)
+		for _, s := range scorers {
+			r.AddScorer(s)
+		}
+		report, err := r.Run()
+		if err != nil {
+			fmt.Fprintf(cfg.Stdout(), "⚠️  Target %s failed: %v\n", t.Name, err)
+		}
+		entries = append(entries, runner.NewLeaderboardEntry(t, report, tcfg.OutputDir, err))
+	}
+
+	lb := runner.RankLeaderboard(entries)
+	fmt.Fprintf(cfg.Stdout(), "\nLeaderboard\n")
+	fmt.Fprintf(cfg.Stdout(), "===========\n")
+	fmt.Fprintf(cfg.Stdout(), "%-4s %-30s %10s %12s %12s %9s\n", "Rank", "Target", "RPS", "P95 Lat(ms)", "Throughput", "Success")
+	for _, e := range lb.Entries {
+		if e.Err != "" {
+			fmt.Fprintf(cfg.Stdout(), "%-4s %-30s failed: %s\n", "-", e.Name, e.Err)
+			continue
+		}
+		fmt.Fprintf(cfg.Stdout(), "%-4d %-30s %10.2f %12d %12.2f %8.2f%%\n", e.Rank, e.Name, e.RPS, e.P95LatencyMs, e.Throughput, e.SuccessRate*100)
+	}
+	fmt.Fprintln(cfg.Stdout())
+	if err := runner.WriteLeaderboard(cfg.Stdout(), lb, cfg.OutputDir); err != nil {
+		log.Fatalf("Failed to write leaderboard: %v", err)
+	}
+	fmt.Fprintf(cfg.Stdout(), "\nResults saved to: %s\n", cfg.OutputDir)
+	if len(lb.Entries) > 0 && lb.Entries[0].Err == "" {
+		best := lb.Entries[0]
+		printQuietSummary(cfg, "leaderboard: targets=%d best=%s rps=%.2f p95_latency=%dms output=%s",
+			len(lb.Entries), best.Name, best.RPS, best.P95LatencyMs, cfg.OutputDir)
+	} else {
+		printQuietSummary(cfg, "leaderboard: targets=%d all failed output=%s", len(lb.Entries), cfg.OutputDir)
+	}
+}
+
+func runProbeContext(cfg *config.GlobalConfig, maxTokens int) {
+	if maxTokens < 1 {
+		fatalConfigf("Error: -probe-context-max must be positive")
+	}
+
+	// Auto-generate output directory if using default
+	if cfg.OutputDir == "./output" {
+		cfg.OutputDir = autoOutputDir("probe", cfg)
+	}
+	checkOutputDir(cfg, cfg.OutputDir)
+
+	p, err := provider.Get(cfg.ProviderType)
+	if err != nil {
+		fatalConfigf("Error: %v\nAvailable providers: %v", err, provider.List())
+	}
+
+	fmt.Fprintf(cfg.Stdout(), "Context Window Probe\n")
+	fmt.Fprintf(cfg.Stdout(), "====================\n")
+	fmt.Fprintf(cfg.Stdout(), "URL:          %s\n", cfg.URL)
+	fmt.Fprintf(cfg.Stdout(), "Model:        %s\n", cfg.ModelName)
+	fmt.Fprintf(cfg.Stdout(), "Search Limit: %d tokens\n", maxTokens)
+	fmt.Fprintln(cfg.Stdout())
+
+	res, err := probe.NewContextProber(cfg, p, maxTokens).Run()
+	if err != nil {
+		log.Fatalf("Context probe failed: %v", err)
+	}
+
+	path, err := probe.WriteResult(res, cfg.OutputDir)
+	if err != nil {
+		log.Fatalf("Failed to write probe result: %v", err)
+	}
+
+	estimated := ""
+	if res.TokensEstimated {
+		estimated = " (estimated; server did not report usage)"
+	}
+	fmt.Fprintf(cfg.Stdout(), "\n✅ Context probe complete!\n")
+	if res.ReachedLimit {
+		fmt.Fprintf(cfg.Stdout(), "   Max accepted prompt: %d tokens%s\n", res.MaxAcceptedTokens, estimated)
+		fmt.Fprintf(cfg.Stdout(), "   Boundary:            accepted %d words, rejected %d words\n", res.MaxAcceptedWords, res.MinRejectedWords)
+	} else {
+		fmt.Fprintf(cfg.Stdout(), "   No context-length error up to %d tokens%s; raise -probe-context-max to search further\n", res.MaxAcceptedTokens, estimated)
+	}
+	fmt.Fprintf(cfg.Stdout(), "   Result: %s\n", path)
+	printQuietSummary(cfg, "probe-context: max_accepted_tokens=%d reached_limit=%t output=%s", res.MaxAcceptedTokens, res.ReachedLimit, path)
+}
+
+func runProbeCapabilities(cfg *config.GlobalConfig) {
+	if cfg.ProviderType != "openai" {
+		fatalConfigf("Error: -probe checks OpenAI-compatible endpoints and requires -provider openai, got '%s'", cfg.ProviderType)
+	}
+
+	// Auto-generate output directory if using default
+	if cfg.OutputDir == "./output" {
+		cfg.OutputDir = autoOutputDir("capabilities", cfg)
+	}
+	checkOutputDir(cfg, cfg.OutputDir)
+
+	fmt.Fprintf(cfg.Stdout(), "Capability Probe\n")
+	fmt.Fprintf(cfg.Stdout(), "================\n")
+	fmt.Fprintf(cfg.Stdout(), "URL:          %s\n", cfg.URL)
+	fmt.Fprintf(cfg.Stdout(), "Model:        %s\n", cfg.ModelName)
+	fmt.Fprintln(cfg.Stdout())
+
+	res := probe.NewCapabilityProber(cfg).Run()
+	path, err := probe.WriteCapabilityResult(res, cfg.OutputDir)
+	if err != nil {
+		log.Fatalf("Failed to write capability result: %v", err)
+	}
+
+	fmt.Fprintf(cfg.Stdout(), "\n✅ Capability probe complete: %d/%d supported\n", res.Supported, len(res.Capabilities))
+	fmt.Fprintf(cfg.Stdout(), "   %-14s %-9s %s\n", "Capability", "Supported", "Detail")
+	for _, c := range res.Capabilities {
+		mark, detail := "yes", c.Detail
+		if !c.Supported {
+			mark = "no"
+		}
+		if c.Err != "" {
+			detail = "error: " + c.Err
+		}
+		if len(detail) > 80 {
+			detail = detail[:80] + "..."
+		}
+		fmt.Fprintf(cfg.Stdout(), "   %-14s %-9s %s\n", c.Name, mark, detail)
+	}
+	fmt.Fprintf(cfg.Stdout(), "   Result: %s\n", path)
+	printQuietSummary(cfg, "probe: supported=%d/%d output=%s", res.Supported, len(res.Capabilities), path)
+}
+
+func runPrefixCacheTest(cfg *config.GlobalConfig, words, repeats int) {
+	if words < 1 {
+		fatalConfigf("Error: -prefix-cache-words must be positive")
+	}
+	if repeats < 2 {
+		fatalConfigf("Error: -prefix-cache-repeats must be at least 2 (one cold, one warm request)")
+	}
+
+	// Auto-generate output directory if using default
+	if cfg.OutputDir == "./output" {
+		cfg.OutputDir = autoOutputDir("prefixcache", cfg)
+	}
+	checkOutputDir(cfg, cfg.OutputDir)
+
+	p, err := provider.Get(cfg.ProviderType)
+	if err != nil {
+		fatalConfigf("Error: %v\nAvailable providers: %v", err, provider.List())
+	}
+
+	fmt.Fprintf(cfg.Stdout(), "Prefix Cache Test\n")
+	fmt.Fprintf(cfg.Stdout(), "=================\n")
+	fmt.Fprintf(cfg.Stdout(), "URL:          %s\n", cfg.URL)
+	fmt.Fprintf(cfg.Stdout(), "Model:        %s\n", cfg.ModelName)
+	fmt.Fprintf(cfg.Stdout(), "Prefix:       %d words\n", words)
+	fmt.Fprintf(cfg.Stdout(), "Requests:     %d (1 cold + %d warm)\n", repeats, repeats-1)
+	fmt.Fprintln(cfg.Stdout())
+
+	res, err := probe.NewPrefixCacheProber(cfg, p, words, repeats).Run()
+	if err != nil {
+		log.Fatalf("Prefix cache test failed: %v", err)
+	}
+
+	path, err := probe.WritePrefixCacheResult(res, cfg.OutputDir)
+	if err != nil {
+		log.Fatalf("Failed to write prefix cache result: %v", err)
+	}
+
+	fmt.Fprintf(cfg.Stdout(), "\n✅ Prefix cache test complete!\n")
+	fmt.Fprintf(cfg.Stdout(), "   Cold TTFT:  %.2f ms\n", res.ColdTTFTMs)
+	fmt.Fprintf(cfg.Stdout(), "   Warm TTFT:  %.2f ms avg, %.2f ms min\n", res.WarmAvgTTFTMs, res.WarmMinTTFTMs)
+	fmt.Fprintf(cfg.Stdout(), "   Speedup:    %.2fx\n", res.Speedup)
+	if res.Speedup > 0 && res.Speedup < 1.2 {
+		fmt.Fprintf(cfg.Stdout(), "   ⚠️  Little or no speedup; the server may not have prefix caching enabled\n")
+	}
+	fmt.Fprintf(cfg.Stdout(), "   Result: %s\n", path)
+	printQuietSummary(cfg, "prefix-cache: cold_ttft=%.2fms warm_ttft=%.2fms speedup=%.2fx output=%s", res.ColdTTFTMs, res.WarmAvgTTFTMs, res.Speedup, path)
+}
+
+func runConversationTest(cfg *config.GlobalConfig, sessions, turns int) {
+	if sessions < 1 || turns < 1 {
+		fatalConfigf("Error: -conv-sessions and -conv-turns must be positive")
+	}
+
+	// User turns come from -workload-file when given
+	var questions []string
+	if cfg.WorkloadFile != "" {
+		workloads, err := workload.NewLoader().LoadFromFile(cfg.WorkloadFile, cfg.MaxTokens)
+		if err != nil {
+			fatalConfigf("Error: failed to load workloads: %v", err)
+		}
+		for _, w := range workloads {
+			if msgs := w.ToMessages(); len(msgs) > 0 {
+				questions = append(questions, msgs[len(msgs)-1].Content)
+			}
+		}
+	}
+
+	// Auto-generate output directory if using default
+	if cfg.OutputDir == "./output" {
+		cfg.OutputDir = autoOutputDir("conversation", cfg)
+	}
+	checkOutputDir(cfg, cfg.OutputDir)
+
+	p, err := provider.Get(cfg.ProviderType)
+	if err != nil {
+		fatalConfigf("Error: %v\nAvailable providers: %v", err, provider.List())
+	}
+
+	fmt.Fprintf(cfg.Stdout(), "Conversation Growth Test\n")
+	fmt.Fprintf(cfg.Stdout(), "========================\n")
+	fmt.Fprintf(cfg.Stdout(), "URL:          %s\n", cfg.URL)
+	fmt.Fprintf(cfg.Stdout(), "Model:        %s\n", cfg.ModelName)
+	fmt.Fprintf(cfg.Stdout(), "Sessions:     %d parallel\n", sessions)
+	fmt.Fprintf(cfg.Stdout(), "Turns:        %d per session (%d requests)\n", turns, sessions*turns)
+	fmt.Fprintln(cfg.Stdout())
+
+	res := conversation.NewRunner(cfg, p, sessions, turns, questions).Run()
+	path, err := conversation.WriteResult(res, cfg.OutputDir)
+	if err != nil {
+		log.Fatalf("Failed to write conversation result: %v", err)
+	}
+
+	fmt.Fprintf(cfg.Stdout(), "\n✅ Conversation growth test complete! (%d/%d requests succeeded)\n\n", res.Success, res.TotalRequests)
+	fmt.Fprintf(cfg.Stdout(), "  Turn  Context tokens  Avg TTFT  P95 TTFT  Avg latency  OK\n")
+	for _, t := range res.PerTurn {
+		estimated := " "
+		if t.ContextEstimated {
+			estimated = "~"
+		}
+		fmt.Fprintf(cfg.Stdout(), "  %4d  %13.0f%s  %6.0fms  %6dms  %9.0fms  %d/%d\n",
+			t.Turn, t.AvgContextTokens, estimated, t.AvgTTFTMs, t.P95TTFTMs, t.AvgLatencyMs, t.Success, t.Requests)
+	}
+	fmt.Fprintf(cfg.Stdout(), "\n  TTFT growth: %.2f ms per 1K context tokens (~ = estimated tokens)\n", res.TTFTMsPer1KTokens)
+	fmt.Fprintf(cfg.Stdout(), "  Result: %s\n", path)
+	printQuietSummary(cfg, "conversation: success=%d/%d ttft_growth=%.2fms_per_1k_tokens output=%s", res.Success, res.TotalRequests, res.TTFTMsPer1KTokens, path)
 }
 
 func runFullTest(cfg *config.GlobalConfig) {
-	// Use moderate benchmark settings
-	moderateCfg := config.ModerateBenchmarkConfig()
-	moderateCfg.URL = cfg.URL
-	moderateCfg.ModelName = cfg.ModelName
-	moderateCfg.Token = cfg.Token
-	moderateCfg.InsecureTLS = cfg.InsecureTLS
-	moderateCfg.CACertPath = cfg.CACertPath
-	moderateCfg.Verbose = cfg.Verbose
-	moderateCfg.DisableThinking = cfg.DisableThinking
+	phases := fulltest.Phases
+	if cfg.FullTestPhases != "" {
+		var err error
+		if phases, err = fulltest.ParsePhases(cfg.FullTestPhases); err != nil {
+			fatalConfigf("Error: invalid -phases: %v", err)
+		}
+	}
+	if cfg.ContextLadder != "" {
+		if _, err := fulltest.ParseContextLadder(cfg.ContextLadder); err != nil {
+			fatalConfigf("Error: invalid -context-ladder: %v", err)
+		}
+	}
+	if cfg.ContextMaxTokens <= 0 {
+		fatalConfigf("Error: -context-max-tokens must be positive")
+	}
+
+	// Start from the flags so request and connection settings (auth, TLS,
+	// timeouts, sampling, -extra-body, ...) reach every phase, then apply the
+	// moderate load of the full test and drop the workload and run-control
+	// options of benchmark mode
+	moderate := config.ModerateBenchmarkConfig()
+	moderateCfg := new(config.GlobalConfig)
+	*moderateCfg = *cfg
+	moderateCfg.Concurrency = moderate.Concurrency
+	moderateCfg.TotalRequests = moderate.TotalRequests
+	moderateCfg.Warmup = moderate.Warmup
+	moderateCfg.MaxTokens = moderate.MaxTokens
+	moderateCfg.TimeoutSec = moderate.TimeoutSec
+	moderateCfg.ProviderType = moderate.ProviderType // The phases build OpenAI-format requests
+	moderateCfg.DurationSec = 0
+	moderateCfg.RPS = 0
+	moderateCfg.MaxInFlight = 0
+	moderateCfg.WarmupStablePct = 0
+	moderateCfg.AbortAfter = 0
+	moderateCfg.N = 0
+	moderateCfg.MinTokens = 0
+	moderateCfg.IgnoreEOS = false
+	moderateCfg.JSONMode = false
+	moderateCfg.JSONSchema = ""
+	moderateCfg.Logprobs = false
+	moderateCfg.WorkloadFile = ""
+	moderateCfg.PromptTemplate = ""
+	moderateCfg.PromptVars = ""
+	moderateCfg.VarsFile = ""
+	moderateCfg.MessagesFile = ""
+	moderateCfg.StreamingStats = false
+	moderateCfg.PercentileCSV = false
+	moderateCfg.SampleRate = 0
+	moderateCfg.RecordAll = false
+	moderateCfg.CheckpointSec = 0
+	moderateCfg.SnapshotSec = 0
+	moderateCfg.Resume = false
+	moderateCfg.SelfMonitor = false
+	moderateCfg.CPUProfile = false
+	moderateCfg.MemProfile = false
 
 	// Auto-generate output directory
-	modelName := cfg.ModelName
-	modelName = strings.ReplaceAll(modelName, "/", "_")
-	modelName = strings.ReplaceAll(modelName, ":", "_")
-	modelName = strings.ReplaceAll(modelName, " ", "_")
-	timestamp := time.Now().Format("20060102_150405")
-	outputDir := filepath.Join("output", fmt.Sprintf("fulltest_%s_%s", modelName, timestamp))
-
-	// Find transcript file - try relative to working directory first
+	outputDir := autoOutputDir("fulltest", cfg)
+	checkOutputDir(cfg, outputDir)
+
+	transcriptFile := ""
+	if slices.Contains(phases, fulltest.PhaseSummary) {
+		transcriptFile = findTranscript(cfg)
+	}
+
+	// Get the provider
+	p, err := provider.Get(moderateCfg.ProviderType)
+	if err != nil {
+		fatalConfigf("Error: %v\nAvailable providers: %v", err, provider.List())
+	}
+
+	// Create and run full test
+	r := fulltest.NewRunner(moderateCfg, p, transcriptFile, outputDir)
+	report, err := r.Run()
+	if err != nil {
+		log.Fatalf("Full test failed: %v", err)
+	}
+
+	fmt.Fprintln(cfg.Stdout())
+	fmt.Fprintln(cfg.Stdout(), "╔════════════════════════════════════════════════════════════════╗")
+	fmt.Fprintln(cfg.Stdout(), "║                    Full Test Complete!                         ║")
+	fmt.Fprintln(cfg.Stdout(), "╚════════════════════════════════════════════════════════════════╝")
+	fmt.Fprintln(cfg.Stdout())
+	fmt.Fprintf(cfg.Stdout(), "📊 Total Duration: %.2f seconds\n", report.TotalDuration.Seconds())
+	fmt.Fprintf(cfg.Stdout(), "📁 Results saved to: %s\n", outputDir)
+	fmt.Fprintf(cfg.Stdout(), "📄 Full report: %s/full_test_report.md\n", outputDir)
+	printQuietSummary(cfg, "full-test: done in %.2fs output=%s", report.TotalDuration.Seconds(), outputDir)
+}
+
+// findTranscript returns the meeting transcript of the full-test summary
+// phase: example/text.txt relative to the working directory or the
+// executable, else the embedded sample written to a temp file, else "" to
+// skip the phase.
+func findTranscript(cfg *config.GlobalConfig) string {
+	// Try relative to working directory first
 	transcriptFile := "example/text.txt"
 	if _, err := os.Stat(transcriptFile); os.IsNotExist(err) {
 		// Try relative to executable
@@ -298,7 +1395,7 @@ func runFullTest(cfg *config.GlobalConfig) {
 				tmpFile := filepath.Join(os.TempDir(), "llm-benchmark-transcript.txt")
 				if err := os.WriteFile(tmpFile, embeddedData, 0644); err == nil {
 					transcriptFile = tmpFile
-					fmt.Println("   Using embedded transcript sample")
+					fmt.Fprintln(cfg.Stdout(), "   Using embedded transcript sample")
 				} else {
 					log.Printf("Warning: failed to write embedded transcript: %v", err)
 					transcriptFile = ""
@@ -309,72 +1406,45 @@ func runFullTest(cfg *config.GlobalConfig) {
 			}
 		}
 	}
-
-	// Get the provider
-	p, err := provider.Get(moderateCfg.ProviderType)
-	if err != nil {
-		log.Fatalf("Error: %v\nAvailable providers: %v", err, provider.List())
-	}
-
-	// Create and run full test
-	r := fulltest.NewRunner(moderateCfg, p, transcriptFile, outputDir)
-	report, err := r.Run()
-	if err != nil {
-		log.Fatalf("Full test failed: %v", err)
-	}
-
-	fmt.Println()
-	fmt.Println("╔════════════════════════════════════════════════════════════════╗")
-	fmt.Println("║                    Full Test Complete!                         ║")
-	fmt.Println("╚════════════════════════════════════════════════════════════════╝")
-	fmt.Println()
-	fmt.Printf("📊 Total Duration: %.2f seconds\n", report.TotalDuration.Seconds())
-	fmt.Printf("📁 Results saved to: %s\n", outputDir)
-	fmt.Printf("📄 Full report: %s/full_test_report.md\n", outputDir)
+	return transcriptFile
 }
 
 func runSummaryBench(cfg *config.GlobalConfig, transcriptFile string, chunkSize, concurrency, requests int) {
 	// Auto-generate output directory
-	modelName := cfg.ModelName
-	modelName = strings.ReplaceAll(modelName, "/", "_")
-	modelName = strings.ReplaceAll(modelName, ":", "_")
-	modelName = strings.ReplaceAll(modelName, " ", "_")
-	timestamp := time.Now().Format("20060102_150405")
-	outputDir := filepath.Join("output", fmt.Sprintf("summarybench_%s_%s", modelName, timestamp))
-
-	fmt.Println()
-	fmt.Println("╔════════════════════════════════════════════════════════════════╗")
-	fmt.Println("║         LLM Benchmark Kit - Summary Benchmark Mode             ║")
-	fmt.Println("╚════════════════════════════════════════════════════════════════╝")
-	fmt.Println()
-	fmt.Printf("📋 Model:       %s\n", cfg.ModelName)
-	fmt.Printf("🔗 URL:         %s\n", cfg.URL)
-	fmt.Printf("👥 Concurrency: %d\n", concurrency)
-	fmt.Printf("📝 Requests:    %d\n", requests)
-	fmt.Printf("📏 Chunk Size:  %d chars\n", chunkSize)
-	fmt.Printf("📁 Output:      %s\n", outputDir)
+	outputDir := autoOutputDir("summarybench", cfg)
+	checkOutputDir(cfg, outputDir)
+
+	fmt.Fprintln(cfg.Stdout())
+	fmt.Fprintln(cfg.Stdout(), "╔════════════════════════════════════════════════════════════════╗")
+	fmt.Fprintln(cfg.Stdout(), "║         LLM Benchmark Kit - Summary Benchmark Mode             ║")
+	fmt.Fprintln(cfg.Stdout(), "╚════════════════════════════════════════════════════════════════╝")
+	fmt.Fprintln(cfg.Stdout())
+	fmt.Fprintf(cfg.Stdout(), "📋 Model:       %s\n", cfg.ModelName)
+	fmt.Fprintf(cfg.Stdout(), "🔗 URL:         %s\n", cfg.URL)
+	fmt.Fprintf(cfg.Stdout(), "👥 Concurrency: %d\n", concurrency)
+	fmt.Fprintf(cfg.Stdout(), "📝 Requests:    %d\n", requests)
+	fmt.Fprintf(cfg.Stdout(), "📏 Chunk Size:  %d chars\n", chunkSize)
+	fmt.Fprintf(cfg.Stdout(), "📁 Output:      %s\n", outputDir)
 
 	bench := summarybench.NewBenchmark(cfg, concurrency, requests, chunkSize)
-	_, err := bench.Run(transcriptFile, outputDir)
+	report, err := bench.Run(transcriptFile, outputDir)
 	if err != nil {
 		log.Fatalf("Summary benchmark failed: %v", err)
 	}
 
-	fmt.Println()
-	fmt.Println("╔════════════════════════════════════════════════════════════════╗")
-	fmt.Println("║              Summary Benchmark Complete!                        ║")
-	fmt.Println("╚════════════════════════════════════════════════════════════════╝")
-	fmt.Printf("📁 Results saved to: %s\n", outputDir)
+	fmt.Fprintln(cfg.Stdout())
+	fmt.Fprintln(cfg.Stdout(), "╔════════════════════════════════════════════════════════════════╗")
+	fmt.Fprintln(cfg.Stdout(), "║              Summary Benchmark Complete!                        ║")
+	fmt.Fprintln(cfg.Stdout(), "╚════════════════════════════════════════════════════════════════╝")
+	fmt.Fprintf(cfg.Stdout(), "📁 Results saved to: %s\n", outputDir)
+	printQuietSummary(cfg, "summary-bench: success=%.1f%% (%d/%d) p95_latency=%.0fms throughput=%.1ftok/s output=%s",
+		report.Stats.SuccessRate, report.Stats.SuccessCount, report.Stats.TotalRequests, report.Stats.LatencyP95, report.Stats.OverallTokensPerSecond, outputDir)
 }
 
 func runSoakTest(cfg *config.GlobalConfig, duration, concurrency, window, metricsInterval, longConcurrency, longMaxTokens int) {
 	// Auto-generate output directory
-	modelName := cfg.ModelName
-	modelName = strings.ReplaceAll(modelName, "/", "_")
-	modelName = strings.ReplaceAll(modelName, ":", "_")
-	modelName = strings.ReplaceAll(modelName, " ", "_")
-	timestamp := time.Now().Format("20060102_150405")
-	outputDir := filepath.Join("output", fmt.Sprintf("soaktest_%s_%s", modelName, timestamp))
+	outputDir := autoOutputDir("soaktest", cfg)
+	checkOutputDir(cfg, outputDir)
 
 	soakCfg := &soaktest.SoakConfig{
 		DurationSec:     duration,
@@ -385,28 +1455,28 @@ func runSoakTest(cfg *config.GlobalConfig, duration, concurrency, window, metric
 		LongMaxTokens:   longMaxTokens,
 	}
 
-	fmt.Println()
-	fmt.Println("╔════════════════════════════════════════════════════════════════╗")
-	fmt.Println("║         LLM Benchmark Kit - Soak Test Mode                     ║")
-	fmt.Println("╚════════════════════════════════════════════════════════════════╝")
-	fmt.Println()
-	fmt.Printf("📋 Model:            %s\n", cfg.ModelName)
-	fmt.Printf("🔗 URL:              %s\n", cfg.URL)
-	fmt.Printf("👥 Concurrency:      %d\n", concurrency)
+	fmt.Fprintln(cfg.Stdout())
+	fmt.Fprintln(cfg.Stdout(), "╔════════════════════════════════════════════════════════════════╗")
+	fmt.Fprintln(cfg.Stdout(), "║         LLM Benchmark Kit - Soak Test Mode                     ║")
+	fmt.Fprintln(cfg.Stdout(), "╚════════════════════════════════════════════════════════════════╝")
+	fmt.Fprintln(cfg.Stdout())
+	fmt.Fprintf(cfg.Stdout(), "📋 Model:            %s\n", cfg.ModelName)
+	fmt.Fprintf(cfg.Stdout(), "🔗 URL:              %s\n", cfg.URL)
+	fmt.Fprintf(cfg.Stdout(), "👥 Concurrency:      %d\n", concurrency)
 	if longConcurrency > 0 {
-		fmt.Printf("   ├─ Short workers: %d (max_tokens=%d)\n", concurrency-longConcurrency, cfg.MaxTokens)
-		fmt.Printf("   └─ Long workers:  %d (max_tokens=%d)\n", longConcurrency, longMaxTokens)
+		fmt.Fprintf(cfg.Stdout(), "   ├─ Short workers: %d (max_tokens=%d)\n", concurrency-longConcurrency, cfg.MaxTokens)
+		fmt.Fprintf(cfg.Stdout(), "   └─ Long workers:  %d (max_tokens=%d)\n", longConcurrency, longMaxTokens)
 	}
-	fmt.Printf("⏱️  Duration:         %ds\n", duration)
-	fmt.Printf("📊 Window Interval:  %ds\n", window)
-	fmt.Printf("💻 Metrics Interval: %ds\n", metricsInterval)
-	fmt.Printf("📁 Output:           %s\n", outputDir)
-	fmt.Println()
+	fmt.Fprintf(cfg.Stdout(), "⏱️  Duration:         %ds\n", duration)
+	fmt.Fprintf(cfg.Stdout(), "📊 Window Interval:  %ds\n", window)
+	fmt.Fprintf(cfg.Stdout(), "💻 Metrics Interval: %ds\n", metricsInterval)
+	fmt.Fprintf(cfg.Stdout(), "📁 Output:           %s\n", outputDir)
+	fmt.Fprintln(cfg.Stdout())
 
 	// Get the provider
 	p, err := provider.Get(cfg.ProviderType)
 	if err != nil {
-		log.Fatalf("Error: %v\nAvailable providers: %v", err, provider.List())
+		fatalConfigf("Error: %v\nAvailable providers: %v", err, provider.List())
 	}
 
 	r := soaktest.NewRunner(cfg, soakCfg, p, outputDir)
@@ -415,51 +1485,62 @@ func runSoakTest(cfg *config.GlobalConfig, duration, concurrency, window, metric
 		log.Fatalf("Soak test failed: %v", err)
 	}
 
-	fmt.Println()
-	fmt.Println("╔════════════════════════════════════════════════════════════════╗")
-	fmt.Println("║                  Soak Test Complete!                            ║")
-	fmt.Println("╚════════════════════════════════════════════════════════════════╝")
-	fmt.Println()
-	fmt.Printf("📊 Duration:     %ds\n", report.DurationSec)
-	fmt.Printf("📝 Total Reqs:   %d\n", report.TotalRequests)
-	fmt.Printf("✅ Success:      %d (%.1f%%)\n", report.TotalSuccess, report.SuccessRate*100)
-	fmt.Printf("❌ Failure:      %d\n", report.TotalFailure)
-	fmt.Printf("🚀 Overall RPS:  %.2f\n", report.OverallRPS)
-	fmt.Printf("⚡ Avg TTFT:     %.0fms\n", report.AvgTTFTMs)
-	fmt.Printf("⏱️  Avg Latency:  %.0fms\n", report.AvgLatencyMs)
-	fmt.Printf("📊 Windows:      %d\n", len(report.Snapshots))
-	fmt.Printf("📁 Results:      %s\n", outputDir)
-	fmt.Printf("📄 HTML Report:  %s/soak_report.html\n", outputDir)
-	fmt.Printf("📄 JSON Report:  %s/soak_report.json\n", outputDir)
-	fmt.Printf("📄 Request Log:  %s/soak_log.jsonl\n", outputDir)
+	fmt.Fprintln(cfg.Stdout())
+	fmt.Fprintln(cfg.Stdout(), "╔════════════════════════════════════════════════════════════════╗")
+	fmt.Fprintln(cfg.Stdout(), "║                  Soak Test Complete!                            ║")
+	fmt.Fprintln(cfg.Stdout(), "╚════════════════════════════════════════════════════════════════╝")
+	fmt.Fprintln(cfg.Stdout())
+	fmt.Fprintf(cfg.Stdout(), "📊 Duration:     %ds\n", report.DurationSec)
+	fmt.Fprintf(cfg.Stdout(), "📝 Total Reqs:   %d\n", report.TotalRequests)
+	fmt.Fprintf(cfg.Stdout(), "✅ Success:      %d (%.1f%%)\n", report.TotalSuccess, report.SuccessRate*100)
+	fmt.Fprintf(cfg.Stdout(), "❌ Failure:      %d\n", report.TotalFailure)
+	fmt.Fprintf(cfg.Stdout(), "🚀 Overall RPS:  %.2f\n", report.OverallRPS)
+	fmt.Fprintf(cfg.Stdout(), "⚡ Avg TTFT:     %.0fms\n", report.AvgTTFTMs)
+	fmt.Fprintf(cfg.Stdout(), "⏱️  Avg Latency:  %.0fms\n", report.AvgLatencyMs)
+	fmt.Fprintf(cfg.Stdout(), "📊 Windows:      %d\n", len(report.Snapshots))
+	fmt.Fprintf(cfg.Stdout(), "📁 Results:      %s\n", outputDir)
+	fmt.Fprintf(cfg.Stdout(), "📄 HTML Report:  %s/soak_report.html\n", outputDir)
+	fmt.Fprintf(cfg.Stdout(), "📄 JSON Report:  %s/soak_report.json\n", outputDir)
+	fmt.Fprintf(cfg.Stdout(), "📄 Request Log:  %s/soak_log.jsonl\n", outputDir)
+	printQuietSummary(cfg, "soak: success=%.1f%% (%d/%d) avg_latency=%.0fms rps=%.2f output=%s",
+		report.SuccessRate*100, report.TotalSuccess, report.TotalRequests, report.AvgLatencyMs, report.OverallRPS, outputDir)
 }
 
-func runSoakReportRebuild(inputDir, outputDir string) {
-	fmt.Println()
-	fmt.Println("╔════════════════════════════════════════════════════════════════╗")
-	fmt.Println("║              Soak Report Rebuild Mode                           ║")
-	fmt.Println("╚════════════════════════════════════════════════════════════════╝")
-	fmt.Println()
-	fmt.Printf("📂 Input dir:  %s\n", inputDir)
-	fmt.Printf("📁 Output dir: %s\n", outputDir)
-	fmt.Println()
+func runSoakReportRebuild(cfg *config.GlobalConfig, inputDir, outputDir string) {
+	fmt.Fprintln(cfg.Stdout())
+	fmt.Fprintln(cfg.Stdout(), "╔════════════════════════════════════════════════════════════════╗")
+	fmt.Fprintln(cfg.Stdout(), "║              Soak Report Rebuild Mode                           ║")
+	fmt.Fprintln(cfg.Stdout(), "╚════════════════════════════════════════════════════════════════╝")
+	fmt.Fprintln(cfg.Stdout())
+	fmt.Fprintf(cfg.Stdout(), "📂 Input dir:  %s\n", inputDir)
+	fmt.Fprintf(cfg.Stdout(), "📁 Output dir: %s\n", outputDir)
+	fmt.Fprintln(cfg.Stdout())
 
 	report, err := soaktest.RebuildReportFromDir(inputDir, outputDir)
 	if err != nil {
 		log.Fatalf("Failed to rebuild report: %v", err)
 	}
 
-	fmt.Println("✅ Report rebuilt successfully!")
-	fmt.Println()
-	fmt.Printf("📊 Duration:     %ds\n", report.DurationSec)
-	fmt.Printf("📝 Total Reqs:   %d\n", report.TotalRequests)
-	fmt.Printf("✅ Success:      %d (%.1f%%)\n", report.TotalSuccess, report.SuccessRate*100)
-	fmt.Printf("❌ Failure:      %d\n", report.TotalFailure)
-	fmt.Printf("🚀 Overall RPS:  %.2f\n", report.OverallRPS)
-	fmt.Printf("⚡ Avg TTFT:     %.0fms\n", report.AvgTTFTMs)
-	fmt.Printf("⏱️  Avg Latency:  %.0fms\n", report.AvgLatencyMs)
-	fmt.Printf("📊 Windows:      %d\n", len(report.Snapshots))
-	fmt.Printf("📁 Results:      %s\n", outputDir)
-	fmt.Printf("📄 HTML Report:  %s/soak_report.html\n", outputDir)
-	fmt.Printf("📄 JSON Report:  %s/soak_report.json\n", outputDir)
+	fmt.Fprintln(cfg.Stdout(), "✅ Report rebuilt successfully!")
+	fmt.Fprintln(cfg.Stdout())
+	fmt.Fprintf(cfg.Stdout(), "📊 Duration:     %ds\n", report.DurationSec)
+	fmt.Fprintf(cfg.Stdout(), "📝 Total Reqs:   %d\n", report.TotalRequests)
+	fmt.Fprintf(cfg.Stdout(), "✅ Success:      %d (%.1f%%)\n", report.TotalSuccess, report.SuccessRate*100)
+	fmt.Fprintf(cfg.Stdout(), "❌ Failure:      %d\n", report.TotalFailure)
+	fmt.Fprintf(cfg.Stdout(), "🚀 Overall RPS:  %.2f\n", report.OverallRPS)
+	fmt.Fprintf(cfg.Stdout(), "⚡ Avg TTFT:     %.0fms\n", report.AvgTTFTMs)
+	fmt.Fprintf(cfg.Stdout(), "⏱️  Avg Latency:  %.0fms\n", report.AvgLatencyMs)
+	fmt.Fprintf(cfg.Stdout(), "📊 Windows:      %d\n", len(report.Snapshots))
+	fmt.Fprintf(cfg.Stdout(), "📁 Results:      %s\n", outputDir)
+	fmt.Fprintf(cfg.Stdout(), "📄 HTML Report:  %s/soak_report.html\n", outputDir)
+	fmt.Fprintf(cfg.Stdout(), "📄 JSON Report:  %s/soak_report.json\n", outputDir)
+	printQuietSummary(cfg, "soak-report: rebuilt %d requests output=%s", report.TotalRequests, outputDir)
+}
+
+// printQuietSummary prints a single result line to stdout in quiet mode.
+func printQuietSummary(cfg *config.GlobalConfig, format string, args ...any) {
+	if !cfg.Quiet {
+		return
+	}
+	fmt.Printf(format+"\n", args...)
 }
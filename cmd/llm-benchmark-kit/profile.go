@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/pprof"
+
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/config"
+)
+
+// startProfiles starts the profiles of the tool itself requested by
+// -cpuprofile/-memprofile, writing them to cfg.OutputDir. The returned stop
+// function ends CPU profiling and writes the heap profile.
+func startProfiles(cfg *config.GlobalConfig) (func() error, error) {
+	if !cfg.CPUProfile && !cfg.MemProfile {
+		return func() error { return nil }, nil
+	}
+	if err := os.MkdirAll(cfg.OutputDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	var cpuFile *os.File
+	if cfg.CPUProfile {
+		f, err := os.Create(filepath.Join(cfg.OutputDir, "cpu.pprof"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create CPU profile: %w", err)
+		}
+		if err := pprof.StartCPUProfile(f); err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to start CPU profile: %w", err)
+		}
+		cpuFile = f
+	}
+
+	return func() error {
+		if cpuFile != nil {
+			pprof.StopCPUProfile()
+			if err := cpuFile.Close(); err != nil {
+				return fmt.Errorf("failed to write CPU profile: %w", err)
+			}
+			fmt.Fprintf(cfg.Stdout(), "  - CPU profile: %s\n", cpuFile.Name())
+		}
+		if cfg.MemProfile {
+			path := filepath.Join(cfg.OutputDir, "mem.pprof")
+			f, err := os.Create(path)
+			if err != nil {
+				return fmt.Errorf("failed to create memory profile: %w", err)
+			}
+			defer f.Close()
+			runtime.GC() // Up-to-date statistics for live objects
+			if err := pprof.Lookup("allocs").WriteTo(f, 0); err != nil {
+				return fmt.Errorf("failed to write memory profile: %w", err)
+			}
+			fmt.Fprintf(cfg.Stdout(), "  - Memory profile: %s\n", path)
+		}
+		return nil
+	}, nil
+}
@@ -0,0 +1,344 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// subcommand describes a CLI subcommand. Each subcommand parses its own flag
+// set holding only the flags it uses, so a flag of another mode is rejected
+// instead of being silently ignored.
+type subcommand struct {
+	name     string
+	modeFlag string   // Legacy mode flag the command sets, or flag that takes the positional argument
+	argName  string   // Name of the positional argument (empty = none); required unless modeFlag is given as a flag
+	flags    []string // Flags the command accepts besides modeFlag
+	desc     string
+}
+
+// Flag groups, named after what they configure. Subcommands combine the
+// groups of the settings they read.
+var (
+	// consoleFlags control console output.
+	consoleFlags = []string{"verbose", "v", "vv", "quiet"}
+
+	// outFlags choose where and under which name results are written.
+	outFlags = []string{"out", "out-overwrite", "run-name"}
+
+	// endpointFlags select the endpoint, authenticate and tune its connections.
+	endpointFlags = []string{
+		"url", "model", "token", "api-key", "token-file", "list-models", "log-secrets",
+		"provider", "region", "custom-cmd",
+		"timeout", "connect-timeout", "first-byte-timeout", "insecure", "ca-cert", "no-keepalive",
+		"max-idle-conns", "max-idle-conns-per-host", "max-conns-per-host",
+	}
+
+	// samplingFlags are sent by every mode that builds its own requests.
+	samplingFlags = []string{"temperature", "top-p", "seed", "stop"}
+
+	// promptFlags add to every request's messages and body.
+	promptFlags = []string{"system-prompt", "system-prompt-file", "extra-body", "no-thinking"}
+
+	// requestFlags are sent by the providers.
+	requestFlags = slices.Concat(samplingFlags, promptFlags, []string{"max-tokens", "min-tokens", "ignore-eos", "logprobs"})
+
+	// loadFlags drive the benchmark runner: load shape, workload, scoring and reports.
+	loadFlags = []string{
+		"concurrency", "total-requests", "duration", "rps", "per-key-rps", "max-in-flight",
+		"retries", "retry-backoff-ms", "count-retry-latency", "abort-after-failures",
+		"warmup", "warmup-until-stable", "warmup-window", "warmup-max", "wait-ready", "wait-ready-timeout",
+		"n", "json-mode", "json-schema", "score", "max-response-chars", "strict-sse",
+		"token-mode", "ttft-min-chars", "think-tag-filter", "chars-per-token", "price-input", "price-output",
+		"workload-file", "prompt-template", "vars", "vars-file", "bust-cache", "messages-file",
+		"output-format", "percentile-csv", "streaming-stats", "cdn-charts", "record-all",
+		"checkpoint-interval", "snapshot-interval", "sample-rate", "sample-middle-frames", "self-monitor",
+	}
+
+	// runFlags apply to a single benchmark run, not to each leaderboard target.
+	runFlags = []string{
+		"sla-p95-latency-ms", "sla-p99-latency-ms", "sla-p95-ttft-ms", "sla-p99-ttft-ms", "sla-success-rate", "sla-min-rps",
+		"fail-on-regression", "resume", "tui", "cpuprofile", "memprofile",
+	}
+
+	// reportFlags shape reports rebuilt from recorded results.
+	reportFlags = []string{"token-mode", "price-input", "price-output", "output-format", "percentile-csv", "streaming-stats", "cdn-charts"}
+)
+
+var subcommands = []subcommand{
+	{
+		name:  "benchmark",
+		flags: slices.Concat(endpointFlags, requestFlags, loadFlags, runFlags, []string{"repeat", "compare-baseline-dir"}, outFlags, consoleFlags),
+		desc:  "Run performance tests against an LLM API (default)",
+	},
+	{
+		name: "compare", modeFlag: "compare-baseline", argName: "summary.json",
+		flags: slices.Concat(endpointFlags, requestFlags, loadFlags, runFlags, outFlags, consoleFlags),
+		desc:  "Benchmark and print metric deltas against an earlier run",
+	},
+	{
+		name: "summary", modeFlag: "transcript-file", argName: "transcript",
+		flags: slices.Concat(endpointFlags, samplingFlags, []string{
+			"chunk-size", "chunk-tokens", "transcript-encoding", "meeting-time", "summary-auto-extend",
+			"summary-concurrency", "no-intermediate", "stream-summary", "intermediate-format",
+		}, outFlags, consoleFlags),
+		desc: "Summarize a meeting transcript",
+	},
+	{
+		name: "summarybench", modeFlag: "summary-bench",
+		flags: slices.Concat(endpointFlags, samplingFlags, []string{
+			"transcript-file", "chunk-size", "transcript-encoding", "sb-concurrency", "sb-requests",
+			"concurrency", "total-requests", "retries", "retry-backoff-ms", "count-retry-latency",
+		}, outFlags, consoleFlags),
+		desc: "Concurrent meeting summary benchmark",
+	},
+	{
+		name: "fulltest", modeFlag: "full-test",
+		flags: slices.Concat(endpointFlags, samplingFlags, promptFlags, []string{
+			"phases", "turn-delay-ms", "turn-jitter-ms", "context-ladder", "context-filler-file",
+			"context-max-tokens", "fc-cases", "cdn-charts",
+		}, outFlags, consoleFlags),
+		desc: "Run the complete test suite",
+	},
+	{
+		name: "soak", modeFlag: "soak",
+		flags: slices.Concat(endpointFlags, requestFlags, []string{
+			"workload-file", "soak-duration", "soak-concurrency", "soak-window",
+			"soak-metrics-interval", "soak-long-concurrency", "soak-long-max-tokens",
+		}, outFlags, consoleFlags),
+		desc: "Long-running stability/endurance test",
+	},
+	{
+		name: "soak-report", modeFlag: "soak-report", argName: "dir",
+		flags: slices.Concat([]string{"soak-report-output"}, consoleFlags),
+		desc:  "Rebuild a soak report from logs",
+	},
+	{
+		name: "probe", modeFlag: "probe",
+		flags: slices.Concat(endpointFlags, outFlags, consoleFlags),
+		desc:  "Check which OpenAI API features the endpoint supports",
+	},
+	{
+		name: "probe-context", modeFlag: "probe-context",
+		flags: slices.Concat(endpointFlags, requestFlags, []string{"probe-context-max"}, outFlags, consoleFlags),
+		desc:  "Discover the effective context window",
+	},
+	{
+		name: "prefix-cache", modeFlag: "prefix-cache-test",
+		flags: slices.Concat(endpointFlags, requestFlags, []string{"prefix-cache-words", "prefix-cache-repeats"}, outFlags, consoleFlags),
+		desc:  "Measure prefix-cache speedup (cold vs warm TTFT)",
+	},
+	{
+		name: "conversation", modeFlag: "conversation",
+		flags: slices.Concat(endpointFlags, requestFlags, []string{
+			"workload-file", "conv-sessions", "conv-turns", "turn-delay-ms", "turn-jitter-ms",
+		}, outFlags, consoleFlags),
+		desc: "Parallel chat sessions with growing context",
+	},
+	{
+		name: "replay", modeFlag: "replay", argName: "results.jsonl",
+		flags: slices.Concat([]string{"model"}, reportFlags, []string{"out", "run-name"}, consoleFlags),
+		desc:  "Regenerate reports from results.jsonl",
+	},
+	{
+		name: "count-tokens", modeFlag: "count-tokens-only",
+		flags: slices.Concat([]string{
+			"model", "workload-file", "messages-file", "system-prompt", "system-prompt-file",
+			"total-requests", "max-tokens", "price-input", "price-output",
+		}, consoleFlags),
+		desc: "Estimate workload prompt tokens and cost (no requests)",
+	},
+	{
+		name: "leaderboard", modeFlag: "leaderboard", argName: "targets.json",
+		flags: slices.Concat(endpointFlags, requestFlags, loadFlags, outFlags, consoleFlags),
+		desc:  "Benchmark several targets and rank them",
+	},
+}
+
+// parse parses the command line args, including the program name, into o.
+// "<command> [arg] [flags]" parses the command's own flag set; without a
+// command every flag is accepted as before subcommands existed, and flags of
+// two modes are rejected. Errors and help are written to out; -h and help
+// return flag.ErrHelp.
+func (o *options) parse(args []string, out io.Writer) error {
+	prog, args := args[0], args[1:]
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		err := parseFlagSet(o.fs, args, out, func() { printUsage(out, prog) }, prog+" help")
+		if err != nil {
+			return err
+		}
+		o.fs.Visit(func(f *flag.Flag) { o.set[f.Name] = true })
+		return reportErr(out, checkModeFlags(o.set))
+	}
+
+	name := args[0]
+	if name == "help" {
+		if len(args) > 1 {
+			sc := findSubcommand(args[1])
+			if sc == nil {
+				return reportErr(out, fmt.Errorf("unknown command %q (run '%s help' for usage)", args[1], prog))
+			}
+			sc.printUsage(out, prog, sc.flagSet(o))
+			return flag.ErrHelp
+		}
+		printUsage(out, prog)
+		return flag.ErrHelp
+	}
+	sc := findSubcommand(name)
+	if sc == nil {
+		return reportErr(out, fmt.Errorf("unknown command %q (run '%s help' for usage)", name, prog))
+	}
+
+	rest := args[1:]
+	arg := ""
+	if sc.argName != "" && len(rest) > 0 && !strings.HasPrefix(rest[0], "-") {
+		arg, rest = rest[0], rest[1:]
+	}
+	fs := sc.flagSet(o)
+	if err := parseFlagSet(fs, rest, out, func() { sc.printUsage(out, prog, fs) }, prog+" help "+sc.name); err != nil {
+		return err
+	}
+	fs.Visit(func(f *flag.Flag) { o.set[f.Name] = true })
+
+	switch {
+	case sc.modeFlag == "":
+	case sc.argName == "":
+		o.fs.Set(sc.modeFlag, "true")
+	case arg != "":
+		o.fs.Set(sc.modeFlag, arg)
+	case !o.set[sc.modeFlag]:
+		// The argument may still be given as a flag, e.g. "summary -transcript-file x"
+		return reportErr(out, fmt.Errorf("%s requires <%s>", sc.name, sc.argName))
+	}
+	if sc.modeFlag != "" {
+		o.set[sc.modeFlag] = true
+	}
+	return reportErr(out, checkModeFlags(o.set))
+}
+
+// parseFlagSet parses args with fs, calling usage for -h. Positional
+// arguments left after the flags are an error, since the flag package would
+// otherwise ignore every flag after them.
+func parseFlagSet(fs *flag.FlagSet, args []string, out io.Writer, usage func(), helpCmd string) error {
+	fs.SetOutput(io.Discard)
+	fs.Usage = func() {}
+	err := fs.Parse(args)
+	if errors.Is(err, flag.ErrHelp) {
+		usage()
+		return err
+	}
+	if err == nil && fs.NArg() > 0 {
+		err = fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+	if err != nil {
+		fmt.Fprintf(out, "Error: %v\nRun '%s' for usage.\n", err, helpCmd)
+		return err
+	}
+	return nil
+}
+
+// reportErr writes err, if any, to out.
+func reportErr(out io.Writer, err error) error {
+	if err == nil {
+		return nil
+	}
+	fmt.Fprintf(out, "Error: %v\n", err)
+	return err
+}
+
+func findSubcommand(name string) *subcommand {
+	for i := range subcommands {
+		if subcommands[i].name == name {
+			return &subcommands[i]
+		}
+	}
+	return nil
+}
+
+// flagSet returns a flag set with the command's flags, sharing their values
+// with o.
+func (sc *subcommand) flagSet(o *options) *flag.FlagSet {
+	fs := flag.NewFlagSet(sc.name, flag.ContinueOnError)
+	names := sc.flags
+	if sc.argName != "" {
+		names = append([]string{sc.modeFlag}, names...)
+	}
+	for _, name := range names {
+		f := o.fs.Lookup(name)
+		if f == nil {
+			panic("subcommand " + sc.name + ": no flag -" + name)
+		}
+		fs.Var(f.Value, f.Name, f.Usage)
+	}
+	return fs
+}
+
+// checkModeFlags returns an error when set, the names of the flags given on
+// the command line, selects more than one mode. Only the first mode would
+// run and the other flags would be silently ignored.
+func checkModeFlags(set map[string]bool) error {
+	var modes []string
+	for _, sc := range subcommands {
+		if sc.modeFlag == "" || !set[sc.modeFlag] {
+			continue
+		}
+		if sc.modeFlag == "transcript-file" && set["summary-bench"] {
+			continue // summarybench reads the transcript given with -transcript-file
+		}
+		modes = append(modes, "-"+sc.modeFlag)
+	}
+	if set["compare-baseline-dir"] && !set["compare-baseline"] {
+		modes = append(modes, "-compare-baseline-dir") // Benchmark mode, like -compare-baseline
+	}
+	if len(modes) > 1 {
+		return fmt.Errorf("%s cannot be combined with %s: they select different modes", modes[0], modes[1])
+	}
+	return nil
+}
+
+func (sc *subcommand) printUsage(w io.Writer, prog string, fs *flag.FlagSet) {
+	usage := sc.name
+	if sc.argName != "" {
+		usage += " <" + sc.argName + ">"
+	}
+	fmt.Fprintf(w, "Usage: %s %s [options]\n\n", prog, usage)
+	fmt.Fprintf(w, "%s.\n\n", sc.desc)
+	fmt.Fprintf(w, "Options:\n")
+	fs.SetOutput(w)
+	fs.PrintDefaults()
+}
+
+func printUsage(w io.Writer, prog string) {
+	fmt.Fprintf(w, "LLM Benchmark Kit - High-Performance LLM Benchmarking Tool\n\n")
+	fmt.Fprintf(w, "Usage: %s [command] [options]\n\n", prog)
+	fmt.Fprintf(w, "Commands:\n")
+	for _, sc := range subcommands {
+		usage := sc.name
+		if sc.argName != "" {
+			usage += " <" + sc.argName + ">"
+		}
+		fmt.Fprintf(w, "  %-28s %s\n", usage, sc.desc)
+	}
+	fmt.Fprintf(w, "\nRun '%s help <command>' for the options of a command.\n\n", prog)
+	fmt.Fprintf(w, "Without a command, the equivalent mode flags (-transcript-file, -summary-bench,\n")
+	fmt.Fprintf(w, "-full-test, -soak, -soak-report, -probe, -probe-context, -replay, -leaderboard,\n")
+	fmt.Fprintf(w, "-count-tokens-only, -compare-baseline) and the flags of every command are accepted;\n")
+	fmt.Fprintf(w, "flags of two modes cannot be combined.\n")
+	fmt.Fprintf(w, "\nExamples:\n")
+	fmt.Fprintf(w, "  # Benchmark mode\n")
+	fmt.Fprintf(w, "  %s -url https://api.openai.com/v1/chat/completions -model gpt-4 -token $OPENAI_API_KEY\n\n", prog)
+	fmt.Fprintf(w, "  # Summary mode\n")
+	fmt.Fprintf(w, "  %s summary meeting.txt -url http://localhost:8000/v1/chat/completions -model qwen\n\n", prog)
+	fmt.Fprintf(w, "  # Full test mode\n")
+	fmt.Fprintf(w, "  %s fulltest -url http://localhost:8000/v1/chat/completions -model qwen\n\n", prog)
+	fmt.Fprintf(w, "  # Summary benchmark mode (concurrent meeting summary stress test)\n")
+	fmt.Fprintf(w, "  %s summarybench -sb-concurrency 10 -sb-requests 50 -url http://localhost:8000/v1/chat/completions -model qwen\n\n", prog)
+	fmt.Fprintf(w, "  # Soak test mode (long-running stability test with system metrics)\n")
+	fmt.Fprintf(w, "  %s soak -soak-duration 3600 -soak-concurrency 10 -soak-window 60 -url http://localhost:8000/v1/chat/completions -model qwen\n\n", prog)
+	fmt.Fprintf(w, "  # Rebuild soak report from logs (download logs from server, generate report locally)\n")
+	fmt.Fprintf(w, "  %s soak-report ./output/soaktest_qwen_20260302_120000\n", prog)
+	printExitCodes(w)
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestParse_Subcommands(t *testing.T) {
+	tests := []struct {
+		name  string
+		args  []string
+		check func(o *options) bool
+	}{
+		{"no command", []string{"-url", "u", "-concurrency", "3"}, func(o *options) bool {
+			return o.cfg.URL == "u" && o.cfg.Concurrency == 3
+		}},
+		{"benchmark", []string{"benchmark", "-concurrency", "3", "-compare-baseline-dir", "out"}, func(o *options) bool {
+			return o.cfg.Concurrency == 3 && *o.baselineDir == "out"
+		}},
+		{"boolean mode", []string{"fulltest", "-url", "u", "-phases", "perf"}, func(o *options) bool {
+			return *o.fullTest && o.cfg.URL == "u" && o.cfg.FullTestPhases == "perf"
+		}},
+		{"positional argument", []string{"replay", "out/results.jsonl", "-model", "m"}, func(o *options) bool {
+			return *o.replayFile == "out/results.jsonl" && o.cfg.ModelName == "m"
+		}},
+		{"argument as flag", []string{"summary", "-transcript-file", "t.txt", "-chunk-size", "100"}, func(o *options) bool {
+			return *o.transcriptFile == "t.txt" && *o.chunkSize == 100
+		}},
+		{"compare", []string{"compare", "base.json", "-fail-on-regression", "5"}, func(o *options) bool {
+			return o.baseline.path == "base.json" && o.baseline.failPct == 5
+		}},
+		{"summarybench transcript", []string{"summarybench", "-transcript-file", "t.txt", "-sb-requests", "5"}, func(o *options) bool {
+			return *o.summaryBench && *o.transcriptFile == "t.txt" && *o.summaryBenchRequests == 5
+		}},
+		{"legacy mode flag", []string{"-replay", "out/results.jsonl"}, func(o *options) bool {
+			return *o.replayFile == "out/results.jsonl"
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newOptions()
+			if err := o.parse(append([]string{"llm-benchmark-kit"}, tt.args...), io.Discard); err != nil {
+				t.Fatalf("parse(%q): %v", tt.args, err)
+			}
+			if !tt.check(o) {
+				t.Errorf("parse(%q) did not set the expected options", tt.args)
+			}
+		})
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{"flag of another mode", []string{"fulltest", "-sb-requests", "5"}, "flag provided but not defined: -sb-requests"},
+		{"benchmark flag in replay", []string{"replay", "x.jsonl", "-concurrency", "50"}, "flag provided but not defined: -concurrency"},
+		{"benchmark flag in summary", []string{"summary", "t.txt", "-rps", "3"}, "flag provided but not defined: -rps"},
+		{"mode flag in command", []string{"fulltest", "-probe"}, "flag provided but not defined: -probe"},
+		{"mixed legacy modes", []string{"-replay", "a.jsonl", "-leaderboard", "b.json"}, "-replay cannot be combined with -leaderboard"},
+		{"mixed legacy boolean modes", []string{"-full-test", "-probe"}, "-full-test cannot be combined with -probe"},
+		{"baseline dir with mode", []string{"-soak", "-compare-baseline-dir", "out"}, "-soak cannot be combined with -compare-baseline-dir"},
+		{"missing argument", []string{"replay"}, "replay requires <results.jsonl>"},
+		{"missing argument with flags", []string{"summary", "-model", "m"}, "summary requires <transcript>"},
+		{"missing leaderboard targets", []string{"leaderboard", "-concurrency", "2"}, "leaderboard requires <targets.json>"},
+		{"unknown command", []string{"bogus"}, `unknown command "bogus"`},
+		{"stray argument", []string{"benchmark", "-model", "m", "extra"}, `unexpected argument "extra"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			err := newOptions().parse(append([]string{"llm-benchmark-kit"}, tt.args...), &out)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("parse(%q) error = %v, want it to contain %q", tt.args, err, tt.wantErr)
+			}
+			if !strings.Contains(out.String(), tt.wantErr) {
+				t.Errorf("output = %q, want the error reported", out.String())
+			}
+		})
+	}
+}
+
+func TestParse_Help(t *testing.T) {
+	var out bytes.Buffer
+	err := newOptions().parse([]string{"llm-benchmark-kit", "help", "replay"}, &out)
+	if !errors.Is(err, flag.ErrHelp) {
+		t.Fatalf("error = %v, want flag.ErrHelp", err)
+	}
+	if !strings.Contains(out.String(), "\n  -output-format") || strings.Contains(out.String(), "\n  -concurrency") {
+		t.Errorf("help replay lists other modes' flags or misses its own:\n%s", out.String())
+	}
+
+	out.Reset()
+	if err := newOptions().parse([]string{"llm-benchmark-kit", "help"}, &out); !errors.Is(err, flag.ErrHelp) {
+		t.Fatalf("error = %v, want flag.ErrHelp", err)
+	}
+	if strings.Contains(out.String(), "\n  -concurrency") {
+		t.Errorf("help lists flags instead of commands:\n%s", out.String())
+	}
+}
+
+// TestSubcommands_CoverEveryFlag checks that every flag, apart from the mode
+// flags and -version, is accepted by at least one command, and that the
+// commands only name flags that exist.
+func TestSubcommands_CoverEveryFlag(t *testing.T) {
+	o := newOptions()
+	used := map[string]bool{"version": true}
+	for _, sc := range subcommands {
+		used[sc.modeFlag] = true
+		sc.flagSet(o).VisitAll(func(f *flag.Flag) { used[f.Name] = true })
+	}
+	o.fs.VisitAll(func(f *flag.Flag) {
+		if !used[f.Name] {
+			t.Errorf("-%s is not accepted by any command", f.Name)
+		}
+	})
+}
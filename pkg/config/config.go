@@ -1,41 +1,139 @@
 // Package config defines the global configuration for the LLM Benchmark Kit.
 package config
 
+import (
+	"io"
+	"os"
+)
+
 // GlobalConfig holds all configuration options for the benchmark.
 type GlobalConfig struct {
 	// API Configuration
-	URL       string // API endpoint URL
-	ModelName string // Model name to benchmark
-	Token     string // API authentication token
+	URL       string   // API endpoint URL
+	ModelName string   // Model name to benchmark
+	Token     string   // API authentication token
+	APIKeys   []string // Tokens rotated across benchmark requests instead of Token (-api-key, -token-file)
+	PerKeyRPS float64  // Requests per second allowed per key in APIKeys (0 = unlimited)
 
 	// Benchmark Parameters
-	Concurrency   int     // Number of concurrent workers
-	TotalRequests int     // Total number of requests to make
-	DurationSec   int     // Duration in seconds (alternative to TotalRequests)
-	RPS           float64 // Requests per second limit (0 = unlimited)
-	Warmup        int     // Number of warmup requests (excluded from stats)
-	MaxTokens     int     // Max tokens for response
+	Concurrency       int     // Number of concurrent workers
+	TotalRequests     int     // Total number of requests to make
+	DurationSec       int     // Duration in seconds (alternative to TotalRequests)
+	RPS               float64 // Requests per second limit (0 = unlimited)
+	MaxInFlight       int     // Max overlapping requests across all workers (0 = Concurrency; larger values let each worker pipeline requests)
+	Warmup            int     // Number of warmup requests (excluded from stats)
+	WarmupStablePct   float64 // Warm up until the P95 latency of successive windows differs by at most this percent (0 = fixed Warmup)
+	WarmupWindow      int     // Completed requests per window for WarmupStablePct (0 = max(20, Concurrency))
+	WarmupMax         int     // Give up on WarmupStablePct after this many warmup requests
+	WaitReady         bool    // Poll the endpoint until it answers before benchmarking
+	WaitReadySec      int     // Give up waiting for readiness after this many seconds
+	AbortAfter        int     // Stop the run after this many consecutive failures (0 = never)
+	Retries           int     // Retry failed requests that streamed nothing up to this many times
+	RetryBackoffMs    int     // Sleep before the first retry; doubles on each further retry
+	CountRetryLatency bool    // Measure retried requests from the first attempt, including backoff
+	MaxTokens         int     // Max tokens for response
+	N                 int     // Completions per request, sent as n (0 = not sent)
+
+	// Generation Parameters (nil = not sent, so 0 stays a meaningful value)
+	Temperature *float64 // Sampling temperature
+	TopP        *float64 // Nucleus sampling probability
+	Seed        *int     // Random seed for reproducible sampling
+	Stop        []string // Stop sequences (empty = not sent)
+	MinTokens   int      // Minimum completion tokens, sent as min_tokens (vLLM extension; 0 = not sent)
+	IgnoreEOS   bool     // Keep generating past end-of-sequence, sent as ignore_eos (vLLM extension)
+	ExtraBody   string   // JSON object merged into every request body (JSON merge patch)
+
+	// Prompting
+	SystemPrompt string // System message prepended to workloads that have none
+
+	// Structured Output
+	JSONMode   bool     // Request JSON output (response_format json_object) and validate responses
+	JSONSchema string   // Raw JSON schema for structured outputs (response_format json_schema); implies JSONMode
+	Scorers    []string // Response scorer specs (see scorer.Parse), applied to successful responses
 
 	// Token Counting Mode
-	TokenMode string // usage|chars|disabled
+	TokenMode      string  // usage|chars|disabled
+	TTFTMinChars   int     // Record TTFT once this many non-whitespace chars have streamed (0 = first content delta)
+	ThinkTagFilter bool    // Exclude inline <think>...</think> blocks from content chars and TTFT
+	CharsPerToken  float64 // Chars per token used to estimate completion tokens when the server sends no usage (0 = built-in tokenizer)
+
+	// Pricing (USD per million tokens; 0 = no cost estimate)
+	PriceInput  float64 // Prompt token price
+	PriceOutput float64 // Completion token price
 
 	// Network Configuration
-	TimeoutSec  int    // Request timeout in seconds
-	InsecureTLS bool   // Skip TLS verification
-	CACertPath  string // Custom CA certificate path
+	TimeoutSec          int    // Request timeout in seconds, covering the whole stream
+	ConnectTimeoutSec   int    // Limit for TCP connect and TLS handshake (0 = only TimeoutSec)
+	FirstByteTimeoutSec int    // Limit from sending a request to the first stream event (0 = only TimeoutSec)
+	InsecureTLS         bool   // Skip TLS verification
+	CACertPath          string // Custom CA certificate path
+	NoKeepAlive         bool   // Disable connection reuse so every request opens a new connection
+	StrictSSE           bool   // Count SSE protocol violations (malformed frames, invalid UTF-8/JSON) per request
+
+	MaxResponseChars int // Abort a request once its streamed content exceeds this many bytes (0 = unlimited)
+
+	MaxIdleConns        int // Idle connections kept for reuse across all hosts (0 = max(100, concurrency))
+	MaxIdleConnsPerHost int // Idle connections kept per host (0 = MaxIdleConns)
+	MaxConnsPerHost     int // Open connections per host, idle or busy; further requests wait for one to free up (0 = unlimited)
 
 	// Input/Output
-	WorkloadFile string // Path to prompts file (each line a prompt or JSONL)
-	OutputDir    string // Output directory for results
+	WorkloadFile   string  // Path to prompts file (each line a prompt or JSONL)
+	PromptTemplate string  // Go text/template prompt expanded with PromptVars/VarsFile
+	PromptVars     string  // Template variables: name=v1|v2,name2=v (cartesian product)
+	VarsFile       string  // JSONL file with one template variable set per line
+	MessagesFile   string  // JSON array of {role,content} sent as the base conversation of every request
+	BustCache      bool    // Append a unique nonce to every prompt so server-side response caches never hit
+	OutputDir      string  // Output directory for results
+	OutOverwrite   bool    // Allow writing into an output directory that already contains results
+	RunName        string  // Label added to auto-generated output directory names and reports
+	OutputFormat   string  // Comma-separated report formats: json, html, or all (results.jsonl is always written)
+	StreamingStats bool    // Estimate percentiles incrementally instead of keeping every result in memory
+	PercentileCSV  bool    // Also write percentiles.csv with TTFT and latency at fixed percentiles, for plotting
+	CDNCharts      bool    // Load ECharts from a CDN in HTML reports instead of embedding it (smaller, needs internet to view)
+	SampleRate     float64 // Fraction of requests (0-1) whose prompt and full response are stored in results.jsonl
+	RecordAll      bool    // Write every request's messages and response text to transcript.jsonl
+	CheckpointSec  int     // Seconds between checkpoint.json updates during a benchmark (0 = only at the end)
+	SnapshotSec    int     // Seconds between interim rewrites of the report files during a benchmark (0 = only at the end)
+	Resume         bool    // Continue the interrupted benchmark in OutputDir instead of starting over
+
+	// SampleMiddleFrames is how many raw content frames, evenly spaced over the
+	// stream, the report shows between the first and final frame (0 = none)
+	SampleMiddleFrames int
 
 	// Provider Selection
-	ProviderType string // Provider type: openai, aliyun, custom
+	ProviderType string // Provider type: openai, bedrock, aliyun, custom
+	Region       string // Cloud region for providers that need one (e.g. bedrock)
+	CustomCmd    string // Shell command for the custom provider (request JSON on stdin, SSE/NDJSON on stdout)
 
 	// Debug Options
-	Verbose bool // Enable verbose logging of requests/responses
+	Verbose     bool // Enable verbose logging of requests/responses
+	Verbosity   int  // 0 = normal, 1 = verbose (-v), 2 = very verbose (-vv, also dumps raw SSE frames)
+	Quiet       bool // Suppress progress output, print only the final summary line
+	LogSecrets  bool // Show the first characters of the API token in logs instead of masking it
+	SelfMonitor bool // Watch the client's own dispatch loop and flag runs limited by the client rather than the server
+	CPUProfile  bool // Write a CPU profile of the tool itself (cpu.pprof) to the output directory
+	MemProfile  bool // Write a heap profile of the tool itself (mem.pprof) to the output directory
 
 	// Model Behavior
 	DisableThinking bool // Disable thinking/reasoning mode (sends chat_template_kwargs.enable_thinking=false)
+	Logprobs        bool // Request per-token log probabilities (logprobs=true) and report response confidence
+
+	// Summary Options
+	SummaryAutoExtend  bool   // Re-request chunks truncated by max_tokens (finish_reason=length) with a larger limit
+	SummaryConcurrency int    // Summarize chunks independently with this many parallel requests and merge them (map-reduce); 0 = rolling summary
+	NoIntermediate     bool   // Skip writing intermediate/chunk_NN files
+	IntermediateFormat string // Intermediate file format: md (summary text) or json (summary + chunk metrics)
+	ChunkTokens        int    // Transcript chunk budget in tokens of the model's encoding (0 = use -chunk-size characters)
+	TranscriptEncoding string // Transcript file encoding: auto (UTF-8, UTF-16 by BOM, else GB18030), utf-8, utf-16le, utf-16be, gbk or gb18030
+
+	// Full Test Options
+	FullTestPhases        string // Comma-separated phases to run (perf, funccall, longctx, summary); empty = all
+	TurnDelayMs           int    // Think time between turns of the multi-turn test
+	TurnJitterMs          int    // Random extra think time (0..N ms) added to TurnDelayMs
+	FunctionCallCasesFile string // JSON file with extra function-call test cases
+	ContextLadder         string // Comma-separated context lengths (chars) of the long context test
+	ContextFillerFile     string // Text repeated to build long contexts (default: built-in Chinese paragraph)
+	ContextMaxTokens      int    // Max output tokens of long context requests
 }
 
 // DefaultConfig returns a configuration with sensible defaults.
@@ -47,7 +145,13 @@ func DefaultConfig() *GlobalConfig {
 		TokenMode:     "usage",
 		TimeoutSec:    60,
 		OutputDir:     "./output",
+		OutOverwrite:  true,
+		OutputFormat:  "all",
 		ProviderType:  "openai",
+
+		RetryBackoffMs:     500,
+		ContextMaxTokens:   256,
+		SampleMiddleFrames: 3,
 	}
 }
 
@@ -63,6 +167,30 @@ func ModerateBenchmarkConfig() *GlobalConfig {
 		TokenMode:     "usage",
 		TimeoutSec:    120,
 		OutputDir:     "./output",
+		OutOverwrite:  true,
+		OutputFormat:  "all",
 		ProviderType:  "openai",
+
+		RetryBackoffMs:     500,
+		ContextMaxTokens:   256,
+		SampleMiddleFrames: 3,
+	}
+}
+
+// Stdout returns where progress output is written: os.Stdout, or io.Discard
+// when Quiet is set.
+func (c *GlobalConfig) Stdout() io.Writer {
+	if c.Quiet {
+		return io.Discard
+	}
+	return os.Stdout
+}
+
+// RedactedToken returns the API token for logging: fully masked as "***" unless
+// LogSecrets is set, in which case the first 10 characters are shown.
+func (c *GlobalConfig) RedactedToken() string {
+	if !c.LogSecrets {
+		return "***"
 	}
+	return c.Token[:min(10, len(c.Token))] + "..."
 }
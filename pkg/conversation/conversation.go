@@ -0,0 +1,311 @@
+// Package conversation simulates growing chat sessions: every turn resends
+// the whole conversation so far, including the model's own replies, so TTFT
+// and latency can be measured as the context accumulates.
+package conversation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/config"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/provider"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/stats"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/tokenizer"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/workload"
+)
+
+// defaultQuestions are the user turns of a session when no workload file is given.
+var defaultQuestions = []string{
+	"I'm planning a small web service in Go. What should the project layout look like?",
+	"How should I structure configuration loading for it?",
+	"What's a sensible way to handle errors across the layers you described?",
+	"How would you add structured logging to that design?",
+	"Which parts of this would you unit test first, and why?",
+	"How could I add graceful shutdown to the server?",
+	"What metrics would you export for monitoring?",
+	"How would you containerize the service?",
+	"Summarize the decisions we've made so far as a short checklist.",
+	"Which of those decisions would you revisit if traffic grew tenfold?",
+}
+
+// TurnStats aggregates one turn index across all sessions.
+type TurnStats struct {
+	Turn             int     `json:"turn"`
+	Requests         int     `json:"requests"`
+	Success          int     `json:"success"`
+	AvgContextTokens float64 `json:"avg_context_tokens"`          // Prompt tokens sent (usage, or estimated)
+	ContextEstimated bool    `json:"context_estimated,omitempty"` // Some prompt tokens were estimated with pkg/tokenizer
+	AvgTTFTMs        float64 `json:"avg_ttft_ms"`
+	P50TTFTMs        int64   `json:"p50_ttft_ms"`
+	P95TTFTMs        int64   `json:"p95_ttft_ms"`
+	AvgLatencyMs     float64 `json:"avg_latency_ms"`
+	P95LatencyMs     int64   `json:"p95_latency_ms"`
+}
+
+// Result is the outcome of a conversation growth run.
+type Result struct {
+	Model         string `json:"model"`
+	URL           string `json:"url"`
+	Sessions      int    `json:"sessions"`
+	Turns         int    `json:"turns"`
+	WallTimeMs    int64  `json:"wall_time_ms"`
+	TotalRequests int    `json:"total_requests"`
+	Success       int    `json:"success"`
+
+	// TTFTMsPer1KTokens is the least-squares slope of average TTFT against
+	// average context size over the turns: the TTFT cost of 1000 more tokens
+	TTFTMsPer1KTokens float64 `json:"ttft_ms_per_1k_tokens"`
+
+	PerTurn []TurnStats `json:"per_turn"`
+}
+
+// turnResult is one request of one session.
+type turnResult struct {
+	turn          int
+	success       bool
+	ttft          time.Duration
+	latency       time.Duration
+	contextTokens int
+	estimated     bool
+	err           string
+}
+
+// Runner runs parallel simulated chat sessions.
+type Runner struct {
+	cfg       *config.GlobalConfig
+	provider  provider.Provider
+	sessions  int
+	turns     int
+	questions []string
+}
+
+// NewRunner creates a runner for sessions parallel sessions of turns turns
+// each. questions are the user turns, cycled as needed; nil uses built-in ones.
+func NewRunner(cfg *config.GlobalConfig, p provider.Provider, sessions, turns int, questions []string) *Runner {
+	if len(questions) == 0 {
+		questions = defaultQuestions
+	}
+	return &Runner{cfg: cfg, provider: p, sessions: sessions, turns: turns, questions: questions}
+}
+
+// Run starts all sessions at once and waits for them to finish.
+func (r *Runner) Run() *Result {
+	start := time.Now()
+	results := make(chan turnResult, r.sessions)
+	var wg sync.WaitGroup
+	for s := 0; s < r.sessions; s++ {
+		wg.Add(1)
+		go func(session int) {
+			defer wg.Done()
+			r.runSession(session, results)
+		}(s)
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	byTurn := make([][]turnResult, r.turns)
+	done := 0
+	for res := range results {
+		byTurn[res.turn-1] = append(byTurn[res.turn-1], res)
+		done++
+		if !r.cfg.Quiet {
+			status := "ok"
+			if !res.success {
+				status = "failed: " + truncate(res.err, 80)
+			}
+			fmt.Fprintf(r.cfg.Stdout(), "  [%d/%d] turn %d, %d context tokens, TTFT %.0f ms (%s)\n",
+				done, r.sessions*r.turns, res.turn, res.contextTokens, float64(res.ttft.Microseconds())/1000, status)
+		}
+	}
+
+	res := &Result{
+		Model:      r.cfg.ModelName,
+		URL:        r.cfg.URL,
+		Sessions:   r.sessions,
+		Turns:      r.turns,
+		WallTimeMs: time.Since(start).Milliseconds(),
+	}
+	for i, turn := range byTurn {
+		ts := summarizeTurn(i+1, turn)
+		res.TotalRequests += ts.Requests
+		res.Success += ts.Success
+		res.PerTurn = append(res.PerTurn, ts)
+	}
+	res.TTFTMsPer1KTokens = ttftSlope(res.PerTurn) * 1000
+	return res
+}
+
+// runSession runs one conversation. Each turn sends every earlier exchange;
+// a failed turn's question is dropped so the history stays well-formed.
+func (r *Runner) runSession(session int, results chan<- turnResult) {
+	var messages []workload.ChatMessage
+	for turn := 1; turn <= r.turns; turn++ {
+		if turn > 1 {
+			r.thinkTime()
+		}
+		question := r.questions[(session+turn-1)%len(r.questions)]
+		messages = append(messages, workload.ChatMessage{Role: "user", Content: question})
+
+		res, reply := r.send(fmt.Sprintf("session-%d-turn-%d", session+1, turn), messages)
+		res.turn = turn
+		results <- res
+
+		if res.success {
+			messages = append(messages, workload.ChatMessage{Role: "assistant", Content: reply})
+		} else {
+			messages = messages[:len(messages)-1]
+		}
+	}
+}
+
+// send streams one request and returns its measurements and the visible reply.
+func (r *Runner) send(id string, messages []workload.ChatMessage) (turnResult, string) {
+	var res turnResult
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(r.cfg.TimeoutSec)*time.Second)
+	defer cancel()
+
+	input := workload.NewChatWorkload(id, messages, r.cfg.MaxTokens)
+	start := time.Now()
+	events, err := r.provider.StreamChat(ctx, r.cfg, input)
+	if err != nil {
+		res.latency = time.Since(start)
+		res.err = err.Error()
+		res.contextTokens, res.estimated = estimateContext(r.cfg, input), true
+		return res, ""
+	}
+
+	var reply strings.Builder
+	var usage *provider.TokenUsage
+	for event := range events {
+		switch event.Type {
+		case provider.EventContent, provider.EventReasoning:
+			if res.ttft == 0 {
+				res.ttft = time.Since(start)
+			}
+			if event.Type == provider.EventContent {
+				reply.WriteString(event.Text)
+			}
+		case provider.EventUsage:
+			usage = event.Usage
+		case provider.EventError:
+			res.err = event.Err.Error()
+		}
+	}
+	res.latency = time.Since(start)
+	if res.err == "" && ctx.Err() != nil {
+		res.err = fmt.Sprintf("request timeout: %v", ctx.Err())
+	}
+	if res.err == "" && res.ttft == 0 {
+		res.err = "no content received"
+	}
+	res.success = res.err == ""
+
+	if usage != nil && usage.PromptTokens > 0 {
+		res.contextTokens = usage.PromptTokens
+	} else {
+		res.contextTokens, res.estimated = estimateContext(r.cfg, input), true
+	}
+	return res, reply.String()
+}
+
+// thinkTime sleeps for -turn-delay-ms plus up to -turn-jitter-ms between turns.
+func (r *Runner) thinkTime() {
+	delay := time.Duration(r.cfg.TurnDelayMs) * time.Millisecond
+	if r.cfg.TurnJitterMs > 0 {
+		delay += time.Duration(rand.Intn(r.cfg.TurnJitterMs+1)) * time.Millisecond
+	}
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+}
+
+// estimateContext estimates the prompt tokens of input, including the system prompt.
+func estimateContext(cfg *config.GlobalConfig, input workload.WorkloadInput) int {
+	n := 0
+	for _, m := range input.ToMessagesWithSystem(cfg.SystemPrompt) {
+		n += tokenizer.Count(m.Content, cfg.ModelName)
+	}
+	return n
+}
+
+// summarizeTurn aggregates the requests of one turn. TTFT and latency cover
+// successful requests; the context size covers all of them.
+func summarizeTurn(turn int, results []turnResult) TurnStats {
+	ts := TurnStats{Turn: turn, Requests: len(results)}
+	var ttfts, latencies []time.Duration
+	contextSum := 0
+	for _, res := range results {
+		contextSum += res.contextTokens
+		ts.ContextEstimated = ts.ContextEstimated || res.estimated
+		if res.success {
+			ts.Success++
+			ttfts = append(ttfts, res.ttft)
+			latencies = append(latencies, res.latency)
+		}
+	}
+	if ts.Requests > 0 {
+		ts.AvgContextTokens = float64(contextSum) / float64(ts.Requests)
+	}
+	if len(ttfts) > 0 {
+		ts.AvgTTFTMs = stats.AverageMs(ttfts)
+		ts.P50TTFTMs = stats.PercentileMs(ttfts, 50)
+		ts.P95TTFTMs = stats.PercentileMs(ttfts, 95)
+		ts.AvgLatencyMs = stats.AverageMs(latencies)
+		ts.P95LatencyMs = stats.PercentileMs(latencies, 95)
+	}
+	return ts
+}
+
+// ttftSlope fits avg TTFT (ms) = a + b·context tokens over turns with
+// successful requests and returns b, or 0 with fewer than two distinct sizes.
+func ttftSlope(turns []TurnStats) float64 {
+	var n, sumX, sumY, sumXY, sumXX float64
+	for _, ts := range turns {
+		if ts.Success == 0 {
+			continue
+		}
+		x, y := ts.AvgContextTokens, ts.AvgTTFTMs
+		n++
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+	denom := n*sumXX - sumX*sumX
+	if n < 2 || denom == 0 {
+		return 0
+	}
+	return (n*sumXY - sumX*sumY) / denom
+}
+
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
+
+// WriteResult writes conversation.json to outputDir.
+func WriteResult(res *Result, outputDir string) (string, error) {
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create output directory: %w", err)
+	}
+	data, err := json.MarshalIndent(res, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal conversation result: %w", err)
+	}
+	path := filepath.Join(outputDir, "conversation.json")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write conversation result: %w", err)
+	}
+	return path, nil
+}
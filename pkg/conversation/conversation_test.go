@@ -0,0 +1,81 @@
+package conversation
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"testing"
+
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/config"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/provider"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/workload"
+)
+
+// echoProvider replies with the number of messages it received and records
+// each request's history.
+type echoProvider struct {
+	mu       sync.Mutex
+	requests [][]workload.ChatMessage
+}
+
+func (p *echoProvider) Name() string { return "echo" }
+
+func (p *echoProvider) StreamChat(ctx context.Context, cfg *config.GlobalConfig, input workload.WorkloadInput) (<-chan provider.StreamEvent, error) {
+	messages := input.ToMessages()
+	p.mu.Lock()
+	p.requests = append(p.requests, append([]workload.ChatMessage(nil), messages...))
+	p.mu.Unlock()
+
+	events := make(chan provider.StreamEvent, 3)
+	events <- provider.StreamEvent{Type: provider.EventContent, Text: fmt.Sprintf("reply to %d messages", len(messages))}
+	events <- provider.StreamEvent{Type: provider.EventUsage, Usage: &provider.TokenUsage{PromptTokens: 100 * len(messages)}}
+	events <- provider.StreamEvent{Type: provider.EventEnd}
+	close(events)
+	return events, nil
+}
+
+func TestRunner_GrowingContext(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.Quiet = true
+	p := &echoProvider{}
+	res := NewRunner(cfg, p, 2, 3, []string{"q1", "q2", "q3"}).Run()
+
+	if res.TotalRequests != 6 || res.Success != 6 {
+		t.Fatalf("requests = %d, success = %d, want 6/6", res.TotalRequests, res.Success)
+	}
+	for i, ts := range res.PerTurn {
+		// Turn k sends k questions and k-1 replies
+		want := float64(100 * (2*(i+1) - 1))
+		if ts.AvgContextTokens != want || ts.ContextEstimated {
+			t.Errorf("turn %d: context = %.0f (estimated %v), want %.0f from usage", ts.Turn, ts.AvgContextTokens, ts.ContextEstimated, want)
+		}
+	}
+
+	// The model's own replies are part of the history
+	for _, msgs := range p.requests {
+		if len(msgs) == 5 {
+			if msgs[1].Role != "assistant" || msgs[1].Content != "reply to 1 messages" ||
+				msgs[3].Role != "assistant" || msgs[3].Content != "reply to 3 messages" {
+				t.Errorf("third turn history = %+v", msgs)
+			}
+			return
+		}
+	}
+	t.Error("no third-turn request with 5 messages")
+}
+
+func TestTTFTSlope(t *testing.T) {
+	turns := []TurnStats{
+		{Success: 1, AvgContextTokens: 1000, AvgTTFTMs: 110},
+		{Success: 1, AvgContextTokens: 2000, AvgTTFTMs: 120},
+		{Success: 0, AvgContextTokens: 3000, AvgTTFTMs: 0}, // Failed turn is ignored
+		{Success: 1, AvgContextTokens: 4000, AvgTTFTMs: 140},
+	}
+	if got := ttftSlope(turns) * 1000; math.Abs(got-10) > 1e-9 {
+		t.Errorf("slope = %v ms per 1K tokens, want 10", got)
+	}
+	if got := ttftSlope(turns[:1]); got != 0 {
+		t.Errorf("single turn slope = %v, want 0", got)
+	}
+}
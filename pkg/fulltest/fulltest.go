@@ -5,28 +5,31 @@ package fulltest
 import (
 	"bytes"
 	"context"
-	"crypto/tls"
 	_ "embed"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"html/template"
-	"io"
+	"math/rand"
 	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"runtime"
+	"slices"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/brianxiadong/llm-benchmark-kit/pkg/config"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/httpclient"
 	"github.com/brianxiadong/llm-benchmark-kit/pkg/provider"
 	"github.com/brianxiadong/llm-benchmark-kit/pkg/result"
 	"github.com/brianxiadong/llm-benchmark-kit/pkg/runner"
 	"github.com/brianxiadong/llm-benchmark-kit/pkg/summarizer"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/tokenizer"
 	"github.com/brianxiadong/llm-benchmark-kit/pkg/workload"
 )
 
@@ -49,6 +52,10 @@ type TestResult struct {
 	LatencyMs float64 `json:"latency_ms"`
 	Tokens    int     `json:"tokens"`
 	Error     string  `json:"error,omitempty"`
+
+	// Multi-turn only: size of the conversation sent with this turn
+	ContextMessages int `json:"context_messages,omitempty"`
+	PromptTokens    int `json:"prompt_tokens,omitempty"`
 }
 
 // PhaseResult holds results for a test phase.
@@ -62,17 +69,6 @@ type PhaseResult struct {
 	Results      []TestResult `json:"results"`
 }
 
-// FunctionCallResult holds function call test results.
-type FunctionCallResult struct {
-	Supported       bool    `json:"supported"`
-	CorrectFunction bool    `json:"correct_function"`
-	CorrectArgs     bool    `json:"correct_args"`
-	LatencyMs       float64 `json:"latency_ms"`
-	FunctionName    string  `json:"function_name"`
-	Arguments       string  `json:"arguments"`
-	Error           string  `json:"error,omitempty"`
-}
-
 // LongContextTestResult holds a single long context test result.
 type LongContextTestResult struct {
 	ContextLength int     `json:"context_length"` // Input context length in chars
@@ -155,11 +151,13 @@ type GraduatedConcurrencyResult struct {
 
 // FullTestReport contains the combined results from all test phases.
 type FullTestReport struct {
+	RunName       string        `json:"run_name,omitempty"`
 	ModelName     string        `json:"model_name"`
 	APIURL        string        `json:"api_url"`
 	StartTime     time.Time     `json:"start_time"`
 	EndTime       time.Time     `json:"end_time"`
 	TotalDuration time.Duration `json:"total_duration"`
+	Phases        []string      `json:"phases,omitempty"` // Phases that were run, in run order
 
 	// Environment Info
 	Environment *EnvironmentInfo `json:"environment,omitempty"`
@@ -191,6 +189,49 @@ type FullTestReport struct {
 	SummaryOutputDir   string `json:"summary_output_dir"`
 }
 
+// Full-test phases selectable with -phases.
+const (
+	PhasePerf     = "perf"     // Phase 1 and 1.5: performance and graduated concurrency
+	PhaseFuncCall = "funccall" // Phase 2: function calling
+	PhaseLongCtx  = "longctx"  // Phase 3 and 3.5: long context, sequential and concurrent
+	PhaseSummary  = "summary"  // Phase 4: meeting summary
+)
+
+// Phases lists every phase in run order.
+var Phases = []string{PhasePerf, PhaseFuncCall, PhaseLongCtx, PhaseSummary}
+
+// ParsePhases parses a comma-separated list of phases, e.g. "perf,funccall",
+// and returns them in run order without duplicates.
+func ParsePhases(s string) ([]string, error) {
+	selected := make(map[string]bool)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.ToLower(strings.TrimSpace(part))
+		if part == "" {
+			continue
+		}
+		if !slices.Contains(Phases, part) {
+			return nil, fmt.Errorf("unknown phase %q (want %s)", part, strings.Join(Phases, ", "))
+		}
+		selected[part] = true
+	}
+	var phases []string
+	for _, phase := range Phases {
+		if selected[phase] {
+			phases = append(phases, phase)
+		}
+	}
+	if len(phases) == 0 {
+		return nil, fmt.Errorf("no phases selected")
+	}
+	return phases, nil
+}
+
+// HasPhase reports whether phase was run. Reports without a phase list ran
+// every phase.
+func (report *FullTestReport) HasPhase(phase string) bool {
+	return len(report.Phases) == 0 || slices.Contains(report.Phases, phase)
+}
+
 // Runner executes the full test suite.
 type Runner struct {
 	cfg            *config.GlobalConfig
@@ -203,29 +244,30 @@ type Runner struct {
 
 // NewRunner creates a new full test runner.
 func NewRunner(cfg *config.GlobalConfig, p provider.Provider, transcriptFile, outputDir string) *Runner {
-	// Create HTTP client for function call test
-	transport := &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: cfg.InsecureTLS},
-	}
-
 	return &Runner{
 		cfg:            cfg,
 		p:              p,
 		transcriptFile: transcriptFile,
 		outputDir:      outputDir,
-		httpClient: &http.Client{
-			Transport: transport,
-			Timeout:   time.Duration(cfg.TimeoutSec) * time.Second,
-		},
+		httpClient:     httpclient.New(cfg),
 	}
 }
 
 // Run executes the full test suite and returns the combined report.
 func (r *Runner) Run() (*FullTestReport, error) {
 	report := &FullTestReport{
+		RunName:   r.cfg.RunName,
 		ModelName: r.cfg.ModelName,
 		APIURL:    r.cfg.URL,
 		StartTime: time.Now(),
+		Phases:    Phases,
+	}
+	if r.cfg.FullTestPhases != "" {
+		phases, err := ParsePhases(r.cfg.FullTestPhases)
+		if err != nil {
+			return nil, fmt.Errorf("invalid phases: %w", err)
+		}
+		report.Phases = phases
 	}
 
 	// Create output directory
@@ -252,26 +294,113 @@ func (r *Runner) Run() (*FullTestReport, error) {
 	r.writeLog("Time: %s", time.Now().Format("2006-01-02 15:04:05"))
 	r.writeLog("=" + strings.Repeat("=", 79))
 
-	r.printHeader()
+	r.printHeader(report.Phases)
 
 	// ===== Collect Environment Info =====
-	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-	fmt.Println("🖥️  Collecting Environment Info")
-	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-	fmt.Println()
+	fmt.Fprintln(r.cfg.Stdout(), "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	fmt.Fprintln(r.cfg.Stdout(), "🖥️  Collecting Environment Info")
+	fmt.Fprintln(r.cfg.Stdout(), "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	fmt.Fprintln(r.cfg.Stdout())
 	report.Environment = r.collectEnvironmentInfo()
 	r.printEnvironmentInfo(report.Environment)
-	fmt.Println()
+	fmt.Fprintln(r.cfg.Stdout())
+
+	if report.HasPhase(PhasePerf) {
+		if err := r.runPerformancePhase(report); err != nil {
+			return nil, err
+		}
+	}
+
+	if report.HasPhase(PhaseFuncCall) {
+		// ===== Phase 2: Function Call Test =====
+		fmt.Fprintln(r.cfg.Stdout(), "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+		fmt.Fprintln(r.cfg.Stdout(), "🔧 Phase 2: Function Call Test")
+		fmt.Fprintln(r.cfg.Stdout(), "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+		fmt.Fprintln(r.cfg.Stdout())
+
+		report.FunctionCallResult = r.runFunctionCallTest()
+		r.printFunctionCallResult(report.FunctionCallResult)
+
+		fmt.Fprintln(r.cfg.Stdout(), "✅ Phase 2 Complete!")
+		fmt.Fprintln(r.cfg.Stdout())
+	}
+
+	if report.HasPhase(PhaseLongCtx) {
+		// ===== Phase 3: Long Context Test =====
+		fmt.Fprintln(r.cfg.Stdout(), "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+		fmt.Fprintln(r.cfg.Stdout(), "📏 Phase 3: Long Context Test")
+		fmt.Fprintln(r.cfg.Stdout(), "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+		fmt.Fprintln(r.cfg.Stdout())
+
+		report.LongContextResult = r.runLongContextTest()
+		r.printLongContextResult(report.LongContextResult)
 
+		fmt.Fprintln(r.cfg.Stdout(), "✅ Phase 3 Complete!")
+		fmt.Fprintln(r.cfg.Stdout())
+
+		// ===== Phase 3.5: Long Context Concurrent Test =====
+		fmt.Fprintln(r.cfg.Stdout(), "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+		fmt.Fprintln(r.cfg.Stdout(), "📏 Phase 3.5: Long Context Concurrent Test")
+		fmt.Fprintln(r.cfg.Stdout(), "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+		fmt.Fprintln(r.cfg.Stdout())
+		fmt.Fprintln(r.cfg.Stdout(), "Testing concurrent long context requests with varied prompts (defeats prefix caching)...")
+		fmt.Fprintln(r.cfg.Stdout())
+
+		report.LongContextConcurrentResult = r.runLongContextConcurrentTest()
+		r.printLongContextConcurrentResult(report.LongContextConcurrentResult)
+
+		fmt.Fprintln(r.cfg.Stdout(), "✅ Phase 3.5 Complete!")
+		fmt.Fprintln(r.cfg.Stdout())
+	}
+
+	if report.HasPhase(PhaseSummary) {
+		// ===== Phase 4: Meeting Summary Test =====
+		fmt.Fprintln(r.cfg.Stdout(), "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+		fmt.Fprintln(r.cfg.Stdout(), "📝 Phase 4: Meeting Summary Test")
+		fmt.Fprintln(r.cfg.Stdout(), "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+		fmt.Fprintln(r.cfg.Stdout())
+
+		if r.transcriptFile != "" {
+			summaryDir := filepath.Join(r.outputDir, "summary")
+			summaryContent, summaryMetrics, err := r.runSummary(summaryDir)
+			if err != nil {
+				fmt.Fprintf(r.cfg.Stdout(), "⚠️  Summary test failed: %v\n", err)
+			} else {
+				report.SummaryOutputDir = summaryDir
+				report.SummaryMetrics = summaryMetrics
+				report.SummaryContent = summaryContent
+				fmt.Fprintln(r.cfg.Stdout(), "✅ Phase 4 Complete!")
+			}
+		} else {
+			fmt.Fprintln(r.cfg.Stdout(), "⚠️  No transcript file provided, skipping summary test")
+		}
+		fmt.Fprintln(r.cfg.Stdout())
+	}
+
+	// Finalize report
+	report.EndTime = time.Now()
+	report.TotalDuration = report.EndTime.Sub(report.StartTime)
+
+	// Generate final report
+	if err := r.generateFinalReport(report); err != nil {
+		return nil, fmt.Errorf("failed to generate final report: %w", err)
+	}
+
+	return report, nil
+}
+
+// runPerformancePhase runs Phase 1 (first call, concurrent, multi-turn and
+// the standard benchmark) and Phase 1.5 (graduated concurrency).
+func (r *Runner) runPerformancePhase(report *FullTestReport) error {
 	// ===== Phase 1: Performance Benchmark =====
-	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-	fmt.Println("📊 Phase 1: Performance Benchmark")
-	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-	fmt.Println()
+	fmt.Fprintln(r.cfg.Stdout(), "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	fmt.Fprintln(r.cfg.Stdout(), "📊 Phase 1: Performance Benchmark")
+	fmt.Fprintln(r.cfg.Stdout(), "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	fmt.Fprintln(r.cfg.Stdout())
 
 	benchmarkDir := filepath.Join(r.outputDir, "benchmark")
 	if err := os.MkdirAll(benchmarkDir, 0755); err != nil {
-		return nil, fmt.Errorf("failed to create benchmark directory: %w", err)
+		return fmt.Errorf("failed to create benchmark directory: %w", err)
 	}
 
 	// Set appropriate max_tokens for full-test (balanced for complete answers)
@@ -280,21 +409,21 @@ func (r *Runner) Run() (*FullTestReport, error) {
 	originalMaxTokens := r.cfg.MaxTokens
 	if r.cfg.MaxTokens > 1024 || r.cfg.MaxTokens == 0 {
 		r.cfg.MaxTokens = 1024
-		fmt.Printf("📝 Note: Set max_tokens to %d for full-test\n\n", r.cfg.MaxTokens)
+		fmt.Fprintf(r.cfg.Stdout(), "📝 Note: Set max_tokens to %d for full-test\n\n", r.cfg.MaxTokens)
 	}
 
 	// 1.1 First Call Test
-	fmt.Println("📌 1.1 First Call Test (冷启动测试)")
+	fmt.Fprintln(r.cfg.Stdout(), "📌 1.1 First Call Test (冷启动测试)")
 	report.FirstCallResults = r.runFirstCallTest(3)
 	r.printPhaseResults(report.FirstCallResults)
 
 	// 1.2 Concurrent Test
-	fmt.Println("📌 1.2 Concurrent Test (并发测试, 2并发)")
+	fmt.Fprintln(r.cfg.Stdout(), "📌 1.2 Concurrent Test (并发测试, 2并发)")
 	report.ConcurrentResults = r.runConcurrentTest(2, 2)
 	r.printPhaseResults(report.ConcurrentResults)
 
 	// 1.3 Multi-turn Test
-	fmt.Println("📌 1.3 Multi-turn Test (多轮对话)")
+	fmt.Fprintln(r.cfg.Stdout(), "📌 1.3 Multi-turn Test (多轮对话)")
 	report.MultiTurnResults = r.runMultiTurnTest(5)
 	r.printPhaseResults(report.MultiTurnResults)
 
@@ -307,7 +436,7 @@ func (r *Runner) Run() (*FullTestReport, error) {
 	benchRunner := runner.New(&benchCfg, r.p)
 	benchReport, err := benchRunner.Run()
 	if err != nil {
-		fmt.Printf("⚠️  Standard benchmark failed: %v\n", err)
+		fmt.Fprintf(r.cfg.Stdout(), "⚠️  Standard benchmark failed: %v\n", err)
 	} else {
 		report.BenchmarkReport = benchReport
 		report.BenchmarkOutputDir = benchmarkDir
@@ -316,102 +445,35 @@ func (r *Runner) Run() (*FullTestReport, error) {
 	// Restore original max_tokens
 	r.cfg.MaxTokens = originalMaxTokens
 
-	fmt.Println("✅ Phase 1 Complete!")
-	fmt.Println()
+	fmt.Fprintln(r.cfg.Stdout(), "✅ Phase 1 Complete!")
+	fmt.Fprintln(r.cfg.Stdout())
 
 	// ===== Phase 1.5: Graduated Concurrency Test =====
-	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-	fmt.Println("📈 Phase 1.5: Graduated Concurrency Test (逐级并发测试)")
-	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-	fmt.Println()
+	fmt.Fprintln(r.cfg.Stdout(), "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	fmt.Fprintln(r.cfg.Stdout(), "📈 Phase 1.5: Graduated Concurrency Test (逐级并发测试)")
+	fmt.Fprintln(r.cfg.Stdout(), "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	fmt.Fprintln(r.cfg.Stdout())
 
 	report.GraduatedConcurrency = r.runGraduatedConcurrencyTest()
 
-	fmt.Println("✅ Phase 1.5 Complete!")
-	fmt.Println()
-
-	// ===== Phase 2: Function Call Test =====
-	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-	fmt.Println("🔧 Phase 2: Function Call Test")
-	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-	fmt.Println()
-
-	report.FunctionCallResult = r.runFunctionCallTest()
-	r.printFunctionCallResult(report.FunctionCallResult)
-
-	fmt.Println("✅ Phase 2 Complete!")
-	fmt.Println()
-
-	// ===== Phase 3: Long Context Test =====
-	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-	fmt.Println("📏 Phase 3: Long Context Test")
-	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-	fmt.Println()
-
-	report.LongContextResult = r.runLongContextTest()
-	r.printLongContextResult(report.LongContextResult)
-
-	fmt.Println("✅ Phase 3 Complete!")
-	fmt.Println()
-
-	// ===== Phase 3.5: Long Context Concurrent Test =====
-	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-	fmt.Println("📏 Phase 3.5: Long Context Concurrent Test")
-	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-	fmt.Println()
-	fmt.Println("Testing concurrent long context requests with varied prompts (defeats prefix caching)...")
-	fmt.Println()
-
-	report.LongContextConcurrentResult = r.runLongContextConcurrentTest()
-	r.printLongContextConcurrentResult(report.LongContextConcurrentResult)
-
-	fmt.Println("✅ Phase 3.5 Complete!")
-	fmt.Println()
-
-	// ===== Phase 4: Meeting Summary Test =====
-	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-	fmt.Println("📝 Phase 4: Meeting Summary Test")
-	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-	fmt.Println()
-
-	if r.transcriptFile != "" {
-		summaryDir := filepath.Join(r.outputDir, "summary")
-		summaryContent, summaryMetrics, err := r.runSummary(summaryDir)
-		if err != nil {
-			fmt.Printf("⚠️  Summary test failed: %v\n", err)
-		} else {
-			report.SummaryOutputDir = summaryDir
-			report.SummaryMetrics = summaryMetrics
-			report.SummaryContent = summaryContent
-			fmt.Println("✅ Phase 4 Complete!")
-		}
-	} else {
-		fmt.Println("⚠️  No transcript file provided, skipping summary test")
-	}
-	fmt.Println()
-
-	// Finalize report
-	report.EndTime = time.Now()
-	report.TotalDuration = report.EndTime.Sub(report.StartTime)
-
-	// Generate final report
-	if err := r.generateFinalReport(report); err != nil {
-		return nil, fmt.Errorf("failed to generate final report: %w", err)
-	}
-
-	return report, nil
+	fmt.Fprintln(r.cfg.Stdout(), "✅ Phase 1.5 Complete!")
+	fmt.Fprintln(r.cfg.Stdout())
+	return nil
 }
 
-func (r *Runner) printHeader() {
-	fmt.Println()
-	fmt.Println("╔════════════════════════════════════════════════════════════════╗")
-	fmt.Println("║              LLM Benchmark Kit - Full Test Mode                ║")
-	fmt.Println("╚════════════════════════════════════════════════════════════════╝")
-	fmt.Println()
-	fmt.Printf("📋 Model:     %s\n", r.cfg.ModelName)
-	fmt.Printf("🔗 URL:       %s\n", r.cfg.URL)
-	fmt.Printf("📁 Output:    %s\n", r.outputDir)
-	fmt.Println()
+func (r *Runner) printHeader(phases []string) {
+	fmt.Fprintln(r.cfg.Stdout())
+	fmt.Fprintln(r.cfg.Stdout(), "╔════════════════════════════════════════════════════════════════╗")
+	fmt.Fprintln(r.cfg.Stdout(), "║              LLM Benchmark Kit - Full Test Mode                ║")
+	fmt.Fprintln(r.cfg.Stdout(), "╚════════════════════════════════════════════════════════════════╝")
+	fmt.Fprintln(r.cfg.Stdout())
+	fmt.Fprintf(r.cfg.Stdout(), "📋 Model:     %s\n", r.cfg.ModelName)
+	fmt.Fprintf(r.cfg.Stdout(), "🔗 URL:       %s\n", r.cfg.URL)
+	fmt.Fprintf(r.cfg.Stdout(), "📁 Output:    %s\n", r.outputDir)
+	if len(phases) < len(Phases) {
+		fmt.Fprintf(r.cfg.Stdout(), "🧪 Phases:    %s\n", strings.Join(phases, ", "))
+	}
+	fmt.Fprintln(r.cfg.Stdout())
 }
 
 // writeLog writes a formatted message to the log file
@@ -492,24 +554,58 @@ func (r *Runner) runMultiTurnTest(turns int) *PhaseResult {
 		"请用两句话介绍一本你推荐的书。",
 	}
 
+	// Each turn carries the whole conversation so far, so latency reflects
+	// the growing context the way a real chat session does.
+	var messages []workload.ChatMessage
 	for i := 0; i < turns && i < len(prompts); i++ {
+		if i > 0 {
+			r.thinkTime()
+		}
+
 		name := fmt.Sprintf("turn_%d", i+1)
-		result := r.executeSingleRequest(name, prompts[i])
+		messages = append(messages, workload.ChatMessage{Role: "user", Content: prompts[i]})
+		result, reply := r.executeChatRequest(name, messages)
+		result.ContextMessages = len(messages)
 		results = append(results, result)
+
+		if result.Success {
+			messages = append(messages, workload.ChatMessage{Role: "assistant", Content: reply})
+		} else {
+			// Drop the unanswered question so the next turn stays well-formed
+			messages = messages[:len(messages)-1]
+		}
 	}
 
 	return r.aggregateResults("Multi-turn Test", results)
 }
 
+// thinkTime sleeps for the configured inter-turn delay plus random jitter.
+func (r *Runner) thinkTime() {
+	delay := time.Duration(r.cfg.TurnDelayMs) * time.Millisecond
+	if r.cfg.TurnJitterMs > 0 {
+		delay += time.Duration(rand.Intn(r.cfg.TurnJitterMs+1)) * time.Millisecond
+	}
+	if delay > 0 {
+		time.Sleep(delay)
+	}
+}
+
 func (r *Runner) executeSingleRequest(name, prompt string) TestResult {
+	result, _ := r.executeChatRequest(name, []workload.ChatMessage{
+		{Role: "user", Content: prompt},
+	})
+	return result
+}
+
+// executeChatRequest sends one streaming request and returns its result along
+// with the visible response text.
+func (r *Runner) executeChatRequest(name string, messages []workload.ChatMessage) (TestResult, string) {
 	start := time.Now()
 
 	// Build raw request body for logging
 	requestBody := map[string]interface{}{
-		"model": r.cfg.ModelName,
-		"messages": []map[string]string{
-			{"role": "user", "content": prompt},
-		},
+		"model":      r.cfg.ModelName,
+		"messages":   messages,
 		"max_tokens": r.cfg.MaxTokens,
 		"stream":     true,
 	}
@@ -526,7 +622,7 @@ func (r *Runner) executeSingleRequest(name, prompt string) TestResult {
 	r.writeLog("Headers:")
 	r.writeLog("  Content-Type: application/json")
 	if r.cfg.Token != "" {
-		r.writeLog("  Authorization: Bearer %s...", r.cfg.Token[:min(10, len(r.cfg.Token))])
+		r.writeLog("  Authorization: Bearer %s", r.cfg.RedactedToken())
 	}
 	r.writeLog("Body:")
 	r.writeLog("%s", string(rawRequestBody))
@@ -535,9 +631,7 @@ func (r *Runner) executeSingleRequest(name, prompt string) TestResult {
 	defer cancel()
 
 	// Create workload input using the proper type
-	input := workload.NewChatWorkload(name, []workload.ChatMessage{
-		{Role: "user", Content: prompt},
-	}, r.cfg.MaxTokens)
+	input := workload.NewChatWorkload(name, messages, r.cfg.MaxTokens)
 
 	// Use the provider's StreamChat
 	events, err := r.p.StreamChat(ctx, r.cfg, input)
@@ -552,7 +646,7 @@ func (r *Runner) executeSingleRequest(name, prompt string) TestResult {
 			Success:   false,
 			LatencyMs: float64(time.Since(start).Milliseconds()),
 			Error:     err.Error(),
-		}
+		}, ""
 	}
 
 	// Log raw response
@@ -561,8 +655,8 @@ func (r *Runner) executeSingleRequest(name, prompt string) TestResult {
 	r.writeLog("[%s] RESPONSE (SSE Stream)", name)
 	r.writeLog("────────────────────────────────────────────────────────────────")
 
-	var tokens int
-	var responseContent strings.Builder
+	var tokens, promptTokens int
+	var responseContent, replyContent strings.Builder
 	var rawFrames []string
 	for event := range events {
 		// Capture raw SSE frame
@@ -573,8 +667,12 @@ func (r *Runner) executeSingleRequest(name, prompt string) TestResult {
 		if event.Type == provider.EventContent || event.Type == provider.EventReasoning {
 			responseContent.WriteString(event.Text)
 		}
+		if event.Type == provider.EventContent {
+			replyContent.WriteString(event.Text)
+		}
 		if event.Type == provider.EventUsage && event.Usage != nil {
 			tokens = event.Usage.CompletionTokens
+			promptTokens = event.Usage.PromptTokens
 		}
 		if event.Type == provider.EventError {
 			r.writeLog("Error: %s", event.Err.Error())
@@ -584,7 +682,7 @@ func (r *Runner) executeSingleRequest(name, prompt string) TestResult {
 				Success:   false,
 				LatencyMs: float64(time.Since(start).Milliseconds()),
 				Error:     event.Err.Error(),
-			}
+			}, ""
 		}
 	}
 
@@ -599,11 +697,12 @@ func (r *Runner) executeSingleRequest(name, prompt string) TestResult {
 	r.writeLog("Status: SUCCESS")
 
 	return TestResult{
-		Name:      name,
-		Success:   true,
-		LatencyMs: latency,
-		Tokens:    tokens,
-	}
+		Name:         name,
+		Success:      true,
+		LatencyMs:    latency,
+		Tokens:       tokens,
+		PromptTokens: promptTokens,
+	}, replyContent.String()
 }
 
 func (r *Runner) aggregateResults(phaseName string, results []TestResult) *PhaseResult {
@@ -636,13 +735,16 @@ func (r *Runner) aggregateResults(phaseName string, results []TestResult) *Phase
 
 func (r *Runner) printPhaseResults(phase *PhaseResult) {
 	for _, res := range phase.Results {
-		if res.Success {
-			fmt.Printf("   ✅ %-15s | %8.2f ms | %4d tokens\n", res.Name, res.LatencyMs, res.Tokens)
+		if res.Success && res.ContextMessages > 0 {
+			fmt.Fprintf(r.cfg.Stdout(), "   ✅ %-15s | %8.2f ms | %4d tokens | context: %d msgs, %d prompt tokens\n",
+				res.Name, res.LatencyMs, res.Tokens, res.ContextMessages, res.PromptTokens)
+		} else if res.Success {
+			fmt.Fprintf(r.cfg.Stdout(), "   ✅ %-15s | %8.2f ms | %4d tokens\n", res.Name, res.LatencyMs, res.Tokens)
 		} else {
-			fmt.Printf("   ❌ %-15s | %8.2f ms | Error: %s\n", res.Name, res.LatencyMs, res.Error)
+			fmt.Fprintf(r.cfg.Stdout(), "   ❌ %-15s | %8.2f ms | Error: %s\n", res.Name, res.LatencyMs, res.Error)
 		}
 	}
-	fmt.Printf("   平均延迟: %.2f ms | 成功: %d/%d\n\n", phase.AvgLatencyMs, phase.Success, phase.Success+phase.Failure)
+	fmt.Fprintf(r.cfg.Stdout(), "   平均延迟: %.2f ms | 成功: %d/%d\n\n", phase.AvgLatencyMs, phase.Success, phase.Success+phase.Failure)
 }
 
 // ========== Environment Info Collection ==========
@@ -722,28 +824,28 @@ func (r *Runner) collectEnvironmentInfo() *EnvironmentInfo {
 }
 
 func (r *Runner) printEnvironmentInfo(env *EnvironmentInfo) {
-	fmt.Println("   ┌─────────────────────────────────────────────────────────────┐")
-	fmt.Printf("   │ 主机名:    %-48s│\n", env.Hostname)
+	fmt.Fprintln(r.cfg.Stdout(), "   ┌─────────────────────────────────────────────────────────────┐")
+	fmt.Fprintf(r.cfg.Stdout(), "   │ 主机名:    %-48s│\n", env.Hostname)
 	if env.ExtraInfo["os_release"] != "" {
-		fmt.Printf("   │ 操作系统:  %-48s│\n", env.ExtraInfo["os_release"])
+		fmt.Fprintf(r.cfg.Stdout(), "   │ 操作系统:  %-48s│\n", env.ExtraInfo["os_release"])
 	}
-	fmt.Printf("   │ OS/Arch:   %-48s│\n", env.OS+"/"+env.Arch)
-	fmt.Printf("   │ 内核:      %-48s│\n", env.Kernel)
-	fmt.Printf("   │ CPU型号:   %-48s│\n", env.CPUModel)
-	fmt.Printf("   │ CPU核心:   %-3d 核 / %-3d 线程%-30s│\n", env.CPUCores, env.CPUThreads, "")
-	fmt.Printf("   │ 总内存:    %-48s│\n", env.TotalMemory)
+	fmt.Fprintf(r.cfg.Stdout(), "   │ OS/Arch:   %-48s│\n", env.OS+"/"+env.Arch)
+	fmt.Fprintf(r.cfg.Stdout(), "   │ 内核:      %-48s│\n", env.Kernel)
+	fmt.Fprintf(r.cfg.Stdout(), "   │ CPU型号:   %-48s│\n", env.CPUModel)
+	fmt.Fprintf(r.cfg.Stdout(), "   │ CPU核心:   %-3d 核 / %-3d 线程%-30s│\n", env.CPUCores, env.CPUThreads, "")
+	fmt.Fprintf(r.cfg.Stdout(), "   │ 总内存:    %-48s│\n", env.TotalMemory)
 	if env.GPUInfo != "" {
 		lines := strings.Split(env.GPUInfo, "\n")
 		for i, line := range lines {
 			if i == 0 {
-				fmt.Printf("   │ GPU:       %-48s│\n", strings.TrimSpace(line))
+				fmt.Fprintf(r.cfg.Stdout(), "   │ GPU:       %-48s│\n", strings.TrimSpace(line))
 			} else {
-				fmt.Printf("   │            %-48s│\n", strings.TrimSpace(line))
+				fmt.Fprintf(r.cfg.Stdout(), "   │            %-48s│\n", strings.TrimSpace(line))
 			}
 		}
 	}
-	fmt.Printf("   │ Go版本:    %-48s│\n", env.GoVersion)
-	fmt.Println("   └─────────────────────────────────────────────────────────────┘")
+	fmt.Fprintf(r.cfg.Stdout(), "   │ Go版本:    %-48s│\n", env.GoVersion)
+	fmt.Fprintln(r.cfg.Stdout(), "   └─────────────────────────────────────────────────────────────┘")
 }
 
 // ========== Graduated Concurrency Test ==========
@@ -777,10 +879,10 @@ func (r *Runner) runGraduatedConcurrencyTest() *GraduatedConcurrencyResult {
 		"请用一句话描述数字孪生。",
 	}
 
-	fmt.Printf("   并发级别: %v | 每级请求数: max(并发数×2, 12)\n\n", concurrencyLevels)
-	fmt.Println("   ┌───────────┬──────────┬──────────┬──────────────┬──────────────┬──────────────┬──────────┬──────────┐")
-	fmt.Println("   │ 并发数    │ 成功/总数│ 平均延迟 │ 最小延迟     │ 最大延迟     │ 吞吐(tok/s)  │ RPS      │ 耗时(ms) │")
-	fmt.Println("   ├───────────┼──────────┼──────────┼──────────────┼──────────────┼──────────────┼──────────┼──────────┤")
+	fmt.Fprintf(r.cfg.Stdout(), "   并发级别: %v | 每级请求数: max(并发数×2, 12)\n\n", concurrencyLevels)
+	fmt.Fprintln(r.cfg.Stdout(), "   ┌───────────┬──────────┬──────────┬──────────────┬──────────────┬──────────────┬──────────┬──────────┐")
+	fmt.Fprintln(r.cfg.Stdout(), "   │ 并发数    │ 成功/总数│ 平均延迟 │ 最小延迟     │ 最大延迟     │ 吞吐(tok/s)  │ RPS      │ 耗时(ms) │")
+	fmt.Fprintln(r.cfg.Stdout(), "   ├───────────┼──────────┼──────────┼──────────────┼──────────────┼──────────────┼──────────┼──────────┤")
 
 	for _, concurrency := range concurrencyLevels {
 		// Dynamic requests: at least 2x concurrency to fully saturate, minimum 12
@@ -791,7 +893,7 @@ func (r *Runner) runGraduatedConcurrencyTest() *GraduatedConcurrencyResult {
 		levelResult := r.runSingleConcurrencyLevel(concurrency, requestsForLevel, prompts)
 		result.Levels = append(result.Levels, levelResult)
 
-		fmt.Printf("   │ %-9d │ %4d/%-4d│ %8.0f │ %10.0f   │ %10.0f   │ %10.1f   │ %6.2f   │ %8.0f │\n",
+		fmt.Fprintf(r.cfg.Stdout(), "   │ %-9d │ %4d/%-4d│ %8.0f │ %10.0f   │ %10.0f   │ %10.1f   │ %6.2f   │ %8.0f │\n",
 			levelResult.Concurrency,
 			levelResult.SuccessCount, levelResult.TotalRequests,
 			levelResult.AvgLatencyMs,
@@ -802,8 +904,8 @@ func (r *Runner) runGraduatedConcurrencyTest() *GraduatedConcurrencyResult {
 			levelResult.WallTimeMs)
 	}
 
-	fmt.Println("   └───────────┴──────────┴──────────┴──────────────┴──────────────┴──────────────┴──────────┴──────────┘")
-	fmt.Println()
+	fmt.Fprintln(r.cfg.Stdout(), "   └───────────┴──────────┴──────────┴──────────────┴──────────────┴──────────────┴──────────┴──────────┘")
+	fmt.Fprintln(r.cfg.Stdout())
 
 	return result
 }
@@ -937,210 +1039,53 @@ func (r *Runner) runSingleConcurrencyLevel(concurrency, totalRequests int, promp
 	return levelResult
 }
 
-// ========== Phase 2: Function Call Test ==========
-
-func (r *Runner) runFunctionCallTest() *FunctionCallResult {
-	fmt.Println("   测试 Query: \"北京今天天气怎么样？\"")
-	fmt.Println("   期望调用: get_weather(city=\"北京\")")
-	fmt.Println()
-
-	result := &FunctionCallResult{}
-	start := time.Now()
-
-	// Build request with tools
-	requestBody := map[string]interface{}{
-		"model": r.cfg.ModelName,
-		"messages": []map[string]string{
-			{"role": "user", "content": "北京今天天气怎么样？"},
-		},
-		"max_tokens": 512, // Enough for function call response
-		"stream":     false,
-		"tools": []map[string]interface{}{
-			{
-				"type": "function",
-				"function": map[string]interface{}{
-					"name":        "get_weather",
-					"description": "获取指定城市的天气信息",
-					"parameters": map[string]interface{}{
-						"type": "object",
-						"properties": map[string]interface{}{
-							"city": map[string]string{
-								"type":        "string",
-								"description": "城市名称",
-							},
-						},
-						"required": []string{"city"},
-					},
-				},
-			},
-		},
-		"tool_choice": "auto",
-	}
-
-	jsonBody, _ := json.Marshal(requestBody)
-	prettyReq, _ := json.MarshalIndent(requestBody, "", "  ")
-
-	// Log raw request
-	r.writeLog("")
-	r.writeLog("════════════════════════════════════════════════════════════════")
-	r.writeLog("[Function Call Test] REQUEST")
-	r.writeLog("════════════════════════════════════════════════════════════════")
-	r.writeLog("Time: %s", start.Format("2006-01-02 15:04:05.000"))
-	r.writeLog("URL: %s", r.cfg.URL)
-	r.writeLog("Method: POST")
-	r.writeLog("Headers:")
-	r.writeLog("  Content-Type: application/json")
-	if r.cfg.Token != "" {
-		r.writeLog("  Authorization: Bearer %s...", r.cfg.Token[:min(10, len(r.cfg.Token))])
-	}
-	r.writeLog("Body:")
-	r.writeLog("%s", string(prettyReq))
-
-	req, err := http.NewRequest("POST", r.cfg.URL, bytes.NewBuffer(jsonBody))
-	if err != nil {
-		result.Error = err.Error()
-		r.writeLog("Error: %s", err.Error())
-		return result
-	}
-
-	req.Header.Set("Content-Type", "application/json")
-	if r.cfg.Token != "" {
-		req.Header.Set("Authorization", "Bearer "+r.cfg.Token)
-	}
-
-	resp, err := r.httpClient.Do(req)
-	if err != nil {
-		result.Error = err.Error()
-		result.LatencyMs = float64(time.Since(start).Milliseconds())
-		r.writeLog("")
-		r.writeLog("[Function Call Test] RESPONSE (ERROR)")
-		r.writeLog("Error: %s", err.Error())
-		r.writeLog("Latency: %.2f ms", result.LatencyMs)
-		return result
-	}
-	defer resp.Body.Close()
-
-	result.LatencyMs = float64(time.Since(start).Milliseconds())
-
-	// Read raw response body
-	body, _ := io.ReadAll(resp.Body)
+// ========== Phase 3: Long Context Test ==========
 
-	// Log raw response
-	r.writeLog("")
-	r.writeLog("────────────────────────────────────────────────────────────────")
-	r.writeLog("[Function Call Test] RESPONSE")
-	r.writeLog("────────────────────────────────────────────────────────────────")
-	r.writeLog("HTTP Status: %d %s", resp.StatusCode, resp.Status)
-	r.writeLog("Headers:")
-	for key, values := range resp.Header {
-		for _, value := range values {
-			r.writeLog("  %s: %s", key, value)
+// defaultContextLadder is the context lengths (characters) of the long context test.
+var defaultContextLadder = []int{1000, 4000, 8000, 16000, 32000}
+
+// ParseContextLadder parses a comma-separated list of context lengths in
+// characters, e.g. "2000,8000,32000".
+func ParseContextLadder(s string) ([]int, error) {
+	var ladder []int
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
 		}
-	}
-	r.writeLog("Body:")
-	// Pretty print if JSON
-	var prettyResp bytes.Buffer
-	if json.Indent(&prettyResp, body, "", "  ") == nil {
-		r.writeLog("%s", prettyResp.String())
-	} else {
-		r.writeLog("%s", string(body))
-	}
-	r.writeLog("Latency: %.2f ms", result.LatencyMs)
-
-	if resp.StatusCode != 200 {
-		result.Error = fmt.Sprintf("HTTP %d: %s", resp.StatusCode, string(body))
-		r.writeLog("Status: FAILED")
-		return result
-	}
-
-	// Parse response
-	var respData struct {
-		Choices []struct {
-			Message struct {
-				ToolCalls []struct {
-					Function struct {
-						Name      string `json:"name"`
-						Arguments string `json:"arguments"`
-					} `json:"function"`
-				} `json:"tool_calls"`
-			} `json:"message"`
-		} `json:"choices"`
-	}
-
-	if err := json.Unmarshal(body, &respData); err != nil {
-		result.Error = fmt.Sprintf("Failed to parse response: %v", err)
-		r.writeLog("Parse Error: %s", result.Error)
-		r.writeLog("Status: FAILED")
-		return result
-	}
-
-	// Check if function call is supported
-	r.writeLog("")
-	r.writeLog("[Function Call Test] SUMMARY")
-	if len(respData.Choices) > 0 && len(respData.Choices[0].Message.ToolCalls) > 0 {
-		result.Supported = true
-		toolCall := respData.Choices[0].Message.ToolCalls[0]
-		result.FunctionName = toolCall.Function.Name
-		result.Arguments = toolCall.Function.Arguments
-
-		// Verify function name
-		result.CorrectFunction = toolCall.Function.Name == "get_weather"
-
-		// Verify arguments contain city
-		var args map[string]interface{}
-		if json.Unmarshal([]byte(toolCall.Function.Arguments), &args) == nil {
-			if city, ok := args["city"]; ok {
-				result.CorrectArgs = city != nil && city != ""
-			}
+		n, err := strconv.Atoi(part)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid context length %q (want a positive number of characters)", part)
 		}
-		r.writeLog("Function Call Supported: YES")
-		r.writeLog("Function Name: %s", result.FunctionName)
-		r.writeLog("Arguments: %s", result.Arguments)
-		r.writeLog("Correct Function: %v", result.CorrectFunction)
-		r.writeLog("Correct Args: %v", result.CorrectArgs)
-		r.writeLog("Status: SUCCESS")
-	} else {
-		result.Supported = false
-		r.writeLog("Function Call Supported: NO")
-		r.writeLog("Status: Function Call NOT SUPPORTED")
+		ladder = append(ladder, n)
 	}
-
-	return result
-}
-
-func (r *Runner) printFunctionCallResult(result *FunctionCallResult) {
-	if result.Error != "" {
-		fmt.Printf("   ❌ 测试失败: %s\n", result.Error)
-		return
+	if len(ladder) == 0 {
+		return nil, fmt.Errorf("context ladder is empty")
 	}
+	return ladder, nil
+}
 
-	if result.Supported {
-		fmt.Printf("   ✅ Function Call 支持: 是\n")
-		if result.CorrectFunction {
-			fmt.Printf("   ✅ 正确识别函数: %s\n", result.FunctionName)
-		} else {
-			fmt.Printf("   ❌ 函数名不匹配: %s (期望: get_weather)\n", result.FunctionName)
-		}
-		if result.CorrectArgs {
-			fmt.Printf("   ✅ 参数解析正确: %s\n", result.Arguments)
-		} else {
-			fmt.Printf("   ⚠️  参数可能不完整: %s\n", result.Arguments)
+// longContextFiller returns the text repeated to build long contexts: the
+// -context-filler-file contents, or the built-in Chinese paragraph.
+func (r *Runner) longContextFiller() string {
+	if r.cfg.ContextFillerFile != "" {
+		data, err := os.ReadFile(r.cfg.ContextFillerFile)
+		if err != nil {
+			fmt.Fprintf(r.cfg.Stdout(), "   ⚠️  failed to read context filler: %v (using built-in text)\n", err)
+		} else if filler := strings.TrimSpace(string(data)); filler != "" {
+			return filler
 		}
-	} else {
-		fmt.Printf("   ❌ Function Call 支持: 否 (模型未返回 tool_calls)\n")
 	}
-	fmt.Printf("   ⏱️  响应延迟: %.2f ms\n\n", result.LatencyMs)
+	return defaultContextFiller
 }
 
-// ========== Phase 3: Long Context Test ==========
-
-// generateLongContext generates a context of specified character length
-func (r *Runner) generateLongContext(targetChars int) string {
-	// Base content to repeat (approximately 500 chars per block)
-	baseContent := `这是一段用于测试长上下文能力的文本内容。在人工智能和大语言模型的发展过程中，处理长文本的能力变得越来越重要。
+// defaultContextFiller is approximately 500 chars of Chinese text.
+const defaultContextFiller = `这是一段用于测试长上下文能力的文本内容。在人工智能和大语言模型的发展过程中，处理长文本的能力变得越来越重要。
 现代的大语言模型需要能够理解和处理长达数万甚至数十万字符的输入文本。这对于文档摘要、长篇对话、代码理解等任务至关重要。
 我们通过不同长度的上下文来测试模型的处理能力，包括响应时间、首字延迟和输出质量等指标。`
 
+// generateLongContext generates a context of specified character length
+func (r *Runner) generateLongContext(baseContent string, targetChars int) string {
 	// Calculate how many times to repeat
 	repeats := (targetChars / len(baseContent)) + 1
 
@@ -1161,20 +1106,28 @@ func (r *Runner) runLongContextTest() *LongContextResult {
 		Results: make([]LongContextTestResult, 0),
 	}
 
-	// Test different context lengths: 1K, 4K, 8K, 16K, 32K chars
-	// Approximately 1 Chinese char ≈ 0.7 token, 1 English word ≈ 1.3 token
-	contextLengths := []int{1000, 4000, 8000, 16000, 32000}
+	// Test different context lengths: 1K, 4K, 8K, 16K, 32K chars unless -context-ladder is set
+	contextLengths := defaultContextLadder
+	if r.cfg.ContextLadder != "" {
+		ladder, err := ParseContextLadder(r.cfg.ContextLadder)
+		if err != nil {
+			fmt.Fprintf(r.cfg.Stdout(), "   ⚠️  %v (using default ladder)\n", err)
+		} else {
+			contextLengths = ladder
+		}
+	}
+	filler := r.longContextFiller()
 
-	fmt.Println("   测试不同上下文长度下的模型性能...")
-	fmt.Println("   ┌─────────────┬──────────────┬──────────────┬──────────────┬──────────────┬────────┐")
-	fmt.Println("   │ 上下文长度  │ 估算Tokens   │ TTFT (ms)    │ Latency (ms) │ 吞吐 (tok/s) │ 状态   │")
-	fmt.Println("   ├─────────────┼──────────────┼──────────────┼──────────────┼──────────────┼────────┤")
+	fmt.Fprintln(r.cfg.Stdout(), "   测试不同上下文长度下的模型性能...")
+	fmt.Fprintln(r.cfg.Stdout(), "   ┌─────────────┬──────────────┬──────────────┬──────────────┬──────────────┬────────┐")
+	fmt.Fprintln(r.cfg.Stdout(), "   │ 上下文长度  │ 估算Tokens   │ TTFT (ms)    │ Latency (ms) │ 吞吐 (tok/s) │ 状态   │")
+	fmt.Fprintln(r.cfg.Stdout(), "   ├─────────────┼──────────────┼──────────────┼──────────────┼──────────────┼────────┤")
 
 	var totalTTFT, totalLatency, totalThroughput float64
 	successCount := 0
 
 	for _, length := range contextLengths {
-		testResult := r.executeLongContextRequest(length)
+		testResult := r.executeLongContextRequest(filler, length)
 		result.Results = append(result.Results, testResult)
 
 		// Print result row
@@ -1189,11 +1142,11 @@ func (r *Runner) runLongContextTest() *LongContextResult {
 			result.MaxSupported = length
 		}
 
-		fmt.Printf("   │ %9d字 │ %10d   │ %10.2f   │ %10.2f   │ %10.2f   │ %s     │\n",
+		fmt.Fprintf(r.cfg.Stdout(), "   │ %9d字 │ %10d   │ %10.2f   │ %10.2f   │ %10.2f   │ %s     │\n",
 			length, testResult.InputTokens, testResult.TTFTMs, testResult.LatencyMs, testResult.Throughput, status)
 	}
 
-	fmt.Println("   └─────────────┴──────────────┴──────────────┴──────────────┴──────────────┴────────┘")
+	fmt.Fprintln(r.cfg.Stdout(), "   └─────────────┴──────────────┴──────────────┴──────────────┴──────────────┴────────┘")
 
 	// Calculate averages
 	if successCount > 0 {
@@ -1202,17 +1155,16 @@ func (r *Runner) runLongContextTest() *LongContextResult {
 		result.AvgThroughput = totalThroughput / float64(successCount)
 	}
 
-	fmt.Printf("\n   📊 最大支持上下文: %d 字符\n", result.MaxSupported)
-	fmt.Printf("   📊 平均 TTFT: %.2f ms | 平均 Latency: %.2f ms | 平均吞吐: %.2f tokens/s\n\n",
+	fmt.Fprintf(r.cfg.Stdout(), "\n   📊 最大支持上下文: %d 字符\n", result.MaxSupported)
+	fmt.Fprintf(r.cfg.Stdout(), "   📊 平均 TTFT: %.2f ms | 平均 Latency: %.2f ms | 平均吞吐: %.2f tokens/s\n\n",
 		result.AvgTTFTMs, result.AvgLatencyMs, result.AvgThroughput)
 
 	return result
 }
 
-func (r *Runner) executeLongContextRequest(contextLength int) LongContextTestResult {
+func (r *Runner) executeLongContextRequest(filler string, contextLength int) LongContextTestResult {
 	result := LongContextTestResult{
 		ContextLength: contextLength,
-		InputTokens:   int(float64(contextLength) * 0.7), // Rough estimate for Chinese text
 	}
 
 	start := time.Now()
@@ -1220,7 +1172,7 @@ func (r *Runner) executeLongContextRequest(contextLength int) LongContextTestRes
 	gotFirstToken := false
 
 	// Generate long context
-	longContext := r.generateLongContext(contextLength)
+	longContext := r.generateLongContext(filler, contextLength)
 
 	// Create prompt with long context
 	prompt := fmt.Sprintf(`以下是一段长文本，请阅读后用一句话总结其主题：
@@ -1228,6 +1180,7 @@ func (r *Runner) executeLongContextRequest(contextLength int) LongContextTestRes
 %s
 
 请用一句话（不超过50字）总结上述内容的主题：`, longContext)
+	result.InputTokens = tokenizer.Count(prompt, r.cfg.ModelName)
 
 	// Log the request
 	r.writeLog("")
@@ -1246,7 +1199,7 @@ func (r *Runner) executeLongContextRequest(contextLength int) LongContextTestRes
 		[]workload.ChatMessage{
 			{Role: "user", Content: prompt},
 		},
-		256, // Limited output tokens for summary
+		r.cfg.ContextMaxTokens, // Limited output tokens for summary
 	)
 
 	// Use the provider's StreamChat
@@ -1313,7 +1266,7 @@ func (r *Runner) executeLongContextRequest(contextLength int) LongContextTestRes
 
 func (r *Runner) printLongContextResult(result *LongContextResult) {
 	if result == nil {
-		fmt.Println("   ⚠️ 长上下文测试未完成")
+		fmt.Fprintln(r.cfg.Stdout(), "   ⚠️ 长上下文测试未完成")
 		return
 	}
 
@@ -1324,26 +1277,26 @@ func (r *Runner) printLongContextResult(result *LongContextResult) {
 		}
 	}
 
-	fmt.Printf("   成功: %d/%d | 最大支持: %d 字符 | 平均TTFT: %.2f ms | 平均吞吐: %.2f tokens/s\n\n",
+	fmt.Fprintf(r.cfg.Stdout(), "   成功: %d/%d | 最大支持: %d 字符 | 平均TTFT: %.2f ms | 平均吞吐: %.2f tokens/s\n\n",
 		successCount, len(result.Results), result.MaxSupported, result.AvgTTFTMs, result.AvgThroughput)
 }
 
 func (r *Runner) printLongContextConcurrentResult(result *LongContextConcurrentResult) {
 	if result == nil {
-		fmt.Println("   ⚠️ 长上下文并发测试未完成")
+		fmt.Fprintln(r.cfg.Stdout(), "   ⚠️ 长上下文并发测试未完成")
 		return
 	}
 
-	fmt.Println("   Context(chars) | Concurrency | Success | Avg TTFT(ms) | Avg Latency(ms) | Throughput(tok/s) | P95 TTFT | RPS")
-	fmt.Println("   " + strings.Repeat("-", 120))
+	fmt.Fprintln(r.cfg.Stdout(), "   Context(chars) | Concurrency | Success | Avg TTFT(ms) | Avg Latency(ms) | Throughput(tok/s) | P95 TTFT | RPS")
+	fmt.Fprintln(r.cfg.Stdout(), "   "+strings.Repeat("-", 120))
 
 	for _, level := range result.Levels {
-		fmt.Printf("   %15d | %11d | %3d/%-3d | %12.2f | %15.2f | %17.2f | %8.2f | %8.2f\n",
+		fmt.Fprintf(r.cfg.Stdout(), "   %15d | %11d | %3d/%-3d | %12.2f | %15.2f | %17.2f | %8.2f | %8.2f\n",
 			level.ContextLength, level.Concurrency, level.SuccessCount, level.TotalRequests,
 			level.AvgTTFTMs, level.AvgLatencyMs, level.Throughput,
 			level.P95TTFTMs, level.RPS)
 	}
-	fmt.Println()
+	fmt.Fprintln(r.cfg.Stdout())
 }
 
 // ========== Phase 3.5: Long Context Concurrent Test ==========
@@ -1417,12 +1370,12 @@ func (r *Runner) runLongContextConcurrentTest() *LongContextConcurrentResult {
 	concurrencyLevels := []int{2, 5, 10}
 	requestsMultiplier := 2 // requests = concurrency × multiplier
 
-	fmt.Printf("   上下文长度: %v | 并发级别: %v | 每级请求数: 并发×%d\n", contextLengths, concurrencyLevels, requestsMultiplier)
-	fmt.Println("   ⚠️  每个请求使用不同的段落顺序和问题，避免 prefix cache 影响")
-	fmt.Println()
-	fmt.Println("   ┌──────────┬────────┬──────────┬──────────────┬──────────────┬──────────────┬──────────────┬────────┬──────────┐")
-	fmt.Println("   │ 上下文   │ 并发   │ 成功/总数│ AvgTTFT(ms)  │ P95TTFT(ms)  │ AvgLatency   │ P95Latency   │ RPS    │ 耗时(ms) │")
-	fmt.Println("   ├──────────┼────────┼──────────┼──────────────┼──────────────┼──────────────┼──────────────┼────────┼──────────┤")
+	fmt.Fprintf(r.cfg.Stdout(), "   上下文长度: %v | 并发级别: %v | 每级请求数: 并发×%d\n", contextLengths, concurrencyLevels, requestsMultiplier)
+	fmt.Fprintln(r.cfg.Stdout(), "   ⚠️  每个请求使用不同的段落顺序和问题，避免 prefix cache 影响")
+	fmt.Fprintln(r.cfg.Stdout())
+	fmt.Fprintln(r.cfg.Stdout(), "   ┌──────────┬────────┬──────────┬──────────────┬──────────────┬──────────────┬──────────────┬────────┬──────────┐")
+	fmt.Fprintln(r.cfg.Stdout(), "   │ 上下文   │ 并发   │ 成功/总数│ AvgTTFT(ms)  │ P95TTFT(ms)  │ AvgLatency   │ P95Latency   │ RPS    │ 耗时(ms) │")
+	fmt.Fprintln(r.cfg.Stdout(), "   ├──────────┼────────┼──────────┼──────────────┼──────────────┼──────────────┼──────────────┼────────┼──────────┤")
 
 	globalReqIdx := 0
 	for _, ctxLen := range contextLengths {
@@ -1434,7 +1387,7 @@ func (r *Runner) runLongContextConcurrentTest() *LongContextConcurrentResult {
 			levelResult := r.runSingleLongContextConcurrentLevel(ctxLen, conc, totalReqs, &globalReqIdx)
 			result.Levels = append(result.Levels, levelResult)
 
-			fmt.Printf("   │ %6d字 │ %5d  │ %4d/%-4d│ %10.0f   │ %10.0f   │ %10.0f   │ %10.0f   │ %5.2f  │ %8.0f │\n",
+			fmt.Fprintf(r.cfg.Stdout(), "   │ %6d字 │ %5d  │ %4d/%-4d│ %10.0f   │ %10.0f   │ %10.0f   │ %10.0f   │ %5.2f  │ %8.0f │\n",
 				levelResult.ContextLength, levelResult.Concurrency,
 				levelResult.SuccessCount, levelResult.TotalRequests,
 				levelResult.AvgTTFTMs, levelResult.P95TTFTMs,
@@ -1443,8 +1396,8 @@ func (r *Runner) runLongContextConcurrentTest() *LongContextConcurrentResult {
 		}
 	}
 
-	fmt.Println("   └──────────┴────────┴──────────┴──────────────┴──────────────┴──────────────┴──────────────┴────────┴──────────┘")
-	fmt.Println()
+	fmt.Fprintln(r.cfg.Stdout(), "   └──────────┴────────┴──────────┴──────────────┴──────────────┴──────────────┴──────────────┴────────┴──────────┘")
+	fmt.Fprintln(r.cfg.Stdout())
 
 	return result
 }
@@ -1492,7 +1445,7 @@ func (r *Runner) runSingleLongContextConcurrentLevel(contextLength, concurrency,
 			input := workload.NewChatWorkload(
 				fmt.Sprintf("lcc_%d_c%d_%d", contextLength, concurrency, idx),
 				[]workload.ChatMessage{{Role: "user", Content: context_}},
-				256,
+				r.cfg.ContextMaxTokens,
 			)
 
 			events, err := r.p.StreamChat(ctx, r.cfg, input)
@@ -1603,9 +1556,9 @@ func (r *Runner) runSummary(outputDir string) (string, *summarizer.SummaryMetric
 		return "", nil, fmt.Errorf("transcript file not found: %s", r.transcriptFile)
 	}
 
-	fmt.Printf("   Transcript:   %s\n", r.transcriptFile)
-	fmt.Printf("   Chunk Size:   8000 chars\n")
-	fmt.Println()
+	fmt.Fprintf(r.cfg.Stdout(), "   Transcript:   %s\n", r.transcriptFile)
+	fmt.Fprintf(r.cfg.Stdout(), "   Chunk Size:   8000 chars\n")
+	fmt.Fprintln(r.cfg.Stdout())
 
 	meetingTime := time.Now().Format("2006-01-02 15:04")
 	sum := summarizer.NewSummarizer(r.cfg, 8000, meetingTime)
@@ -1630,11 +1583,17 @@ func (r *Runner) generateFinalReport(report *FullTestReport) error {
 	sb.WriteString("## 基本信息\n\n")
 	sb.WriteString("| 项目 | 值 |\n")
 	sb.WriteString("|------|----|\\n")
+	if report.RunName != "" {
+		sb.WriteString(fmt.Sprintf("| 运行名称 | %s |\n", report.RunName))
+	}
 	sb.WriteString(fmt.Sprintf("| 模型名称 | %s |\n", report.ModelName))
 	sb.WriteString(fmt.Sprintf("| API URL | %s |\n", report.APIURL))
 	sb.WriteString(fmt.Sprintf("| 开始时间 | %s |\n", report.StartTime.Format("2006-01-02 15:04:05")))
 	sb.WriteString(fmt.Sprintf("| 结束时间 | %s |\n", report.EndTime.Format("2006-01-02 15:04:05")))
 	sb.WriteString(fmt.Sprintf("| 总耗时 | %.2f 秒 |\n", report.TotalDuration.Seconds()))
+	if len(report.Phases) < len(Phases) {
+		sb.WriteString(fmt.Sprintf("| 测试阶段 | %s |\n", strings.Join(report.Phases, ", ")))
+	}
 	sb.WriteString("\n")
 
 	// Environment Info
@@ -1659,99 +1618,125 @@ func (r *Runner) generateFinalReport(report *FullTestReport) error {
 		sb.WriteString("\n")
 	}
 
-	// Phase 1: Performance Results
-	sb.WriteString("## Phase 1: 性能测试结果\n\n")
+	if report.HasPhase(PhasePerf) {
+		// Phase 1: Performance Results
+		sb.WriteString("## Phase 1: 性能测试结果\n\n")
 
-	if report.FirstCallResults != nil {
-		sb.WriteString("### 1.1 冷启动测试 (First Call)\n\n")
-		r.writePhaseTable(&sb, report.FirstCallResults)
-	}
+		if report.FirstCallResults != nil {
+			sb.WriteString("### 1.1 冷启动测试 (First Call)\n\n")
+			r.writePhaseTable(&sb, report.FirstCallResults)
+		}
 
-	if report.ConcurrentResults != nil {
-		sb.WriteString("### 1.2 并发测试 (Concurrent)\n\n")
-		r.writePhaseTable(&sb, report.ConcurrentResults)
-	}
+		if report.ConcurrentResults != nil {
+			sb.WriteString("### 1.2 并发测试 (Concurrent)\n\n")
+			r.writePhaseTable(&sb, report.ConcurrentResults)
+		}
 
-	if report.MultiTurnResults != nil {
-		sb.WriteString("### 1.3 多轮对话测试 (Multi-turn)\n\n")
-		r.writePhaseTable(&sb, report.MultiTurnResults)
-	}
+		if report.MultiTurnResults != nil {
+			sb.WriteString("### 1.3 多轮对话测试 (Multi-turn)\n\n")
+			r.writePhaseTable(&sb, report.MultiTurnResults)
+			writeContextGrowth(&sb, report.MultiTurnResults)
+		}
 
-	// Phase 1.5: Graduated Concurrency Results
-	if report.GraduatedConcurrency != nil && len(report.GraduatedConcurrency.Levels) > 0 {
-		sb.WriteString("### 1.5 逐级并发测试 (Graduated Concurrency)\n\n")
-		sb.WriteString("| 并发数 | 成功/总数 | 平均延迟(ms) | 最小延迟(ms) | 最大延迟(ms) | 吞吐(tok/s) | RPS | 耗时(ms) |\n")
-		sb.WriteString("|--------|-----------|-------------|-------------|-------------|-------------|------|--------|\n")
-		for _, lv := range report.GraduatedConcurrency.Levels {
-			sb.WriteString(fmt.Sprintf("| %d | %d/%d | %.0f | %.0f | %.0f | %.1f | %.2f | %.0f |\n",
-				lv.Concurrency, lv.SuccessCount, lv.TotalRequests,
-				lv.AvgLatencyMs, lv.MinLatencyMs, lv.MaxLatencyMs,
-				lv.Throughput, lv.RPS, lv.WallTimeMs))
+		// Phase 1.5: Graduated Concurrency Results
+		if report.GraduatedConcurrency != nil && len(report.GraduatedConcurrency.Levels) > 0 {
+			sb.WriteString("### 1.5 逐级并发测试 (Graduated Concurrency)\n\n")
+			sb.WriteString("| 并发数 | 成功/总数 | 平均延迟(ms) | 最小延迟(ms) | 最大延迟(ms) | 吞吐(tok/s) | RPS | 耗时(ms) |\n")
+			sb.WriteString("|--------|-----------|-------------|-------------|-------------|-------------|------|--------|\n")
+			for _, lv := range report.GraduatedConcurrency.Levels {
+				sb.WriteString(fmt.Sprintf("| %d | %d/%d | %.0f | %.0f | %.0f | %.1f | %.2f | %.0f |\n",
+					lv.Concurrency, lv.SuccessCount, lv.TotalRequests,
+					lv.AvgLatencyMs, lv.MinLatencyMs, lv.MaxLatencyMs,
+					lv.Throughput, lv.RPS, lv.WallTimeMs))
+			}
+			sb.WriteString("\n")
 		}
-		sb.WriteString("\n")
 	}
 
-	// Phase 2: Function Call Results
-	sb.WriteString("## Phase 2: Function Call 测试\n\n")
-	if report.FunctionCallResult != nil {
-		fc := report.FunctionCallResult
-		if fc.Supported {
-			sb.WriteString("✅ **支持 Function Call**\n\n")
-			sb.WriteString(fmt.Sprintf("- 函数名: `%s`\n", fc.FunctionName))
-			sb.WriteString(fmt.Sprintf("- 参数: `%s`\n", fc.Arguments))
-			sb.WriteString(fmt.Sprintf("- 响应延迟: %.2f ms\n", fc.LatencyMs))
-		} else {
-			sb.WriteString("❌ **不支持 Function Call**\n\n")
-			if fc.Error != "" {
-				sb.WriteString(fmt.Sprintf("错误信息: %s\n", fc.Error))
+	if report.HasPhase(PhaseFuncCall) {
+		// Phase 2: Function Call Results
+		sb.WriteString("## Phase 2: Function Call 测试\n\n")
+		if report.FunctionCallResult != nil {
+			fc := report.FunctionCallResult
+			if fc.Supported {
+				sb.WriteString("✅ **支持 Function Call**\n\n")
+				sb.WriteString(fmt.Sprintf("- 函数名: `%s`\n", fc.FunctionName))
+				sb.WriteString(fmt.Sprintf("- 参数: `%s`\n", fc.Arguments))
+				if len(fc.SchemaViolations) > 0 {
+					sb.WriteString(fmt.Sprintf("- Schema 校验: ❌ %s\n", formatViolations(fc.SchemaViolations)))
+				} else {
+					sb.WriteString("- Schema 校验: ✅ 通过\n")
+				}
+				sb.WriteString(fmt.Sprintf("- 响应延迟: %.2f ms\n", fc.LatencyMs))
+			} else {
+				sb.WriteString("❌ **不支持 Function Call**\n\n")
+				if fc.Error != "" {
+					sb.WriteString(fmt.Sprintf("错误信息: %s\n", fc.Error))
+				}
 			}
+			sb.WriteString(fmt.Sprintf("\n**用例通过**: %d/%d (%.0f%%)\n", fc.Passed, fc.Total, fc.Score*100))
+			sb.WriteString("\n| 用例 | 问题 | 期望函数 | 实际函数 | 参数 | 延迟 (ms) | 结果 |\n")
+			sb.WriteString("|------|------|----------|----------|------|-----------|------|\n")
+			for _, c := range fc.Cases {
+				status := "✅"
+				if !c.Passed {
+					status = "❌"
+				}
+				sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s | `%s` | %.2f | %s |\n",
+					c.Name, c.Query, displayFunction(c.ExpectedFunction), displayFunction(c.FunctionName),
+					c.Arguments, c.LatencyMs, status))
+			}
+			sb.WriteString("\n")
 		}
-		sb.WriteString("\n")
 	}
 
-	// Phase 3: Long Context Results
-	sb.WriteString("## Phase 3: 长上下文测试\n\n")
-	if report.LongContextResult != nil {
-		lc := report.LongContextResult
-		sb.WriteString("| 上下文长度 | 估算Tokens | TTFT (ms) | Latency (ms) | 吞吐 (tok/s) | 状态 |\n")
-		sb.WriteString("|------------|------------|-----------|--------------|--------------|------|\n")
-		for _, res := range lc.Results {
-			status := "✅"
-			if !res.Success {
-				status = "❌"
+	if report.HasPhase(PhaseLongCtx) {
+		// Phase 3: Long Context Results
+		sb.WriteString("## Phase 3: 长上下文测试\n\n")
+		if report.LongContextResult != nil {
+			lc := report.LongContextResult
+			sb.WriteString("| 上下文长度 | 估算Tokens | TTFT (ms) | Latency (ms) | 吞吐 (tok/s) | 状态 |\n")
+			sb.WriteString("|------------|------------|-----------|--------------|--------------|------|\n")
+			for _, res := range lc.Results {
+				status := "✅"
+				if !res.Success {
+					status = "❌"
+				}
+				sb.WriteString(fmt.Sprintf("| %d 字符 | %d | %.2f | %.2f | %.2f | %s |\n",
+					res.ContextLength, res.InputTokens, res.TTFTMs, res.LatencyMs, res.Throughput, status))
 			}
-			sb.WriteString(fmt.Sprintf("| %d 字符 | %d | %.2f | %.2f | %.2f | %s |\n",
-				res.ContextLength, res.InputTokens, res.TTFTMs, res.LatencyMs, res.Throughput, status))
+			sb.WriteString(fmt.Sprintf("\n**最大支持上下文**: %d 字符 | **平均 TTFT**: %.2f ms | **平均吞吐**: %.2f tokens/s\n\n",
+				lc.MaxSupported, lc.AvgTTFTMs, lc.AvgThroughput))
+		} else {
+			sb.WriteString("⚠️ 长上下文测试未完成\n\n")
 		}
-		sb.WriteString(fmt.Sprintf("\n**最大支持上下文**: %d 字符 | **平均 TTFT**: %.2f ms | **平均吞吐**: %.2f tokens/s\n\n",
-			lc.MaxSupported, lc.AvgTTFTMs, lc.AvgThroughput))
-	} else {
-		sb.WriteString("⚠️ 长上下文测试未完成\n\n")
-	}
 
-	// Phase 3.5: Long Context Concurrent Results
-	sb.WriteString("## Phase 3.5: 长上下文并发测试\n\n")
-	sb.WriteString("*使用不同内容的提示词，避免前缀缓存 (Prefix Caching) 干扰结果*\n\n")
-	if report.LongContextConcurrentResult != nil && len(report.LongContextConcurrentResult.Levels) > 0 {
-		sb.WriteString("| 上下文长度 | 并发数 | 成功率 | 平均TTFT (ms) | P95 TTFT | 平均延迟 (ms) | 吞吐 (tok/s) | RPS |\n")
-		sb.WriteString("|------------|--------|--------|---------------|----------|---------------|--------------|------|\n")
-		for _, level := range report.LongContextConcurrentResult.Levels {
-			sb.WriteString(fmt.Sprintf("| %d 字符 | %d | %d/%d | %.2f | %.2f | %.2f | %.2f | %.2f |\n",
-				level.ContextLength, level.Concurrency, level.SuccessCount, level.TotalRequests,
-				level.AvgTTFTMs, level.P95TTFTMs, level.AvgLatencyMs, level.Throughput, level.RPS))
+		// Phase 3.5: Long Context Concurrent Results
+		sb.WriteString("## Phase 3.5: 长上下文并发测试\n\n")
+		sb.WriteString("*使用不同内容的提示词，避免前缀缓存 (Prefix Caching) 干扰结果*\n\n")
+		if report.LongContextConcurrentResult != nil && len(report.LongContextConcurrentResult.Levels) > 0 {
+			sb.WriteString("| 上下文长度 | 并发数 | 成功率 | 平均TTFT (ms) | P95 TTFT | 平均延迟 (ms) | 吞吐 (tok/s) | RPS |\n")
+			sb.WriteString("|------------|--------|--------|---------------|----------|---------------|--------------|------|\n")
+			for _, level := range report.LongContextConcurrentResult.Levels {
+				sb.WriteString(fmt.Sprintf("| %d 字符 | %d | %d/%d | %.2f | %.2f | %.2f | %.2f | %.2f |\n",
+					level.ContextLength, level.Concurrency, level.SuccessCount, level.TotalRequests,
+					level.AvgTTFTMs, level.P95TTFTMs, level.AvgLatencyMs, level.Throughput, level.RPS))
+			}
+			sb.WriteString("\n")
+		} else {
+			sb.WriteString("⚠️ 长上下文并发测试未完成\n\n")
 		}
-		sb.WriteString("\n")
-	} else {
-		sb.WriteString("⚠️ 长上下文并发测试未完成\n\n")
 	}
 
-	// Phase 4: Summary Results
-	sb.WriteString("## Phase 4: 会议纪要测试\n\n")
-	if report.SummaryOutputDir != "" {
-		sb.WriteString(fmt.Sprintf("📁 会议纪要: [summary/meeting_summary.md](%s/meeting_summary.md)\n", report.SummaryOutputDir))
-		sb.WriteString(fmt.Sprintf("📊 性能报告: [summary/performance_report.md](%s/performance_report.md)\n\n", report.SummaryOutputDir))
-	} else {
-		sb.WriteString("⚠️ 会议纪要测试未完成或跳过\n\n")
+	if report.HasPhase(PhaseSummary) {
+		// Phase 4: Summary Results
+		sb.WriteString("## Phase 4: 会议纪要测试\n\n")
+		if report.SummaryOutputDir != "" {
+			sb.WriteString(fmt.Sprintf("📁 会议纪要: [summary/meeting_summary.md](%s/meeting_summary.md)\n", report.SummaryOutputDir))
+			sb.WriteString(fmt.Sprintf("📊 性能报告: [summary/performance_report.md](%s/performance_report.md)\n\n", report.SummaryOutputDir))
+		} else {
+			sb.WriteString("⚠️ 会议纪要测试未完成或跳过\n\n")
+		}
 	}
 
 	// Write MD report
@@ -1760,17 +1745,28 @@ func (r *Runner) generateFinalReport(report *FullTestReport) error {
 		return fmt.Errorf("failed to write report: %w", err)
 	}
 
+	// Write JSON report for CI and scripts
+	jsonPath := filepath.Join(r.outputDir, "full_test_report.json")
+	jsonData, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal report: %w", err)
+	}
+	if err := os.WriteFile(jsonPath, jsonData, 0644); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+
 	// Write HTML report
 	htmlPath := filepath.Join(r.outputDir, "full_test_report.html")
 	if err := r.generateHTMLReport(report, htmlPath); err != nil {
-		fmt.Printf("Warning: failed to generate HTML report: %v\n", err)
+		fmt.Fprintf(r.cfg.Stdout(), "Warning: failed to generate HTML report: %v\n", err)
 	}
 
-	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-	fmt.Println("📋 Phase 5: Final Report Generated")
-	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-	fmt.Printf("📄 Markdown: %s\n", reportPath)
-	fmt.Printf("📄 HTML:     %s\n", htmlPath)
+	fmt.Fprintln(r.cfg.Stdout(), "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	fmt.Fprintln(r.cfg.Stdout(), "📋 Phase 5: Final Report Generated")
+	fmt.Fprintln(r.cfg.Stdout(), "━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
+	fmt.Fprintf(r.cfg.Stdout(), "📄 Markdown: %s\n", reportPath)
+	fmt.Fprintf(r.cfg.Stdout(), "📄 HTML:     %s\n", htmlPath)
+	fmt.Fprintf(r.cfg.Stdout(), "📄 JSON:     %s\n", jsonPath)
 
 	return nil
 }
@@ -1790,6 +1786,27 @@ func (r *Runner) writePhaseTable(sb *strings.Builder, phase *PhaseResult) {
 		phase.AvgLatencyMs, phase.Success, phase.Success+phase.Failure, phase.TotalTokens))
 }
 
+// writeContextGrowth summarizes how prompt size and latency changed from the
+// first to the last successful turn of the multi-turn test.
+func writeContextGrowth(sb *strings.Builder, phase *PhaseResult) {
+	var first, last *TestResult
+	for i := range phase.Results {
+		if !phase.Results[i].Success {
+			continue
+		}
+		if first == nil {
+			first = &phase.Results[i]
+		}
+		last = &phase.Results[i]
+	}
+	if first == nil || first == last {
+		return
+	}
+	sb.WriteString(fmt.Sprintf("**上下文增长**: %s → %s | 消息数 %d → %d | Prompt Tokens %d → %d | 延迟 %.2f ms → %.2f ms\n\n",
+		first.Name, last.Name, first.ContextMessages, last.ContextMessages,
+		first.PromptTokens, last.PromptTokens, first.LatencyMs, last.LatencyMs))
+}
+
 // SampleDataItem represents a sample data item for the template.
 type SampleDataItem struct {
 	Title   string
@@ -1909,7 +1926,9 @@ func (r *Runner) generateHTMLReport(report *FullTestReport, outputPath string) e
 	if report.FunctionCallResult != nil {
 		if report.FunctionCallResult.Supported {
 			fcSupported = true
-			fcDetails = fmt.Sprintf("函数: %s, 参数: %s, 延迟: %.2f ms",
+			fcDetails = fmt.Sprintf("通过: %d/%d, 函数: %s, 参数: %s, 延迟: %.2f ms",
+				report.FunctionCallResult.Passed,
+				report.FunctionCallResult.Total,
 				report.FunctionCallResult.FunctionName,
 				report.FunctionCallResult.Arguments,
 				report.FunctionCallResult.LatencyMs)
@@ -2106,10 +2125,17 @@ func (r *Runner) generateHTMLReport(report *FullTestReport, outputPath string) e
 	jetBrainsMonoBase64 := base64.StdEncoding.EncodeToString(jetBrainsMonoFont)
 	plusJakartaSansBase64 := base64.StdEncoding.EncodeToString(plusJakartaSansFont)
 
+	// With -cdn-charts the template loads ECharts instead of inlining it
+	echartsCDN := ""
+	if r.cfg.CDNCharts {
+		echartsCDN = runner.EChartsCDNURL
+	}
+
 	// Prepare template data
 	data := map[string]interface{}{
 		"Report":                report,
 		"EChartsJS":             template.JS(echartsJS),
+		"EChartsCDN":            echartsCDN,
 		"JetBrainsMonoBase64":   jetBrainsMonoBase64,
 		"PlusJakartaSansBase64": plusJakartaSansBase64,
 		"DurationSeconds":       report.TotalDuration.Seconds(),
@@ -0,0 +1,56 @@
+package fulltest
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseContextLadder(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    []int
+		wantErr bool
+	}{
+		{"2000,8000,32000,128000", []int{2000, 8000, 32000, 128000}, false},
+		{" 1000 , 4000, ", []int{1000, 4000}, false},
+		{"", nil, true},
+		{"1000,abc", nil, true},
+		{"1000,-5", nil, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseContextLadder(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseContextLadder(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseContextLadder(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestParsePhases(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    []string
+		wantErr bool
+	}{
+		{"perf,funccall,longctx,summary", []string{"perf", "funccall", "longctx", "summary"}, false},
+		{"summary, PERF,summary", []string{"perf", "summary"}, false},
+		{"funccall", []string{"funccall"}, false},
+		{" , ", nil, true},
+		{"perf,latency", nil, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParsePhases(tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParsePhases(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParsePhases(%q) = %v, want %v", tt.input, got, tt.want)
+		}
+	}
+}
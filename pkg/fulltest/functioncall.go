@@ -0,0 +1,483 @@
+package fulltest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/provider"
+)
+
+// ========== Phase 2: Function Call Test ==========
+
+// FunctionCallResult holds function call test results.
+//
+// The top-level fields describe the first case (get_weather) and are kept for
+// existing report consumers; Cases holds every scenario in the suite.
+type FunctionCallResult struct {
+	Supported       bool    `json:"supported"`
+	CorrectFunction bool    `json:"correct_function"`
+	CorrectArgs     bool    `json:"correct_args"`
+	LatencyMs       float64 `json:"latency_ms"`
+	FunctionName    string  `json:"function_name"`
+	Arguments       string  `json:"arguments"`
+	Error           string  `json:"error,omitempty"`
+
+	// SchemaViolations lists where the arguments break the tool's parameters schema
+	SchemaViolations []string `json:"schema_violations,omitempty"`
+
+	// Suite results
+	Cases  []FunctionCallCaseResult `json:"cases"`
+	Passed int                      `json:"passed"`
+	Total  int                      `json:"total"`
+	Score  float64                  `json:"score"` // Passed / Total
+}
+
+// FunctionCallCase is one function-calling scenario. An empty ExpectedFunction
+// means the model should answer directly without calling any tool.
+type FunctionCallCase struct {
+	Name             string                 `json:"name"`
+	Query            string                 `json:"query"`
+	ExpectedFunction string                 `json:"expected_function"`
+	ExpectedArgs     map[string]interface{} `json:"expected_args,omitempty"` // Must match; strings match by substring
+	Tools            []ToolDefinition       `json:"tools,omitempty"`         // Defaults to the built-in toolbox
+}
+
+// FunctionCallCaseResult is the outcome of one scenario.
+type FunctionCallCaseResult struct {
+	Name             string   `json:"name"`
+	Query            string   `json:"query"`
+	ExpectedFunction string   `json:"expected_function"`
+	FunctionName     string   `json:"function_name,omitempty"`
+	Arguments        string   `json:"arguments,omitempty"`
+	Passed           bool     `json:"passed"`
+	SchemaViolations []string `json:"schema_violations,omitempty"`
+	ArgMismatches    []string `json:"arg_mismatches,omitempty"`
+	LatencyMs        float64  `json:"latency_ms"`
+	Error            string   `json:"error,omitempty"`
+}
+
+// ToolDefinition is an OpenAI-style tool declaration.
+type ToolDefinition struct {
+	Type     string       `json:"type"`
+	Function ToolFunction `json:"function"`
+}
+
+// ToolFunction describes a callable function and its parameters schema.
+type ToolFunction struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Parameters  interface{} `json:"parameters"`
+}
+
+// weatherToolParameters is the parameters schema of the get_weather test tool.
+var weatherToolParameters = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"city": map[string]string{
+			"type":        "string",
+			"description": "城市名称",
+		},
+	},
+	"required": []string{"city"},
+}
+
+// defaultTools is the toolbox offered to the model in every built-in case, so
+// each case also checks that the right tool is chosen.
+var defaultTools = []ToolDefinition{
+	{Type: "function", Function: ToolFunction{
+		Name:        "get_weather",
+		Description: "获取指定城市的天气信息",
+		Parameters:  weatherToolParameters,
+	}},
+	{Type: "function", Function: ToolFunction{
+		Name:        "calculate",
+		Description: "计算数学表达式的结果",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"expression": map[string]string{"type": "string", "description": "数学表达式，例如 (3+5)*2"},
+			},
+			"required": []string{"expression"},
+		},
+	}},
+	{Type: "function", Function: ToolFunction{
+		Name:        "web_search",
+		Description: "在互联网上搜索最新信息",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"query":       map[string]string{"type": "string", "description": "搜索关键词"},
+				"max_results": map[string]string{"type": "integer", "description": "返回结果数量"},
+			},
+			"required": []string{"query"},
+		},
+	}},
+	{Type: "function", Function: ToolFunction{
+		Name:        "book_flight",
+		Description: "预订机票",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"from":       map[string]string{"type": "string", "description": "出发城市"},
+				"to":         map[string]string{"type": "string", "description": "到达城市"},
+				"date":       map[string]string{"type": "string", "description": "出发日期，格式 YYYY-MM-DD"},
+				"passengers": map[string]string{"type": "integer", "description": "乘客人数"},
+				"cabin": map[string]interface{}{
+					"type": "string", "enum": []string{"economy", "business", "first"}, "description": "舱位",
+				},
+			},
+			"required": []string{"from", "to", "date"},
+		},
+	}},
+	{Type: "function", Function: ToolFunction{
+		Name:        "convert_currency",
+		Description: "按当前汇率换算货币金额",
+		Parameters: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"amount": map[string]string{"type": "number", "description": "金额"},
+				"from":   map[string]string{"type": "string", "description": "源货币代码，例如 USD"},
+				"to":     map[string]string{"type": "string", "description": "目标货币代码，例如 CNY"},
+			},
+			"required": []string{"amount", "from", "to"},
+		},
+	}},
+}
+
+// defaultFunctionCallCases is the built-in suite. The first case is the
+// original get_weather check whose result fills the top-level report fields.
+var defaultFunctionCallCases = []FunctionCallCase{
+	{Name: "weather", Query: "北京今天天气怎么样？", ExpectedFunction: "get_weather",
+		ExpectedArgs: map[string]interface{}{"city": "北京"}},
+	{Name: "math", Query: "帮我算一下 (123 + 456) * 7 等于多少？", ExpectedFunction: "calculate"},
+	{Name: "search", Query: "搜索一下最近关于量子计算的新闻", ExpectedFunction: "web_search"},
+	{Name: "multi_param", Query: "帮我订一张 2025-05-01 从上海飞往成都的商务舱机票，2 个人", ExpectedFunction: "book_flight",
+		ExpectedArgs: map[string]interface{}{"from": "上海", "to": "成都", "date": "2025-05-01", "passengers": 2, "cabin": "business"}},
+	{Name: "currency", Query: "100 美元能换多少人民币？", ExpectedFunction: "convert_currency",
+		ExpectedArgs: map[string]interface{}{"amount": 100, "from": "USD", "to": "CNY"}},
+	{Name: "no_tool_greeting", Query: "你好，请用一句话介绍一下你自己。", ExpectedFunction: ""},
+	{Name: "no_tool_knowledge", Query: "《静夜思》的作者是谁？", ExpectedFunction: ""},
+}
+
+// LoadFunctionCallCases reads additional cases from a JSON array file.
+func LoadFunctionCallCases(path string) ([]FunctionCallCase, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read function call cases: %w", err)
+	}
+	var cases []FunctionCallCase
+	if err := json.Unmarshal(data, &cases); err != nil {
+		return nil, fmt.Errorf("failed to parse function call cases: %w", err)
+	}
+	for i := range cases {
+		if cases[i].Query == "" {
+			return nil, fmt.Errorf("function call case %d has no query", i+1)
+		}
+		if cases[i].Name == "" {
+			cases[i].Name = fmt.Sprintf("custom_%d", i+1)
+		}
+	}
+	return cases, nil
+}
+
+func (r *Runner) runFunctionCallTest() *FunctionCallResult {
+	cases := defaultFunctionCallCases
+	if r.cfg.FunctionCallCasesFile != "" {
+		extra, err := LoadFunctionCallCases(r.cfg.FunctionCallCasesFile)
+		if err != nil {
+			fmt.Fprintf(r.cfg.Stdout(), "   ⚠️  %v (using built-in cases only)\n", err)
+		} else {
+			cases = append(append([]FunctionCallCase{}, cases...), extra...)
+		}
+	}
+
+	result := &FunctionCallResult{Total: len(cases)}
+	for i, c := range cases {
+		caseResult := r.executeFunctionCallCase(c)
+		result.Cases = append(result.Cases, caseResult)
+		if caseResult.Passed {
+			result.Passed++
+		}
+		if caseResult.FunctionName != "" {
+			result.Supported = true
+		}
+
+		// The first case keeps the original single-query fields populated
+		if i == 0 {
+			result.FunctionName = caseResult.FunctionName
+			result.Arguments = caseResult.Arguments
+			result.LatencyMs = caseResult.LatencyMs
+			result.Error = caseResult.Error
+			result.SchemaViolations = caseResult.SchemaViolations
+			result.CorrectFunction = caseResult.FunctionName == c.ExpectedFunction
+			result.CorrectArgs = result.CorrectFunction && len(caseResult.SchemaViolations) == 0 && len(caseResult.ArgMismatches) == 0
+		}
+	}
+	if result.Total > 0 {
+		result.Score = float64(result.Passed) / float64(result.Total)
+	}
+	// A transport error on every case means the test could not run at all
+	if result.Supported || result.Passed > 0 {
+		result.Error = ""
+	}
+	return result
+}
+
+// executeFunctionCallCase sends one non-streaming request with tools and
+// checks the returned tool call.
+func (r *Runner) executeFunctionCallCase(c FunctionCallCase) FunctionCallCaseResult {
+	res := FunctionCallCaseResult{Name: c.Name, Query: c.Query, ExpectedFunction: c.ExpectedFunction}
+	logName := fmt.Sprintf("Function Call Test: %s", c.Name)
+
+	tools := c.Tools
+	if len(tools) == 0 {
+		tools = defaultTools
+	}
+
+	requestBody := map[string]interface{}{
+		"model": r.cfg.ModelName,
+		"messages": []map[string]string{
+			{"role": "user", "content": c.Query},
+		},
+		"max_tokens":  512, // Enough for function call response
+		"stream":      false,
+		"tools":       tools,
+		"tool_choice": "auto",
+	}
+
+	jsonBody, err := provider.MarshalBody(r.cfg, requestBody)
+	if err != nil {
+		res.Error = fmt.Sprintf("failed to marshal request: %v", err)
+		r.writeLog("Error: %s", res.Error)
+		return res
+	}
+	prettyReq, _ := json.MarshalIndent(requestBody, "", "  ")
+	start := time.Now()
+
+	// Log raw request
+	r.writeLog("")
+	r.writeLog("════════════════════════════════════════════════════════════════")
+	r.writeLog("[%s] REQUEST", logName)
+	r.writeLog("════════════════════════════════════════════════════════════════")
+	r.writeLog("Time: %s", start.Format("2006-01-02 15:04:05.000"))
+	r.writeLog("URL: %s", r.cfg.URL)
+	r.writeLog("Method: POST")
+	r.writeLog("Headers:")
+	r.writeLog("  Content-Type: application/json")
+	if r.cfg.Token != "" {
+		r.writeLog("  Authorization: Bearer %s", r.cfg.RedactedToken())
+	}
+	r.writeLog("Body:")
+	r.writeLog("%s", string(prettyReq))
+
+	req, err := http.NewRequest("POST", r.cfg.URL, bytes.NewBuffer(jsonBody))
+	if err != nil {
+		res.Error = err.Error()
+		r.writeLog("Error: %s", err.Error())
+		return res
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	if r.cfg.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+r.cfg.Token)
+	}
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		res.Error = err.Error()
+		res.LatencyMs = float64(time.Since(start).Milliseconds())
+		r.writeLog("")
+		r.writeLog("[%s] RESPONSE (ERROR)", logName)
+		r.writeLog("Error: %s", err.Error())
+		r.writeLog("Latency: %.2f ms", res.LatencyMs)
+		return res
+	}
+	defer resp.Body.Close()
+
+	res.LatencyMs = float64(time.Since(start).Milliseconds())
+
+	// Read raw response body
+	body, _ := io.ReadAll(resp.Body)
+
+	// Log raw response
+	r.writeLog("")
+	r.writeLog("────────────────────────────────────────────────────────────────")
+	r.writeLog("[%s] RESPONSE", logName)
+	r.writeLog("────────────────────────────────────────────────────────────────")
+	r.writeLog("HTTP Status: %d %s", resp.StatusCode, resp.Status)
+	r.writeLog("Headers:")
+	for key, values := range resp.Header {
+		for _, value := range values {
+			r.writeLog("  %s: %s", key, value)
+		}
+	}
+	r.writeLog("Body:")
+	// Pretty print if JSON
+	var prettyResp bytes.Buffer
+	if json.Indent(&prettyResp, body, "", "  ") == nil {
+		r.writeLog("%s", prettyResp.String())
+	} else {
+		r.writeLog("%s", string(body))
+	}
+	r.writeLog("Latency: %.2f ms", res.LatencyMs)
+
+	if resp.StatusCode != 200 {
+		res.Error = provider.StatusError(resp.StatusCode, body).Error()
+		r.writeLog("Status: FAILED")
+		return res
+	}
+
+	// Parse response
+	var respData struct {
+		Choices []struct {
+			Message struct {
+				ToolCalls []struct {
+					Function struct {
+						Name      string `json:"name"`
+						Arguments string `json:"arguments"`
+					} `json:"function"`
+				} `json:"tool_calls"`
+			} `json:"message"`
+		} `json:"choices"`
+	}
+
+	if err := json.Unmarshal(body, &respData); err != nil {
+		res.Error = fmt.Sprintf("Failed to parse response: %v", err)
+		r.writeLog("Parse Error: %s", res.Error)
+		r.writeLog("Status: FAILED")
+		return res
+	}
+
+	if len(respData.Choices) > 0 && len(respData.Choices[0].Message.ToolCalls) > 0 {
+		toolCall := respData.Choices[0].Message.ToolCalls[0]
+		res.FunctionName = toolCall.Function.Name
+		res.Arguments = toolCall.Function.Arguments
+	}
+
+	res.Passed = res.FunctionName == c.ExpectedFunction
+	if res.Passed && res.FunctionName != "" {
+		// Validate arguments against the declared parameters schema
+		for _, tool := range tools {
+			if tool.Function.Name == res.FunctionName {
+				res.SchemaViolations = validateToolArgs(tool.Function.Parameters, res.Arguments)
+				break
+			}
+		}
+		res.ArgMismatches = compareExpectedArgs(c.ExpectedArgs, res.Arguments)
+		res.Passed = len(res.SchemaViolations) == 0 && len(res.ArgMismatches) == 0
+	}
+
+	r.writeLog("")
+	r.writeLog("[%s] SUMMARY", logName)
+	r.writeLog("Expected Function: %s", displayFunction(c.ExpectedFunction))
+	r.writeLog("Function Name: %s", displayFunction(res.FunctionName))
+	r.writeLog("Arguments: %s", res.Arguments)
+	for _, v := range res.SchemaViolations {
+		r.writeLog("Schema Violation: %s", v)
+	}
+	for _, m := range res.ArgMismatches {
+		r.writeLog("Argument Mismatch: %s", m)
+	}
+	r.writeLog("Passed: %v", res.Passed)
+
+	return res
+}
+
+// compareExpectedArgs checks that each expected argument is present and
+// matches: strings by case-insensitive substring, numbers numerically.
+func compareExpectedArgs(expected map[string]interface{}, arguments string) []string {
+	if len(expected) == 0 {
+		return nil
+	}
+	var args map[string]interface{}
+	if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+		return []string{"arguments are not a JSON object"}
+	}
+
+	keys := make([]string, 0, len(expected))
+	for key := range expected {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var mismatches []string
+	for _, key := range keys {
+		want := expected[key]
+		got, ok := args[key]
+		if !ok {
+			mismatches = append(mismatches, fmt.Sprintf("%s: missing (want %v)", key, want))
+			continue
+		}
+		if !argMatches(want, got) {
+			mismatches = append(mismatches, fmt.Sprintf("%s: got %v, want %v", key, got, want))
+		}
+	}
+	return mismatches
+}
+
+func argMatches(want, got interface{}) bool {
+	if wantNum, ok := toFloat(want); ok {
+		gotNum, ok := toFloat(got)
+		return ok && math.Abs(wantNum-gotNum) < 1e-6
+	}
+	if wantStr, ok := want.(string); ok {
+		gotStr, ok := got.(string)
+		return ok && strings.Contains(strings.ToLower(gotStr), strings.ToLower(wantStr))
+	}
+	return fmt.Sprint(want) == fmt.Sprint(got)
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+func displayFunction(name string) string {
+	if name == "" {
+		return "(none)"
+	}
+	return name
+}
+
+func (r *Runner) printFunctionCallResult(result *FunctionCallResult) {
+	for _, c := range result.Cases {
+		status := "✅"
+		if !c.Passed {
+			status = "❌"
+		}
+		fmt.Fprintf(r.cfg.Stdout(), "   %s %-18s 期望: %-16s 实际: %-16s %8.2f ms\n",
+			status, c.Name, displayFunction(c.ExpectedFunction), displayFunction(c.FunctionName), c.LatencyMs)
+		if c.Error != "" {
+			fmt.Fprintf(r.cfg.Stdout(), "      错误: %s\n", truncateError(c.Error))
+		}
+		for _, v := range c.SchemaViolations {
+			fmt.Fprintf(r.cfg.Stdout(), "      - schema: %s\n", v)
+		}
+		for _, m := range c.ArgMismatches {
+			fmt.Fprintf(r.cfg.Stdout(), "      - 参数: %s\n", m)
+		}
+	}
+	fmt.Fprintf(r.cfg.Stdout(), "   Function Call 支持: %s | 通过: %d/%d (%.0f%%)\n\n",
+		map[bool]string{true: "是", false: "否"}[result.Supported], result.Passed, result.Total, result.Score*100)
+}
+
+// truncateError shortens long error bodies for console output.
+func truncateError(s string) string {
+	if len(s) > 200 {
+		return s[:200] + "..."
+	}
+	return s
+}
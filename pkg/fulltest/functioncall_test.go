@@ -0,0 +1,26 @@
+package fulltest
+
+import "testing"
+
+func TestCompareExpectedArgs(t *testing.T) {
+	expected := map[string]interface{}{"city": "北京", "passengers": 2}
+
+	tests := []struct {
+		name string
+		args string
+		want int
+	}{
+		{"exact", `{"city":"北京","passengers":2}`, 0},
+		{"substring and float", `{"city":"北京市","passengers":2.0}`, 0},
+		{"wrong value", `{"city":"上海","passengers":2}`, 1},
+		{"missing", `{"city":"北京"}`, 1},
+		{"not an object", `"北京"`, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := compareExpectedArgs(expected, tt.args); len(got) != tt.want {
+				t.Errorf("mismatches = %q, want %d", got, tt.want)
+			}
+		})
+	}
+}
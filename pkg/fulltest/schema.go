@@ -0,0 +1,128 @@
+package fulltest
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// validateToolArgs checks tool-call arguments (a JSON string) against the
+// tool's declared parameters schema and returns one message per violation.
+func validateToolArgs(parameters interface{}, arguments string) []string {
+	var args interface{}
+	if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+		return []string{fmt.Sprintf("arguments are not valid JSON: %v", err)}
+	}
+	return validateSchema(normalizeSchema(parameters), args, "$")
+}
+
+// normalizeSchema converts a Go literal schema (which may mix map types) into
+// the generic form produced by encoding/json.
+func normalizeSchema(schema interface{}) map[string]interface{} {
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return nil
+	}
+	var out map[string]interface{}
+	if json.Unmarshal(data, &out) != nil {
+		return nil
+	}
+	return out
+}
+
+// validateSchema implements the subset of JSON Schema used by tool
+// definitions: type, properties, required, enum and items.
+func validateSchema(schema map[string]interface{}, value interface{}, path string) []string {
+	if schema == nil {
+		return nil
+	}
+
+	if want, ok := schema["type"].(string); ok && !matchesType(want, value) {
+		return []string{fmt.Sprintf("%s: expected %s, got %s", path, want, jsonType(value))}
+	}
+
+	var violations []string
+	if enum, ok := schema["enum"].([]interface{}); ok && !inEnum(enum, value) {
+		violations = append(violations, fmt.Sprintf("%s: value %v is not one of %v", path, value, enum))
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if required, ok := schema["required"].([]interface{}); ok {
+			for _, name := range required {
+				key, _ := name.(string)
+				if _, present := v[key]; !present {
+					violations = append(violations, fmt.Sprintf("%s: missing required field %q", path, key))
+				}
+			}
+		}
+		if props, ok := schema["properties"].(map[string]interface{}); ok {
+			keys := make([]string, 0, len(v))
+			for key := range v {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			for _, key := range keys {
+				if propSchema, ok := props[key].(map[string]interface{}); ok {
+					violations = append(violations, validateSchema(propSchema, v[key], path+"."+key)...)
+				}
+			}
+		}
+	case []interface{}:
+		if items, ok := schema["items"].(map[string]interface{}); ok {
+			for i, item := range v {
+				violations = append(violations, validateSchema(items, item, fmt.Sprintf("%s[%d]", path, i))...)
+			}
+		}
+	}
+	return violations
+}
+
+func matchesType(want string, value interface{}) bool {
+	switch want {
+	case "integer":
+		f, ok := value.(float64)
+		return ok && f == math.Trunc(f)
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	default:
+		return jsonType(value) == want
+	}
+}
+
+// jsonType names the JSON type of a value decoded by encoding/json.
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+func inEnum(enum []interface{}, value interface{}) bool {
+	for _, e := range enum {
+		if fmt.Sprint(e) == fmt.Sprint(value) && jsonType(e) == jsonType(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// formatViolations joins violations for single-line display.
+func formatViolations(violations []string) string {
+	return strings.Join(violations, "; ")
+}
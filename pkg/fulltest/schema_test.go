@@ -0,0 +1,47 @@
+package fulltest
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestValidateToolArgs(t *testing.T) {
+	schema := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"city":  map[string]string{"type": "string"},
+			"days":  map[string]string{"type": "integer"},
+			"unit":  map[string]interface{}{"type": "string", "enum": []string{"celsius", "fahrenheit"}},
+			"hours": map[string]interface{}{"type": "array", "items": map[string]string{"type": "number"}},
+		},
+		"required": []string{"city"},
+	}
+
+	tests := []struct {
+		name string
+		args string
+		want []string // Substrings expected in the violations, in order
+	}{
+		{"valid", `{"city":"北京","days":3,"unit":"celsius","hours":[1,2.5]}`, nil},
+		{"missing required", `{"days":3}`, []string{`missing required field "city"`}},
+		{"wrong type", `{"city":42}`, []string{"$.city: expected string, got number"}},
+		{"non-integer", `{"city":"x","days":1.5}`, []string{"$.days: expected integer"}},
+		{"enum", `{"city":"x","unit":"kelvin"}`, []string{"$.unit: value kelvin is not one of"}},
+		{"array item", `{"city":"x","hours":[1,"two"]}`, []string{"$.hours[1]: expected number, got string"}},
+		{"not an object", `["北京"]`, []string{"$: expected object, got array"}},
+		{"invalid json", `{"city":`, []string{"arguments are not valid JSON"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := validateToolArgs(schema, tt.args)
+			if len(got) != len(tt.want) {
+				t.Fatalf("violations = %q, want %d", got, len(tt.want))
+			}
+			for i, want := range tt.want {
+				if !strings.Contains(got[i], want) {
+					t.Errorf("violation %d = %q, want it to contain %q", i, got[i], want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+// Package httpclient builds the HTTP clients shared by all modes, so TLS,
+// keep-alive and connection pool settings are applied the same way everywhere.
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/config"
+)
+
+// defaultIdleConns matches net/http's DefaultTransport pool size.
+const defaultIdleConns = 100
+
+// New creates an HTTP client configured from cfg.
+func New(cfg *config.GlobalConfig) *http.Client {
+	maxIdle, maxIdlePerHost := IdleConns(cfg)
+	transport := &http.Transport{
+		MaxIdleConns:        maxIdle,
+		MaxIdleConnsPerHost: maxIdlePerHost,
+		MaxConnsPerHost:     cfg.MaxConnsPerHost,
+		IdleConnTimeout:     90 * time.Second,
+		DisableKeepAlives:   cfg.NoKeepAlive,
+		TLSClientConfig:     TLSConfig(cfg),
+	}
+	if cfg.ConnectTimeoutSec > 0 {
+		// Fail fast on unreachable hosts instead of waiting out the request timeout
+		connectTimeout := time.Duration(cfg.ConnectTimeoutSec) * time.Second
+		transport.DialContext = (&net.Dialer{Timeout: connectTimeout, KeepAlive: 30 * time.Second}).DialContext
+		transport.TLSHandshakeTimeout = connectTimeout
+	}
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   time.Duration(cfg.TimeoutSec) * time.Second,
+	}
+}
+
+// TLSConfig returns the TLS settings for cfg (-insecure, -ca-cert), or nil
+// for the system defaults.
+func TLSConfig(cfg *config.GlobalConfig) *tls.Config {
+	if cfg.InsecureTLS {
+		return &tls.Config{InsecureSkipVerify: true}
+	}
+	if cfg.CACertPath != "" {
+		caCert, err := os.ReadFile(cfg.CACertPath)
+		if err == nil {
+			caCertPool := x509.NewCertPool()
+			caCertPool.AppendCertsFromPEM(caCert)
+			return &tls.Config{RootCAs: caCertPool}
+		}
+	}
+	return nil
+}
+
+// IdleConns returns the idle connection pool sizes (total, per host) for cfg.
+// Unset values default to at least the number of concurrent requests, since
+// net/http otherwise keeps only 2 idle connections per host and the rest are
+// re-dialed on every request. The per-host pool never exceeds
+// MaxConnsPerHost, which bounds all connections to a host.
+func IdleConns(cfg *config.GlobalConfig) (maxIdle, maxIdlePerHost int) {
+	maxIdle = cfg.MaxIdleConns
+	if maxIdle <= 0 {
+		maxIdle = max(defaultIdleConns, cfg.Concurrency, cfg.MaxInFlight)
+	}
+	maxIdlePerHost = cfg.MaxIdleConnsPerHost
+	if maxIdlePerHost <= 0 {
+		maxIdlePerHost = maxIdle
+	}
+	if cfg.MaxConnsPerHost > 0 {
+		maxIdlePerHost = min(maxIdlePerHost, cfg.MaxConnsPerHost)
+	}
+	return maxIdle, maxIdlePerHost
+}
+
+// Cache reuses one client per configuration so that requests share a
+// connection pool. The zero value is ready to use.
+type Cache struct {
+	mu     sync.Mutex
+	key    clientKey
+	client *http.Client
+}
+
+// clientKey holds the settings New reads. Configs that differ only in request
+// fields (token, model, ...), such as per-request copies, share a client.
+type clientKey struct {
+	timeoutSec, connectTimeoutSec       int
+	insecureTLS, noKeepAlive            bool
+	caCertPath                          string
+	maxIdle, maxIdlePerHost, maxPerHost int
+}
+
+func keyOf(cfg *config.GlobalConfig) clientKey {
+	maxIdle, maxIdlePerHost := IdleConns(cfg)
+	return clientKey{
+		timeoutSec:        cfg.TimeoutSec,
+		connectTimeoutSec: cfg.ConnectTimeoutSec,
+		insecureTLS:       cfg.InsecureTLS,
+		noKeepAlive:       cfg.NoKeepAlive,
+		caCertPath:        cfg.CACertPath,
+		maxIdle:           maxIdle,
+		maxIdlePerHost:    maxIdlePerHost,
+		maxPerHost:        cfg.MaxConnsPerHost,
+	}
+}
+
+// Get returns the client for cfg, creating it on first use or when the
+// connection settings of cfg change.
+func (c *Cache) Get(cfg *config.GlobalConfig) *http.Client {
+	key := keyOf(cfg)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.client == nil || c.key != key {
+		c.key = key
+		c.client = New(cfg)
+	}
+	return c.client
+}
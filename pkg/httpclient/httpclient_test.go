@@ -0,0 +1,50 @@
+package httpclient
+
+import (
+	"testing"
+
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/config"
+)
+
+func TestIdleConns(t *testing.T) {
+	tests := []struct {
+		name                   string
+		cfg                    config.GlobalConfig
+		wantIdle, wantIdleHost int
+	}{
+		{"defaults", config.GlobalConfig{Concurrency: 4}, 100, 100},
+		{"high concurrency", config.GlobalConfig{Concurrency: 256}, 256, 256},
+		{"max in flight", config.GlobalConfig{Concurrency: 8, MaxInFlight: 512}, 512, 512},
+		{"explicit", config.GlobalConfig{Concurrency: 256, MaxIdleConns: 50, MaxIdleConnsPerHost: 10}, 50, 10},
+		{"per host follows total", config.GlobalConfig{MaxIdleConns: 20}, 20, 20},
+		{"capped by max conns per host", config.GlobalConfig{Concurrency: 256, MaxConnsPerHost: 32}, 256, 32},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			idle, idleHost := IdleConns(&tt.cfg)
+			if idle != tt.wantIdle || idleHost != tt.wantIdleHost {
+				t.Errorf("IdleConns() = %d, %d, want %d, %d", idle, idleHost, tt.wantIdle, tt.wantIdleHost)
+			}
+		})
+	}
+}
+
+func TestCache_Get(t *testing.T) {
+	var c Cache
+	cfg := config.DefaultConfig()
+	if c.Get(cfg) != c.Get(cfg) {
+		t.Error("expected the same client for the same config")
+	}
+	// aliyun copies cfg per request to fill in the token
+	withToken := *cfg
+	withToken.Token = "sk-test"
+	if c.Get(&withToken) != c.Get(cfg) {
+		t.Error("expected the same client for a copy that differs only in the token")
+	}
+	insecure := *cfg
+	insecure.InsecureTLS = true
+	if c.Get(&insecure) == c.Get(cfg) {
+		t.Error("expected a new client for different connection settings")
+	}
+}
@@ -0,0 +1,427 @@
+package probe
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/config"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/httpclient"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/provider"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/provider/openai"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/sse"
+)
+
+// Capability is the outcome of one capability check.
+type Capability struct {
+	Name      string  `json:"name"`
+	Supported bool    `json:"supported"`
+	Detail    string  `json:"detail,omitempty"` // What was observed, e.g. "2 choices"
+	LatencyMs float64 `json:"latency_ms"`
+	Err       string  `json:"err,omitempty"`
+}
+
+// CapabilityResult is the compatibility matrix of an OpenAI-compatible endpoint.
+type CapabilityResult struct {
+	Model        string       `json:"model"`
+	URL          string       `json:"url"`
+	Supported    int          `json:"supported"`
+	MaxContext   int          `json:"max_context,omitempty"` // Context length reported by /models (0 if not reported)
+	Capabilities []Capability `json:"capabilities"`
+}
+
+// CapabilityProber checks which OpenAI API features an endpoint actually
+// honors. Each check is one small request that passes or fails on what the
+// server returns, regardless of what its documentation claims.
+type CapabilityProber struct {
+	cfg        *config.GlobalConfig
+	client     *http.Client
+	maxContext int // Set by checkMaxContext
+}
+
+// NewCapabilityProber creates a prober for the OpenAI-compatible endpoint in cfg.
+func NewCapabilityProber(cfg *config.GlobalConfig) *CapabilityProber {
+	return &CapabilityProber{cfg: cfg, client: httpclient.New(cfg)}
+}
+
+// capabilityCheck sends one request and fills in Supported and Detail.
+type capabilityCheck struct {
+	name string
+	run  func(c *CapabilityProber, capability *Capability) error
+}
+
+var capabilityChecks = []capabilityCheck{
+	{"streaming", (*CapabilityProber).checkStreaming},
+	{"stream_usage", (*CapabilityProber).checkStreamUsage},
+	{"tools", (*CapabilityProber).checkTools},
+	{"json_mode", (*CapabilityProber).checkJSONMode},
+	{"logprobs", (*CapabilityProber).checkLogprobs},
+	{"n", (*CapabilityProber).checkN},
+	{"stop", (*CapabilityProber).checkStop},
+	{"max_context", (*CapabilityProber).checkMaxContext},
+}
+
+// Run performs every check in turn. A failed request marks that capability
+// unsupported and the probe moves on to the next one.
+func (c *CapabilityProber) Run() *CapabilityResult {
+	res := &CapabilityResult{Model: c.cfg.ModelName, URL: c.cfg.URL}
+	for _, check := range capabilityChecks {
+		fmt.Fprintf(c.cfg.Stdout(), "  Checking %-15s ", check.name+"...")
+		capability := Capability{Name: check.name}
+		start := time.Now()
+		err := check.run(c, &capability)
+		capability.LatencyMs = float64(time.Since(start).Microseconds()) / 1000.0
+		switch {
+		case err != nil:
+			capability.Err = err.Error()
+			fmt.Fprintf(c.cfg.Stdout(), "❌ %s\n", truncateErr(capability.Err))
+		case capability.Supported:
+			fmt.Fprintf(c.cfg.Stdout(), "✅ %s\n", capability.Detail)
+		default:
+			fmt.Fprintf(c.cfg.Stdout(), "❌ %s\n", capability.Detail)
+		}
+		if capability.Supported {
+			res.Supported++
+		}
+		res.Capabilities = append(res.Capabilities, capability)
+	}
+	res.MaxContext = c.maxContext
+	return res
+}
+
+// checkStreaming passes if stream=true returns the answer as several SSE chunks.
+func (c *CapabilityProber) checkStreaming(capability *Capability) error {
+	chunks, err := c.stream(map[string]interface{}{
+		"messages":   userMessage("Count from 1 to 5, separated by spaces."),
+		"max_tokens": 32,
+	})
+	if err != nil {
+		return err
+	}
+	content := 0
+	for _, chunk := range chunks {
+		if len(chunk.Choices) > 0 && chunk.Choices[0].Delta.Content != "" {
+			content++
+		}
+	}
+	capability.Supported = content > 1
+	capability.Detail = fmt.Sprintf("%d content chunks", content)
+	return nil
+}
+
+// checkStreamUsage passes if stream_options.include_usage adds a usage chunk.
+func (c *CapabilityProber) checkStreamUsage(capability *Capability) error {
+	chunks, err := c.stream(map[string]interface{}{
+		"messages":       userMessage("Reply with OK."),
+		"max_tokens":     8,
+		"stream_options": map[string]bool{"include_usage": true},
+	})
+	if err != nil {
+		return err
+	}
+	for _, chunk := range chunks {
+		if chunk.Usage != nil && chunk.Usage.CompletionTokens > 0 {
+			capability.Supported = true
+			capability.Detail = fmt.Sprintf("%d prompt + %d completion tokens", chunk.Usage.PromptTokens, chunk.Usage.CompletionTokens)
+			return nil
+		}
+	}
+	capability.Detail = "no usage chunk in the stream"
+	return nil
+}
+
+// checkTools passes if the model answers a weather question with a call to the offered tool.
+func (c *CapabilityProber) checkTools(capability *Capability) error {
+	resp, err := c.complete(map[string]interface{}{
+		"messages":    userMessage("What is the weather in Paris right now? Use the tool."),
+		"max_tokens":  128,
+		"tools":       []provider.Tool{weatherTool},
+		"tool_choice": "auto",
+	})
+	if err != nil {
+		return err
+	}
+	calls := resp.firstMessage().ToolCalls
+	if len(calls) == 0 {
+		capability.Detail = "no tool_calls in the response"
+		return nil
+	}
+	call := calls[0].Function
+	capability.Supported = call.Name == weatherTool.Function.Name && json.Valid([]byte(call.Arguments))
+	capability.Detail = fmt.Sprintf("called %s(%s)", call.Name, call.Arguments)
+	return nil
+}
+
+// checkJSONMode passes if response_format json_object yields a parseable JSON object.
+func (c *CapabilityProber) checkJSONMode(capability *Capability) error {
+	resp, err := c.complete(map[string]interface{}{
+		"messages":        userMessage("Return a JSON object with the keys name and age for Alice, who is 30."),
+		"max_tokens":      64,
+		"response_format": map[string]string{"type": "json_object"},
+	})
+	if err != nil {
+		return err
+	}
+	var obj map[string]interface{}
+	content := resp.firstMessage().Content
+	if err := json.Unmarshal([]byte(strings.TrimSpace(content)), &obj); err != nil {
+		capability.Detail = fmt.Sprintf("content is not a JSON object: %q", truncateErr(content))
+		return nil
+	}
+	capability.Supported = true
+	capability.Detail = fmt.Sprintf("%d keys", len(obj))
+	return nil
+}
+
+// checkLogprobs passes if logprobs=true returns per-token log probabilities.
+func (c *CapabilityProber) checkLogprobs(capability *Capability) error {
+	resp, err := c.complete(map[string]interface{}{
+		"messages":   userMessage("Reply with OK."),
+		"max_tokens": 8,
+		"logprobs":   true,
+	})
+	if err != nil {
+		return err
+	}
+	if len(resp.Choices) == 0 || resp.Choices[0].Logprobs == nil || len(resp.Choices[0].Logprobs.Content) == 0 {
+		capability.Detail = "no logprobs in the response"
+		return nil
+	}
+	capability.Supported = true
+	capability.Detail = fmt.Sprintf("%d tokens with logprobs", len(resp.Choices[0].Logprobs.Content))
+	return nil
+}
+
+// checkN passes if n=2 returns two choices.
+func (c *CapabilityProber) checkN(capability *Capability) error {
+	resp, err := c.complete(map[string]interface{}{
+		"messages":   userMessage("Name a color."),
+		"max_tokens": 8,
+		"n":          2,
+	})
+	if err != nil {
+		return err
+	}
+	capability.Supported = len(resp.Choices) == 2
+	capability.Detail = fmt.Sprintf("%d choices", len(resp.Choices))
+	return nil
+}
+
+// checkStop passes if generation ends before a stop sequence the model was asked to write.
+func (c *CapabilityProber) checkStop(capability *Capability) error {
+	resp, err := c.complete(map[string]interface{}{
+		"messages":   userMessage("Repeat exactly: alpha beta gamma delta"),
+		"max_tokens": 16,
+		"stop":       []string{"gamma"},
+	})
+	if err != nil {
+		return err
+	}
+	content := strings.ToLower(resp.firstMessage().Content)
+	switch {
+	case strings.Contains(content, "gamma"):
+		capability.Detail = "stop sequence appeared in the output"
+	case !strings.Contains(content, "alpha"):
+		capability.Detail = fmt.Sprintf("inconclusive, model did not follow the prompt: %q", truncateErr(content))
+	default:
+		capability.Supported = true
+		capability.Detail = fmt.Sprintf("output cut before the stop sequence: %q", strings.TrimSpace(content))
+	}
+	return nil
+}
+
+// contextFields are the /models fields in which servers report the context
+// length (vLLM, OpenRouter, LM Studio and others).
+var contextFields = []string{"max_model_len", "context_length", "context_window", "max_context_length", "max_input_tokens"}
+
+// checkMaxContext passes if /models reports the model's context length.
+func (c *CapabilityProber) checkMaxContext(capability *Capability) error {
+	body, err := c.do("GET", openai.ModelsURL(c.cfg.URL), nil)
+	if err != nil {
+		return err
+	}
+	var list struct {
+		Data []map[string]interface{} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &list); err != nil {
+		return fmt.Errorf("failed to parse model list: %w", err)
+	}
+	for _, m := range list.Data {
+		if id, _ := m["id"].(string); id != c.cfg.ModelName {
+			continue
+		}
+		for _, field := range contextFields {
+			if n, ok := m[field].(float64); ok && n > 0 {
+				c.maxContext = int(n)
+				capability.Supported = true
+				capability.Detail = fmt.Sprintf("%d tokens (%s)", c.maxContext, field)
+				return nil
+			}
+		}
+	}
+	capability.Detail = "not reported by /models; use -probe-context to measure it"
+	return nil
+}
+
+var weatherTool = provider.Tool{
+	Type: "function",
+	Function: provider.Function{
+		Name:        "get_weather",
+		Description: "Get the current weather for a city",
+		Parameters: provider.Parameters{
+			Type:       "object",
+			Properties: map[string]provider.Property{"city": {Type: "string", Description: "City name"}},
+			Required:   []string{"city"},
+		},
+	},
+}
+
+func userMessage(content string) []map[string]string {
+	return []map[string]string{{"role": "user", "content": content}}
+}
+
+// chatMessage is the part of a chat completion message the checks inspect.
+type chatMessage struct {
+	Content   string `json:"content"`
+	ToolCalls []struct {
+		Function provider.ToolCallFunction `json:"function"`
+	} `json:"tool_calls"`
+}
+
+// chatCompletion is a non-streaming chat completion response.
+type chatCompletion struct {
+	Choices []struct {
+		Message  chatMessage `json:"message"`
+		Logprobs *struct {
+			Content []provider.TokenLogprob `json:"content"`
+		} `json:"logprobs"`
+	} `json:"choices"`
+}
+
+func (r *chatCompletion) firstMessage() chatMessage {
+	if len(r.Choices) == 0 {
+		return chatMessage{}
+	}
+	return r.Choices[0].Message
+}
+
+// chatChunk is one chunk of a streaming chat completion.
+type chatChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+	} `json:"choices"`
+	Usage *provider.TokenUsage `json:"usage"`
+}
+
+// complete sends a non-streaming chat completion.
+func (c *CapabilityProber) complete(body map[string]interface{}) (*chatCompletion, error) {
+	body["model"] = c.cfg.ModelName
+	body["stream"] = false
+	data, err := provider.MarshalBody(c.cfg, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	respBody, err := c.do("POST", c.cfg.URL, data)
+	if err != nil {
+		return nil, err
+	}
+	var resp chatCompletion
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+	return &resp, nil
+}
+
+// stream sends a streaming chat completion and returns its parsed chunks.
+func (c *CapabilityProber) stream(body map[string]interface{}) ([]chatChunk, error) {
+	body["model"] = c.cfg.ModelName
+	body["stream"] = true
+	data, err := provider.MarshalBody(c.cfg, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	respBody, err := c.do("POST", c.cfg.URL, data)
+	if err != nil {
+		return nil, err
+	}
+	events, err := sse.ReadEvents(bytes.NewReader(respBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read stream: %w", err)
+	}
+	var chunks []chatChunk
+	for _, event := range events {
+		payload := strings.TrimSpace(event.Data)
+		if payload == "" || payload == "[DONE]" {
+			continue
+		}
+		var chunk chatChunk
+		if err := json.Unmarshal([]byte(payload), &chunk); err != nil {
+			return nil, fmt.Errorf("failed to parse stream chunk: %w", err)
+		}
+		chunks = append(chunks, chunk)
+	}
+	return chunks, nil
+}
+
+// do sends one request and returns the body of a 200 response.
+func (c *CapabilityProber) do(method, url string, data []byte) ([]byte, error) {
+	var body io.Reader
+	if data != nil {
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if data != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.cfg.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.cfg.Token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, provider.StatusError(resp.StatusCode, bytes.TrimSpace(respBody))
+	}
+	return respBody, nil
+}
+
+func truncateErr(s string) string {
+	if len(s) > 80 {
+		return s[:80] + "..."
+	}
+	return s
+}
+
+// WriteCapabilityResult writes capabilities.json to outputDir.
+func WriteCapabilityResult(res *CapabilityResult, outputDir string) (string, error) {
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create output directory: %w", err)
+	}
+	data, err := json.MarshalIndent(res, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal capability result: %w", err)
+	}
+	path := filepath.Join(outputDir, "capabilities.json")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write capability result: %w", err)
+	}
+	return path, nil
+}
@@ -0,0 +1,198 @@
+// Package probe discovers endpoint limits by issuing targeted requests.
+package probe
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/config"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/provider"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/workload"
+)
+
+// fillerWords are common English words that tokenize to roughly one token each.
+var fillerWords = []string{
+	"apple", "river", "stone", "cloud", "green", "table", "music", "light",
+	"house", "paper", "water", "bread", "chair", "night", "train", "field",
+}
+
+const (
+	probeStartWords = 1024 // First probe size
+	probeMinStep    = 16   // Stop bisecting once the bracket is this narrow (words)
+)
+
+// ContextAttempt records one probe request.
+type ContextAttempt struct {
+	Words        int     `json:"words"`
+	PromptTokens int     `json:"prompt_tokens,omitempty"` // As reported by the server (0 if unknown)
+	Accepted     bool    `json:"accepted"`
+	LatencyMs    float64 `json:"latency_ms"`
+	Err          string  `json:"err,omitempty"`
+}
+
+// ContextResult is the outcome of a context-window probe.
+type ContextResult struct {
+	Model string `json:"model"`
+	URL   string `json:"url"`
+
+	// MaxAcceptedTokens is the largest prompt (server-reported tokens) that was accepted.
+	// When the server does not report usage it is estimated from the word count.
+	MaxAcceptedTokens int  `json:"max_accepted_tokens"`
+	MinRejectedWords  int  `json:"min_rejected_words,omitempty"` // Smallest prompt that overflowed (0 if never)
+	MaxAcceptedWords  int  `json:"max_accepted_words"`
+	TokensEstimated   bool `json:"tokens_estimated,omitempty"`
+	ReachedLimit      bool `json:"reached_limit"` // False if the probe hit its cap without any overflow
+
+	Attempts []ContextAttempt `json:"attempts"`
+}
+
+// ContextProber binary-searches the largest prompt an endpoint accepts.
+type ContextProber struct {
+	cfg       *config.GlobalConfig
+	provider  provider.Provider
+	maxTokens int // Upper bound for the search (approximate tokens)
+}
+
+// NewContextProber creates a prober that searches up to maxTokens prompt tokens.
+func NewContextProber(cfg *config.GlobalConfig, p provider.Provider, maxTokens int) *ContextProber {
+	return &ContextProber{cfg: cfg, provider: p, maxTokens: maxTokens}
+}
+
+// Run grows the prompt exponentially until the server reports a context-length
+// error, then bisects between the last accepted and first rejected size.
+// Errors other than context overflow abort the probe.
+func (c *ContextProber) Run() (*ContextResult, error) {
+	res := &ContextResult{Model: c.cfg.ModelName, URL: c.cfg.URL}
+
+	lo, hi := 0, 0 // lo: largest accepted, hi: smallest rejected (0 = unknown)
+	loTokens := 0
+	size := probeStartWords
+	if size > c.maxTokens {
+		size = c.maxTokens
+	}
+
+	// Exponential phase
+	for {
+		attempt, err := c.try(size)
+		res.Attempts = append(res.Attempts, attempt)
+		if err != nil {
+			return res, err
+		}
+		if !attempt.Accepted {
+			hi = size
+			break
+		}
+		lo, loTokens = size, attempt.PromptTokens
+		if size >= c.maxTokens {
+			break
+		}
+		size *= 2
+		if size > c.maxTokens {
+			size = c.maxTokens
+		}
+	}
+
+	// Bisection phase
+	for hi > 0 && hi-lo > probeMinStep {
+		mid := lo + (hi-lo)/2
+		attempt, err := c.try(mid)
+		res.Attempts = append(res.Attempts, attempt)
+		if err != nil {
+			return res, err
+		}
+		if attempt.Accepted {
+			lo, loTokens = mid, attempt.PromptTokens
+		} else {
+			hi = mid
+		}
+	}
+
+	res.MaxAcceptedWords = lo
+	res.MinRejectedWords = hi
+	res.ReachedLimit = hi > 0
+	res.MaxAcceptedTokens = loTokens
+	if loTokens == 0 && lo > 0 {
+		res.MaxAcceptedTokens = lo
+		res.TokensEstimated = true
+	}
+	return res, nil
+}
+
+// try sends a prompt of the given word count. It returns an error only for
+// failures that are not context-length errors.
+func (c *ContextProber) try(words int) (ContextAttempt, error) {
+	attempt := ContextAttempt{Words: words}
+	fmt.Fprintf(c.cfg.Stdout(), "  Probing %7d words... ", words)
+
+	reqCfg := *c.cfg
+	reqCfg.MaxTokens = 1
+	input := workload.NewSimpleWorkload(fmt.Sprintf("probe-%d", words), buildProbePrompt(words), 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(c.cfg.TimeoutSec)*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	events, err := c.provider.StreamChat(ctx, &reqCfg, input)
+	if err == nil {
+		for event := range events {
+			switch event.Type {
+			case provider.EventUsage:
+				attempt.PromptTokens = event.Usage.PromptTokens
+			case provider.EventError:
+				err = event.Err
+			}
+		}
+		if err == nil && ctx.Err() != nil {
+			err = fmt.Errorf("request timeout: %w", ctx.Err())
+		}
+	}
+	attempt.LatencyMs = float64(time.Since(start).Microseconds()) / 1000.0
+
+	switch {
+	case err == nil:
+		attempt.Accepted = true
+		fmt.Fprintf(c.cfg.Stdout(), "✅ accepted (%d prompt tokens, %.0f ms)\n", attempt.PromptTokens, attempt.LatencyMs)
+		return attempt, nil
+	case errors.Is(err, provider.ErrContextOverflow):
+		attempt.Err = err.Error()
+		fmt.Fprintf(c.cfg.Stdout(), "❌ context overflow\n")
+		return attempt, nil
+	default:
+		attempt.Err = err.Error()
+		fmt.Fprintf(c.cfg.Stdout(), "⚠️  error\n")
+		return attempt, fmt.Errorf("probe request with %d words failed: %w", words, err)
+	}
+}
+
+func buildProbePrompt(words int) string {
+	var sb strings.Builder
+	sb.Grow(words * 6)
+	sb.WriteString("Reply with OK.")
+	for i := 0; i < words; i++ {
+		sb.WriteByte(' ')
+		sb.WriteString(fillerWords[i%len(fillerWords)])
+	}
+	return sb.String()
+}
+
+// WriteResult writes context_probe.json to outputDir.
+func WriteResult(res *ContextResult, outputDir string) (string, error) {
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create output directory: %w", err)
+	}
+	data, err := json.MarshalIndent(res, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal probe result: %w", err)
+	}
+	path := filepath.Join(outputDir, "context_probe.json")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write probe result: %w", err)
+	}
+	return path, nil
+}
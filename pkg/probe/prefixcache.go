@@ -0,0 +1,173 @@
+package probe
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/config"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/provider"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/workload"
+)
+
+// PrefixCacheAttempt records one request of the prefix-cache test.
+type PrefixCacheAttempt struct {
+	Index        int     `json:"index"`
+	Warm         bool    `json:"warm"` // Shares its prefix with an earlier request
+	TTFTMs       float64 `json:"ttft_ms"`
+	LatencyMs    float64 `json:"latency_ms"`
+	PromptTokens int     `json:"prompt_tokens,omitempty"` // As reported by the server (0 if unknown)
+	Err          string  `json:"err,omitempty"`
+}
+
+// PrefixCacheResult compares the TTFT of a cold prompt with repeats that share its prefix.
+type PrefixCacheResult struct {
+	Model       string `json:"model"`
+	URL         string `json:"url"`
+	PrefixWords int    `json:"prefix_words"`
+	Repeats     int    `json:"repeats"`
+
+	ColdTTFTMs    float64 `json:"cold_ttft_ms"`
+	WarmAvgTTFTMs float64 `json:"warm_avg_ttft_ms"`
+	WarmMinTTFTMs float64 `json:"warm_min_ttft_ms"`
+	Speedup       float64 `json:"speedup"` // Cold TTFT / warm average TTFT (0 if unknown)
+
+	Attempts []PrefixCacheAttempt `json:"attempts"`
+}
+
+// PrefixCacheProber measures how much a server's prefix cache speeds up
+// repeated prompts. Every request shares one long prefix, which starts with a
+// per-run nonce so earlier runs cannot warm the cache, and ends with a unique
+// suffix so no two prompts are identical and only the prefix can be reused.
+type PrefixCacheProber struct {
+	cfg      *config.GlobalConfig
+	provider provider.Provider
+	words    int // Prefix length in filler words
+	repeats  int // Requests in total, including the cold one
+}
+
+// NewPrefixCacheProber creates a prober that sends repeats requests sharing a prefix of words words.
+func NewPrefixCacheProber(cfg *config.GlobalConfig, p provider.Provider, words, repeats int) *PrefixCacheProber {
+	return &PrefixCacheProber{cfg: cfg, provider: p, words: words, repeats: repeats}
+}
+
+// Run sends the cold request followed by the warm repeats, one at a time.
+// Any failed request aborts the test, since a partial comparison is misleading.
+func (c *PrefixCacheProber) Run() (*PrefixCacheResult, error) {
+	res := &PrefixCacheResult{
+		Model:       c.cfg.ModelName,
+		URL:         c.cfg.URL,
+		PrefixWords: c.words,
+		Repeats:     c.repeats,
+	}
+
+	prefix := buildCachePrefix(fmt.Sprintf("%x", time.Now().UnixNano()), c.words)
+	var warmSum float64
+	for i := 0; i < c.repeats; i++ {
+		attempt, err := c.try(i, prefix)
+		res.Attempts = append(res.Attempts, attempt)
+		if err != nil {
+			return res, err
+		}
+		if !attempt.Warm {
+			res.ColdTTFTMs = attempt.TTFTMs
+			continue
+		}
+		warmSum += attempt.TTFTMs
+		if res.WarmMinTTFTMs == 0 || attempt.TTFTMs < res.WarmMinTTFTMs {
+			res.WarmMinTTFTMs = attempt.TTFTMs
+		}
+	}
+
+	if warm := c.repeats - 1; warm > 0 {
+		res.WarmAvgTTFTMs = warmSum / float64(warm)
+		if res.WarmAvgTTFTMs > 0 {
+			res.Speedup = res.ColdTTFTMs / res.WarmAvgTTFTMs
+		}
+	}
+	return res, nil
+}
+
+// try sends request i with the shared prefix and waits for the full response.
+func (c *PrefixCacheProber) try(i int, prefix string) (PrefixCacheAttempt, error) {
+	attempt := PrefixCacheAttempt{Index: i + 1, Warm: i > 0}
+	label := "cold"
+	if attempt.Warm {
+		label = "warm"
+	}
+	fmt.Fprintf(c.cfg.Stdout(), "  Request %2d (%s)... ", attempt.Index, label)
+
+	reqCfg := *c.cfg
+	reqCfg.MaxTokens = 1
+	prompt := fmt.Sprintf("%s\n\nRequest %d: reply with OK.", prefix, attempt.Index)
+	input := workload.NewSimpleWorkload(fmt.Sprintf("prefix-cache-%d", attempt.Index), prompt, 1)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(c.cfg.TimeoutSec)*time.Second)
+	defer cancel()
+
+	start := time.Now()
+	var firstToken time.Time
+	events, err := c.provider.StreamChat(ctx, &reqCfg, input)
+	if err == nil {
+		for event := range events {
+			switch event.Type {
+			case provider.EventContent, provider.EventReasoning:
+				if firstToken.IsZero() {
+					firstToken = time.Now()
+				}
+			case provider.EventUsage:
+				attempt.PromptTokens = event.Usage.PromptTokens
+			case provider.EventError:
+				err = event.Err
+			}
+		}
+		if err == nil && ctx.Err() != nil {
+			err = fmt.Errorf("request timeout: %w", ctx.Err())
+		}
+		if err == nil && firstToken.IsZero() {
+			err = fmt.Errorf("no content received")
+		}
+	}
+	attempt.LatencyMs = float64(time.Since(start).Microseconds()) / 1000.0
+
+	if err != nil {
+		attempt.Err = err.Error()
+		fmt.Fprintf(c.cfg.Stdout(), "⚠️  error\n")
+		return attempt, fmt.Errorf("prefix cache request %d failed: %w", attempt.Index, err)
+	}
+	attempt.TTFTMs = float64(firstToken.Sub(start).Microseconds()) / 1000.0
+	fmt.Fprintf(c.cfg.Stdout(), "✅ TTFT %.0f ms (%d prompt tokens)\n", attempt.TTFTMs, attempt.PromptTokens)
+	return attempt, nil
+}
+
+// buildCachePrefix builds a long shared prefix that starts with nonce.
+func buildCachePrefix(nonce string, words int) string {
+	var sb strings.Builder
+	sb.Grow(words*6 + 64)
+	fmt.Fprintf(&sb, "[session %s] Read the following text.", nonce)
+	for i := 0; i < words; i++ {
+		sb.WriteByte(' ')
+		sb.WriteString(fillerWords[i%len(fillerWords)])
+	}
+	return sb.String()
+}
+
+// WritePrefixCacheResult writes prefix_cache.json to outputDir.
+func WritePrefixCacheResult(res *PrefixCacheResult, outputDir string) (string, error) {
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create output directory: %w", err)
+	}
+	data, err := json.MarshalIndent(res, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal prefix cache result: %w", err)
+	}
+	path := filepath.Join(outputDir, "prefix_cache.json")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to write prefix cache result: %w", err)
+	}
+	return path, nil
+}
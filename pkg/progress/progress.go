@@ -0,0 +1,139 @@
+// Package progress provides a live, in-place terminal progress display for benchmark runs.
+package progress
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/result"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/stats"
+)
+
+// RefreshInterval is how often the display is redrawn.
+const RefreshInterval = 500 * time.Millisecond
+
+const barWidth = 30
+
+// IsTerminal reports whether f is attached to an interactive terminal.
+func IsTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
+// Display renders completed/total, RPS, running latency percentiles and success rate
+// using ANSI cursor movement so the block is redrawn in place. Percentiles are
+// P² estimates, so a redraw costs the same however many requests have completed.
+type Display struct {
+	mu        sync.Mutex
+	out       io.Writer
+	total     int
+	completed int
+	success   int
+	latency   *stats.StreamingDurations
+	start     time.Time
+	lines     int // Number of lines drawn in the previous frame
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewDisplay creates a display writing to out.
+func NewDisplay(out io.Writer) *Display {
+	return &Display{
+		out:     out,
+		latency: stats.NewStreamingDurations(),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// Start begins periodic redraws for a run of total requests (0 = unknown).
+func (d *Display) Start(total int) {
+	d.mu.Lock()
+	d.total = total
+	d.start = time.Now()
+	d.mu.Unlock()
+
+	go func() {
+		defer close(d.done)
+		ticker := time.NewTicker(RefreshInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				d.render()
+			case <-d.stop:
+				d.render()
+				return
+			}
+		}
+	}()
+}
+
+// Record adds a completed request to the running statistics.
+func (d *Display) Record(res result.RequestResult) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.completed++
+	if res.Status == result.StatusOK {
+		d.success++
+		d.latency.Add(res.Latency)
+	}
+}
+
+// Stop draws the final frame and stops redrawing.
+func (d *Display) Stop() {
+	close(d.stop)
+	<-d.done
+}
+
+func (d *Display) render() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	elapsed := time.Since(d.start).Seconds()
+	var rps, successRate float64
+	if elapsed > 0 {
+		rps = float64(d.completed) / elapsed
+	}
+	if d.completed > 0 {
+		successRate = float64(d.success) / float64(d.completed) * 100
+	}
+
+	p50 := d.latency.PercentileMs(50)
+	p95 := d.latency.PercentileMs(95)
+
+	var frame []string
+	if d.total > 0 {
+		filled := d.completed * barWidth / d.total
+		if filled > barWidth {
+			filled = barWidth
+		}
+		bar := strings.Repeat("█", filled) + strings.Repeat("░", barWidth-filled)
+		frame = append(frame, fmt.Sprintf("Progress:  [%s] %d/%d (%.1f%%)",
+			bar, d.completed, d.total, float64(d.completed)/float64(d.total)*100))
+	} else {
+		frame = append(frame, fmt.Sprintf("Progress:  %d completed", d.completed))
+	}
+	frame = append(frame,
+		fmt.Sprintf("RPS:       %-10.2f Success: %.1f%% (%d/%d)", rps, successRate, d.success, d.completed),
+		fmt.Sprintf("Latency:   p50 %dms   p95 %dms   elapsed %.1fs", p50, p95, elapsed),
+	)
+
+	// Move the cursor back to the start of the previous frame and clear each line
+	if d.lines > 0 {
+		fmt.Fprintf(d.out, "\033[%dA", d.lines)
+	}
+	for _, line := range frame {
+		fmt.Fprintf(d.out, "\033[2K\r%s\n", line)
+	}
+	d.lines = len(frame)
+}
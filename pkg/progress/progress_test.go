@@ -0,0 +1,44 @@
+package progress
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/result"
+)
+
+func TestDisplay(t *testing.T) {
+	var out bytes.Buffer
+	d := NewDisplay(&out)
+	d.Start(4)
+	for _, ms := range []int{100, 200, 300} {
+		d.Record(result.RequestResult{Status: result.StatusOK, Latency: time.Duration(ms) * time.Millisecond})
+	}
+	d.Record(result.RequestResult{Status: result.StatusTimeout, Latency: time.Minute})
+	d.Stop()
+
+	frame := out.String()
+	for _, want := range []string{"4/4 (100.0%)", "Success: 75.0% (3/4)", "p50 200ms", "p95 290ms"} {
+		if !strings.Contains(frame, want) {
+			t.Errorf("frame does not contain %q:\n%s", want, frame)
+		}
+	}
+}
+
+func TestDisplay_RedrawsInPlace(t *testing.T) {
+	var out bytes.Buffer
+	d := NewDisplay(&out)
+	d.Start(0)
+	d.render()
+	d.Stop()
+
+	// The second frame moves the cursor up over the three lines of the first
+	if n := strings.Count(out.String(), "\033[3A"); n != 1 {
+		t.Errorf("got %d cursor-up sequences, want 1:\n%q", n, out.String())
+	}
+	if !strings.Contains(out.String(), "Progress:  0 completed") {
+		t.Errorf("unknown total not shown as a count:\n%s", out.String())
+	}
+}
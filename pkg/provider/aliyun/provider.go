@@ -0,0 +1,269 @@
+// Package aliyun implements the Alibaba Cloud DashScope provider.
+//
+// URLs containing "/compatible-mode/" are OpenAI-compatible and are handled by
+// the openai provider. Any other URL uses DashScope's native text-generation API
+// (input.messages / parameters body, X-DashScope-SSE streaming).
+package aliyun
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/config"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/httpclient"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/provider"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/provider/openai"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/sse"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/workload"
+)
+
+// DefaultURL is the native DashScope text-generation endpoint used when -url is empty.
+const DefaultURL = "https://dashscope.aliyuncs.com/api/v1/services/aigc/text-generation/generation"
+
+func init() {
+	provider.Register("aliyun", func() provider.Provider {
+		return &Provider{compatible: &openai.Provider{}}
+	})
+}
+
+// Provider implements the DashScope API provider.
+type Provider struct {
+	compatible *openai.Provider
+	clients    httpclient.Cache // Reused across requests so connections are pooled
+}
+
+// Name returns the provider name.
+func (p *Provider) Name() string {
+	return "aliyun"
+}
+
+// GenerationRequest is the native DashScope request body.
+type GenerationRequest struct {
+	Model      string     `json:"model"`
+	Input      Input      `json:"input"`
+	Parameters Parameters `json:"parameters"`
+}
+
+// Input holds the conversation.
+type Input struct {
+	Messages []workload.ChatMessage `json:"messages"`
+}
+
+// Parameters holds generation parameters.
+type Parameters struct {
+	ResultFormat      string   `json:"result_format"`      // "message" for chat-style choices
+	IncrementalOutput bool     `json:"incremental_output"` // Stream deltas instead of the full text so far
+	MaxTokens         int      `json:"max_tokens,omitempty"`
+	Temperature       *float64 `json:"temperature,omitempty"`
+	TopP              *float64 `json:"top_p,omitempty"`
+	Seed              *int     `json:"seed,omitempty"`
+	Stop              []string `json:"stop,omitempty"`
+	EnableThinking    *bool    `json:"enable_thinking,omitempty"`
+}
+
+// GenerationResponse is a single native streaming frame.
+type GenerationResponse struct {
+	RequestID string `json:"request_id"`
+	Code      string `json:"code,omitempty"`
+	Message   string `json:"message,omitempty"`
+	Output    struct {
+		Choices []struct {
+			Message struct {
+				Role             string `json:"role"`
+				Content          string `json:"content"`
+				ReasoningContent string `json:"reasoning_content"`
+			} `json:"message"`
+			FinishReason string `json:"finish_reason"`
+		} `json:"choices"`
+	} `json:"output"`
+	Usage *struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage,omitempty"`
+}
+
+// StreamChat executes a streaming chat request.
+func (p *Provider) StreamChat(ctx context.Context, cfg *config.GlobalConfig, input workload.WorkloadInput) (<-chan provider.StreamEvent, error) {
+	if strings.Contains(cfg.URL, "/compatible-mode/") {
+		return p.compatible.StreamChat(ctx, withToken(cfg), input)
+	}
+	cfg = withToken(cfg)
+
+	messages := input.ToMessagesWithSystem(cfg.SystemPrompt)
+	if len(messages) == 0 {
+		return nil, fmt.Errorf("no messages provided")
+	}
+
+	maxTokens := input.MaxTokens
+	if maxTokens == 0 {
+		maxTokens = cfg.MaxTokens
+	}
+
+	reqBody := GenerationRequest{
+		Model: cfg.ModelName,
+		Input: Input{Messages: messages},
+		Parameters: Parameters{
+			ResultFormat:      "message",
+			IncrementalOutput: true,
+			MaxTokens:         maxTokens,
+			Temperature:       cfg.Temperature,
+			TopP:              cfg.TopP,
+			Seed:              cfg.Seed,
+			Stop:              cfg.Stop,
+		},
+	}
+	if cfg.DisableThinking {
+		disabled := false
+		reqBody.Parameters.EnableThinking = &disabled
+	}
+
+	jsonBody, err := provider.MarshalBody(cfg, reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	url := cfg.URL
+	if url == "" {
+		url = DefaultURL
+	}
+
+	if cfg.Verbose {
+		fmt.Println("\n" + strings.Repeat("=", 80))
+		fmt.Println("[VERBOSE] DASHSCOPE STREAM REQUEST")
+		fmt.Println(strings.Repeat("-", 80))
+		fmt.Printf("URL: %s\n", url)
+		fmt.Printf("Model: %s\n", cfg.ModelName)
+		fmt.Printf("MaxTokens: %d\n", maxTokens)
+		fmt.Println(strings.Repeat("=", 80))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(jsonBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("X-DashScope-SSE", "enable")
+	if token := provider.APIKey(ctx, cfg); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := p.clients.Get(cfg).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		return nil, provider.StatusError(resp.StatusCode, body)
+	}
+
+	events := make(chan provider.StreamEvent, 100)
+	go p.parseStream(resp.Body, events, cfg.Verbosity >= 2, cfg.StrictSSE)
+
+	return events, nil
+}
+
+// withToken returns cfg with Token defaulted from DASHSCOPE_API_KEY.
+func withToken(cfg *config.GlobalConfig) *config.GlobalConfig {
+	if cfg.Token != "" {
+		return cfg
+	}
+	key := os.Getenv("DASHSCOPE_API_KEY")
+	if key == "" {
+		return cfg
+	}
+	c := *cfg
+	c.Token = key
+	return &c
+}
+
+func (p *Provider) parseStream(body io.ReadCloser, events chan<- provider.StreamEvent, dumpFrames, strict bool) {
+	defer close(events)
+	defer body.Close()
+
+	parser := sse.NewParser(body)
+	violation := func(msg string) {
+		events <- provider.StreamEvent{Type: provider.EventViolation, Err: errors.New(msg)}
+	}
+	if strict {
+		parser.OnViolation = violation
+	}
+	var usage *provider.TokenUsage
+	finishReason := ""
+
+	for {
+		event, err := parser.Next()
+		if err == io.EOF {
+			if usage != nil {
+				events <- provider.StreamEvent{Type: provider.EventUsage, Usage: usage}
+			}
+			events <- provider.StreamEvent{Type: provider.EventEnd, FinishReason: finishReason}
+			return
+		}
+		if err != nil {
+			events <- provider.StreamEvent{
+				Type: provider.EventError,
+				Err:  fmt.Errorf("SSE parse error: %w", err),
+			}
+			return
+		}
+
+		if dumpFrames {
+			fmt.Printf("[SSE] %s\n", event.Data)
+		}
+
+		var resp GenerationResponse
+		if err := json.Unmarshal([]byte(event.Data), &resp); err != nil {
+			if strict {
+				violation(fmt.Sprintf("invalid JSON in data: %v", err))
+			}
+			continue
+		}
+
+		if event.Event == "error" || resp.Code != "" {
+			events <- provider.StreamEvent{
+				Type: provider.EventError,
+				Raw:  event.Data,
+				Err:  fmt.Errorf("dashscope %s: %s", resp.Code, resp.Message),
+			}
+			return
+		}
+
+		if resp.Usage != nil {
+			usage = &provider.TokenUsage{
+				PromptTokens:     resp.Usage.InputTokens,
+				CompletionTokens: resp.Usage.OutputTokens,
+			}
+		}
+
+		for _, choice := range resp.Output.Choices {
+			if choice.Message.ReasoningContent != "" {
+				events <- provider.StreamEvent{
+					Type: provider.EventReasoning,
+					Raw:  event.Data,
+					Text: choice.Message.ReasoningContent,
+				}
+			}
+			if choice.Message.Content != "" {
+				events <- provider.StreamEvent{
+					Type: provider.EventContent,
+					Raw:  event.Data,
+					Text: choice.Message.Content,
+				}
+			}
+			// DashScope sends the string "null" until the final frame
+			if choice.FinishReason != "" && choice.FinishReason != "null" {
+				finishReason = choice.FinishReason
+			}
+		}
+	}
+}
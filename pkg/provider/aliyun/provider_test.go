@@ -0,0 +1,84 @@
+package aliyun
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/provider"
+)
+
+func TestParseStream(t *testing.T) {
+	stream := `id:1
+event:result
+:HTTP_STATUS/200
+data:{"output":{"choices":[{"message":{"content":"","reasoning_content":"thinking","role":"assistant"},"finish_reason":"null"}]},"usage":{"input_tokens":12,"output_tokens":1},"request_id":"r1"}
+
+id:2
+event:result
+:HTTP_STATUS/200
+data:{"output":{"choices":[{"message":{"content":"Hello","role":"assistant"},"finish_reason":"null"}]},"usage":{"input_tokens":12,"output_tokens":2},"request_id":"r1"}
+
+id:3
+event:result
+:HTTP_STATUS/200
+data:{"output":{"choices":[{"message":{"content":" world","role":"assistant"},"finish_reason":"stop"}]},"usage":{"input_tokens":12,"output_tokens":3},"request_id":"r1"}
+
+`
+	events := make(chan provider.StreamEvent, 100)
+	p := &Provider{}
+	p.parseStream(io.NopCloser(strings.NewReader(stream)), events, false, false)
+
+	var content, reasoning string
+	var usage *provider.TokenUsage
+	var end provider.StreamEvent
+	for e := range events {
+		switch e.Type {
+		case provider.EventContent:
+			content += e.Text
+		case provider.EventReasoning:
+			reasoning += e.Text
+		case provider.EventUsage:
+			usage = e.Usage
+		case provider.EventEnd:
+			end = e
+		case provider.EventError:
+			t.Fatalf("unexpected error: %v", e.Err)
+		}
+	}
+
+	if content != "Hello world" {
+		t.Errorf("expected content 'Hello world', got %q", content)
+	}
+	if reasoning != "thinking" {
+		t.Errorf("expected reasoning 'thinking', got %q", reasoning)
+	}
+	if usage == nil || usage.PromptTokens != 12 || usage.CompletionTokens != 3 {
+		t.Errorf("unexpected usage: %+v", usage)
+	}
+	if end.FinishReason != "stop" {
+		t.Errorf("expected finish reason 'stop', got %q", end.FinishReason)
+	}
+}
+
+func TestParseStream_Error(t *testing.T) {
+	stream := `id:1
+event:error
+:HTTP_STATUS/400
+data:{"code":"InvalidParameter","message":"Range of input length should be [1, 30720]","request_id":"r2"}
+
+`
+	events := make(chan provider.StreamEvent, 10)
+	p := &Provider{}
+	p.parseStream(io.NopCloser(strings.NewReader(stream)), events, false, false)
+
+	var gotErr error
+	for e := range events {
+		if e.Type == provider.EventError {
+			gotErr = e.Err
+		}
+	}
+	if gotErr == nil || !strings.Contains(gotErr.Error(), "InvalidParameter") {
+		t.Errorf("expected InvalidParameter error, got %v", gotErr)
+	}
+}
@@ -0,0 +1,24 @@
+package provider
+
+import (
+	"context"
+
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/config"
+)
+
+type apiKeyContextKey struct{}
+
+// WithAPIKey returns a context whose requests authenticate with key instead of
+// cfg.Token, so requests can rotate keys (-api-key) without a per-request config.
+func WithAPIKey(ctx context.Context, key string) context.Context {
+	return context.WithValue(ctx, apiKeyContextKey{}, key)
+}
+
+// APIKey returns the token for a request made with ctx: the key set by
+// WithAPIKey, or cfg.Token.
+func APIKey(ctx context.Context, cfg *config.GlobalConfig) string {
+	if key, ok := ctx.Value(apiKeyContextKey{}).(string); ok {
+		return key
+	}
+	return cfg.Token
+}
@@ -0,0 +1,135 @@
+package bedrock
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+// AWS event-stream framing (application/vnd.amazon.eventstream):
+//
+//	[total length:4][headers length:4][prelude crc:4][headers][payload][message crc:4]
+//
+// All integers are big-endian and both CRCs are CRC32 (IEEE).
+const (
+	preludeLen       = 8
+	preludeCRCLen    = 4
+	messageCRCLen    = 4
+	minMessageLen    = preludeLen + preludeCRCLen + messageCRCLen
+	maxMessageLen    = 16 * 1024 * 1024
+	headerTypeString = 7
+)
+
+// Message is a single decoded event-stream message.
+type Message struct {
+	Headers map[string]string // String-valued headers (e.g. :event-type, :message-type)
+	Payload []byte
+}
+
+// Decoder reads event-stream messages from a reader.
+type Decoder struct {
+	r io.Reader
+}
+
+// NewDecoder creates a new event-stream decoder.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{r: r}
+}
+
+// Next reads the next message. Returns io.EOF when the stream ends cleanly.
+func (d *Decoder) Next() (*Message, error) {
+	prelude := make([]byte, preludeLen+preludeCRCLen)
+	if _, err := io.ReadFull(d.r, prelude); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return nil, fmt.Errorf("truncated message prelude")
+		}
+		return nil, err
+	}
+
+	totalLen := binary.BigEndian.Uint32(prelude[0:4])
+	headersLen := binary.BigEndian.Uint32(prelude[4:8])
+	if crc32.ChecksumIEEE(prelude[:preludeLen]) != binary.BigEndian.Uint32(prelude[8:12]) {
+		return nil, fmt.Errorf("prelude checksum mismatch")
+	}
+	if totalLen < minMessageLen || totalLen > maxMessageLen {
+		return nil, fmt.Errorf("invalid message length: %d", totalLen)
+	}
+	if headersLen > totalLen-minMessageLen {
+		return nil, fmt.Errorf("invalid headers length: %d", headersLen)
+	}
+
+	msg := make([]byte, totalLen)
+	copy(msg, prelude)
+	if _, err := io.ReadFull(d.r, msg[len(prelude):]); err != nil {
+		return nil, fmt.Errorf("truncated message: %w", err)
+	}
+
+	crcOffset := totalLen - messageCRCLen
+	if crc32.ChecksumIEEE(msg[:crcOffset]) != binary.BigEndian.Uint32(msg[crcOffset:]) {
+		return nil, fmt.Errorf("message checksum mismatch")
+	}
+
+	headersStart := uint32(preludeLen + preludeCRCLen)
+	headers, err := parseHeaders(msg[headersStart : headersStart+headersLen])
+	if err != nil {
+		return nil, err
+	}
+
+	return &Message{
+		Headers: headers,
+		Payload: msg[headersStart+headersLen : crcOffset],
+	}, nil
+}
+
+// parseHeaders decodes the header block. Only string values are kept; other
+// value types are skipped since Bedrock only uses string headers for routing.
+func parseHeaders(b []byte) (map[string]string, error) {
+	headers := make(map[string]string)
+	for len(b) > 0 {
+		nameLen := int(b[0])
+		if len(b) < 1+nameLen+1 {
+			return nil, fmt.Errorf("truncated header name")
+		}
+		name := string(b[1 : 1+nameLen])
+		valueType := b[1+nameLen]
+		b = b[2+nameLen:]
+
+		var size int
+		switch valueType {
+		case 0, 1: // bool true / false
+			size = 0
+		case 2: // byte
+			size = 1
+		case 3: // short
+			size = 2
+		case 4: // int
+			size = 4
+		case 5, 8: // long, timestamp
+			size = 8
+		case 9: // uuid
+			size = 16
+		case 6, headerTypeString: // byte array, string
+			if len(b) < 2 {
+				return nil, fmt.Errorf("truncated header value length")
+			}
+			n := int(binary.BigEndian.Uint16(b[:2]))
+			if len(b) < 2+n {
+				return nil, fmt.Errorf("truncated header value")
+			}
+			if valueType == headerTypeString {
+				headers[name] = string(b[2 : 2+n])
+			}
+			b = b[2+n:]
+			continue
+		default:
+			return nil, fmt.Errorf("unknown header value type: %d", valueType)
+		}
+
+		if len(b) < size {
+			return nil, fmt.Errorf("truncated header value")
+		}
+		b = b[size:]
+	}
+	return headers, nil
+}
@@ -0,0 +1,80 @@
+package bedrock
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"strings"
+	"testing"
+)
+
+// encodeMessage builds an event-stream message with string headers.
+func encodeMessage(headers map[string]string, payload []byte) []byte {
+	var hb bytes.Buffer
+	for name, value := range headers {
+		hb.WriteByte(byte(len(name)))
+		hb.WriteString(name)
+		hb.WriteByte(headerTypeString)
+		binary.Write(&hb, binary.BigEndian, uint16(len(value)))
+		hb.WriteString(value)
+	}
+
+	total := uint32(minMessageLen + hb.Len() + len(payload))
+	var msg bytes.Buffer
+	binary.Write(&msg, binary.BigEndian, total)
+	binary.Write(&msg, binary.BigEndian, uint32(hb.Len()))
+	binary.Write(&msg, binary.BigEndian, crc32.ChecksumIEEE(msg.Bytes()))
+	msg.Write(hb.Bytes())
+	msg.Write(payload)
+	binary.Write(&msg, binary.BigEndian, crc32.ChecksumIEEE(msg.Bytes()))
+	return msg.Bytes()
+}
+
+func TestDecoder_Messages(t *testing.T) {
+	var stream bytes.Buffer
+	stream.Write(encodeMessage(map[string]string{":event-type": "chunk", ":message-type": "event"}, []byte(`{"bytes":"aGk="}`)))
+	stream.Write(encodeMessage(map[string]string{":event-type": "chunk"}, nil))
+
+	d := NewDecoder(&stream)
+
+	msg, err := d.Next()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if msg.Headers[":event-type"] != "chunk" || msg.Headers[":message-type"] != "event" {
+		t.Errorf("unexpected headers: %v", msg.Headers)
+	}
+	if string(msg.Payload) != `{"bytes":"aGk="}` {
+		t.Errorf("unexpected payload: %q", msg.Payload)
+	}
+
+	msg, err = d.Next()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(msg.Payload) != 0 {
+		t.Errorf("expected empty payload, got %q", msg.Payload)
+	}
+
+	if _, err := d.Next(); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+}
+
+func TestDecoder_ChecksumMismatch(t *testing.T) {
+	msg := encodeMessage(map[string]string{":event-type": "chunk"}, []byte("payload"))
+	msg[len(msg)-6] ^= 0xFF // corrupt the payload
+
+	if _, err := NewDecoder(bytes.NewReader(msg)).Next(); err == nil || !strings.Contains(err.Error(), "checksum") {
+		t.Errorf("expected checksum error, got %v", err)
+	}
+}
+
+func TestDecoder_Truncated(t *testing.T) {
+	msg := encodeMessage(map[string]string{":event-type": "chunk"}, []byte("payload"))
+
+	if _, err := NewDecoder(bytes.NewReader(msg[:len(msg)-3])).Next(); err == nil {
+		t.Error("expected error for truncated message")
+	}
+}
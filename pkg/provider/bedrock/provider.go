@@ -0,0 +1,378 @@
+// Package bedrock provides an AWS Bedrock provider using the
+// invoke-with-response-stream API with SigV4 request signing.
+package bedrock
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/smithy-go/encoding/httpbinding"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/config"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/httpclient"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/provider"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/workload"
+)
+
+const signingService = "bedrock"
+
+// signer signs requests with AWS Signature Version 4. It caches derived
+// signing keys and is safe for concurrent use.
+var signer = v4.NewSigner()
+
+func init() {
+	provider.Register("bedrock", func() provider.Provider {
+		return &Provider{}
+	})
+}
+
+// Provider implements the AWS Bedrock provider.
+type Provider struct {
+	clients httpclient.Cache // Reused across requests so connections are pooled
+
+	mu     sync.Mutex
+	awsCfg *aws.Config // Loaded once; its credentials are cached and refreshed before they expire
+}
+
+// Name returns the provider name.
+func (p *Provider) Name() string {
+	return "bedrock"
+}
+
+// AnthropicRequest is the Bedrock request body for Anthropic Claude models.
+type AnthropicRequest struct {
+	AnthropicVersion string                 `json:"anthropic_version"`
+	MaxTokens        int                    `json:"max_tokens"`
+	Temperature      *float64               `json:"temperature,omitempty"`
+	TopP             *float64               `json:"top_p,omitempty"`
+	StopSequences    []string               `json:"stop_sequences,omitempty"`
+	System           string                 `json:"system,omitempty"`
+	Messages         []workload.ChatMessage `json:"messages"`
+}
+
+// TitanRequest is the Bedrock request body for Amazon Titan text models.
+type TitanRequest struct {
+	InputText            string                `json:"inputText"`
+	TextGenerationConfig TitanGenerationConfig `json:"textGenerationConfig"`
+}
+
+// TitanGenerationConfig holds Titan generation parameters.
+type TitanGenerationConfig struct {
+	MaxTokenCount int      `json:"maxTokenCount"`
+	Temperature   *float64 `json:"temperature,omitempty"`
+	TopP          *float64 `json:"topP,omitempty"`
+	StopSequences []string `json:"stopSequences,omitempty"`
+}
+
+// ChunkPayload is the JSON payload of a "chunk" event.
+type ChunkPayload struct {
+	Bytes string `json:"bytes"` // Base64-encoded model-native chunk
+}
+
+// ModelChunk holds the fields we read from model-native chunks (Claude and Titan).
+type ModelChunk struct {
+	// Anthropic Claude
+	Type  string `json:"type"`
+	Delta *struct {
+		Type       string `json:"type"`
+		Text       string `json:"text"`
+		StopReason string `json:"stop_reason"` // Set on message_delta
+	} `json:"delta,omitempty"`
+	Message *struct {
+		Usage struct {
+			InputTokens int `json:"input_tokens"`
+		} `json:"usage"`
+	} `json:"message,omitempty"`
+	Usage *struct {
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage,omitempty"`
+
+	// Amazon Titan
+	OutputText       string `json:"outputText"`
+	CompletionReason string `json:"completionReason"`
+
+	// Added by Bedrock to the final chunk for every model
+	InvocationMetrics *struct {
+		InputTokenCount  int `json:"inputTokenCount"`
+		OutputTokenCount int `json:"outputTokenCount"`
+	} `json:"amazon-bedrock-invocationMetrics,omitempty"`
+}
+
+// loadAWSConfig resolves the region and credentials through the standard AWS
+// chain (environment, shared config and credentials files, SSO, assume-role,
+// ECS and EC2 instance roles) on first use. A failed load is retried on the
+// next request.
+func (p *Provider) loadAWSConfig(ctx context.Context, region string) (aws.Config, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.awsCfg != nil {
+		return *p.awsCfg, nil
+	}
+
+	var opts []func(*awsconfig.LoadOptions) error
+	if region != "" {
+		opts = append(opts, awsconfig.WithRegion(region))
+	}
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return aws.Config{}, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	p.awsCfg = &awsCfg
+	return awsCfg, nil
+}
+
+// StreamChat executes a streaming invoke-with-response-stream request.
+func (p *Provider) StreamChat(ctx context.Context, cfg *config.GlobalConfig, input workload.WorkloadInput) (<-chan provider.StreamEvent, error) {
+	messages := input.ToMessagesWithSystem(cfg.SystemPrompt)
+	if len(messages) == 0 {
+		return nil, fmt.Errorf("no messages provided")
+	}
+
+	maxTokens := input.MaxTokens
+	if maxTokens == 0 {
+		maxTokens = cfg.MaxTokens
+	}
+
+	awsCfg, err := p.loadAWSConfig(ctx, cfg.Region)
+	if err != nil {
+		return nil, err
+	}
+	region := awsCfg.Region
+	if region == "" {
+		return nil, fmt.Errorf("bedrock requires a region (-region or AWS_REGION)")
+	}
+
+	creds, err := awsCfg.Credentials.Retrieve(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve AWS credentials: %w", err)
+	}
+
+	jsonBody, err := buildRequestBody(cfg, messages, maxTokens)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	endpoint := strings.TrimRight(cfg.URL, "/")
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://bedrock-runtime.%s.amazonaws.com", region)
+	}
+	reqURL, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid bedrock endpoint: %w", err)
+	}
+	// Model IDs contain ':' (e.g. anthropic.claude-3-haiku-20240307-v1:0), which must be escaped
+	reqURL.Path = "/model/" + cfg.ModelName + "/invoke-with-response-stream"
+	reqURL.RawPath = "/model/" + httpbinding.EscapePath(cfg.ModelName, true) + "/invoke-with-response-stream"
+
+	if cfg.Verbose {
+		fmt.Println("\n" + strings.Repeat("=", 80))
+		fmt.Println("[VERBOSE] BEDROCK STREAM REQUEST")
+		fmt.Println(strings.Repeat("-", 80))
+		fmt.Printf("URL: %s\n", reqURL.String())
+		fmt.Printf("Region: %s\n", region)
+		fmt.Printf("MaxTokens: %d\n", maxTokens)
+		fmt.Println(strings.Repeat("=", 80))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", reqURL.String(), bytes.NewReader(jsonBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.URL = reqURL
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/vnd.amazon.eventstream")
+	payloadHash := sha256.Sum256(jsonBody)
+	if err := signer.SignHTTP(ctx, creds, req, hex.EncodeToString(payloadHash[:]), signingService, region, time.Now()); err != nil {
+		return nil, fmt.Errorf("failed to sign request: %w", err)
+	}
+
+	resp, err := p.clients.Get(cfg).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		return nil, provider.StatusError(resp.StatusCode, body)
+	}
+
+	events := make(chan provider.StreamEvent, 100)
+	go p.parseStream(resp.Body, events, cfg.Verbosity >= 2)
+
+	return events, nil
+}
+
+// buildRequestBody builds the model-family specific request body.
+// Bedrock models do not accept a seed, so cfg.Seed is ignored.
+func buildRequestBody(cfg *config.GlobalConfig, messages []workload.ChatMessage, maxTokens int) ([]byte, error) {
+	if isTitan(cfg.ModelName) {
+		var sb strings.Builder
+		for _, msg := range messages {
+			switch msg.Role {
+			case "user":
+				sb.WriteString("User: " + msg.Content + "\n")
+			case "assistant":
+				sb.WriteString("Bot: " + msg.Content + "\n")
+			default:
+				sb.WriteString(msg.Content + "\n")
+			}
+		}
+		sb.WriteString("Bot:")
+		return provider.MarshalBody(cfg, TitanRequest{
+			InputText: sb.String(),
+			TextGenerationConfig: TitanGenerationConfig{
+				MaxTokenCount: maxTokens,
+				Temperature:   cfg.Temperature,
+				TopP:          cfg.TopP,
+				StopSequences: cfg.Stop,
+			},
+		})
+	}
+
+	// Default to the Anthropic Messages format; system prompts go in a dedicated field
+	req := AnthropicRequest{
+		AnthropicVersion: "bedrock-2023-05-31",
+		MaxTokens:        maxTokens,
+		Temperature:      cfg.Temperature,
+		TopP:             cfg.TopP,
+		StopSequences:    cfg.Stop,
+	}
+	for _, msg := range messages {
+		if msg.Role == "system" {
+			req.System = msg.Content
+			continue
+		}
+		req.Messages = append(req.Messages, msg)
+	}
+	return provider.MarshalBody(cfg, req)
+}
+
+func isTitan(modelID string) bool {
+	return strings.Contains(modelID, "amazon.titan")
+}
+
+func (p *Provider) parseStream(body io.ReadCloser, events chan<- provider.StreamEvent, dumpFrames bool) {
+	defer close(events)
+	defer body.Close()
+
+	decoder := NewDecoder(body)
+	usage := &provider.TokenUsage{}
+	usageSent := false
+	finishReason := ""
+
+	for {
+		msg, err := decoder.Next()
+		if err == io.EOF {
+			if !usageSent && (usage.PromptTokens > 0 || usage.CompletionTokens > 0) {
+				events <- provider.StreamEvent{Type: provider.EventUsage, Usage: usage}
+			}
+			events <- provider.StreamEvent{Type: provider.EventEnd, FinishReason: finishReason}
+			return
+		}
+		if err != nil {
+			events <- provider.StreamEvent{
+				Type: provider.EventError,
+				Err:  fmt.Errorf("event-stream decode error: %w", err),
+			}
+			return
+		}
+
+		if msg.Headers[":message-type"] == "exception" || msg.Headers[":message-type"] == "error" {
+			exType := msg.Headers[":exception-type"]
+			if exType == "" {
+				exType = msg.Headers[":error-code"]
+			}
+			events <- provider.StreamEvent{
+				Type: provider.EventError,
+				Raw:  string(msg.Payload),
+				Err:  fmt.Errorf("bedrock %s: %s", exType, string(msg.Payload)),
+			}
+			return
+		}
+
+		if msg.Headers[":event-type"] != "chunk" {
+			continue
+		}
+
+		var payload ChunkPayload
+		if err := json.Unmarshal(msg.Payload, &payload); err != nil {
+			continue
+		}
+		raw, err := base64.StdEncoding.DecodeString(payload.Bytes)
+		if err != nil {
+			continue
+		}
+		if dumpFrames {
+			fmt.Printf("[EVENTSTREAM] %s\n", raw)
+		}
+
+		var chunk ModelChunk
+		if err := json.Unmarshal(raw, &chunk); err != nil {
+			continue
+		}
+
+		text := chunk.OutputText
+		if chunk.Delta != nil && chunk.Delta.Type == "text_delta" {
+			text = chunk.Delta.Text
+		}
+		if text != "" {
+			events <- provider.StreamEvent{
+				Type: provider.EventContent,
+				Raw:  string(raw),
+				Text: text,
+			}
+		}
+
+		if chunk.Delta != nil && chunk.Delta.StopReason != "" {
+			finishReason = normalizeFinishReason(chunk.Delta.StopReason)
+		} else if chunk.CompletionReason != "" {
+			finishReason = normalizeFinishReason(chunk.CompletionReason)
+		}
+
+		// Claude reports input tokens on message_start and output tokens on message_delta;
+		// Bedrock's invocation metrics on the last chunk are authoritative for all models.
+		if chunk.Message != nil && chunk.Message.Usage.InputTokens > 0 {
+			usage.PromptTokens = chunk.Message.Usage.InputTokens
+		}
+		if chunk.Usage != nil && chunk.Usage.OutputTokens > 0 {
+			usage.CompletionTokens = chunk.Usage.OutputTokens
+		}
+		if m := chunk.InvocationMetrics; m != nil {
+			usage.PromptTokens = m.InputTokenCount
+			usage.CompletionTokens = m.OutputTokenCount
+			usageSent = true
+			events <- provider.StreamEvent{
+				Type:  provider.EventUsage,
+				Raw:   string(raw),
+				Usage: &provider.TokenUsage{PromptTokens: usage.PromptTokens, CompletionTokens: usage.CompletionTokens},
+			}
+		}
+	}
+}
+
+// normalizeFinishReason maps Claude stop_reason and Titan completionReason values
+// onto the OpenAI finish_reason vocabulary.
+func normalizeFinishReason(reason string) string {
+	switch strings.ToLower(reason) {
+	case "end_turn", "stop_sequence", "finish":
+		return "stop"
+	case "max_tokens", "length":
+		return "length"
+	default:
+		return strings.ToLower(reason)
+	}
+}
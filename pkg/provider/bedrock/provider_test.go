@@ -0,0 +1,109 @@
+package bedrock
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/config"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/workload"
+)
+
+// setupProfile points the AWS SDK at a temporary "bench" profile and clears
+// any credentials or region inherited from the environment.
+func setupProfile(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	config := filepath.Join(dir, "config")
+	credentials := filepath.Join(dir, "credentials")
+	if err := os.WriteFile(config, []byte("[profile bench]\nregion = eu-west-1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(credentials, []byte("[bench]\naws_access_key_id = AKIDBENCH\naws_secret_access_key = secret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN", "AWS_REGION", "AWS_DEFAULT_REGION"} {
+		t.Setenv(name, "")
+	}
+	t.Setenv("AWS_CONFIG_FILE", config)
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", credentials)
+	t.Setenv("AWS_PROFILE", "bench")
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+}
+
+func TestLoadAWSConfig_Profile(t *testing.T) {
+	setupProfile(t)
+	p := &Provider{}
+
+	awsCfg, err := p.loadAWSConfig(context.Background(), "")
+	if err != nil {
+		t.Fatalf("loadAWSConfig: %v", err)
+	}
+	if awsCfg.Region != "eu-west-1" {
+		t.Errorf("region = %q, want the profile's region", awsCfg.Region)
+	}
+	creds, err := awsCfg.Credentials.Retrieve(context.Background())
+	if err != nil {
+		t.Fatalf("Retrieve: %v", err)
+	}
+	if creds.AccessKeyID != "AKIDBENCH" {
+		t.Errorf("access key = %q, want the profile's key", creds.AccessKeyID)
+	}
+
+	// The config is resolved once per provider, not on every request
+	t.Setenv("AWS_PROFILE", "missing")
+	again, err := p.loadAWSConfig(context.Background(), "")
+	if err != nil {
+		t.Fatalf("second loadAWSConfig: %v", err)
+	}
+	if again.Credentials != awsCfg.Credentials {
+		t.Error("second call loaded a new credentials provider, want the cached one")
+	}
+}
+
+func TestLoadAWSConfig_RegionFlag(t *testing.T) {
+	setupProfile(t)
+
+	awsCfg, err := (&Provider{}).loadAWSConfig(context.Background(), "us-west-2")
+	if err != nil {
+		t.Fatalf("loadAWSConfig: %v", err)
+	}
+	if awsCfg.Region != "us-west-2" {
+		t.Errorf("region = %q, want -region to override the profile", awsCfg.Region)
+	}
+}
+
+func TestStreamChat_SignsRequest(t *testing.T) {
+	setupProfile(t)
+	var gotURI, gotAuth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotURI, gotAuth = r.RequestURI, r.Header.Get("Authorization")
+		http.Error(w, "denied", http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	cfg := config.DefaultConfig()
+	cfg.URL = srv.URL
+	cfg.ModelName = "anthropic.claude-v2:1"
+	_, err := (&Provider{}).StreamChat(context.Background(), cfg, workload.NewSimpleWorkload("t", "hi", 8))
+	if err == nil || !strings.HasPrefix(err.Error(), "HTTP 403:") {
+		t.Fatalf("error = %v, want the server's HTTP 403", err)
+	}
+
+	if want := "/model/anthropic.claude-v2%3A1/invoke-with-response-stream"; gotURI != want {
+		t.Errorf("request URI = %q, want %q", gotURI, want)
+	}
+	for _, want := range []string{
+		"AWS4-HMAC-SHA256 Credential=AKIDBENCH/",
+		"/eu-west-1/bedrock/aws4_request",
+		"SignedHeaders=accept;content-length;content-type;host;x-amz-date",
+	} {
+		if !strings.Contains(gotAuth, want) {
+			t.Errorf("Authorization = %q, want it to contain %q", gotAuth, want)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/config"
+)
+
+// MarshalBody marshals a request body and merges cfg.ExtraBody (-extra-body)
+// into it, so server-specific parameters reach every provider without a flag
+// of their own.
+func MarshalBody(cfg *config.GlobalConfig, body interface{}) ([]byte, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.ExtraBody == "" {
+		return data, nil
+	}
+	return MergeJSON(data, []byte(cfg.ExtraBody))
+}
+
+// MergeJSON applies patch to the JSON object doc as a JSON merge patch
+// (RFC 7386): objects are merged recursively, a null removes the field, and
+// any other value replaces the field of the same name.
+func MergeJSON(doc, patch []byte) ([]byte, error) {
+	base, err := decodeObject(doc)
+	if err != nil {
+		return nil, fmt.Errorf("request body is not a JSON object: %w", err)
+	}
+	extra, err := decodeObject(patch)
+	if err != nil {
+		return nil, fmt.Errorf("extra body is not a JSON object: %w", err)
+	}
+	return json.Marshal(mergePatch(base, extra))
+}
+
+// decodeObject decodes a JSON object, keeping numbers as json.Number so
+// integers such as a 64-bit seed survive the round trip exactly.
+func decodeObject(data []byte) (map[string]interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var m map[string]interface{}
+	if err := dec.Decode(&m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func mergePatch(base, patch map[string]interface{}) map[string]interface{} {
+	if base == nil {
+		base = map[string]interface{}{}
+	}
+	for k, v := range patch {
+		if v == nil {
+			delete(base, k)
+			continue
+		}
+		if p, ok := v.(map[string]interface{}); ok {
+			b, _ := base[k].(map[string]interface{})
+			base[k] = mergePatch(b, p)
+			continue
+		}
+		base[k] = v
+	}
+	return base
+}
@@ -0,0 +1,54 @@
+package provider
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMergeJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     string
+		patch   string
+		want    string
+		wantErr bool
+	}{
+		{"add fields", `{"model":"m"}`, `{"top_k":40,"repetition_penalty":1.1}`, `{"model":"m","top_k":40,"repetition_penalty":1.1}`, false},
+		{"override", `{"model":"m","max_tokens":256}`, `{"max_tokens":64}`, `{"model":"m","max_tokens":64}`, false},
+		{"nested merge", `{"parameters":{"top_p":0.9}}`, `{"parameters":{"top_k":40}}`, `{"parameters":{"top_p":0.9,"top_k":40}}`, false},
+		{"null removes", `{"model":"m","stream_options":{"include_usage":true}}`, `{"stream_options":null}`, `{"model":"m"}`, false},
+		{"not an object", `{"model":"m"}`, `[1,2]`, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MergeJSON([]byte(tt.doc), []byte(tt.patch))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("MergeJSON() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			var gotV, wantV interface{}
+			json.Unmarshal(got, &gotV)
+			json.Unmarshal([]byte(tt.want), &wantV)
+			if !reflect.DeepEqual(gotV, wantV) {
+				t.Errorf("MergeJSON() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeJSON_KeepsLargeIntegers(t *testing.T) {
+	got, err := MergeJSON([]byte(`{"seed":9007199254740993,"temperature":0.7}`), []byte(`{"top_k":40,"parameters":{"seed":18446744073709551615}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"seed":9007199254740993`, `"temperature":0.7`, `"seed":18446744073709551615`} {
+		if !strings.Contains(string(got), want) {
+			t.Errorf("MergeJSON() = %s, want it to contain %s", got, want)
+		}
+	}
+}
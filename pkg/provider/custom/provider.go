@@ -0,0 +1,162 @@
+// Package custom implements a provider that delegates each request to an
+// external command, so proprietary gateways can be benchmarked without Go code.
+//
+// Contract: the command (run via "sh -c") receives one JSON object on stdin:
+//
+//	{"url": "...", "model": "...", "messages": [...], "max_tokens": 256}
+//
+// and writes an OpenAI-style stream to stdout, either as SSE ("data: {...}"
+// lines ending with "data: [DONE]") or as NDJSON (one chunk object per line).
+// A non-zero exit status is reported as a request error including stderr.
+package custom
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/config"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/provider"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/provider/openai"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/workload"
+)
+
+func init() {
+	provider.Register("custom", func() provider.Provider {
+		return &Provider{}
+	})
+}
+
+// Provider implements the external command provider.
+type Provider struct{}
+
+// Name returns the provider name.
+func (p *Provider) Name() string {
+	return "custom"
+}
+
+// CommandRequest is the JSON object written to the command's stdin.
+type CommandRequest struct {
+	URL       string                 `json:"url"`
+	Model     string                 `json:"model"`
+	Messages  []workload.ChatMessage `json:"messages"`
+	MaxTokens int                    `json:"max_tokens"`
+	Stop      []string               `json:"stop,omitempty"`
+}
+
+// StreamChat runs the configured command for one request.
+func (p *Provider) StreamChat(ctx context.Context, cfg *config.GlobalConfig, input workload.WorkloadInput) (<-chan provider.StreamEvent, error) {
+	if cfg.CustomCmd == "" {
+		return nil, fmt.Errorf("custom provider requires -custom-cmd")
+	}
+
+	messages := input.ToMessagesWithSystem(cfg.SystemPrompt)
+	if len(messages) == 0 {
+		return nil, fmt.Errorf("no messages provided")
+	}
+
+	maxTokens := input.MaxTokens
+	if maxTokens == 0 {
+		maxTokens = cfg.MaxTokens
+	}
+
+	reqBody, err := provider.MarshalBody(cfg, CommandRequest{
+		URL:       cfg.URL,
+		Model:     cfg.ModelName,
+		Messages:  messages,
+		MaxTokens: maxTokens,
+		Stop:      cfg.Stop,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", cfg.CustomCmd)
+	cmd.Stdin = bytes.NewReader(reqBody)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stdout pipe: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start custom command: %w", err)
+	}
+
+	body := &commandOutput{
+		reader: newSSEAdapter(stdout),
+		cmd:    cmd,
+		stderr: &stderr,
+	}
+
+	events := make(chan provider.StreamEvent, 100)
+	go openai.ParseStream(ctx, body, events, cfg.Verbose, cfg.Verbosity >= 2, cfg.StrictSSE)
+
+	return events, nil
+}
+
+// commandOutput exposes the command's stdout as a stream body. At EOF it waits
+// for the command and turns a non-zero exit into a read error.
+type commandOutput struct {
+	reader *io.PipeReader
+	cmd    *exec.Cmd
+	stderr *bytes.Buffer
+	waited bool
+}
+
+func (c *commandOutput) Read(p []byte) (int, error) {
+	n, err := c.reader.Read(p)
+	if err == io.EOF && !c.waited {
+		c.waited = true
+		if waitErr := c.cmd.Wait(); waitErr != nil {
+			return n, fmt.Errorf("custom command failed: %w: %s", waitErr, strings.TrimSpace(c.stderr.String()))
+		}
+	}
+	return n, err
+}
+
+func (c *commandOutput) Close() error {
+	c.reader.Close()
+	if !c.waited {
+		c.waited = true
+		if c.cmd.Process != nil {
+			c.cmd.Process.Kill()
+		}
+		c.cmd.Wait()
+	}
+	return nil
+}
+
+// newSSEAdapter passes SSE through unchanged and wraps bare NDJSON lines
+// (and a bare [DONE]) as SSE data events.
+func newSSEAdapter(r io.Reader) *io.PipeReader {
+	pr, pw := io.Pipe()
+	go func() {
+		reader := bufio.NewReader(r)
+		for {
+			line, err := reader.ReadString('\n')
+			if line != "" {
+				trimmed := strings.TrimSpace(line)
+				if strings.HasPrefix(trimmed, "{") || trimmed == "[DONE]" {
+					line = "data: " + trimmed + "\n\n"
+				}
+				if _, werr := io.WriteString(pw, line); werr != nil {
+					return
+				}
+			}
+			if err != nil {
+				if err == io.EOF {
+					pw.Close()
+				} else {
+					pw.CloseWithError(err)
+				}
+				return
+			}
+		}
+	}()
+	return pr
+}
@@ -0,0 +1,82 @@
+package custom
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/config"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/provider"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/workload"
+)
+
+func collect(t *testing.T, command string) (string, error) {
+	t.Helper()
+	cfg := config.DefaultConfig()
+	cfg.CustomCmd = command
+
+	events, err := (&Provider{}).StreamChat(context.Background(), cfg, workload.NewSimpleWorkload("t", "hi", 8))
+	if err != nil {
+		t.Fatalf("StreamChat failed: %v", err)
+	}
+
+	var content strings.Builder
+	var streamErr error
+	for e := range events {
+		switch e.Type {
+		case provider.EventContent:
+			content.WriteString(e.Text)
+		case provider.EventError:
+			streamErr = e.Err
+		}
+	}
+	return content.String(), streamErr
+}
+
+func TestStreamChat(t *testing.T) {
+	tests := []struct {
+		name    string
+		command string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "ndjson",
+			command: `cat >/dev/null; echo '{"choices":[{"delta":{"content":"a"}}]}'; echo '{"choices":[{"delta":{"content":"b"}}]}'; echo '[DONE]'`,
+			content: "ab",
+		},
+		{
+			name:    "sse",
+			command: `cat >/dev/null; printf 'data: {"choices":[{"delta":{"content":"x"}}]}\n\ndata: [DONE]\n\n'`,
+			content: "x",
+		},
+		{
+			name:    "stdin is request json",
+			command: `grep -q '"max_tokens":8' && echo '{"choices":[{"delta":{"content":"ok"}}]}'`,
+			content: "ok",
+		},
+		{
+			name:    "non-zero exit",
+			command: `echo boom >&2; exit 3`,
+			wantErr: "boom",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, err := collect(t, tt.command)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if content != tt.content {
+				t.Errorf("expected content %q, got %q", tt.content, content)
+			}
+		})
+	}
+}
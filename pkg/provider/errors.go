@@ -0,0 +1,152 @@
+package provider
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ErrContextOverflow is wrapped by the error of a request that the server
+// rejected because the prompt (plus max_tokens) does not fit the model's
+// context window. Test for it with errors.Is.
+var ErrContextOverflow = errors.New("context length exceeded")
+
+// HTTPError is a non-200 response. Its message keeps the "HTTP <code>:
+// <body>" form used across the tool, and it wraps ErrContextOverflow when the
+// status and body identify a context overflow.
+type HTTPError struct {
+	StatusCode int
+	Body       string
+	kind       error
+}
+
+func (e *HTTPError) Error() string { return fmt.Sprintf("HTTP %d: %s", e.StatusCode, e.Body) }
+func (e *HTTPError) Unwrap() error { return e.kind }
+
+// StatusError returns the error for a response with the given status and body.
+func StatusError(status int, body []byte) error {
+	e := &HTTPError{StatusCode: status, Body: string(body)}
+	if isContextOverflow(status, body) {
+		e.kind = ErrContextOverflow
+	}
+	return e
+}
+
+// BodyError returns the error for a 200 response whose body, or one of whose
+// stream frames, is an error object instead of a completion, or nil if data is
+// not one. Some gateways report failures this way rather than with an error
+// status. The error reads "HTTP 200: <message>".
+func BodyError(data []byte) error {
+	var parsed struct {
+		Object  string          `json:"object"`
+		Message string          `json:"message"`
+		Error   json.RawMessage `json:"error"`
+	}
+	if json.Unmarshal(data, &parsed) != nil {
+		return nil
+	}
+	// OpenAI: {"error": {"message": ...}} or {"error": "..."}; vLLM: {"object": "error", "message": ...}
+	var nested struct {
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(parsed.Error, &nested) != nil {
+		json.Unmarshal(parsed.Error, &nested.Message)
+	}
+	message := nested.Message
+	if message == "" && parsed.Object == "error" {
+		message = parsed.Message
+	}
+	if message == "" {
+		return nil
+	}
+	e := &HTTPError{StatusCode: http.StatusOK, Body: message}
+	if isContextOverflow(http.StatusOK, data) {
+		e.kind = ErrContextOverflow
+	}
+	return e
+}
+
+// overflowCodes are machine-readable error codes and types for a prompt that
+// is too long (OpenAI, Azure and compatible servers).
+var overflowCodes = map[string]bool{
+	"context_length_exceeded":       true,
+	"context_window_exceeded":       true,
+	"model_context_window_exceeded": true,
+	"string_above_max_length":       true,
+}
+
+// overflowMessages are phrases (lowercase) of servers without such a code:
+// vLLM, TGI, SGLang, llama.cpp, Bedrock, DashScope and Chinese-language gateways.
+// Generic phrases such as "too many tokens" are left out: Bedrock throttles
+// with "Too many tokens, please wait before trying again."
+var overflowMessages = []string{
+	"maximum context length",
+	"context length exceeded",
+	"than the model's context length",
+	"context window",
+	"context size",
+	"input is too long",
+	"prompt is too long",
+	"range of input length",
+	"max_new_tokens` must be <=",
+	"exceeds the available context",
+	"上下文长度",
+	"最大长度",
+	"超出长度",
+	"超过最大",
+}
+
+// isContextOverflow reports whether a response says the prompt was too long.
+// Only 400, 413 and 422, the statuses of a rejected request body, and error
+// bodies sent with 200 qualify, so rate limits (429), auth failures and 5xx
+// responses that happen to mention tokens keep their own meaning.
+func isContextOverflow(status int, body []byte) bool {
+	switch status {
+	case http.StatusRequestEntityTooLarge:
+		return true
+	case http.StatusOK, http.StatusBadRequest, http.StatusUnprocessableEntity:
+	default:
+		return false
+	}
+
+	var parsed struct {
+		Code    any             `json:"code"`
+		Type    string          `json:"type"`
+		Message string          `json:"message"`
+		Detail  any             `json:"detail"`
+		Error   json.RawMessage `json:"error"`
+	}
+	texts := []string{string(body)}
+	if json.Unmarshal(body, &parsed) == nil {
+		// "error" is an object with its own code, type and message, or just a message
+		var nested struct {
+			Code    any    `json:"code"`
+			Type    string `json:"type"`
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(parsed.Error, &nested) != nil {
+			json.Unmarshal(parsed.Error, &nested.Message)
+		}
+		for _, code := range []any{nested.Code, nested.Type, parsed.Code, parsed.Type} {
+			if s, ok := code.(string); ok && overflowCodes[strings.ToLower(s)] {
+				return true
+			}
+		}
+		texts = []string{nested.Message, parsed.Message}
+		if parsed.Detail != nil {
+			texts = append(texts, fmt.Sprint(parsed.Detail))
+		}
+	}
+
+	for _, text := range texts {
+		text = strings.ToLower(text)
+		for _, marker := range overflowMessages {
+			if strings.Contains(text, marker) {
+				return true
+			}
+		}
+	}
+	return false
+}
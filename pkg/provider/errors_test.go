@@ -0,0 +1,79 @@
+package provider
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestStatusError(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		overflow bool
+	}{
+		{"openai code", 400, `{"error":{"message":"Please reduce the length of the messages.","type":"invalid_request_error","code":"context_length_exceeded"}}`, true},
+		{"vllm message", 400, `{"object":"error","message":"This model's maximum context length is 4096 tokens. However, you requested 5000 tokens.","type":"BadRequestError","code":400}`, true},
+		{"tgi", 422, `{"error":"Input validation error: ` + "`inputs` tokens + `max_new_tokens` must be <= 4096. Given: 4000 `inputs` tokens and 200 `max_new_tokens`" + `","error_type":"validation"}`, true},
+		{"bedrock", 400, `{"message":"Input is too long for requested model."}`, true},
+		{"chinese gateway", 400, `{"code":"InvalidParameter","message":"输入超过最大长度限制"}`, true},
+		{"payload too large", 413, `request entity too large`, true},
+		{"plain text", 400, `prompt is too long: 9000 tokens > 8192 maximum`, true},
+		{"other client error", 400, `{"error":{"message":"Invalid value for temperature","type":"invalid_request_error"}}`, false},
+		{"dashscope", 400, `{"code":"InvalidParameter","message":"Range of input length should be [1, 30720]"}`, true},
+		{"sglang", 400, `{"object":"error","message":"The input (9000 tokens) is longer than the model's context length (8192 tokens).","code":400}`, true},
+		{"unauthorized", 401, `{"error":"invalid api key"}`, false},
+		{"forbidden mentioning context", 403, `{"message":"Your plan does not allow a context window of this size"}`, false},
+		{"bedrock throttling", 429, `{"message":"Too many tokens, please wait before trying again."}`, false},
+		{"rate limit with overflow code", 429, `{"error":{"message":"Rate limit reached","code":"context_length_exceeded"}}`, false},
+		{"token limit without overflow", 400, `{"message":"Too many tokens per minute for this token limit"}`, false},
+		{"server error mentioning tokens", 500, `{"message":"too many tokens in the batch queue"}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := StatusError(tt.status, []byte(tt.body))
+			if got := errors.Is(err, ErrContextOverflow); got != tt.overflow {
+				t.Errorf("errors.Is(ErrContextOverflow) = %v, want %v", got, tt.overflow)
+			}
+			var httpErr *HTTPError
+			if !errors.As(err, &httpErr) || httpErr.StatusCode != tt.status {
+				t.Errorf("StatusError() = %v, want an *HTTPError with status %d", err, tt.status)
+			}
+		})
+	}
+}
+
+func TestBodyError(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		want     string // "" = not an error body
+		overflow bool
+	}{
+		{"openai object", `{"error":{"message":"Rate limit reached","type":"requests","code":"rate_limit_exceeded"}}`, "HTTP 200: Rate limit reached", false},
+		{"string", `{"error":"upstream unavailable"}`, "HTTP 200: upstream unavailable", false},
+		{"vllm", `{"object":"error","message":"This model's maximum context length is 4096 tokens.","type":"BadRequestError","code":400}`, "HTTP 200: This model's maximum context length is 4096 tokens.", true},
+		{"completion chunk", `{"choices":[{"index":0,"delta":{"content":"hi"}}]}`, "", false},
+		{"null error", `{"error":null,"choices":[]}`, "", false},
+		{"not json", `event: ping`, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := BodyError([]byte(tt.body))
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("BodyError() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.want {
+				t.Fatalf("BodyError() = %v, want %q", err, tt.want)
+			}
+			if got := errors.Is(err, ErrContextOverflow); got != tt.overflow {
+				t.Errorf("errors.Is(ErrContextOverflow) = %v, want %v", got, tt.overflow)
+			}
+		})
+	}
+}
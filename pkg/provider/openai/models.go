@@ -0,0 +1,67 @@
+package openai
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/config"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/httpclient"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/provider"
+)
+
+// ModelsURL derives the /models endpoint from a chat or completions URL,
+// e.g. http://host/v1/chat/completions -> http://host/v1/models.
+func ModelsURL(chatURL string) string {
+	base := strings.TrimRight(chatURL, "/")
+	for _, suffix := range []string{"/chat/completions", "/completions", "/responses"} {
+		if strings.HasSuffix(base, suffix) {
+			base = strings.TrimSuffix(base, suffix)
+			break
+		}
+	}
+	return base + "/models"
+}
+
+// ListModels fetches the model IDs served by the endpoint behind cfg.URL.
+func ListModels(cfg *config.GlobalConfig) ([]string, error) {
+	req, err := http.NewRequest("GET", ModelsURL(cfg.URL), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if cfg.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+cfg.Token)
+	}
+
+	resp, err := httpclient.New(cfg).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, provider.StatusError(resp.StatusCode, body)
+	}
+
+	var list struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse model list: %w", err)
+	}
+
+	ids := make([]string, 0, len(list.Data))
+	for _, m := range list.Data {
+		ids = append(ids, m.ID)
+	}
+	return ids, nil
+}
@@ -0,0 +1,20 @@
+package openai
+
+import "testing"
+
+func TestModelsURL(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"http://localhost:8000/v1/chat/completions", "http://localhost:8000/v1/models"},
+		{"http://localhost:8000/v1/chat/completions/", "http://localhost:8000/v1/models"},
+		{"https://api.example.com/v1/completions", "https://api.example.com/v1/models"},
+		{"http://localhost:8000/v1", "http://localhost:8000/v1/models"},
+	}
+	for _, tt := range tests {
+		if got := ModelsURL(tt.in); got != tt.want {
+			t.Errorf("ModelsURL(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -4,17 +4,15 @@ package openai
 import (
 	"bytes"
 	"context"
-	"crypto/tls"
-	"crypto/x509"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
-	"os"
 	"strings"
-	"time"
 
 	"github.com/brianxiadong/llm-benchmark-kit/pkg/config"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/httpclient"
 	"github.com/brianxiadong/llm-benchmark-kit/pkg/provider"
 	"github.com/brianxiadong/llm-benchmark-kit/pkg/sse"
 	"github.com/brianxiadong/llm-benchmark-kit/pkg/workload"
@@ -27,7 +25,9 @@ func init() {
 }
 
 // Provider implements the OpenAI-compatible API provider.
-type Provider struct{}
+type Provider struct {
+	clients httpclient.Cache // Reused across requests so connections are pooled
+}
 
 // Name returns the provider name.
 func (p *Provider) Name() string {
@@ -44,9 +44,31 @@ type ChatRequest struct {
 	Model              string                 `json:"model"`
 	Messages           []workload.ChatMessage `json:"messages"`
 	MaxTokens          int                    `json:"max_tokens,omitempty"`
+	Temperature        *float64               `json:"temperature,omitempty"`
+	TopP               *float64               `json:"top_p,omitempty"`
+	Seed               *int                   `json:"seed,omitempty"`
+	Stop               []string               `json:"stop,omitempty"`
+	N                  int                    `json:"n,omitempty"`
 	Stream             bool                   `json:"stream"`
 	StreamOptions      *StreamOptions         `json:"stream_options,omitempty"`
 	ChatTemplateKwargs *ChatTemplateKwargs    `json:"chat_template_kwargs,omitempty"`
+	ResponseFormat     *ResponseFormat        `json:"response_format,omitempty"`
+	Logprobs           bool                   `json:"logprobs,omitempty"`
+	MinTokens          int                    `json:"min_tokens,omitempty"` // vLLM extension
+	IgnoreEOS          bool                   `json:"ignore_eos,omitempty"` // vLLM extension
+}
+
+// ResponseFormat requests JSON mode ("json_object") or schema-constrained output ("json_schema").
+type ResponseFormat struct {
+	Type       string            `json:"type"`
+	JSONSchema *JSONSchemaFormat `json:"json_schema,omitempty"`
+}
+
+// JSONSchemaFormat carries the schema for "json_schema" structured outputs.
+type JSONSchemaFormat struct {
+	Name   string          `json:"name"`
+	Schema json.RawMessage `json:"schema"`
+	Strict bool            `json:"strict"`
 }
 
 // StreamOptions configures stream behavior.
@@ -59,6 +81,12 @@ type StreamChoice struct {
 	Index        int          `json:"index"`
 	Delta        DeltaContent `json:"delta"`
 	FinishReason *string      `json:"finish_reason"`
+	Logprobs     *Logprobs    `json:"logprobs,omitempty"`
+}
+
+// Logprobs holds the per-token log probabilities of a delta.
+type Logprobs struct {
+	Content []provider.TokenLogprob `json:"content"`
 }
 
 // DeltaContent represents the delta content in streaming.
@@ -67,6 +95,17 @@ type DeltaContent struct {
 	Content          string `json:"content,omitempty"`
 	Reasoning        string `json:"reasoning,omitempty"`
 	ReasoningContent string `json:"reasoning_content,omitempty"`
+
+	ToolCalls []ToolCallDelta `json:"tool_calls,omitempty"`
+}
+
+// ToolCallDelta is a streamed piece of a tool call; arguments arrive as
+// consecutive JSON string fragments for the same index.
+type ToolCallDelta struct {
+	Index    int                       `json:"index"`
+	ID       string                    `json:"id,omitempty"`
+	Type     string                    `json:"type,omitempty"`
+	Function provider.ToolCallFunction `json:"function"`
 }
 
 // StreamResponse represents a single streaming response chunk.
@@ -79,10 +118,11 @@ type StreamResponse struct {
 	Usage   *provider.TokenUsage `json:"usage,omitempty"`
 }
 
-// StreamChat executes a streaming chat request.
-func (p *Provider) StreamChat(ctx context.Context, cfg *config.GlobalConfig, input workload.WorkloadInput) (<-chan provider.StreamEvent, error) {
-	// Build request body
-	messages := input.ToMessages()
+// NewChatRequest builds the streaming chat request body for input. Providers
+// that speak the OpenAI chunk format over another transport (e.g. WebSocket)
+// reuse it.
+func NewChatRequest(cfg *config.GlobalConfig, input workload.WorkloadInput) (*ChatRequest, error) {
+	messages := input.ToMessagesWithSystem(cfg.SystemPrompt)
 	if len(messages) == 0 {
 		return nil, fmt.Errorf("no messages provided")
 	}
@@ -91,12 +131,22 @@ func (p *Provider) StreamChat(ctx context.Context, cfg *config.GlobalConfig, inp
 	if maxTokens == 0 {
 		maxTokens = cfg.MaxTokens
 	}
-
-	reqBody := ChatRequest{
-		Model:     cfg.ModelName,
-		Messages:  messages,
-		MaxTokens: maxTokens,
-		Stream:    true,
+	// A workload entry may ask for fewer tokens than -min-tokens; the server would reject min > max
+	minTokens := min(cfg.MinTokens, maxTokens)
+
+	reqBody := &ChatRequest{
+		Model:       cfg.ModelName,
+		Messages:    messages,
+		MaxTokens:   maxTokens,
+		Temperature: cfg.Temperature,
+		TopP:        cfg.TopP,
+		Seed:        cfg.Seed,
+		Stop:        cfg.Stop,
+		N:           cfg.N,
+		Logprobs:    cfg.Logprobs,
+		MinTokens:   minTokens,
+		IgnoreEOS:   cfg.IgnoreEOS,
+		Stream:      true,
 		StreamOptions: &StreamOptions{
 			IncludeUsage: true, // Request usage info in stream (for vLLM compatibility)
 		},
@@ -106,7 +156,30 @@ func (p *Provider) StreamChat(ctx context.Context, cfg *config.GlobalConfig, inp
 		reqBody.ChatTemplateKwargs = &ChatTemplateKwargs{EnableThinking: false}
 	}
 
-	jsonBody, err := json.Marshal(reqBody)
+	if cfg.JSONSchema != "" {
+		reqBody.ResponseFormat = &ResponseFormat{
+			Type: "json_schema",
+			JSONSchema: &JSONSchemaFormat{
+				Name:   "benchmark_schema",
+				Schema: json.RawMessage(cfg.JSONSchema),
+				Strict: true,
+			},
+		}
+	} else if cfg.JSONMode {
+		reqBody.ResponseFormat = &ResponseFormat{Type: "json_object"}
+	}
+	return reqBody, nil
+}
+
+// StreamChat executes a streaming chat request.
+func (p *Provider) StreamChat(ctx context.Context, cfg *config.GlobalConfig, input workload.WorkloadInput) (<-chan provider.StreamEvent, error) {
+	// Build request body
+	reqBody, err := NewChatRequest(cfg, input)
+	if err != nil {
+		return nil, err
+	}
+
+	jsonBody, err := provider.MarshalBody(cfg, reqBody)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
@@ -118,9 +191,9 @@ func (p *Provider) StreamChat(ctx context.Context, cfg *config.GlobalConfig, inp
 		fmt.Println(strings.Repeat("-", 80))
 		fmt.Printf("URL: %s\n", cfg.URL)
 		fmt.Printf("Model: %s\n", cfg.ModelName)
-		fmt.Printf("MaxTokens: %d\n", maxTokens)
+		fmt.Printf("MaxTokens: %d\n", reqBody.MaxTokens)
 		fmt.Println("\n[Messages]:")
-		for i, msg := range messages {
+		for i, msg := range reqBody.Messages {
 			fmt.Printf("  [%d] %s: %s\n", i, msg.Role, truncateString(msg.Content, 200))
 		}
 		fmt.Println(strings.Repeat("=", 80))
@@ -134,15 +207,12 @@ func (p *Provider) StreamChat(ctx context.Context, cfg *config.GlobalConfig, inp
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "text/event-stream")
-	if cfg.Token != "" {
-		req.Header.Set("Authorization", "Bearer "+cfg.Token)
+	if token := provider.APIKey(ctx, cfg); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
 	}
 
-	// Create HTTP client
-	client := p.createClient(cfg)
-
 	// Execute request
-	resp, err := client.Do(req)
+	resp, err := p.clients.Get(cfg).Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
@@ -150,14 +220,27 @@ func (p *Provider) StreamChat(ctx context.Context, cfg *config.GlobalConfig, inp
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
 		resp.Body.Close()
-		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body))
+		return nil, provider.StatusError(resp.StatusCode, body)
+	}
+
+	// A JSON body instead of an event stream may be an error object sent with 200
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response: %w", err)
+		}
+		if err := provider.BodyError(body); err != nil {
+			return nil, err
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(body))
 	}
 
 	// Create event channel
 	events := make(chan provider.StreamEvent, 100)
 
 	// Start goroutine to parse SSE
-	go p.parseStream(resp.Body, events, cfg.Verbose)
+	go p.parseStream(ctx, resp.Body, events, cfg.Verbose, cfg.Verbosity >= 2, cfg.StrictSSE)
 
 	return events, nil
 }
@@ -170,32 +253,42 @@ func truncateString(s string, maxLen int) string {
 	return s[:maxLen] + "..."
 }
 
-func (p *Provider) createClient(cfg *config.GlobalConfig) *http.Client {
-	transport := &http.Transport{}
-
-	if cfg.InsecureTLS {
-		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
-	} else if cfg.CACertPath != "" {
-		caCert, err := os.ReadFile(cfg.CACertPath)
-		if err == nil {
-			caCertPool := x509.NewCertPool()
-			caCertPool.AppendCertsFromPEM(caCert)
-			transport.TLSClientConfig = &tls.Config{RootCAs: caCertPool}
-		}
-	}
-
-	return &http.Client{
-		Transport: transport,
-		Timeout:   time.Duration(cfg.TimeoutSec) * time.Second,
-	}
+// ParseStream reads an OpenAI-style SSE stream from body and emits events until
+// the stream ends or ctx is done. It closes events and body when done. Other
+// providers that produce OpenAI-format frames (e.g. custom commands) reuse it.
+// With strict, each tolerated protocol violation is emitted as EventViolation.
+func ParseStream(ctx context.Context, body io.ReadCloser, events chan<- provider.StreamEvent, verbose, dumpFrames, strict bool) {
+	(&Provider{}).parseStream(ctx, body, events, verbose, dumpFrames, strict)
 }
 
-func (p *Provider) parseStream(body io.ReadCloser, events chan<- provider.StreamEvent, verbose bool) {
+// parseStream stops as soon as ctx is done, even if the consumer has stopped
+// reading events: closing body unblocks a pending read and every send also
+// selects on ctx, so the goroutine and connection are never leaked.
+func (p *Provider) parseStream(ctx context.Context, body io.ReadCloser, events chan<- provider.StreamEvent, verbose, dumpFrames, strict bool) {
 	defer close(events)
 	defer body.Close()
 
+	stop := context.AfterFunc(ctx, func() { body.Close() })
+	defer stop()
+
+	send := func(event provider.StreamEvent) bool {
+		select {
+		case events <- event:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
 	parser := sse.NewParser(body)
+	violation := func(msg string) {
+		send(provider.StreamEvent{Type: provider.EventViolation, Err: errors.New(msg)})
+	}
+	if strict {
+		parser.OnViolation = violation
+	}
 	var lastUsage *provider.TokenUsage
+	var finishReason string
 	var fullContent strings.Builder // Accumulate content for verbose logging
 
 	for {
@@ -211,17 +304,21 @@ func (p *Provider) parseStream(body io.ReadCloser, events chan<- provider.Stream
 				fmt.Println(strings.Repeat("=", 80))
 			}
 			// Send end event if we haven't received one
-			events <- provider.StreamEvent{Type: provider.EventEnd}
+			send(provider.StreamEvent{Type: provider.EventEnd, FinishReason: finishReason})
 			return
 		}
 		if err != nil {
-			events <- provider.StreamEvent{
+			send(provider.StreamEvent{
 				Type: provider.EventError,
 				Err:  fmt.Errorf("SSE parse error: %w", err),
-			}
+			})
 			return
 		}
 
+		if dumpFrames {
+			fmt.Printf("[SSE] %s\n", event.Data)
+		}
+
 		// Check for [DONE] signal
 		if event.Data == "[DONE]" {
 			// Verbose logging: response
@@ -234,16 +331,17 @@ func (p *Provider) parseStream(body io.ReadCloser, events chan<- provider.Stream
 				fmt.Println(strings.Repeat("=", 80))
 			}
 			// Send any remaining usage if not already sent
-			if lastUsage != nil {
-				events <- provider.StreamEvent{
-					Type:  provider.EventUsage,
-					Usage: lastUsage,
-				}
-			}
-			events <- provider.StreamEvent{
-				Type: provider.EventEnd,
-				Raw:  event.Data,
+			if lastUsage != nil && !send(provider.StreamEvent{
+				Type:  provider.EventUsage,
+				Usage: lastUsage,
+			}) {
+				return
 			}
+			send(provider.StreamEvent{
+				Type:         provider.EventEnd,
+				Raw:          event.Data,
+				FinishReason: finishReason,
+			})
 			return
 		}
 
@@ -254,17 +352,30 @@ func (p *Provider) parseStream(body io.ReadCloser, events chan<- provider.Stream
 			if verbose {
 				fmt.Printf("[DEBUG] Failed to parse JSON: %v\nData: %s\n", err, truncateString(event.Data, 200))
 			}
+			if strict {
+				violation(fmt.Sprintf("invalid JSON in data: %v", err))
+			}
 			continue
 		}
 
+		// An error object in place of a chunk ends the stream
+		if len(resp.Choices) == 0 && resp.Usage == nil {
+			if err := provider.BodyError([]byte(event.Data)); err != nil {
+				send(provider.StreamEvent{Type: provider.EventError, Raw: event.Data, Err: err})
+				return
+			}
+		}
+
 		// Store usage for later (usually comes with final chunk or [DONE])
 		if resp.Usage != nil {
 			lastUsage = resp.Usage
 			// For vLLM, send usage event immediately when received
 			// (vLLM sends usage in a separate chunk with empty choices)
-			events <- provider.StreamEvent{
+			if !send(provider.StreamEvent{
 				Type:  provider.EventUsage,
 				Usage: lastUsage,
+			}) {
+				return
 			}
 		}
 
@@ -276,10 +387,13 @@ func (p *Provider) parseStream(body io.ReadCloser, events chan<- provider.Stream
 				reasoningText = choice.Delta.Reasoning
 			}
 			if reasoningText != "" {
-				events <- provider.StreamEvent{
-					Type: provider.EventReasoning,
-					Raw:  event.Data,
-					Text: reasoningText,
+				if !send(provider.StreamEvent{
+					Type:   provider.EventReasoning,
+					Raw:    event.Data,
+					Text:   reasoningText,
+					Choice: choice.Index,
+				}) {
+					return
 				}
 			}
 
@@ -289,15 +403,42 @@ func (p *Provider) parseStream(body io.ReadCloser, events chan<- provider.Stream
 				if verbose {
 					fullContent.WriteString(choice.Delta.Content)
 				}
-				events <- provider.StreamEvent{
-					Type: provider.EventContent,
-					Raw:  event.Data,
-					Text: choice.Delta.Content,
+				contentEvent := provider.StreamEvent{
+					Type:   provider.EventContent,
+					Raw:    event.Data,
+					Text:   choice.Delta.Content,
+					Choice: choice.Index,
+				}
+				if choice.Logprobs != nil {
+					contentEvent.Logprobs = choice.Logprobs.Content
+				}
+				if !send(contentEvent) {
+					return
+				}
+			}
+
+			// Emit tool calls; they carry no delta.content but are still generated output.
+			// The function name is kept apart so only the arguments count as output text
+			for _, tc := range choice.Delta.ToolCalls {
+				if tc.Function.Name == "" && tc.Function.Arguments == "" {
+					continue
+				}
+				if !send(provider.StreamEvent{
+					Type:     provider.EventToolCall,
+					Raw:      event.Data,
+					Text:     tc.Function.Arguments,
+					ToolName: tc.Function.Name,
+					Choice:   choice.Index,
+				}) {
+					return
 				}
 			}
 
 			// Note: We no longer return on finish_reason because vLLM sends usage
 			// in a separate chunk AFTER finish_reason. We wait for [DONE] instead.
+			if choice.FinishReason != nil && *choice.FinishReason != "" {
+				finishReason = *choice.FinishReason
+			}
 		}
 	}
 }
@@ -0,0 +1,213 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/config"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/provider"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/workload"
+)
+
+func TestParseStream(t *testing.T) {
+	stream := `data: {"choices":[{"index":0,"delta":{"content":"Hello"},"logprobs":{"content":[{"token":"Hello","logprob":-0.5}]}}]}
+
+data: {"choices":[{"index":0,"delta":{"content":" world"},"finish_reason":"stop"}]}
+
+data: {"choices":[],"usage":{"prompt_tokens":3,"completion_tokens":2}}
+
+data: [DONE]
+
+`
+	events := make(chan provider.StreamEvent, 100)
+	ParseStream(context.Background(), io.NopCloser(strings.NewReader(stream)), events, false, false, false)
+
+	var content string
+	var usage *provider.TokenUsage
+	var end provider.StreamEvent
+	var logprobs []provider.TokenLogprob
+	for e := range events {
+		switch e.Type {
+		case provider.EventContent:
+			content += e.Text
+			logprobs = append(logprobs, e.Logprobs...)
+		case provider.EventUsage:
+			usage = e.Usage
+		case provider.EventEnd:
+			end = e
+		}
+	}
+	if content != "Hello world" {
+		t.Errorf("content = %q, want %q", content, "Hello world")
+	}
+	if usage == nil || usage.CompletionTokens != 2 {
+		t.Errorf("usage = %+v, want 2 completion tokens", usage)
+	}
+	if len(logprobs) != 1 || logprobs[0].Token != "Hello" || logprobs[0].Logprob != -0.5 {
+		t.Errorf("logprobs = %+v, want one entry for Hello at -0.5", logprobs)
+	}
+	if end.FinishReason != "stop" {
+		t.Errorf("finish reason = %q, want stop", end.FinishReason)
+	}
+}
+
+func TestParseStream_MultipleChoices(t *testing.T) {
+	stream := `data: {"choices":[{"index":0,"delta":{"content":"A"}},{"index":1,"delta":{"content":"B"}}]}
+
+data: {"choices":[{"index":1,"delta":{"content":"b"}}]}
+
+data: [DONE]
+
+`
+	events := make(chan provider.StreamEvent, 100)
+	ParseStream(context.Background(), io.NopCloser(strings.NewReader(stream)), events, false, false, false)
+
+	content := map[int]string{}
+	for e := range events {
+		if e.Type == provider.EventContent {
+			content[e.Choice] += e.Text
+		}
+	}
+	if len(content) != 2 || content[0] != "A" || content[1] != "Bb" {
+		t.Errorf("content by choice = %v, want 0:A 1:Bb", content)
+	}
+}
+
+func TestParseStream_StrictViolations(t *testing.T) {
+	stream := "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"Hi\"}}]}\n\n" +
+		"data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\n\n" + // Truncated JSON
+		"data: {\"choices\":[]}\ndata: {\"choices\":[]}\n\n" + // Two frames without a boundary
+		"data: [DONE]\n\n"
+
+	for _, strict := range []bool{false, true} {
+		events := make(chan provider.StreamEvent, 100)
+		ParseStream(context.Background(), io.NopCloser(strings.NewReader(stream)), events, false, false, strict)
+
+		var content string
+		violations := 0
+		for e := range events {
+			switch e.Type {
+			case provider.EventContent:
+				content += e.Text
+			case provider.EventViolation:
+				violations++
+			}
+		}
+		want := 0
+		if strict {
+			want = 2
+		}
+		if content != "Hi" || violations != want {
+			t.Errorf("strict=%v: content = %q, violations = %d, want %q and %d", strict, content, violations, "Hi", want)
+		}
+	}
+}
+
+func TestParseStream_ToolCalls(t *testing.T) {
+	stream := `data: {"choices":[{"index":0,"delta":{"role":"assistant","tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"get_weather","arguments":""}}]}}]}
+
+data: {"choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"{\"city\":"}}]}}]}
+
+data: {"choices":[{"index":0,"delta":{"tool_calls":[{"index":0,"function":{"arguments":"\"Paris\"}"}}]},"finish_reason":"tool_calls"}]}
+
+data: [DONE]
+
+`
+	events := make(chan provider.StreamEvent, 100)
+	ParseStream(context.Background(), io.NopCloser(strings.NewReader(stream)), events, false, false, false)
+
+	var toolName, toolText string
+	for e := range events {
+		switch e.Type {
+		case provider.EventContent:
+			t.Errorf("unexpected content event %q", e.Text)
+		case provider.EventToolCall:
+			toolName += e.ToolName
+			toolText += e.Text
+		}
+	}
+	if toolName != "get_weather" {
+		t.Errorf("tool name = %q, want get_weather", toolName)
+	}
+	if want := `{"city":"Paris"}`; toolText != want {
+		t.Errorf("tool call text = %q, want only the arguments %q", toolText, want)
+	}
+}
+
+func TestParseStream_CancelWithoutConsumer(t *testing.T) {
+	// An endless stream and a consumer that never reads: parseStream must
+	// return once the context is cancelled instead of blocking on a full channel.
+	pr, pw := io.Pipe()
+	go func() {
+		for {
+			if _, err := io.WriteString(pw, "data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"x\"}}]}\n\n"); err != nil {
+				return
+			}
+		}
+	}()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	events := make(chan provider.StreamEvent, 1)
+	done := make(chan struct{})
+	go func() {
+		ParseStream(ctx, pr, events, false, false, false)
+		close(done)
+	}()
+
+	time.Sleep(20 * time.Millisecond) // Let the channel fill up
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("parseStream did not return after context cancellation")
+	}
+	if _, err := pw.Write([]byte("x")); err == nil {
+		t.Error("stream body was not closed")
+	}
+}
+
+func TestNewChatRequest_MinTokens(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.MaxTokens = 256
+	cfg.MinTokens = 256
+	cfg.IgnoreEOS = true
+
+	tests := []struct {
+		name      string
+		maxTokens int
+		want      string
+	}{
+		{"global max_tokens", 0, `"max_tokens":256,"min_tokens":256,"ignore_eos":true`},
+		{"smaller per-request max_tokens", 64, `"max_tokens":64,"min_tokens":64,"ignore_eos":true`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := NewChatRequest(cfg, workload.WorkloadInput{Prompt: "hi", MaxTokens: tt.maxTokens})
+			if err != nil {
+				t.Fatal(err)
+			}
+			data, _ := json.Marshal(req)
+			var got map[string]interface{}
+			json.Unmarshal(data, &got)
+			want := map[string]interface{}{}
+			json.Unmarshal([]byte("{"+tt.want+"}"), &want)
+			for k, v := range want {
+				if got[k] != v {
+					t.Errorf("%s = %v, want %v", k, got[k], v)
+				}
+			}
+		})
+	}
+
+	cfg.MinTokens, cfg.IgnoreEOS = 0, false
+	req, _ := NewChatRequest(cfg, workload.WorkloadInput{Prompt: "hi"})
+	data, _ := json.Marshal(req)
+	if strings.Contains(string(data), "min_tokens") || strings.Contains(string(data), "ignore_eos") {
+		t.Errorf("request without -min-tokens/-ignore-eos sends them: %s", data)
+	}
+}
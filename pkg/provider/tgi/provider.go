@@ -0,0 +1,251 @@
+// Package tgi implements the HuggingFace Text Generation Inference provider.
+//
+// It targets TGI's native /generate_stream endpoint, which takes a single
+// prompt string ({inputs, parameters}) and streams one token per frame. TGI
+// 1.4+ also serves an OpenAI-compatible /v1/chat/completions, which can be
+// benchmarked with the openai provider instead.
+package tgi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/config"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/httpclient"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/provider"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/sse"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/workload"
+)
+
+func init() {
+	provider.Register("tgi", func() provider.Provider {
+		return &Provider{}
+	})
+}
+
+// Provider implements the TGI /generate_stream provider.
+type Provider struct {
+	clients httpclient.Cache // Reused across requests so connections are pooled
+}
+
+// Name returns the provider name.
+func (p *Provider) Name() string {
+	return "tgi"
+}
+
+// GenerateRequest is the /generate_stream request body.
+type GenerateRequest struct {
+	Inputs     string     `json:"inputs"`
+	Parameters Parameters `json:"parameters"`
+}
+
+// Parameters holds generation parameters.
+type Parameters struct {
+	MaxNewTokens   int      `json:"max_new_tokens,omitempty"`
+	Temperature    *float64 `json:"temperature,omitempty"` // Must be strictly positive; 0 is sent as greedy decoding (omitted)
+	TopP           *float64 `json:"top_p,omitempty"`
+	Seed           *int     `json:"seed,omitempty"`
+	Stop           []string `json:"stop,omitempty"`
+	Details        bool     `json:"details"`          // Include generated_tokens and finish_reason in the final frame
+	ReturnFullText bool     `json:"return_full_text"` // Do not echo the prompt in generated_text
+}
+
+// StreamResponse is a single /generate_stream frame.
+type StreamResponse struct {
+	Token struct {
+		ID      int    `json:"id"`
+		Text    string `json:"text"`
+		Special bool   `json:"special"` // EOS and other control tokens, not part of the output text
+	} `json:"token"`
+	GeneratedText *string `json:"generated_text"` // Set on the final frame only
+	Details       *struct {
+		FinishReason    string `json:"finish_reason"`
+		GeneratedTokens int    `json:"generated_tokens"`
+	} `json:"details"`
+}
+
+// StreamChat executes a streaming generation request.
+func (p *Provider) StreamChat(ctx context.Context, cfg *config.GlobalConfig, input workload.WorkloadInput) (<-chan provider.StreamEvent, error) {
+	messages := input.ToMessagesWithSystem(cfg.SystemPrompt)
+	if len(messages) == 0 {
+		return nil, fmt.Errorf("no messages provided")
+	}
+
+	maxTokens := input.MaxTokens
+	if maxTokens == 0 {
+		maxTokens = cfg.MaxTokens
+	}
+
+	reqBody := GenerateRequest{
+		Inputs: BuildPrompt(messages),
+		Parameters: Parameters{
+			MaxNewTokens: maxTokens,
+			TopP:         cfg.TopP,
+			Seed:         cfg.Seed,
+			Stop:         cfg.Stop,
+			Details:      true,
+		},
+	}
+	if cfg.Temperature != nil && *cfg.Temperature > 0 {
+		reqBody.Parameters.Temperature = cfg.Temperature
+	}
+
+	jsonBody, err := provider.MarshalBody(cfg, reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	if cfg.Verbose {
+		fmt.Println("\n" + strings.Repeat("=", 80))
+		fmt.Println("[VERBOSE] TGI STREAM REQUEST")
+		fmt.Println(strings.Repeat("-", 80))
+		fmt.Printf("URL: %s\n", cfg.URL)
+		fmt.Printf("MaxNewTokens: %d\n", maxTokens)
+		fmt.Println(strings.Repeat("=", 80))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", cfg.URL, bytes.NewReader(jsonBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+	if token := provider.APIKey(ctx, cfg); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := p.clients.Get(cfg).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		return nil, provider.StatusError(resp.StatusCode, body)
+	}
+
+	events := make(chan provider.StreamEvent, 100)
+	go p.parseStream(ctx, resp.Body, events, cfg.Verbosity >= 2, cfg.StrictSSE)
+
+	return events, nil
+}
+
+// BuildPrompt flattens messages into the single prompt /generate_stream
+// takes. A lone user message is sent as is; a conversation is rendered as
+// "Role: content" turns ending with an open "Assistant:" turn. TGI does not
+// apply the model's chat template on this endpoint.
+func BuildPrompt(messages []workload.ChatMessage) string {
+	if len(messages) == 1 && messages[0].Role == "user" {
+		return messages[0].Content
+	}
+	var b strings.Builder
+	for _, msg := range messages {
+		role := msg.Role
+		if role != "" {
+			role = strings.ToUpper(role[:1]) + role[1:]
+		}
+		fmt.Fprintf(&b, "%s: %s\n\n", role, msg.Content)
+	}
+	b.WriteString("Assistant:")
+	return b.String()
+}
+
+// parseStream stops as soon as ctx is done, even if the consumer has stopped
+// reading events.
+func (p *Provider) parseStream(ctx context.Context, body io.ReadCloser, events chan<- provider.StreamEvent, dumpFrames, strict bool) {
+	defer close(events)
+	defer body.Close()
+
+	stop := context.AfterFunc(ctx, func() { body.Close() })
+	defer stop()
+
+	send := func(event provider.StreamEvent) bool {
+		select {
+		case events <- event:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	parser := sse.NewParser(body)
+	violation := func(msg string) {
+		send(provider.StreamEvent{Type: provider.EventViolation, Err: errors.New(msg)})
+	}
+	if strict {
+		parser.OnViolation = violation
+	}
+	finishReason := ""
+
+	for {
+		event, err := parser.Next()
+		if err == io.EOF {
+			send(provider.StreamEvent{Type: provider.EventEnd, FinishReason: finishReason})
+			return
+		}
+		if err != nil {
+			send(provider.StreamEvent{
+				Type: provider.EventError,
+				Err:  fmt.Errorf("SSE parse error: %w", err),
+			})
+			return
+		}
+
+		if dumpFrames {
+			fmt.Printf("[SSE] %s\n", event.Data)
+		}
+
+		// TGI reports failures mid-stream as {"error": "...", "error_type": "..."}
+		if err := provider.BodyError([]byte(event.Data)); err != nil {
+			send(provider.StreamEvent{Type: provider.EventError, Raw: event.Data, Err: err})
+			return
+		}
+
+		var resp StreamResponse
+		if err := json.Unmarshal([]byte(event.Data), &resp); err != nil {
+			if strict {
+				violation(fmt.Sprintf("invalid JSON in data: %v", err))
+			}
+			continue
+		}
+
+		if resp.Token.Text != "" && !resp.Token.Special {
+			if !send(provider.StreamEvent{
+				Type: provider.EventContent,
+				Raw:  event.Data,
+				Text: resp.Token.Text,
+			}) {
+				return
+			}
+		}
+
+		// Only the final frame carries details; TGI does not report prompt tokens
+		if resp.Details != nil {
+			finishReason = normalizeFinishReason(resp.Details.FinishReason)
+			if !send(provider.StreamEvent{
+				Type:  provider.EventUsage,
+				Usage: &provider.TokenUsage{CompletionTokens: resp.Details.GeneratedTokens},
+			}) {
+				return
+			}
+		}
+	}
+}
+
+// normalizeFinishReason maps TGI finish_reason values onto the OpenAI
+// finish_reason vocabulary.
+func normalizeFinishReason(reason string) string {
+	switch reason {
+	case "eos_token", "stop_sequence":
+		return "stop"
+	default:
+		return reason // "length" already matches
+	}
+}
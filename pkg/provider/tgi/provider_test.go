@@ -0,0 +1,102 @@
+package tgi
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/provider"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/workload"
+)
+
+func TestParseStream(t *testing.T) {
+	stream := `data:{"index":1,"token":{"id":9906,"text":"Hello","logprob":-0.2,"special":false},"generated_text":null,"details":null}
+
+data:{"index":2,"token":{"id":1917,"text":" world","logprob":-0.4,"special":false},"generated_text":null,"details":null}
+
+data:{"index":3,"token":{"id":2,"text":"</s>","logprob":-0.1,"special":true},"generated_text":"Hello world","details":{"finish_reason":"eos_token","generated_tokens":3,"seed":null}}
+
+`
+	events := make(chan provider.StreamEvent, 100)
+	p := &Provider{}
+	p.parseStream(context.Background(), io.NopCloser(strings.NewReader(stream)), events, false, false)
+
+	var content string
+	var usage *provider.TokenUsage
+	var end provider.StreamEvent
+	for e := range events {
+		switch e.Type {
+		case provider.EventContent:
+			content += e.Text
+		case provider.EventUsage:
+			usage = e.Usage
+		case provider.EventEnd:
+			end = e
+		case provider.EventError:
+			t.Fatalf("unexpected error: %v", e.Err)
+		}
+	}
+
+	if content != "Hello world" {
+		t.Errorf("content = %q, want %q (special tokens skipped)", content, "Hello world")
+	}
+	if usage == nil || usage.CompletionTokens != 3 {
+		t.Errorf("usage = %+v, want 3 completion tokens", usage)
+	}
+	if end.FinishReason != "stop" {
+		t.Errorf("finish reason = %q, want stop", end.FinishReason)
+	}
+}
+
+func TestParseStream_ErrorFrame(t *testing.T) {
+	stream := `data:{"index":1,"token":{"id":9906,"text":"Hi","special":false},"generated_text":null,"details":null}
+
+data:{"error":"Request failed during generation: Server error: CUDA out of memory","error_type":"generation"}
+
+`
+	events := make(chan provider.StreamEvent, 100)
+	p := &Provider{}
+	p.parseStream(context.Background(), io.NopCloser(strings.NewReader(stream)), events, false, false)
+
+	var gotErr error
+	for e := range events {
+		if e.Type == provider.EventError {
+			gotErr = e.Err
+		}
+	}
+	var httpErr *provider.HTTPError
+	if !errors.As(gotErr, &httpErr) || !strings.Contains(httpErr.Body, "CUDA out of memory") {
+		t.Errorf("error = %v, want the error frame's message", gotErr)
+	}
+}
+
+func TestBuildPrompt(t *testing.T) {
+	tests := []struct {
+		name     string
+		messages []workload.ChatMessage
+		want     string
+	}{
+		{
+			name:     "single user message",
+			messages: []workload.ChatMessage{{Role: "user", Content: "Hi"}},
+			want:     "Hi",
+		},
+		{
+			name: "conversation",
+			messages: []workload.ChatMessage{
+				{Role: "system", Content: "Be brief."},
+				{Role: "user", Content: "Hi"},
+			},
+			want: "System: Be brief.\n\nUser: Hi\n\nAssistant:",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := BuildPrompt(tt.messages); got != tt.want {
+				t.Errorf("BuildPrompt() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -18,12 +18,16 @@ const (
 	EventContent
 	// EventReasoning represents reasoning/thinking content (internal chain-of-thought).
 	EventReasoning
+	// EventToolCall represents a streamed tool-call fragment (function name and/or arguments JSON).
+	EventToolCall
 	// EventUsage represents token usage information.
 	EventUsage
 	// EventEnd represents explicit end signal ([DONE] / finish_reason).
 	EventEnd
 	// EventError represents an error event.
 	EventError
+	// EventViolation reports a tolerated SSE protocol violation (only with -strict-sse).
+	EventViolation
 )
 
 // String returns the string representation of the event type.
@@ -35,12 +39,16 @@ func (t StreamEventType) String() string {
 		return "content"
 	case EventReasoning:
 		return "reasoning"
+	case EventToolCall:
+		return "tool_call"
 	case EventUsage:
 		return "usage"
 	case EventEnd:
 		return "end"
 	case EventError:
 		return "error"
+	case EventViolation:
+		return "violation"
 	default:
 		return "unknown"
 	}
@@ -52,13 +60,24 @@ type TokenUsage struct {
 	CompletionTokens int `json:"completion_tokens"`
 }
 
+// TokenLogprob is the log probability of one generated token.
+type TokenLogprob struct {
+	Token   string  `json:"token"`
+	Logprob float64 `json:"logprob"`
+}
+
 // StreamEvent represents a single event from the SSE stream.
 type StreamEvent struct {
-	Type  StreamEventType
-	Raw   string      // Original raw data (for sampling/debugging)
-	Text  string      // Content text (if EventContent)
-	Usage *TokenUsage // Token usage (if EventUsage)
-	Err   error       // Error (if EventError)
+	Type     StreamEventType
+	Raw      string         // Original raw data (for sampling/debugging)
+	Text     string         // Content text (if EventContent), or tool-call arguments fragment (if EventToolCall)
+	ToolName string         // Function name (if EventToolCall and the fragment carries it); not generated text
+	Usage    *TokenUsage    // Token usage (if EventUsage)
+	Logprobs []TokenLogprob // Logprobs of the tokens in Text (if EventContent and requested)
+	Choice   int            // Index of the completion the text belongs to (0 unless n > 1)
+
+	FinishReason string // Normalized finish reason, e.g. "stop" or "length" (if EventEnd and known)
+	Err          error  // Error (if EventError), or the violation (if EventViolation)
 }
 
 // Provider defines the interface for LLM API providers.
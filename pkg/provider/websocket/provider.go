@@ -0,0 +1,210 @@
+// Package websocket implements a provider for inference servers that stream
+// over WebSocket instead of SSE.
+//
+// Each request opens its own connection, sends the OpenAI-style chat request
+// (as for -provider openai) as one text message, and reads one chunk object
+// per message until a "[DONE]" message or the server closes the connection:
+//
+//	→ {"model": "...", "messages": [...], "stream": true, ...}
+//	← {"choices": [{"delta": {"content": "Hel"}}]}
+//	← {"choices": [{"delta": {}, "finish_reason": "stop"}], "usage": {...}}
+//	← [DONE]
+//
+// Connections are made with gorilla/websocket, through the proxy named by
+// HTTPS_PROXY/HTTP_PROXY like the HTTP providers.
+package websocket
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/config"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/httpclient"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/provider"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/provider/openai"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/workload"
+	"github.com/gorilla/websocket"
+)
+
+func init() {
+	provider.Register("websocket", func() provider.Provider {
+		return &Provider{}
+	})
+}
+
+// Provider implements the WebSocket provider.
+type Provider struct{}
+
+// Name returns the provider name.
+func (p *Provider) Name() string {
+	return "websocket"
+}
+
+// StreamChat opens a connection for one request and streams its reply.
+func (p *Provider) StreamChat(ctx context.Context, cfg *config.GlobalConfig, input workload.WorkloadInput) (<-chan provider.StreamEvent, error) {
+	reqBody, err := openai.NewChatRequest(cfg, input)
+	if err != nil {
+		return nil, err
+	}
+	jsonBody, err := provider.MarshalBody(cfg, reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	header := http.Header{}
+	if token := provider.APIKey(ctx, cfg); token != "" {
+		header.Set("Authorization", "Bearer "+token)
+	}
+	conn, err := dial(ctx, cfg, header)
+	if err != nil {
+		return nil, err
+	}
+	conn.SetReadLimit(maxMessageSize)
+	m := &messageReader{conn: conn, trace: httptrace.ContextClientTrace(ctx)}
+	if err := conn.WriteMessage(websocket.TextMessage, jsonBody); err != nil {
+		m.Close()
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	if m.trace != nil && m.trace.WroteRequest != nil {
+		m.trace.WroteRequest(httptrace.WroteRequestInfo{})
+	}
+
+	events := make(chan provider.StreamEvent, 100)
+	go openai.ParseStream(ctx, m, events, cfg.Verbose, cfg.Verbosity >= 2, cfg.StrictSSE)
+
+	return events, nil
+}
+
+// maxMessageSize bounds one message, so a misbehaving server cannot exhaust
+// memory.
+const maxMessageSize = 16 << 20
+
+// dial opens the connection for cfg.URL (ws://, wss://, or http(s):// for the
+// same endpoints). A rejected handshake is returned as a *provider.HTTPError,
+// like the HTTP providers. DNS, connect and TLS are reported to the
+// httptrace.ClientTrace of ctx, if any.
+func dial(ctx context.Context, cfg *config.GlobalConfig, header http.Header) (*websocket.Conn, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid WebSocket URL: %w", err)
+	}
+	switch u.Scheme {
+	case "ws", "wss":
+	case "http":
+		u.Scheme = "ws"
+	case "https":
+		u.Scheme = "wss"
+	default:
+		return nil, fmt.Errorf("invalid WebSocket URL scheme %q (use ws:// or wss://)", u.Scheme)
+	}
+
+	// Dial under a copy of ctx's trace without GotFirstResponseByte, so the
+	// handshake response is not taken for the reply's first byte. The copy
+	// must not be layered on ctx: WithClientTrace would keep calling the
+	// original hooks too.
+	dialCtx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stopCancel := context.AfterFunc(ctx, cancel)
+	defer stopCancel()
+	if trace := httptrace.ContextClientTrace(ctx); trace != nil {
+		t := *trace
+		t.GotFirstResponseByte = nil
+		dialCtx = httptrace.WithClientTrace(dialCtx, &t)
+	}
+	if cfg.ConnectTimeoutSec > 0 {
+		dialCtx, cancel = context.WithTimeout(dialCtx, time.Duration(cfg.ConnectTimeoutSec)*time.Second)
+		defer cancel()
+	}
+
+	// gorilla/websocket only watches ctx while connecting; abort the rest of
+	// the handshake by expiring the connection's deadline
+	var stop func() bool
+	d := websocket.Dialer{
+		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: httpclient.TLSConfig(cfg),
+		NetDialContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			var nd net.Dialer
+			nc, err := nd.DialContext(ctx, network, addr)
+			if err == nil {
+				stop = context.AfterFunc(ctx, func() { nc.SetDeadline(time.Now()) })
+			}
+			return nc, err
+		},
+	}
+	conn, resp, err := d.DialContext(dialCtx, u.String(), header)
+	aborted := stop != nil && !stop()
+	if errors.Is(err, websocket.ErrBadHandshake) && resp != nil && resp.StatusCode != http.StatusSwitchingProtocols {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, provider.StatusError(resp.StatusCode, body)
+	}
+	if err == nil && aborted {
+		conn.Close()
+	}
+	if err != nil || aborted {
+		if dialCtx.Err() != nil {
+			return nil, fmt.Errorf("WebSocket handshake aborted: %w", dialCtx.Err())
+		}
+		return nil, fmt.Errorf("WebSocket handshake failed: %w", err)
+	}
+	return conn, nil
+}
+
+// messageReader presents the connection's messages as an SSE stream, one
+// "data:" event per message, so the OpenAI stream parser can consume them.
+type messageReader struct {
+	conn      *websocket.Conn
+	trace     *httptrace.ClientTrace
+	buf       bytes.Buffer
+	gotByte   bool
+	closeOnce sync.Once
+}
+
+func (m *messageReader) Read(p []byte) (int, error) {
+	for m.buf.Len() == 0 {
+		_, msg, err := m.conn.ReadMessage()
+		if err != nil {
+			var ce *websocket.CloseError
+			if errors.As(err, &ce) {
+				if ce.Code == websocket.CloseNormalClosure || ce.Code == websocket.CloseNoStatusReceived {
+					return 0, io.EOF
+				}
+				return 0, fmt.Errorf("WebSocket closed by server: code %d: %s", ce.Code, ce.Text)
+			}
+			return 0, err
+		}
+		if !m.gotByte {
+			m.gotByte = true
+			if m.trace != nil && m.trace.GotFirstResponseByte != nil {
+				m.trace.GotFirstResponseByte()
+			}
+		}
+		for _, line := range bytes.Split(bytes.TrimSpace(msg), []byte("\n")) {
+			m.buf.WriteString("data: ")
+			m.buf.Write(bytes.TrimRight(line, "\r"))
+			m.buf.WriteByte('\n')
+		}
+		m.buf.WriteByte('\n')
+	}
+	return m.buf.Read(p)
+}
+
+// Close sends a normal close message and closes the connection. It is safe to
+// call more than once and concurrently with Read.
+func (m *messageReader) Close() error {
+	var err error
+	m.closeOnce.Do(func() {
+		msg := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "")
+		m.conn.WriteControl(websocket.CloseMessage, msg, time.Now().Add(time.Second))
+		err = m.conn.Close()
+	})
+	return err
+}
@@ -0,0 +1,204 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/config"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/provider"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/workload"
+	"github.com/gorilla/websocket"
+)
+
+// newServer starts a WebSocket server that reads the request message and
+// hands the connection to reply.
+func newServer(t *testing.T, reply func(*websocket.Conn)) (*httptest.Server, chan map[string]any) {
+	t.Helper()
+	requests := make(chan map[string]any, 1)
+	var upgrader websocket.Upgrader
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			http.Error(w, "bad token", http.StatusUnauthorized)
+			return
+		}
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("upgrade failed: %v", err)
+			return
+		}
+		defer conn.Close()
+
+		var body map[string]any
+		if err := conn.ReadJSON(&body); err != nil {
+			t.Errorf("failed to read request message: %v", err)
+			return
+		}
+		requests <- body
+		reply(conn)
+		conn.ReadMessage() // Wait for the client's close
+	}))
+	t.Cleanup(srv.Close)
+	return srv, requests
+}
+
+// send returns a reply that writes msgs as text messages.
+func send(msgs ...string) func(*websocket.Conn) {
+	return func(c *websocket.Conn) {
+		for _, msg := range msgs {
+			c.WriteMessage(websocket.TextMessage, []byte(msg))
+		}
+	}
+}
+
+func closeWith(code int, text string) func(*websocket.Conn) {
+	return func(c *websocket.Conn) {
+		c.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(code, text), time.Now().Add(time.Second))
+	}
+}
+
+func testConfig(srv *httptest.Server) *config.GlobalConfig {
+	cfg := config.DefaultConfig()
+	cfg.URL = "ws" + strings.TrimPrefix(srv.URL, "http") + "/v1/chat"
+	cfg.ModelName = "m"
+	cfg.Token = "secret"
+	return cfg
+}
+
+func TestStreamChat(t *testing.T) {
+	tests := []struct {
+		name    string
+		reply   []func(*websocket.Conn)
+		content string
+		usage   int
+		wantErr string
+	}{
+		{
+			name: "chunks and done",
+			reply: []func(*websocket.Conn){
+				send(`{"choices":[{"delta":{"content":"Hel"}}]}`),
+				func(c *websocket.Conn) {
+					c.WriteControl(websocket.PingMessage, []byte("p"), time.Now().Add(time.Second))
+				},
+				send(`{"choices":[{"delta":{"content":"lo"},"finish_reason":"stop"}],"usage":{"prompt_tokens":3,"completion_tokens":2}}`, `[DONE]`),
+			},
+			content: "Hello",
+			usage:   2,
+		},
+		{
+			name:    "normal close",
+			reply:   []func(*websocket.Conn){send(`{"choices":[{"delta":{"content":"ok"}}]}`), closeWith(websocket.CloseNormalClosure, "")},
+			content: "ok",
+		},
+		{
+			name:    "abnormal close",
+			reply:   []func(*websocket.Conn){send(`{"choices":[{"delta":{"content":"a"}}]}`), closeWith(websocket.CloseGoingAway, "going away")},
+			content: "a",
+			wantErr: "code 1001: going away",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, requests := newServer(t, func(c *websocket.Conn) {
+				for _, r := range tt.reply {
+					r(c)
+				}
+			})
+
+			events, err := (&Provider{}).StreamChat(context.Background(), testConfig(srv), workload.NewSimpleWorkload("t", "hi", 8))
+			if err != nil {
+				t.Fatalf("StreamChat failed: %v", err)
+			}
+			var content strings.Builder
+			var usage int
+			var streamErr error
+			for e := range events {
+				switch e.Type {
+				case provider.EventContent:
+					content.WriteString(e.Text)
+				case provider.EventUsage:
+					usage = e.Usage.CompletionTokens
+				case provider.EventError:
+					streamErr = e.Err
+				}
+			}
+
+			if req := <-requests; req["model"] != "m" || req["stream"] != true {
+				t.Errorf("request = %v, want model m and stream true", req)
+			}
+			if content.String() != tt.content {
+				t.Errorf("content = %q, want %q", content.String(), tt.content)
+			}
+			if usage != tt.usage {
+				t.Errorf("completion tokens = %d, want %d", usage, tt.usage)
+			}
+			if tt.wantErr == "" && streamErr != nil {
+				t.Errorf("unexpected error: %v", streamErr)
+			}
+			if tt.wantErr != "" && (streamErr == nil || !strings.Contains(streamErr.Error(), tt.wantErr)) {
+				t.Errorf("error = %v, want it to contain %q", streamErr, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestStreamChat_HandshakeRejected(t *testing.T) {
+	srv, _ := newServer(t, send())
+	cfg := testConfig(srv)
+	cfg.Token = ""
+
+	_, err := (&Provider{}).StreamChat(context.Background(), cfg, workload.NewSimpleWorkload("t", "hi", 8))
+	var httpErr *provider.HTTPError
+	if !errors.As(err, &httpErr) || httpErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("error = %v, want a provider.HTTPError with status 401", err)
+	}
+	if !strings.HasPrefix(err.Error(), "HTTP 401: bad token") {
+		t.Errorf("error = %q, want HTTP 401 with the response body", err)
+	}
+}
+
+// TestStreamChat_Trace checks that the connection phases are reported and
+// that the first response byte is the first message, not the handshake.
+func TestStreamChat_Trace(t *testing.T) {
+	release := make(chan struct{})
+	srv, _ := newServer(t, func(c *websocket.Conn) {
+		<-release
+		send(`[DONE]`)(c)
+	})
+	cfg := testConfig(srv)
+	cfg.URL = strings.Replace(cfg.URL, "127.0.0.1", "localhost", 1)
+
+	var dns, connect, wrote, firstByte bool
+	trace := &httptrace.ClientTrace{
+		DNSStart:     func(httptrace.DNSStartInfo) { dns = true },
+		ConnectStart: func(string, string) { connect = true },
+		WroteRequest: func(httptrace.WroteRequestInfo) { wrote = true },
+		GotFirstResponseByte: func() {
+			if !wrote {
+				t.Error("first response byte reported before the request was written")
+			}
+			firstByte = true
+		},
+	}
+	ctx := httptrace.WithClientTrace(context.Background(), trace)
+
+	events, err := (&Provider{}).StreamChat(ctx, cfg, workload.NewSimpleWorkload("t", "hi", 8))
+	if err != nil {
+		t.Fatalf("StreamChat failed: %v", err)
+	}
+	if firstByte {
+		t.Error("handshake response reported as the first response byte")
+	}
+	close(release)
+	for range events {
+	}
+	if !dns || !connect || !wrote || !firstByte {
+		t.Errorf("dns=%v connect=%v wrote=%v firstByte=%v, want all reported", dns, connect, wrote, firstByte)
+	}
+}
@@ -0,0 +1,34 @@
+package result
+
+// Pricing holds per-million-token prices in USD. Zero prices are free.
+type Pricing struct {
+	InputPerMillion  float64 // Price of one million prompt tokens
+	OutputPerMillion float64 // Price of one million completion tokens
+}
+
+// IsZero returns true if no price is set.
+func (p Pricing) IsZero() bool {
+	return p == Pricing{}
+}
+
+// Cost returns the estimated USD cost of the given token counts.
+func (p Pricing) Cost(promptTokens, completionTokens int) float64 {
+	return (float64(promptTokens)*p.InputPerMillion + float64(completionTokens)*p.OutputPerMillion) / 1e6
+}
+
+// ApplyCost fills the cost fields of report from its token totals. Requests
+// without token counts (e.g. -token-mode chars) cost nothing, so the estimate
+// is only meaningful with usage or estimated tokens.
+func ApplyCost(report *BenchmarkReport, p Pricing) {
+	if p.IsZero() {
+		return
+	}
+	cost := p.Cost(report.TotalPromptTokens, report.TotalCompletionTokens)
+	report.EstimatedCostUSD = &cost
+	if report.Success > 0 {
+		report.CostPer1KRequestsUSD = cost / float64(report.Success) * 1000
+	}
+	if cost > 0 {
+		report.CompletionTokensPerUSD = float64(report.TotalCompletionTokens) / cost
+	}
+}
@@ -0,0 +1,32 @@
+package result
+
+import (
+	"math"
+	"testing"
+)
+
+func TestApplyCost(t *testing.T) {
+	report := &BenchmarkReport{
+		Success:               200,
+		TotalPromptTokens:     400_000,
+		TotalCompletionTokens: 100_000,
+	}
+	ApplyCost(report, Pricing{InputPerMillion: 0.5, OutputPerMillion: 1.5})
+
+	// 0.4M × $0.5 + 0.1M × $1.5 = $0.35
+	if report.EstimatedCostUSD == nil || math.Abs(*report.EstimatedCostUSD-0.35) > 1e-9 {
+		t.Fatalf("EstimatedCostUSD = %v, want 0.35", report.EstimatedCostUSD)
+	}
+	if math.Abs(report.CostPer1KRequestsUSD-1.75) > 1e-9 {
+		t.Errorf("CostPer1KRequestsUSD = %v, want 1.75", report.CostPer1KRequestsUSD)
+	}
+	if math.Abs(report.CompletionTokensPerUSD-100_000/0.35) > 1e-6 {
+		t.Errorf("CompletionTokensPerUSD = %v", report.CompletionTokensPerUSD)
+	}
+
+	unpriced := &BenchmarkReport{Success: 1, TotalPromptTokens: 10}
+	ApplyCost(unpriced, Pricing{})
+	if unpriced.EstimatedCostUSD != nil {
+		t.Errorf("EstimatedCostUSD = %v without pricing, want nil", *unpriced.EstimatedCostUSD)
+	}
+}
@@ -0,0 +1,54 @@
+package result
+
+import "fmt"
+
+// MetricDelta compares one metric of the current run with a baseline run.
+type MetricDelta struct {
+	Metric    string  `json:"metric"`
+	Baseline  float64 `json:"baseline"`
+	Current   float64 `json:"current"`
+	ChangePct float64 `json:"change_pct"` // (current - baseline) / baseline × 100; 0 when the baseline is 0
+	Regressed bool    `json:"regressed"`  // Moved in the worse direction by more than the threshold
+}
+
+// String formats the delta for console output.
+func (d MetricDelta) String() string {
+	return fmt.Sprintf("%s: %g -> %g (%+.1f%%)", d.Metric, d.Baseline, d.Current, d.ChangePct)
+}
+
+// CompareReports compares the headline metrics of current against baseline in
+// a stable order. A metric is marked regressed when it got worse by more than
+// thresholdPct percent of the baseline value; thresholdPct <= 0 only reports
+// the deltas. Metrics with a zero baseline are never marked regressed.
+func CompareReports(baseline, current *BenchmarkReport, thresholdPct float64) []MetricDelta {
+	metrics := []struct {
+		name           string
+		baseline       float64
+		current        float64
+		higherIsBetter bool
+	}{
+		{"success_rate", baseline.SuccessRate, current.SuccessRate, true},
+		{"avg_ttft_ms", baseline.AvgTTFTMs, current.AvgTTFTMs, false},
+		{"p95_ttft_ms", float64(baseline.P95TTFTMs), float64(current.P95TTFTMs), false},
+		{"avg_latency_ms", baseline.AvgLatencyMs, current.AvgLatencyMs, false},
+		{"p95_latency_ms", float64(baseline.P95LatencyMs), float64(current.P95LatencyMs), false},
+		{"p99_latency_ms", float64(baseline.P99LatencyMs), float64(current.P99LatencyMs), false},
+		{"rps", baseline.RPS, current.RPS, true},
+		{"token_throughput", baseline.TokenThroughput, current.TokenThroughput, true},
+	}
+
+	deltas := make([]MetricDelta, 0, len(metrics))
+	for _, m := range metrics {
+		d := MetricDelta{Metric: m.name, Baseline: m.baseline, Current: m.current}
+		if m.baseline != 0 {
+			d.ChangePct = (m.current - m.baseline) / m.baseline * 100
+			worse := d.ChangePct
+			if m.higherIsBetter {
+				worse = -worse
+			}
+			d.Regressed = thresholdPct > 0 && worse > thresholdPct
+		}
+		deltas = append(deltas, d)
+	}
+	return deltas
+}
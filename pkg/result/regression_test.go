@@ -0,0 +1,68 @@
+package result
+
+import "testing"
+
+func TestCompareReports(t *testing.T) {
+	baseline := &BenchmarkReport{
+		SuccessRate:     1,
+		AvgTTFTMs:       200,
+		P95TTFTMs:       400,
+		AvgLatencyMs:    1000,
+		P95LatencyMs:    2000,
+		P99LatencyMs:    3000,
+		RPS:             10,
+		TokenThroughput: 0,
+	}
+	current := &BenchmarkReport{
+		SuccessRate:     0.99, // -1%: within threshold
+		AvgTTFTMs:       150,  // Faster: never a regression
+		P95TTFTMs:       400,
+		AvgLatencyMs:    1040, // +4%: within threshold
+		P95LatencyMs:    2400, // +20%: regressed
+		P99LatencyMs:    3000,
+		RPS:             8, // -20%: regressed
+		TokenThroughput: 50,
+	}
+
+	tests := []struct {
+		name      string
+		threshold float64
+		regressed []string
+	}{
+		{"report only", 0, nil},
+		{"10 percent", 10, []string{"p95_latency_ms", "rps"}},
+		{"25 percent", 25, nil},
+		{"half percent", 0.5, []string{"success_rate", "avg_latency_ms", "p95_latency_ms", "rps"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			deltas := CompareReports(baseline, current, tt.threshold)
+			if len(deltas) != 8 {
+				t.Fatalf("expected 8 deltas, got %d", len(deltas))
+			}
+			var regressed []string
+			for _, d := range deltas {
+				if d.Regressed {
+					regressed = append(regressed, d.Metric)
+				}
+			}
+			if len(regressed) != len(tt.regressed) {
+				t.Fatalf("regressed = %v, want %v", regressed, tt.regressed)
+			}
+			for i := range regressed {
+				if regressed[i] != tt.regressed[i] {
+					t.Errorf("regressed = %v, want %v", regressed, tt.regressed)
+					break
+				}
+			}
+		})
+	}
+
+	// A zero baseline has no meaningful percentage
+	for _, d := range CompareReports(baseline, current, 1) {
+		if d.Metric == "token_throughput" && (d.ChangePct != 0 || d.Regressed) {
+			t.Errorf("token_throughput with zero baseline: %+v", d)
+		}
+	}
+}
@@ -1,16 +1,23 @@
 // Package result defines result and report types.
 package result
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // RequestStatus represents the status of a benchmark request.
 type RequestStatus string
 
 const (
-	StatusOK         RequestStatus = "ok"
-	StatusHTTPError  RequestStatus = "http_error"
-	StatusTimeout    RequestStatus = "timeout"
-	StatusParseError RequestStatus = "parse_error"
+	StatusOK               RequestStatus = "ok"
+	StatusHTTPError        RequestStatus = "http_error"
+	StatusTimeout          RequestStatus = "timeout"
+	StatusFirstByteTimeout RequestStatus = "first_byte_timeout" // Nothing arrived within -first-byte-timeout
+	StatusParseError       RequestStatus = "parse_error"
+	StatusPartial          RequestStatus = "partial"   // Stream produced content, then errored or timed out
+	StatusTooLarge         RequestStatus = "too_large" // Response exceeded -max-response-chars and was aborted
+	StatusEmpty            RequestStatus = "empty"     // Stream ended normally without any content
 )
 
 // RequestResult holds the result of a single benchmark request.
@@ -20,10 +27,73 @@ type RequestResult struct {
 	TTFT      time.Duration `json:"ttft_ns"`       // Time to first token
 	Latency   time.Duration `json:"latency_ns"`    // Total request latency
 	Decode    time.Duration `json:"decode_ns"`     // Decode time (end - first_content)
+	TTLT      time.Duration `json:"ttlt_ns"`       // Time to last token: start to the last generated output
 	InTokens  int           `json:"in_tokens"`     // Input (prompt) token count
 	OutTokens int           `json:"out_tokens"`    // Output token count
 	OutChars  int           `json:"out_chars"`     // Output character count
 	Err       string        `json:"err,omitempty"` // Error message if failed
+	Seq       int           `json:"seq,omitempty"` // Position in the benchmark run (1-based; 0 outside a run)
+
+	ResponseHash string `json:"response_hash,omitempty"` // SHA-256 prefix of the full response content
+	FinishReason string `json:"finish_reason,omitempty"` // stop, length, ... as reported by the provider
+	ValidJSON    bool   `json:"valid_json,omitempty"`    // Response content parsed as JSON (only checked in JSON mode)
+
+	// PromptLength is the prompt length used for the prompt-length buckets:
+	// tokens estimated with the built-in tokenizer, or characters with
+	// -token-mode chars. Unlike InTokens it is known for failed requests too.
+	PromptLength int `json:"prompt_length,omitempty"`
+
+	// ThinkChars is the length of inline <think> blocks removed from the
+	// content (only with -think-tag-filter); they are not part of OutChars
+	ThinkChars int `json:"think_chars,omitempty"`
+
+	// ToolCallChars is the length of streamed tool-call arguments, included in
+	// OutChars. Function names are not generated text and are not counted
+	ToolCallChars int `json:"tool_call_chars,omitempty"`
+
+	// APIKeyIndex is the 1-based position in the -api-key list of the key that sent the request (0 = -token)
+	APIKeyIndex int `json:"api_key_index,omitempty"`
+
+	// Choices is the number of distinct completions streamed (only set when above 1, with -n)
+	Choices int `json:"choices,omitempty"`
+
+	// SSE protocol violations tolerated by the parser (only with -strict-sse),
+	// and the first one seen
+	SSEViolations     int    `json:"sse_violations,omitempty"`
+	SSEFirstViolation string `json:"sse_first_violation,omitempty"`
+
+	// Network phases of TTFT from httptrace (zero for non-HTTP providers). Connect
+	// includes DNS; Connect and TLS are zero when a pooled connection was reused.
+	Connect         time.Duration `json:"connect_ns,omitempty"`
+	TLS             time.Duration `json:"tls_ns,omitempty"`
+	RequestWrite    time.Duration `json:"request_write_ns,omitempty"`     // Connection obtained -> request written
+	ServerFirstByte time.Duration `json:"server_first_byte_ns,omitempty"` // Request written -> first response byte
+	ConnReused      bool          `json:"conn_reused,omitempty"`
+
+	// Retries (only set when the request needed more than one attempt).
+	// AttemptLatencies holds each attempt's own latency, excluding backoff.
+	Attempts         int       `json:"attempts,omitempty"`
+	AttemptLatencies []float64 `json:"attempt_latencies_ms,omitempty"`
+
+	// TokensEstimated is true when the server sent no usage and OutTokens was
+	// estimated from the response length
+	TokensEstimated bool `json:"tokens_estimated,omitempty"`
+
+	// Confidence (only with -logprobs): mean token logprob of the response
+	AvgLogprob    *float64 `json:"avg_logprob,omitempty"`
+	LogprobTokens int      `json:"logprob_tokens,omitempty"` // Tokens that carried a logprob
+
+	// Scores holds each attached scorer's rating of the response (successful requests only)
+	Scores map[string]float64 `json:"scores,omitempty"`
+
+	// Sampled bodies, only set for the -sample-rate fraction of requests
+	SampledRequest  json.RawMessage `json:"sampled_request,omitempty"`  // Workload input (prompt or messages)
+	SampledResponse string          `json:"sampled_response,omitempty"` // Full reconstructed response text
+	SampledLogprobs json.RawMessage `json:"sampled_logprobs,omitempty"` // Per-token logprobs (with -logprobs)
+
+	// Full exchange of every request, only set with -record-all
+	RecordedMessages json.RawMessage `json:"recorded_messages,omitempty"` // Messages sent, including the system prompt
+	RecordedResponse string          `json:"recorded_response,omitempty"` // Visible response text of the first completion
 
 	// Internal timestamps
 	StartTime        time.Time `json:"-"`
@@ -31,9 +101,10 @@ type RequestResult struct {
 	EndTime          time.Time `json:"-"`
 
 	// Sampling
-	FirstContentRaw string   `json:"-"` // First content frame raw data
-	MiddleFramesRaw []string `json:"-"` // Middle content frames raw data
-	FinalFrameRaw   string   `json:"-"` // Final frame raw data
+	FirstContentRaw    string   `json:"-"` // First content frame raw data
+	MiddleFramesRaw    []string `json:"-"` // Middle content frames raw data
+	MiddleFrameNumbers []int    `json:"-"` // Position of each middle frame among the content frames
+	FinalFrameRaw      string   `json:"-"` // Final frame raw data
 }
 
 // IsSuccess returns true if the request was successful.
@@ -41,24 +112,94 @@ func (r *RequestResult) IsSuccess() bool {
 	return r.Status == StatusOK
 }
 
+// ClientStats describes how the benchmark client itself kept up (only with -self-monitor).
+type ClientStats struct {
+	TargetRPS         float64 `json:"target_rps,omitempty"` // -rps, if set
+	DispatchRPS       float64 `json:"dispatch_rps"`         // Requests handed to workers per second
+	DispatchLagMs     float64 `json:"dispatch_lag_ms"`      // How far the last dispatch was behind the -rps schedule
+	MaxDispatchLagMs  float64 `json:"max_dispatch_lag_ms"`  // Largest lag behind the -rps schedule
+	WorkerWaitRatio   float64 `json:"worker_wait_ratio"`    // Share of requests that waited for a free worker
+	WorkerWaitMs      float64 `json:"worker_wait_ms"`       // Total time spent waiting for a free worker
+	SchedLatencyP99Ms float64 `json:"sched_latency_p99_ms"` // Go scheduler latency p99 during the run
+	GOMAXPROCS        int     `json:"gomaxprocs"`
+	Saturated         bool    `json:"saturated"`
+	Reason            string  `json:"reason,omitempty"`
+}
+
+// ScoreStat aggregates one response scorer over the successful requests it rated.
+type ScoreStat struct {
+	Name     string  `json:"name"`
+	Mean     float64 `json:"mean"`
+	PassRate float64 `json:"pass_rate"` // Share of scored responses at or above the pass threshold
+	Scored   int     `json:"scored"`
+}
+
+// APIKeyStat summarizes the requests sent with one -api-key.
+type APIKeyStat struct {
+	Index       int     `json:"index"` // 1-based position in the key list (-api-key, then -token-file)
+	Requests    int     `json:"requests"`
+	Failures    int     `json:"failures"`
+	RateLimited int     `json:"rate_limited"` // Failures with HTTP 429
+	SuccessRate float64 `json:"success_rate"`
+}
+
+// PromptBucketStat summarizes the requests whose prompt length is within
+// MinLength..MaxLength (inclusive).
+type PromptBucketStat struct {
+	MinLength    int     `json:"min_length"`
+	MaxLength    int     `json:"max_length"`
+	Requests     int     `json:"requests"`
+	Success      int     `json:"success"`
+	AvgTTFTMs    float64 `json:"avg_ttft_ms"`
+	P50TTFTMs    int64   `json:"p50_ttft_ms"`
+	P95TTFTMs    int64   `json:"p95_ttft_ms"`
+	AvgLatencyMs float64 `json:"avg_latency_ms"`
+	P50LatencyMs int64   `json:"p50_latency_ms"`
+	P95LatencyMs int64   `json:"p95_latency_ms"`
+}
+
 // ErrorStat holds error statistics.
 type ErrorStat struct {
 	Key   string `json:"key"`
 	Count int    `json:"count"`
 }
 
+// WindowStat summarizes the requests that completed within one second of the run.
+type WindowStat struct {
+	Second       int     `json:"second"` // Offset from the first completion window
+	Success      int     `json:"success"`
+	Failure      int     `json:"failure"`
+	RPS          float64 `json:"rps"` // Successful completions per second
+	P95LatencyMs int64   `json:"p95_latency_ms"`
+}
+
 // BenchmarkReport holds the aggregated benchmark results.
 type BenchmarkReport struct {
 	// Metadata
+	RunName    string `json:"run_name,omitempty"`
 	Provider   string `json:"provider"`
 	Model      string `json:"model"`
 	StartedAt  string `json:"started_at"`
 	WallTimeMs int64  `json:"wall_time_ms"`
 
+	// ReadyWaitMs is how long -wait-ready polled before the endpoint answered
+	ReadyWaitMs int64 `json:"ready_wait_ms,omitempty"`
+
+	// Aborted is true when -abort-after-failures stopped the run before all
+	// requests were sent; the statistics cover only the requests that ran
+	Aborted     bool   `json:"aborted,omitempty"`
+	AbortReason string `json:"abort_reason,omitempty"`
+
+	// Snapshot is true for the interim reports written by -snapshot-interval
+	// while the run is still going; the final report replaces them
+	Snapshot bool `json:"snapshot,omitempty"`
+
 	// Request Counts
 	TotalRequests int     `json:"total_requests"`
 	Success       int     `json:"success"`
 	Failure       int     `json:"failure"`
+	PartialCount  int     `json:"partial_count"` // Failures that streamed some content first (subset of Failure)
+	EmptyCount    int     `json:"empty_count"`   // Responses that ended without any content (subset of Failure)
 	SuccessRate   float64 `json:"success_rate"`
 
 	// TTFT Statistics (milliseconds)
@@ -78,13 +219,84 @@ type BenchmarkReport struct {
 	TokenThroughput float64 `json:"token_throughput"` // tokens/s (single request avg)
 	RPS             float64 `json:"rps"`
 
+	// SSE conformance (only with -strict-sse): total violations, requests with
+	// at least one, and the first violation seen in the run
+	SSEViolations        int    `json:"sse_violations,omitempty"`
+	SSEViolationRequests int    `json:"sse_violation_requests,omitempty"`
+	SSEViolationExample  string `json:"sse_violation_example,omitempty"`
+
+	// ClientSaturated is true when -self-monitor found the benchmark client
+	// itself limiting the run (see ClientStats.Reason)
+	ClientSaturated bool         `json:"client_saturated,omitempty"`
+	ClientStats     *ClientStats `json:"client_stats,omitempty"`
+
+	// Retries: requests that needed more than one attempt, and the extra attempts made
+	RetriedRequests int `json:"retried_requests,omitempty"`
+	RetryAttempts   int `json:"retry_attempts,omitempty"`
+
+	// Latency attribution (averages over successful HTTP requests). Connect and
+	// TLS average only the requests that opened a new connection.
+	AvgConnectMs         float64 `json:"avg_connect_ms,omitempty"`
+	AvgTLSMs             float64 `json:"avg_tls_ms,omitempty"`
+	AvgRequestWriteMs    float64 `json:"avg_request_write_ms,omitempty"`
+	AvgServerFirstByteMs float64 `json:"avg_server_first_byte_ms,omitempty"`
+	NewConnections       int     `json:"new_connections,omitempty"`
+
+	// KeepAliveDisabled is true when every request used a fresh connection,
+	// so TTFT and latency include TCP/TLS setup
+	KeepAliveDisabled bool `json:"keepalive_disabled,omitempty"`
+
+	// CacheBusted is true when every prompt carried a unique nonce (-bust-cache)
+	CacheBusted bool `json:"cache_busted,omitempty"`
+
+	// Latency spread and outliers (successful requests; not available with streaming stats).
+	// Outliers are requests whose latency is beyond median ± 3·MAD.
+	LatencyMADMs float64  `json:"latency_mad_ms,omitempty"`
+	LatencyIQRMs float64  `json:"latency_iqr_ms,omitempty"`
+	OutlierCount int      `json:"outlier_count"`
+	OutlierIDs   []string `json:"outlier_ids,omitempty"` // Up to 10, furthest from the median first
+
 	// Sampling
-	FirstContentRaw string   `json:"first_content_raw,omitempty"`
-	MiddleFramesRaw []string `json:"middle_frames_raw,omitempty"`
-	FinalFrameRaw   string   `json:"final_frame_raw,omitempty"`
+	FirstContentRaw    string   `json:"first_content_raw,omitempty"`
+	MiddleFramesRaw    []string `json:"middle_frames_raw,omitempty"`
+	MiddleFrameNumbers []int    `json:"middle_frame_numbers,omitempty"` // Position of each middle frame among the content frames
+	FinalFrameRaw      string   `json:"final_frame_raw,omitempty"`
 
 	// Error Breakdown
-	ErrorsTopN []ErrorStat `json:"errors_top_n,omitempty"`
+	ErrorsTopN       []ErrorStat `json:"errors_top_n,omitempty"`
+	HTTPStatusCounts map[int]int `json:"http_status_counts,omitempty"` // Failed requests by HTTP status code
+
+	// Prompt-length sensitivity (only when the prompts span several buckets):
+	// requests grouped by prompt length in power-of-two buckets, in
+	// PromptLengthUnit (estimated tokens, or chars with -token-mode chars)
+	PromptLengthUnit string             `json:"prompt_length_unit,omitempty"`
+	PromptBuckets    []PromptBucketStat `json:"prompt_buckets,omitempty"`
+
+	// Per-key breakdown (only with -api-key)
+	APIKeys []APIKeyStat `json:"api_keys,omitempty"`
+
+	// JSON Mode (only set when -json-mode or -json-schema is used)
+	ValidJSONCount int      `json:"valid_json_count,omitempty"`
+	ValidJSONRate  *float64 `json:"valid_json_rate,omitempty"` // Share of successful responses that parse as JSON
+
+	// Confidence (only with -logprobs): averages over each response's mean token logprob
+	AvgLogprob       *float64 `json:"avg_logprob,omitempty"`
+	MinAvgLogprob    *float64 `json:"min_avg_logprob,omitempty"` // Least confident response; very low values suggest degenerate output
+	LogprobResponses int      `json:"logprob_responses,omitempty"`
+
+	// Response quality (only with -score), sorted by scorer name
+	Scores []ScoreStat `json:"scores,omitempty"`
+
+	// FinishReasonCounts counts successful requests by finish reason ("unknown" if not reported)
+	FinishReasonCounts map[string]int `json:"finish_reason_counts,omitempty"`
+
+	// Response Diversity (successful requests only; identical responses may indicate caching or canned replies)
+	DistinctResponses int `json:"distinct_responses"`
+	TopResponseCount  int `json:"top_response_count"` // Occurrences of the most common response
+
+	// DistinctResponsesCapped is set in streaming mode when more distinct responses
+	// arrived than are tracked; DistinctResponses is then a lower bound
+	DistinctResponsesCapped bool `json:"distinct_responses_capped,omitempty"`
 
 	// Decode Statistics (milliseconds)
 	AvgDecodeMs float64 `json:"avg_decode_ms"`
@@ -92,12 +304,62 @@ type BenchmarkReport struct {
 	P95DecodeMs int64   `json:"p95_decode_ms"`
 	P99DecodeMs int64   `json:"p99_decode_ms"`
 
+	// Time to last token (milliseconds): request start to the last generated
+	// output. The stream tail is Latency - TTLT, the time spent waiting for
+	// [DONE] and the stream to close after generation finished.
+	AvgTTLTMs       float64 `json:"avg_ttlt_ms,omitempty"`
+	P50TTLTMs       int64   `json:"p50_ttlt_ms,omitempty"`
+	P95TTLTMs       int64   `json:"p95_ttlt_ms,omitempty"`
+	P99TTLTMs       int64   `json:"p99_ttlt_ms,omitempty"`
+	AvgStreamTailMs float64 `json:"avg_stream_tail_ms,omitempty"`
+	P95StreamTailMs int64   `json:"p95_stream_tail_ms,omitempty"`
+
+	// Token Totals (successful requests, from provider usage)
+	TotalPromptTokens     int `json:"total_prompt_tokens"`
+	TotalCompletionTokens int `json:"total_completion_tokens"`
+	EstimatedTokenCount   int `json:"estimated_token_count,omitempty"` // Successful requests whose completion tokens were estimated from chars
+	ToolCallResponses     int `json:"tool_call_responses,omitempty"`   // Successful requests that streamed tool calls (counted in chars/tokens)
+
+	// Cost (only with -price-input/-price-output), from the token totals above
+	EstimatedCostUSD       *float64 `json:"estimated_cost_usd,omitempty"`
+	CostPer1KRequestsUSD   float64  `json:"cost_per_1k_requests_usd,omitempty"`
+	CompletionTokensPerUSD float64  `json:"completion_tokens_per_usd,omitempty"`
+
 	// Speed Metrics
 	PrefillSpeed float64 `json:"prefill_speed"` // tokens/s (input_tokens / TTFT)
 	DecodeSpeed  float64 `json:"decode_speed"`  // tokens/s (output_tokens / decode_time)
 
+	// Per-request decode speed distribution (tokens/s, or chars/s in chars mode).
+	// The geometric mean is less skewed than the arithmetic mean by a few very fast requests.
+	MeanTokensPerSec    float64 `json:"mean_tokens_per_sec,omitempty"`
+	GeoMeanTokensPerSec float64 `json:"geomean_tokens_per_sec,omitempty"`
+	P50TokensPerSec     float64 `json:"p50_tokens_per_sec,omitempty"`
+	P95TokensPerSec     float64 `json:"p95_tokens_per_sec,omitempty"`
+	P99TokensPerSec     float64 `json:"p99_tokens_per_sec,omitempty"`
+
+	// StreamingStats is true when percentiles are P² estimates and distributions are omitted
+	StreamingStats bool `json:"streaming_stats,omitempty"`
+
+	// Timeseries buckets results into 1-second windows by completion time,
+	// exposing slowdowns over the run that the aggregates hide
+	Timeseries []WindowStat `json:"timeseries,omitempty"`
+
 	// Raw data for visualization
 	TTFTDistribution    []int64 `json:"ttft_distribution_ms,omitempty"`
 	LatencyDistribution []int64 `json:"latency_distribution_ms,omitempty"`
 	DecodeDistribution  []int64 `json:"decode_distribution_ms,omitempty"`
+
+	// PromptLengthDistribution holds the prompt length of each request in
+	// LatencyDistribution and TTFTDistribution, in the same order (only with PromptBuckets)
+	PromptLengthDistribution []int `json:"prompt_length_distribution,omitempty"`
+
+	// WarmupReport holds statistics for the warmup requests, which are
+	// excluded from every figure above (nil when -warmup is 0)
+	WarmupReport *BenchmarkReport `json:"warmup_report,omitempty"`
+
+	// Warmup until stable (-warmup-until-stable, set on the warmup report):
+	// P95 latency of each window of warmup requests, and whether two
+	// successive windows agreed within the tolerance before -warmup-max
+	WarmupWindowP95Ms []int64 `json:"warmup_window_p95_ms,omitempty"`
+	WarmupStable      bool    `json:"warmup_stable,omitempty"`
 }
@@ -0,0 +1,80 @@
+package result
+
+import "fmt"
+
+// SLA holds pass/fail thresholds checked against a BenchmarkReport.
+// Zero-valued thresholds are disabled.
+type SLA struct {
+	MaxP95LatencyMs int64   // Upper bound for P95 latency
+	MaxP99LatencyMs int64   // Upper bound for P99 latency
+	MaxP95TTFTMs    int64   // Upper bound for P95 TTFT
+	MaxP99TTFTMs    int64   // Upper bound for P99 TTFT
+	MinSuccessRate  float64 // Lower bound for success rate (0-1)
+	MinRPS          float64 // Lower bound for requests per second
+}
+
+// IsZero returns true if no threshold is set.
+func (s SLA) IsZero() bool {
+	return s == SLA{}
+}
+
+// SLAViolation describes a single SLA threshold that was not met.
+type SLAViolation struct {
+	Metric    string  `json:"metric"`
+	Threshold float64 `json:"threshold"`
+	Actual    float64 `json:"actual"`
+	Op        string  `json:"op"` // "<=" or ">=": the relation that should have held
+}
+
+// String formats the violation for console output.
+func (v SLAViolation) String() string {
+	return fmt.Sprintf("%s = %g, want %s %g", v.Metric, v.Actual, v.Op, v.Threshold)
+}
+
+// CheckSLA compares the report against each configured threshold and returns
+// the violations in a stable order. An empty result means all SLAs passed.
+func CheckSLA(report *BenchmarkReport, sla SLA) []SLAViolation {
+	var violations []SLAViolation
+
+	maxChecks := []struct {
+		metric    string
+		threshold int64
+		actual    int64
+	}{
+		{"p95_latency_ms", sla.MaxP95LatencyMs, report.P95LatencyMs},
+		{"p99_latency_ms", sla.MaxP99LatencyMs, report.P99LatencyMs},
+		{"p95_ttft_ms", sla.MaxP95TTFTMs, report.P95TTFTMs},
+		{"p99_ttft_ms", sla.MaxP99TTFTMs, report.P99TTFTMs},
+	}
+	for _, c := range maxChecks {
+		if c.threshold > 0 && c.actual > c.threshold {
+			violations = append(violations, SLAViolation{
+				Metric:    c.metric,
+				Threshold: float64(c.threshold),
+				Actual:    float64(c.actual),
+				Op:        "<=",
+			})
+		}
+	}
+
+	minChecks := []struct {
+		metric    string
+		threshold float64
+		actual    float64
+	}{
+		{"success_rate", sla.MinSuccessRate, report.SuccessRate},
+		{"rps", sla.MinRPS, report.RPS},
+	}
+	for _, c := range minChecks {
+		if c.threshold > 0 && c.actual < c.threshold {
+			violations = append(violations, SLAViolation{
+				Metric:    c.metric,
+				Threshold: c.threshold,
+				Actual:    c.actual,
+				Op:        ">=",
+			})
+		}
+	}
+
+	return violations
+}
@@ -0,0 +1,51 @@
+package result
+
+import "testing"
+
+func TestCheckSLA(t *testing.T) {
+	report := &BenchmarkReport{
+		SuccessRate:  0.98,
+		P95LatencyMs: 2500,
+		P99LatencyMs: 3000,
+		P95TTFTMs:    400,
+		P99TTFTMs:    900,
+		RPS:          12.5,
+	}
+
+	tests := []struct {
+		name    string
+		sla     SLA
+		metrics []string
+	}{
+		{name: "no thresholds", sla: SLA{}, metrics: nil},
+		{name: "all pass", sla: SLA{MaxP95LatencyMs: 3000, MaxP99TTFTMs: 1000, MinSuccessRate: 0.95, MinRPS: 10}, metrics: nil},
+		{name: "boundary passes", sla: SLA{MaxP95LatencyMs: 2500, MinSuccessRate: 0.98}, metrics: nil},
+		{name: "latency violated", sla: SLA{MaxP95LatencyMs: 2000}, metrics: []string{"p95_latency_ms"}},
+		{
+			name:    "multiple violations",
+			sla:     SLA{MaxP95LatencyMs: 2000, MaxP99TTFTMs: 800, MinSuccessRate: 0.99, MinRPS: 20},
+			metrics: []string{"p95_latency_ms", "p99_ttft_ms", "success_rate", "rps"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations := CheckSLA(report, tt.sla)
+			if len(violations) != len(tt.metrics) {
+				t.Fatalf("expected %d violations, got %d: %v", len(tt.metrics), len(violations), violations)
+			}
+			for i, v := range violations {
+				if v.Metric != tt.metrics[i] {
+					t.Errorf("violation %d: expected metric %s, got %s", i, tt.metrics[i], v.Metric)
+				}
+			}
+		})
+	}
+}
+
+func TestSLAViolationString(t *testing.T) {
+	v := SLAViolation{Metric: "success_rate", Threshold: 0.99, Actual: 0.98, Op: ">="}
+	if got, want := v.String(), "success_rate = 0.98, want >= 0.99"; got != want {
+		t.Errorf("expected %q, got %q", want, got)
+	}
+}
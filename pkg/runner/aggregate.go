@@ -0,0 +1,164 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/result"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/stats"
+)
+
+// MetricSpread summarizes one metric across repeated runs.
+type MetricSpread struct {
+	Mean   float64 `json:"mean"`
+	StdDev float64 `json:"stddev"`
+	CV     float64 `json:"cv"` // Coefficient of variation (stddev / mean)
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+}
+
+// RunSummary is one row of the per-run table in the aggregate report.
+type RunSummary struct {
+	Run          int     `json:"run"`
+	OutputDir    string  `json:"output_dir"`
+	SuccessRate  float64 `json:"success_rate"`
+	RPS          float64 `json:"rps"`
+	AvgTTFTMs    float64 `json:"avg_ttft_ms"`
+	P95TTFTMs    int64   `json:"p95_ttft_ms"`
+	P95LatencyMs int64   `json:"p95_latency_ms"`
+	Throughput   float64 `json:"token_throughput"`
+}
+
+// AggregateReport summarizes run-to-run variance for -repeat.
+type AggregateReport struct {
+	Provider string       `json:"provider"`
+	Model    string       `json:"model"`
+	Runs     int          `json:"runs"`
+	PerRun   []RunSummary `json:"per_run"`
+
+	SuccessRate  MetricSpread `json:"success_rate"`
+	RPS          MetricSpread `json:"rps"`
+	AvgTTFTMs    MetricSpread `json:"avg_ttft_ms"`
+	P95TTFTMs    MetricSpread `json:"p95_ttft_ms"`
+	P95LatencyMs MetricSpread `json:"p95_latency_ms"`
+	Throughput   MetricSpread `json:"token_throughput"`
+}
+
+// Aggregate combines the reports of repeated runs. outputDirs[i] is where run i wrote its files.
+func Aggregate(reports []*result.BenchmarkReport, outputDirs []string) *AggregateReport {
+	agg := &AggregateReport{Runs: len(reports)}
+	if len(reports) == 0 {
+		return agg
+	}
+	agg.Provider = reports[0].Provider
+	agg.Model = reports[0].Model
+
+	var success, rps, avgTTFT, p95TTFT, p95Latency, throughput []float64
+	for i, rep := range reports {
+		agg.PerRun = append(agg.PerRun, RunSummary{
+			Run:          i + 1,
+			OutputDir:    outputDirs[i],
+			SuccessRate:  rep.SuccessRate,
+			RPS:          rep.RPS,
+			AvgTTFTMs:    rep.AvgTTFTMs,
+			P95TTFTMs:    rep.P95TTFTMs,
+			P95LatencyMs: rep.P95LatencyMs,
+			Throughput:   rep.TokenThroughput,
+		})
+		success = append(success, rep.SuccessRate)
+		rps = append(rps, rep.RPS)
+		avgTTFT = append(avgTTFT, rep.AvgTTFTMs)
+		p95TTFT = append(p95TTFT, float64(rep.P95TTFTMs))
+		p95Latency = append(p95Latency, float64(rep.P95LatencyMs))
+		throughput = append(throughput, rep.TokenThroughput)
+	}
+
+	agg.SuccessRate = spread(success)
+	agg.RPS = spread(rps)
+	agg.AvgTTFTMs = spread(avgTTFT)
+	agg.P95TTFTMs = spread(p95TTFT)
+	agg.P95LatencyMs = spread(p95Latency)
+	agg.Throughput = spread(throughput)
+	return agg
+}
+
+func spread(values []float64) MetricSpread {
+	mean, stddev := stats.MeanStdDev(values)
+	s := MetricSpread{Mean: mean, StdDev: stddev, Min: values[0], Max: values[0]}
+	if mean != 0 {
+		s.CV = stddev / mean
+	}
+	for _, v := range values[1:] {
+		if v < s.Min {
+			s.Min = v
+		}
+		if v > s.Max {
+			s.Max = v
+		}
+	}
+	return s
+}
+
+// WriteAggregate writes aggregate.json and aggregate.md to outputDir and lists
+// them on w.
+func WriteAggregate(w io.Writer, agg *AggregateReport, outputDir string) error {
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	jsonPath := filepath.Join(outputDir, "aggregate.json")
+	data, err := json.MarshalIndent(agg, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal aggregate: %w", err)
+	}
+	if err := os.WriteFile(jsonPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write aggregate: %w", err)
+	}
+	fmt.Fprintf(w, "  - Aggregate: %s\n", jsonPath)
+
+	mdPath := filepath.Join(outputDir, "aggregate.md")
+	if err := os.WriteFile(mdPath, []byte(agg.Markdown()), 0644); err != nil {
+		return fmt.Errorf("failed to write aggregate markdown: %w", err)
+	}
+	fmt.Fprintf(w, "  - Aggregate: %s\n", mdPath)
+	return nil
+}
+
+// Markdown renders the aggregate as a Markdown report.
+func (a *AggregateReport) Markdown() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Repeated Benchmark Aggregate\n\n")
+	fmt.Fprintf(&sb, "- Provider: %s\n- Model: %s\n- Runs: %d\n\n", a.Provider, a.Model, a.Runs)
+
+	fmt.Fprintf(&sb, "## Run-to-Run Variance\n\n")
+	fmt.Fprintf(&sb, "| Metric | Mean | StdDev | CV | Min | Max |\n")
+	fmt.Fprintf(&sb, "|--------|------|--------|----|-----|-----|\n")
+	rows := []struct {
+		name string
+		s    MetricSpread
+	}{
+		{"Success Rate", a.SuccessRate},
+		{"RPS", a.RPS},
+		{"Avg TTFT (ms)", a.AvgTTFTMs},
+		{"P95 TTFT (ms)", a.P95TTFTMs},
+		{"P95 Latency (ms)", a.P95LatencyMs},
+		{"Throughput", a.Throughput},
+	}
+	for _, row := range rows {
+		fmt.Fprintf(&sb, "| %s | %.2f | %.2f | %.1f%% | %.2f | %.2f |\n",
+			row.name, row.s.Mean, row.s.StdDev, row.s.CV*100, row.s.Min, row.s.Max)
+	}
+
+	fmt.Fprintf(&sb, "\n## Per-Run Results\n\n")
+	fmt.Fprintf(&sb, "| Run | Success Rate | RPS | Avg TTFT (ms) | P95 TTFT (ms) | P95 Latency (ms) | Throughput | Output |\n")
+	fmt.Fprintf(&sb, "|-----|--------------|-----|---------------|---------------|------------------|------------|--------|\n")
+	for _, run := range a.PerRun {
+		fmt.Fprintf(&sb, "| %d | %.2f%% | %.2f | %.2f | %d | %d | %.2f | %s |\n",
+			run.Run, run.SuccessRate*100, run.RPS, run.AvgTTFTMs, run.P95TTFTMs, run.P95LatencyMs, run.Throughput, run.OutputDir)
+	}
+	return sb.String()
+}
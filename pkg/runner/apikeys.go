@@ -0,0 +1,78 @@
+package runner
+
+import (
+	"sync"
+	"time"
+)
+
+// keyPool spreads requests across the -api-key list round-robin. With a
+// per-key RPS cap, each key has its own token bucket (burst 1) and a request
+// takes the next key with a token, waiting for the first one to refill if
+// every key is exhausted.
+type keyPool struct {
+	mu     sync.Mutex
+	keys   []string
+	rps    float64     // Per-key requests per second (0 = unlimited)
+	tokens []float64   // Available requests per key
+	last   []time.Time // Last refill per key
+	next   int         // Key to try first
+}
+
+func newKeyPool(keys []string, rps float64) *keyPool {
+	p := &keyPool{
+		keys:   keys,
+		rps:    rps,
+		tokens: make([]float64, len(keys)),
+		last:   make([]time.Time, len(keys)),
+	}
+	now := time.Now()
+	for i := range keys {
+		p.tokens[i] = 1
+		p.last[i] = now
+	}
+	return p
+}
+
+// acquire blocks until a key may send a request and returns its index.
+func (p *keyPool) acquire() int {
+	for {
+		wait, i := p.tryAcquire(time.Now())
+		if i >= 0 {
+			return i
+		}
+		time.Sleep(wait)
+	}
+}
+
+// tryAcquire takes a token from the next key that has one and returns its
+// index, or returns -1 and how long until a key refills.
+func (p *keyPool) tryAcquire(now time.Time) (time.Duration, int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	n := len(p.keys)
+	if p.rps <= 0 {
+		i := p.next
+		p.next = (i + 1) % n
+		return 0, i
+	}
+
+	minWait := time.Duration(-1)
+	for k := 0; k < n; k++ {
+		i := (p.next + k) % n
+		if elapsed := now.Sub(p.last[i]); elapsed > 0 {
+			p.tokens[i] = min(1, p.tokens[i]+elapsed.Seconds()*p.rps)
+			p.last[i] = now
+		}
+		if p.tokens[i] >= 1 {
+			p.tokens[i]--
+			p.next = (i + 1) % n
+			return 0, i
+		}
+		wait := time.Duration((1 - p.tokens[i]) / p.rps * float64(time.Second))
+		if minWait < 0 || wait < minWait {
+			minWait = wait
+		}
+	}
+	return minWait, -1
+}
@@ -0,0 +1,33 @@
+package runner
+
+import (
+	"testing"
+	"time"
+)
+
+func TestKeyPool(t *testing.T) {
+	p := newKeyPool([]string{"a", "b"}, 2) // One request per key every 500ms
+	start := time.Now()
+
+	var got []int
+	for _, offset := range []time.Duration{0, 0, 0, 200 * time.Millisecond, 500 * time.Millisecond, 500 * time.Millisecond} {
+		wait, i := p.tryAcquire(start.Add(offset))
+		got = append(got, i)
+		if i < 0 && (wait <= 0 || wait > 500*time.Millisecond) {
+			t.Errorf("at %v: wait = %v, want within the refill interval", offset, wait)
+		}
+	}
+	want := []int{0, 1, -1, -1, 0, 1}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("keys = %v, want %v", got, want)
+		}
+	}
+
+	unlimited := newKeyPool([]string{"a", "b", "c"}, 0)
+	for i, want := range []int{0, 1, 2, 0} {
+		if _, got := unlimited.tryAcquire(start); got != want {
+			t.Errorf("unlimited acquire %d = %d, want %d", i, got, want)
+		}
+	}
+}
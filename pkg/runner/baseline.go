@@ -0,0 +1,76 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/result"
+)
+
+// runDirTimeLayouts are the timestamps that end auto-generated output
+// directory names, newest format first.
+var runDirTimeLayouts = []string{"20060102_150405.000", "20060102_150405"}
+
+// LoadSummary reads a summary.json written by a previous run.
+func LoadSummary(path string) (*result.BenchmarkReport, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read summary: %w", err)
+	}
+	var report result.BenchmarkReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to parse summary %s: %w", path, err)
+	}
+	return &report, nil
+}
+
+// FindLatestSummary returns the summary.json of the newest run directly under
+// dir. Runs are ordered by the timestamp that ends auto-generated directory
+// names (output/<model>_<timestamp>); directories without one are ordered by
+// the summary's modification time.
+func FindLatestSummary(dir string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to read baseline directory: %w", err)
+	}
+
+	var latest string
+	var latestTime time.Time
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		path := filepath.Join(dir, entry.Name(), "summary.json")
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		ts, ok := runDirTime(entry.Name())
+		if !ok {
+			ts = info.ModTime()
+		}
+		if latest == "" || ts.After(latestTime) {
+			latest, latestTime = path, ts
+		}
+	}
+	if latest == "" {
+		return "", fmt.Errorf("no summary.json found in subdirectories of %s", dir)
+	}
+	return latest, nil
+}
+
+// runDirTime parses the timestamp suffix of an auto-generated run directory name.
+func runDirTime(name string) (time.Time, bool) {
+	for _, layout := range runDirTimeLayouts {
+		if len(name) < len(layout) {
+			continue
+		}
+		if ts, err := time.ParseInLocation(layout, name[len(name)-len(layout):], time.Local); err == nil {
+			return ts, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,49 @@
+package runner
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFindLatestSummary(t *testing.T) {
+	dir := t.TempDir()
+	runs := map[string]bool{ // Directory -> has summary.json
+		"qwen_20250101_120000.000":     true,
+		"qwen_20250301_080000.500":     true,
+		"qwen_20250201_090000":         true,
+		"qwen_20250401_000000.000":     false, // Newest, but failed before writing a summary
+		"nightly_qwen_20241231_235959": true,
+	}
+	for name, hasSummary := range runs {
+		if err := os.MkdirAll(filepath.Join(dir, name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if hasSummary {
+			if err := os.WriteFile(filepath.Join(dir, name, "summary.json"), []byte(`{"model":"`+name+`"}`), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	path, err := FindLatestSummary(dir)
+	if err != nil {
+		t.Fatalf("FindLatestSummary: %v", err)
+	}
+	want := filepath.Join(dir, "qwen_20250301_080000.500", "summary.json")
+	if path != want {
+		t.Errorf("FindLatestSummary = %s, want %s", path, want)
+	}
+
+	report, err := LoadSummary(path)
+	if err != nil {
+		t.Fatalf("LoadSummary: %v", err)
+	}
+	if report.Model != "qwen_20250301_080000.500" {
+		t.Errorf("Model = %q", report.Model)
+	}
+
+	if _, err := FindLatestSummary(t.TempDir()); err == nil {
+		t.Error("expected an error for a directory without runs")
+	}
+}
@@ -0,0 +1,183 @@
+package runner
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/workload"
+)
+
+const checkpointFile = "checkpoint.json"
+
+// ErrResumeMismatch is returned by Run when -resume finds a checkpoint written
+// for a different model, URL or request count.
+var ErrResumeMismatch = errors.New("checkpoint does not match the current config")
+
+// checkpoint records the progress of a benchmark run so an interrupted run
+// can be continued with -resume. Completed requests themselves are in
+// results.jsonl; the checkpoint only says how far the run got.
+type checkpoint struct {
+	Model         string `json:"model"`
+	URL           string `json:"url"`
+	TotalRequests int    `json:"total_requests"`
+	Completed     int    `json:"completed"`
+	ElapsedMs     int64  `json:"elapsed_ms"` // Benchmark time across all sessions, excluding warmup
+	UpdatedAt     string `json:"updated_at"`
+}
+
+// writeCheckpoint replaces checkpoint.json in the output directory. The file
+// is written to a temporary name first so a crash never leaves it half written.
+func (r *Runner) writeCheckpoint(completed int, elapsed time.Duration) error {
+	cp := checkpoint{
+		Model:         r.cfg.ModelName,
+		URL:           r.cfg.URL,
+		TotalRequests: r.cfg.TotalRequests,
+		Completed:     completed,
+		ElapsedMs:     elapsed.Milliseconds(),
+		UpdatedAt:     time.Now().Format(time.RFC3339),
+	}
+	data, err := json.MarshalIndent(cp, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal checkpoint: %w", err)
+	}
+	path := filepath.Join(r.cfg.OutputDir, checkpointFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	return nil
+}
+
+// resumeState is what -resume restores from an earlier, interrupted run.
+type resumeState struct {
+	done    map[int]bool  // Seq numbers already in results.jsonl
+	elapsed time.Duration // Benchmark time spent by the earlier sessions
+}
+
+// loadResume checks the checkpoint in the output directory against the
+// current config, feeds the results already in results.jsonl to agg and
+// returns which requests are done. A truncated last line, left by a crash
+// mid-write, is cut off so new results append cleanly. The earlier results
+// are shifted forward in time to end now, so the per-second time series does
+// not show the interruption as a stretch of idle seconds.
+func (r *Runner) loadResume(agg *aggregator) (*resumeState, error) {
+	data, err := os.ReadFile(filepath.Join(r.cfg.OutputDir, checkpointFile))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read checkpoint (was the run started with this version?): %w", err)
+	}
+	var cp checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return nil, fmt.Errorf("failed to parse checkpoint: %w", err)
+	}
+	if cp.TotalRequests != r.cfg.TotalRequests {
+		return nil, fmt.Errorf("%w: it is for %d requests, but -total-requests is %d", ErrResumeMismatch, cp.TotalRequests, r.cfg.TotalRequests)
+	}
+	if cp.Model != r.cfg.ModelName {
+		return nil, fmt.Errorf("%w: it is for model %q, but -model is %q", ErrResumeMismatch, cp.Model, r.cfg.ModelName)
+	}
+	if cp.URL != r.cfg.URL {
+		return nil, fmt.Errorf("%w: it is for %s, but -url is %s", ErrResumeMismatch, cp.URL, r.cfg.URL)
+	}
+
+	path := filepath.Join(r.cfg.OutputDir, "results.jsonl")
+	file, err := os.OpenFile(path, os.O_RDWR, 0)
+	if errors.Is(err, os.ErrNotExist) {
+		return &resumeState{done: map[int]bool{}, elapsed: time.Duration(cp.ElapsedMs) * time.Millisecond}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open results file: %w", err)
+	}
+	defer file.Close()
+
+	state := &resumeState{done: make(map[int]bool), elapsed: time.Duration(cp.ElapsedMs) * time.Millisecond}
+	reader := bufio.NewReader(file)
+	var offset int64
+	var records []resultRecord
+	var lastEnd time.Time
+	for lineNum := 1; ; lineNum++ {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			// Anything after the last newline is a record cut short by the crash
+			if len(line) > 0 {
+				fmt.Fprintf(r.cfg.Stdout(), "Dropping truncated last line of %s\n", path)
+				if err := file.Truncate(offset); err != nil {
+					return nil, fmt.Errorf("failed to truncate results file: %w", err)
+				}
+			}
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read results file: %w", err)
+		}
+		offset += int64(len(line))
+
+		var rec resultRecord
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return nil, fmt.Errorf("failed to parse results line %d: %w", lineNum, err)
+		}
+		if rec.Seq < 1 || rec.Seq > r.cfg.TotalRequests || state.done[rec.Seq] {
+			return nil, fmt.Errorf("results line %d has no usable seq; only runs written with checkpointing can be resumed", lineNum)
+		}
+		state.done[rec.Seq] = true
+		records = append(records, rec)
+		if rec.EndTS.After(lastEnd) {
+			lastEnd = rec.EndTS
+		}
+	}
+
+	var shift time.Duration
+	if !lastEnd.IsZero() {
+		shift = time.Since(lastEnd)
+	}
+	for _, rec := range records {
+		res := rec.toResult()
+		res.StartTime = shiftTime(res.StartTime, shift)
+		res.FirstContentTime = shiftTime(res.FirstContentTime, shift)
+		res.EndTime = shiftTime(res.EndTime, shift)
+		agg.add(res)
+	}
+	return state, nil
+}
+
+// shiftTime moves t by d, leaving unset times unset.
+func shiftTime(t time.Time, d time.Duration) time.Time {
+	if t.IsZero() {
+		return t
+	}
+	return t.Add(d)
+}
+
+// sequence numbers the first n workloads from src 1..n in order and forwards
+// those not in done, leaving the rest of src for the next caller. It gives up
+// once stop is closed, since runBatch no longer reads from it then.
+func sequence(src <-chan workload.WorkloadInput, n int, done map[int]bool, stop <-chan struct{}) <-chan workload.WorkloadInput {
+	out := make(chan workload.WorkloadInput)
+	go func() {
+		defer close(out)
+		for seq := 1; seq <= n; seq++ {
+			w, ok := <-src
+			if !ok {
+				return
+			}
+			if done[seq] {
+				continue
+			}
+			w.Seq = seq
+			select {
+			case out <- w:
+			case <-stop:
+				return
+			}
+		}
+	}()
+	return out
+}
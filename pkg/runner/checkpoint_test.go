@@ -0,0 +1,134 @@
+package runner
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/config"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/workload"
+)
+
+func TestRunResume(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.TotalRequests = 6
+	cfg.Concurrency = 2
+	cfg.OutputDir = t.TempDir()
+	cfg.OutputFormat = "json"
+	p := &scriptedProvider{deltas: []string{"ok"}}
+
+	if _, err := New(cfg, p).Run(); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	// Simulate a crash: keep two results and half of the third
+	path := filepath.Join(cfg.OutputDir, "results.jsonl")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := bytes.SplitAfter(data, []byte("\n"))
+	kept := append(bytes.Join(lines[:2], nil), lines[2][:10]...)
+	if err := os.WriteFile(path, kept, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg.Resume = true
+	report, err := New(cfg, p).Run()
+	if err != nil {
+		t.Fatalf("resumed Run: %v", err)
+	}
+	if report.TotalRequests != 6 || report.Success != 6 {
+		t.Errorf("report has %d/%d successes, want 6/6", report.Success, report.TotalRequests)
+	}
+
+	data, err = os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := make(map[int]bool)
+	for _, line := range bytes.Split(bytes.TrimSpace(data), []byte("\n")) {
+		var rec resultRecord
+		if err := json.Unmarshal(line, &rec); err != nil {
+			t.Fatalf("invalid results line %q: %v", line, err)
+		}
+		if seen[rec.Seq] {
+			t.Errorf("seq %d written twice", rec.Seq)
+		}
+		seen[rec.Seq] = true
+	}
+	for seq := 1; seq <= 6; seq++ {
+		if !seen[seq] {
+			t.Errorf("seq %d missing from results", seq)
+		}
+	}
+
+	cfg.TotalRequests = 8
+	if _, err := New(cfg, p).Run(); !errors.Is(err, ErrResumeMismatch) {
+		t.Errorf("resume with a different -total-requests: err = %v, want ErrResumeMismatch", err)
+	}
+	cfg.TotalRequests = 6
+	cfg.URL = "http://other.example/v1/chat/completions"
+	if _, err := New(cfg, p).Run(); !errors.Is(err, ErrResumeMismatch) {
+		t.Errorf("resume with a different -url: err = %v, want ErrResumeMismatch", err)
+	}
+}
+
+func TestLoadResume_ShiftsTimestamps(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.TotalRequests = 4
+	cfg.OutputDir = t.TempDir()
+	r := New(cfg, &scriptedProvider{})
+	if err := r.writeCheckpoint(2, 2*time.Second); err != nil {
+		t.Fatal(err)
+	}
+
+	// Two results from a session that ended an hour ago
+	end := time.Now().Add(-time.Hour)
+	var buf bytes.Buffer
+	for seq := 1; seq <= 2; seq++ {
+		rec := resultRecord{
+			Seq:       seq,
+			Status:    "ok",
+			LatencyMs: 1000,
+			StartTS:   end.Add(-time.Duration(3-seq) * time.Second),
+			EndTS:     end.Add(-time.Duration(2-seq) * time.Second),
+		}
+		line, _ := json.Marshal(rec)
+		buf.Write(append(line, '\n'))
+	}
+	if err := os.WriteFile(filepath.Join(cfg.OutputDir, "results.jsonl"), buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	agg := newAggregator(false)
+	if _, err := r.loadResume(agg); err != nil {
+		t.Fatalf("loadResume: %v", err)
+	}
+	for sec := range agg.windows {
+		if age := time.Since(time.Unix(sec, 0)); age > time.Minute {
+			t.Errorf("result window is %v old, want it shifted to the resume time", age)
+		}
+	}
+	if n := len(agg.timeseries()); n > 2 {
+		t.Errorf("time series has %d seconds for 2 results 1s apart, want at most 2", n)
+	}
+}
+
+func TestSequence_Stop(t *testing.T) {
+	src := feed(make([]workload.WorkloadInput, 3))
+	stop := make(chan struct{})
+	out := sequence(src, 3, nil, stop)
+	<-out
+	close(stop)
+
+	// Nobody reads after the abort; sequence must give up the pending send
+	time.Sleep(50 * time.Millisecond)
+	if _, ok := <-out; ok {
+		t.Error("sequence was still sending after stop was closed")
+	}
+}
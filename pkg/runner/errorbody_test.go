@@ -0,0 +1,51 @@
+package runner
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/config"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/provider/openai"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/result"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/workload"
+)
+
+func TestExecuteRequest_ErrorBodyWith200(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		status      result.RequestStatus
+		err         string
+	}{
+		{"JSON body", "application/json", `{"error":{"message":"quota exceeded","code":"insufficient_quota"}}`,
+			result.StatusHTTPError, "HTTP 200: quota exceeded"},
+		{"error frame", "text/event-stream", "data: {\"error\":{\"message\":\"overloaded\"}}\n\n",
+			result.StatusHTTPError, "HTTP 200: overloaded"},
+		{"error frame after content", "text/event-stream",
+			"data: {\"choices\":[{\"index\":0,\"delta\":{\"content\":\"Hel\"}}]}\n\ndata: {\"object\":\"error\",\"message\":\"engine died\"}\n\n",
+			result.StatusPartial, "HTTP 200: engine died"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", tt.contentType)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			cfg := config.DefaultConfig()
+			cfg.URL = server.URL
+			cfg.ModelName = "test"
+			res := New(cfg, &openai.Provider{}).executeRequest(workload.NewSimpleWorkload("req-1", "hi", 8))
+			if res.Status != tt.status || res.Err != tt.err {
+				t.Errorf("Status = %q, Err = %q, want %q, %q", res.Status, res.Err, tt.status, tt.err)
+			}
+			if code := httpStatusCode(res.Err); code != http.StatusOK {
+				t.Errorf("httpStatusCode = %d, want 200 counted among the HTTP errors", code)
+			}
+		})
+	}
+}
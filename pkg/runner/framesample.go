@@ -0,0 +1,52 @@
+package runner
+
+// sampledFrame is a raw content frame and its 1-based position in the stream.
+type sampledFrame struct {
+	number int
+	raw    string
+}
+
+// frameSampler keeps content frames at a regular stride so that n frames
+// spread evenly over the stream can be picked when it ends, without knowing
+// its length in advance. It holds at most 2n frames: when full, every other
+// frame is dropped and the stride doubles.
+type frameSampler struct {
+	n      int
+	stride int
+	frames []sampledFrame
+}
+
+func newFrameSampler(n int) *frameSampler {
+	return &frameSampler{n: n, stride: 1}
+}
+
+// add offers content frame number. The first frame is sampled separately.
+func (s *frameSampler) add(number int, raw string) {
+	if s.n <= 0 || number < 2 || number%s.stride != 0 {
+		return
+	}
+	s.frames = append(s.frames, sampledFrame{number: number, raw: truncateString(raw, MaxSampleSize)})
+	if len(s.frames) <= 2*s.n {
+		return
+	}
+	s.stride *= 2
+	kept := s.frames[:0]
+	for _, f := range s.frames {
+		if f.number%s.stride == 0 {
+			kept = append(kept, f)
+		}
+	}
+	s.frames = kept
+}
+
+// pick returns up to n of the kept frames, evenly spaced and in stream order.
+func (s *frameSampler) pick() []sampledFrame {
+	if len(s.frames) <= s.n {
+		return s.frames
+	}
+	picked := make([]sampledFrame, s.n)
+	for i := range picked {
+		picked[i] = s.frames[(2*i+1)*len(s.frames)/(2*s.n)]
+	}
+	return picked
+}
@@ -0,0 +1,45 @@
+package runner
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestFrameSampler(t *testing.T) {
+	tests := []struct {
+		name   string
+		n      int
+		frames int
+		want   []int
+	}{
+		{"disabled", 0, 100, nil},
+		{"short stream keeps every middle frame", 3, 4, []int{2, 3, 4}},
+		{"first frame is never a middle frame", 3, 1, nil},
+		{"evenly spaced", 3, 12, []int{4, 8, 12}},
+		{"long stream", 3, 1000, []int{256, 512, 768}},
+		{"one frame", 1, 1000, []int{512}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newFrameSampler(tt.n)
+			for i := 1; i <= tt.frames; i++ {
+				s.add(i, "frame "+strconv.Itoa(i))
+			}
+			if len(s.frames) > 2*tt.n {
+				t.Errorf("kept %d frames, want at most %d", len(s.frames), 2*tt.n)
+			}
+			var got []int
+			for _, f := range s.pick() {
+				if f.raw != "frame "+strconv.Itoa(f.number) {
+					t.Errorf("frame %d has raw %q", f.number, f.raw)
+				}
+				got = append(got, f.number)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("picked frames %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
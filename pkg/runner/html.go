@@ -18,12 +18,23 @@ var reportTemplate string
 //go:embed templates/assets/js/echarts.min.js
 var echartsJS []byte
 
+// EChartsCDNURL is the CDN copy of the embedded ECharts version, used by -cdn-charts.
+const EChartsCDNURL = "https://cdn.jsdelivr.net/npm/echarts@5.4.4/dist/echarts.min.js"
+
 //go:embed templates/assets/fonts/JetBrainsMono-Regular.woff2
 var jetBrainsMonoFont []byte
 
 //go:embed templates/assets/fonts/PlusJakartaSans-Variable.woff2
 var plusJakartaSansFont []byte
 
+// chartsCDN returns the ECharts CDN URL with -cdn-charts, or "" to embed the library.
+func (r *Runner) chartsCDN() string {
+	if r.cfg.CDNCharts {
+		return EChartsCDNURL
+	}
+	return ""
+}
+
 func (r *Runner) writeHTMLReport(report *result.BenchmarkReport, path string) error {
 	tmpl, err := template.New("report").Parse(reportTemplate)
 	if err != nil {
@@ -44,8 +55,14 @@ func (r *Runner) writeHTMLReport(report *result.BenchmarkReport, path string) er
 		"Report":                report,
 		"ReportJSON":            template.JS(reportJSON),
 		"EChartsJS":             template.JS(echartsJS),
+		"EChartsCDN":            r.chartsCDN(),
 		"JetBrainsMonoBase64":   jetBrainsMonoBase64,
 		"PlusJakartaSansBase64": plusJakartaSansBase64,
+		"RefreshSec":            0,
+	}
+	if report.Snapshot {
+		// Reload with the next snapshot when the report is left open
+		data["RefreshSec"] = r.cfg.SnapshotSec
 	}
 
 	var buf bytes.Buffer
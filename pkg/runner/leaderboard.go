@@ -0,0 +1,244 @@
+package runner
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/result"
+)
+
+//go:embed templates/leaderboard.html
+var leaderboardTemplate string
+
+// LeaderboardTarget is one endpoint of a -leaderboard run. Empty fields fall
+// back to the corresponding command-line flag.
+type LeaderboardTarget struct {
+	Name     string `json:"name"`
+	Provider string `json:"provider"`
+	URL      string `json:"url"`
+	Model    string `json:"model"`
+	Token    string `json:"token,omitempty"`
+	TokenEnv string `json:"token_env,omitempty"` // Read the token from this environment variable
+}
+
+// LoadLeaderboardTargets reads targets from a JSON array file. Targets without
+// a name are called "<provider>:<model>", using defaultProvider when the
+// target does not set one.
+func LoadLeaderboardTargets(path, defaultProvider string) ([]LeaderboardTarget, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read leaderboard targets: %w", err)
+	}
+	var targets []LeaderboardTarget
+	if err := json.Unmarshal(data, &targets); err != nil {
+		return nil, fmt.Errorf("failed to parse leaderboard targets: %w", err)
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("leaderboard targets file %s contains no targets", path)
+	}
+	seen := make(map[string]bool)
+	for i := range targets {
+		t := &targets[i]
+		if t.Name == "" {
+			provider := t.Provider
+			if provider == "" {
+				provider = defaultProvider
+			}
+			t.Name = provider + ":" + t.Model
+		}
+		if seen[t.Name] {
+			return nil, fmt.Errorf("leaderboard target %d: duplicate name %q", i+1, t.Name)
+		}
+		seen[t.Name] = true
+	}
+	return targets, nil
+}
+
+// LeaderboardEntry is one ranked row of the leaderboard.
+type LeaderboardEntry struct {
+	Rank      int    `json:"rank"` // 0 for targets that failed
+	Name      string `json:"name"`
+	Provider  string `json:"provider"`
+	URL       string `json:"url"`
+	Model     string `json:"model"`
+	OutputDir string `json:"output_dir"`
+	Err       string `json:"err,omitempty"`
+
+	SuccessRate  float64 `json:"success_rate"`
+	RPS          float64 `json:"rps"`
+	AvgTTFTMs    float64 `json:"avg_ttft_ms"`
+	P95LatencyMs int64   `json:"p95_latency_ms"`
+	Throughput   float64 `json:"token_throughput"`
+
+	// Per-metric ranks (1 = best) and their mean, which orders the leaderboard
+	RPSRank        int     `json:"rps_rank,omitempty"`
+	P95Rank        int     `json:"p95_latency_rank,omitempty"`
+	ThroughputRank int     `json:"throughput_rank,omitempty"`
+	Score          float64 `json:"score,omitempty"`
+}
+
+// Leaderboard compares the same benchmark run against several targets.
+type Leaderboard struct {
+	GeneratedAt time.Time          `json:"generated_at"`
+	Entries     []LeaderboardEntry `json:"entries"`
+}
+
+// NewLeaderboardEntry summarizes the report of target. A nil report or runErr
+// marks the target as failed, as does a run without any successful request,
+// since its latency percentiles would otherwise rank as the best.
+func NewLeaderboardEntry(target LeaderboardTarget, report *result.BenchmarkReport, outputDir string, runErr error) LeaderboardEntry {
+	e := LeaderboardEntry{
+		Name:      target.Name,
+		Provider:  target.Provider,
+		URL:       target.URL,
+		Model:     target.Model,
+		OutputDir: outputDir,
+	}
+	switch {
+	case runErr != nil:
+		e.Err = runErr.Error()
+	case report == nil:
+		e.Err = "no report"
+	default:
+		e.SuccessRate = report.SuccessRate
+		e.RPS = report.RPS
+		e.AvgTTFTMs = report.AvgTTFTMs
+		e.P95LatencyMs = report.P95LatencyMs
+		e.Throughput = report.TokenThroughput
+		if report.Success == 0 {
+			e.Err = "no successful requests"
+		}
+	}
+	return e
+}
+
+// RankLeaderboard ranks the entries by RPS (higher is better), P95 latency
+// (lower is better) and token throughput (higher is better), and orders them
+// by the mean of the three ranks. Ties share a rank. Failed entries are listed
+// last, unranked, in their original order.
+func RankLeaderboard(entries []LeaderboardEntry) *Leaderboard {
+	var ranked, failed []LeaderboardEntry
+	for _, e := range entries {
+		if e.Err != "" {
+			failed = append(failed, e)
+		} else {
+			ranked = append(ranked, e)
+		}
+	}
+
+	assignRanks(ranked, func(e LeaderboardEntry) float64 { return e.RPS }, true,
+		func(e *LeaderboardEntry, rank int) { e.RPSRank = rank })
+	assignRanks(ranked, func(e LeaderboardEntry) float64 { return float64(e.P95LatencyMs) }, false,
+		func(e *LeaderboardEntry, rank int) { e.P95Rank = rank })
+	assignRanks(ranked, func(e LeaderboardEntry) float64 { return e.Throughput }, true,
+		func(e *LeaderboardEntry, rank int) { e.ThroughputRank = rank })
+	for i := range ranked {
+		e := &ranked[i]
+		e.Score = float64(e.RPSRank+e.P95Rank+e.ThroughputRank) / 3
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score < ranked[j].Score
+		}
+		return ranked[i].RPS > ranked[j].RPS
+	})
+	for i := range ranked {
+		ranked[i].Rank = i + 1
+		if i > 0 && ranked[i].Score == ranked[i-1].Score && ranked[i].RPS == ranked[i-1].RPS {
+			ranked[i].Rank = ranked[i-1].Rank
+		}
+	}
+
+	return &Leaderboard{
+		GeneratedAt: time.Now(),
+		Entries:     append(ranked, failed...),
+	}
+}
+
+// assignRanks sets each entry's rank for one metric, 1 being the best value.
+func assignRanks(entries []LeaderboardEntry, metric func(LeaderboardEntry) float64, higherIsBetter bool, set func(*LeaderboardEntry, int)) {
+	for i := range entries {
+		rank := 1
+		for j := range entries {
+			a, b := metric(entries[j]), metric(entries[i])
+			if (higherIsBetter && a > b) || (!higherIsBetter && a < b) {
+				rank++
+			}
+		}
+		set(&entries[i], rank)
+	}
+}
+
+// WriteLeaderboard writes leaderboard.json, leaderboard.md and leaderboard.html to
+// outputDir and lists them on w.
+func WriteLeaderboard(w io.Writer, lb *Leaderboard, outputDir string) error {
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	jsonPath := filepath.Join(outputDir, "leaderboard.json")
+	data, err := json.MarshalIndent(lb, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal leaderboard: %w", err)
+	}
+	if err := os.WriteFile(jsonPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write leaderboard: %w", err)
+	}
+	fmt.Fprintf(w, "  - Leaderboard: %s\n", jsonPath)
+
+	mdPath := filepath.Join(outputDir, "leaderboard.md")
+	if err := os.WriteFile(mdPath, []byte(lb.Markdown()), 0644); err != nil {
+		return fmt.Errorf("failed to write leaderboard markdown: %w", err)
+	}
+	fmt.Fprintf(w, "  - Leaderboard: %s\n", mdPath)
+
+	tmpl, err := template.New("leaderboard").Funcs(template.FuncMap{
+		"mulPercent": func(v float64) float64 { return v * 100 },
+	}).Parse(leaderboardTemplate)
+	if err != nil {
+		return fmt.Errorf("failed to parse leaderboard template: %w", err)
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, lb); err != nil {
+		return fmt.Errorf("failed to render leaderboard HTML: %w", err)
+	}
+	htmlPath := filepath.Join(outputDir, "leaderboard.html")
+	if err := os.WriteFile(htmlPath, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("failed to write leaderboard HTML: %w", err)
+	}
+	fmt.Fprintf(w, "  - Leaderboard: %s\n", htmlPath)
+	return nil
+}
+
+// Markdown renders the leaderboard as a Markdown report.
+func (lb *Leaderboard) Markdown() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# Benchmark Leaderboard\n\n")
+	fmt.Fprintf(&sb, "- Generated: %s\n- Targets: %d\n\n", lb.GeneratedAt.Format("2006-01-02 15:04:05"), len(lb.Entries))
+	fmt.Fprintf(&sb, "Ranked by the mean of the RPS, P95 latency and throughput ranks (shown in parentheses).\n\n")
+
+	fmt.Fprintf(&sb, "| Rank | Target | Provider | Model | RPS | P95 Latency (ms) | Throughput | Avg TTFT (ms) | Success Rate | Output |\n")
+	fmt.Fprintf(&sb, "|------|--------|----------|-------|-----|------------------|------------|---------------|--------------|--------|\n")
+	for _, e := range lb.Entries {
+		if e.Err != "" {
+			fmt.Fprintf(&sb, "| - | %s | %s | %s | failed: %s | | | | %.2f%% | %s |\n",
+				markdownCell(e.Name, 60), e.Provider, markdownCell(e.Model, 60), markdownCell(e.Err, 80), e.SuccessRate*100, e.OutputDir)
+			continue
+		}
+		fmt.Fprintf(&sb, "| %d | %s | %s | %s | %.2f (%d) | %d (%d) | %.2f (%d) | %.2f | %.2f%% | %s |\n",
+			e.Rank, markdownCell(e.Name, 60), e.Provider, markdownCell(e.Model, 60),
+			e.RPS, e.RPSRank, e.P95LatencyMs, e.P95Rank, e.Throughput, e.ThroughputRank,
+			e.AvgTTFTMs, e.SuccessRate*100, e.OutputDir)
+	}
+	return sb.String()
+}
@@ -0,0 +1,49 @@
+package runner
+
+import (
+	"testing"
+)
+
+func TestRankLeaderboard(t *testing.T) {
+	entries := []LeaderboardEntry{
+		{Name: "slow", RPS: 1, P95LatencyMs: 900, Throughput: 10},
+		{Name: "down", Err: "no successful requests"},
+		{Name: "fast", RPS: 5, P95LatencyMs: 200, Throughput: 40},
+		{Name: "mixed", RPS: 3, P95LatencyMs: 100, Throughput: 20},
+	}
+
+	lb := RankLeaderboard(entries)
+
+	want := []struct {
+		name                     string
+		rank, rps, p95, tputRank int
+	}{
+		{"fast", 1, 1, 2, 1},
+		{"mixed", 2, 2, 1, 2},
+		{"slow", 3, 3, 3, 3},
+		{"down", 0, 0, 0, 0},
+	}
+	if len(lb.Entries) != len(want) {
+		t.Fatalf("got %d entries, want %d", len(lb.Entries), len(want))
+	}
+	for i, w := range want {
+		e := lb.Entries[i]
+		if e.Name != w.name || e.Rank != w.rank || e.RPSRank != w.rps || e.P95Rank != w.p95 || e.ThroughputRank != w.tputRank {
+			t.Errorf("entry %d = %s rank %d (rps %d, p95 %d, throughput %d), want %s rank %d (rps %d, p95 %d, throughput %d)",
+				i, e.Name, e.Rank, e.RPSRank, e.P95Rank, e.ThroughputRank, w.name, w.rank, w.rps, w.p95, w.tputRank)
+		}
+	}
+}
+
+func TestRankLeaderboard_Ties(t *testing.T) {
+	lb := RankLeaderboard([]LeaderboardEntry{
+		{Name: "a", RPS: 2, P95LatencyMs: 100, Throughput: 10},
+		{Name: "b", RPS: 2, P95LatencyMs: 100, Throughput: 10},
+	})
+	for _, e := range lb.Entries {
+		if e.Rank != 1 || e.RPSRank != 1 || e.P95Rank != 1 || e.ThroughputRank != 1 {
+			t.Errorf("%s: rank %d (rps %d, p95 %d, throughput %d), want all 1",
+				e.Name, e.Rank, e.RPSRank, e.P95Rank, e.ThroughputRank)
+		}
+	}
+}
@@ -0,0 +1,285 @@
+// Package runner implements Markdown report generation.
+package runner
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/result"
+)
+
+// maxTimeseriesRows caps the timeseries table so long runs keep report.md readable.
+const maxTimeseriesRows = 120
+
+func (r *Runner) writeMarkdownReport(report *result.BenchmarkReport, path string) error {
+	return os.WriteFile(path, []byte(r.generateMarkdown(report)), 0644)
+}
+
+func (r *Runner) generateMarkdown(report *result.BenchmarkReport) string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "# LLM Benchmark Report\n\n")
+	if report.Aborted {
+		fmt.Fprintf(&sb, "> ⚠️ **Aborted:** %s. Statistics cover only the %d requests that ran.\n\n", report.AbortReason, report.TotalRequests)
+	}
+	if report.Snapshot {
+		fmt.Fprintf(&sb, "> ⏳ **Interim snapshot:** the run is still going. Statistics cover the %d requests completed after %.0fs.\n\n", report.TotalRequests, float64(report.WallTimeMs)/1000)
+	}
+
+	fmt.Fprintf(&sb, "## Configuration\n\n")
+	fmt.Fprintf(&sb, "| Setting | Value |\n")
+	fmt.Fprintf(&sb, "|---------|-------|\n")
+	if report.RunName != "" {
+		fmt.Fprintf(&sb, "| Run Name | %s |\n", report.RunName)
+	}
+	fmt.Fprintf(&sb, "| Provider | %s |\n", report.Provider)
+	fmt.Fprintf(&sb, "| URL | %s |\n", r.cfg.URL)
+	fmt.Fprintf(&sb, "| Model | %s |\n", report.Model)
+	fmt.Fprintf(&sb, "| Concurrency | %d |\n", r.cfg.Concurrency)
+	fmt.Fprintf(&sb, "| Requests | %d |\n", r.cfg.TotalRequests)
+	if w := report.WarmupReport; w != nil && r.cfg.WarmupStablePct > 0 {
+		state := "P95 latency stable"
+		if !w.WarmupStable {
+			state = "P95 latency not stable by -warmup-max"
+		}
+		fmt.Fprintf(&sb, "| Warmup | %d requests until stable within %g%% (%s; window P95s %v ms) |\n",
+			w.TotalRequests, r.cfg.WarmupStablePct, state, w.WarmupWindowP95Ms)
+	} else {
+		fmt.Fprintf(&sb, "| Warmup | %d |\n", r.cfg.Warmup)
+	}
+	fmt.Fprintf(&sb, "| Max Tokens | %d |\n", r.cfg.MaxTokens)
+	if r.cfg.IgnoreEOS {
+		fmt.Fprintf(&sb, "| Ignore EOS | on (completions run to max tokens where the server supports it) |\n")
+	} else if r.cfg.MinTokens > 0 {
+		fmt.Fprintf(&sb, "| Min Tokens | %d |\n", r.cfg.MinTokens)
+	}
+	if r.cfg.N > 1 {
+		fmt.Fprintf(&sb, "| Completions per Request (n) | %d (tokens and chars summed across completions) |\n", r.cfg.N)
+	}
+	fmt.Fprintf(&sb, "| Token Mode | %s |\n", report.TokenMode)
+	if report.KeepAliveDisabled {
+		fmt.Fprintf(&sb, "| Keep-Alive | disabled (latency includes TCP/TLS connection setup) |\n")
+	}
+	if report.CacheBusted {
+		fmt.Fprintf(&sb, "| Cache Busting | on (a unique nonce was appended to every prompt) |\n")
+	}
+	fmt.Fprintf(&sb, "| Started At | %s |\n", report.StartedAt)
+	if report.ReadyWaitMs > 0 {
+		fmt.Fprintf(&sb, "| Ready Wait | %.2f s (before the run started) |\n", float64(report.ReadyWaitMs)/1000.0)
+	}
+	fmt.Fprintf(&sb, "| Wall Time | %.2f s |\n\n", float64(report.WallTimeMs)/1000.0)
+
+	fmt.Fprintf(&sb, "## Summary\n\n")
+	fmt.Fprintf(&sb, "| Metric | Value |\n")
+	fmt.Fprintf(&sb, "|--------|-------|\n")
+	fmt.Fprintf(&sb, "| Success Rate | %.2f%% (%d/%d) |\n", report.SuccessRate*100, report.Success, report.TotalRequests)
+	if report.PartialCount > 0 {
+		fmt.Fprintf(&sb, "| Partial Streams | %d (content received, then failed) |\n", report.PartialCount)
+	}
+	if report.EmptyCount > 0 {
+		fmt.Fprintf(&sb, "| Empty Responses | %d (ended without content) |\n", report.EmptyCount)
+	}
+	if report.ClientSaturated {
+		fmt.Fprintf(&sb, "| ⚠️ Client Saturated | %s |\n", report.ClientStats.Reason)
+	}
+	if report.RetriedRequests > 0 {
+		fmt.Fprintf(&sb, "| Retried Requests | %d (%d extra attempts) |\n", report.RetriedRequests, report.RetryAttempts)
+	}
+	if r.cfg.StrictSSE {
+		fmt.Fprintf(&sb, "| SSE Violations | %d in %d requests |\n", report.SSEViolations, report.SSEViolationRequests)
+		if report.SSEViolationExample != "" {
+			fmt.Fprintf(&sb, "| First SSE Violation | `%s` |\n", report.SSEViolationExample)
+		}
+	}
+	fmt.Fprintf(&sb, "| RPS | %.2f |\n", report.RPS)
+	if report.TokenMode != "disabled" {
+		fmt.Fprintf(&sb, "| Throughput | %.2f %s/s |\n", report.TokenThroughput, report.TokenMode)
+		fmt.Fprintf(&sb, "| Prompt / Completion Tokens | %d / %d |\n", report.TotalPromptTokens, report.TotalCompletionTokens)
+		if report.EstimatedTokenCount > 0 {
+			fmt.Fprintf(&sb, "| Estimated Completion Tokens | %d requests (no usage from server) |\n", report.EstimatedTokenCount)
+		}
+		if report.ToolCallResponses > 0 {
+			fmt.Fprintf(&sb, "| Tool-Call Responses | %d (tool-call arguments counted as output) |\n", report.ToolCallResponses)
+		}
+		if report.EstimatedCostUSD != nil {
+			fmt.Fprintf(&sb, "| Estimated Cost | $%.6f ($%.4f per 1K requests, %.0f completion tokens per $) |\n",
+				*report.EstimatedCostUSD, report.CostPer1KRequestsUSD, report.CompletionTokensPerUSD)
+		}
+		fmt.Fprintf(&sb, "| Prefill Speed | %.2f tokens/s |\n", report.PrefillSpeed)
+		fmt.Fprintf(&sb, "| Decode Speed | %.2f %s/s |\n", report.DecodeSpeed, report.TokenMode)
+		if report.P50TokensPerSec > 0 {
+			fmt.Fprintf(&sb, "| Per-Request Decode Speed Mean / Geometric Mean | %.2f / %.2f %s/s |\n",
+				report.MeanTokensPerSec, report.GeoMeanTokensPerSec, report.TokenMode)
+			fmt.Fprintf(&sb, "| Per-Request Decode Speed P50 / P95 / P99 | %.2f / %.2f / %.2f %s/s |\n",
+				report.P50TokensPerSec, report.P95TokensPerSec, report.P99TokensPerSec, report.TokenMode)
+		}
+	}
+	if report.AvgLogprob != nil {
+		fmt.Fprintf(&sb, "| Avg Logprob | %.4f (least confident response %.4f, %d responses) |\n",
+			*report.AvgLogprob, *report.MinAvgLogprob, report.LogprobResponses)
+	}
+	if report.ValidJSONRate != nil {
+		fmt.Fprintf(&sb, "| Valid JSON | %.2f%% (%d/%d) |\n", *report.ValidJSONRate*100, report.ValidJSONCount, report.Success)
+	}
+	for _, s := range report.Scores {
+		fmt.Fprintf(&sb, "| Score `%s` | mean %.3f, pass %.2f%% (%d scored) |\n", s.Name, s.Mean, s.PassRate*100, s.Scored)
+	}
+	distinct := fmt.Sprint(report.DistinctResponses)
+	if report.DistinctResponsesCapped {
+		distinct = "≥ " + distinct
+	}
+	fmt.Fprintf(&sb, "| Distinct Responses | %s (most common seen %d times) |\n\n", distinct, report.TopResponseCount)
+
+	fmt.Fprintf(&sb, "## Percentiles (ms)\n\n")
+	if report.StreamingStats {
+		fmt.Fprintf(&sb, "_Percentiles are streaming (P²) estimates._\n\n")
+	}
+	fmt.Fprintf(&sb, "| Metric | Avg | P50 | P95 | P99 |\n")
+	fmt.Fprintf(&sb, "|--------|-----|-----|-----|-----|\n")
+	fmt.Fprintf(&sb, "| TTFT | %.2f | %d | %d | %d |\n", report.AvgTTFTMs, report.P50TTFTMs, report.P95TTFTMs, report.P99TTFTMs)
+	fmt.Fprintf(&sb, "| Latency | %.2f | %d | %d | %d |\n", report.AvgLatencyMs, report.P50LatencyMs, report.P95LatencyMs, report.P99LatencyMs)
+	fmt.Fprintf(&sb, "| Decode | %.2f | %d | %d | %d |\n", report.AvgDecodeMs, report.P50DecodeMs, report.P95DecodeMs, report.P99DecodeMs)
+	if report.AvgTTLTMs > 0 {
+		fmt.Fprintf(&sb, "| TTLT (time to last token) | %.2f | %d | %d | %d |\n", report.AvgTTLTMs, report.P50TTLTMs, report.P95TTLTMs, report.P99TTLTMs)
+		fmt.Fprintf(&sb, "\nStream tail after the last token (waiting for `[DONE]` and the stream to close): avg %.2f ms, P95 %d ms.\n",
+			report.AvgStreamTailMs, report.P95StreamTailMs)
+	}
+	sb.WriteString("\n")
+
+	if report.AvgServerFirstByteMs > 0 {
+		fmt.Fprintf(&sb, "## TTFT Breakdown (ms)\n\n")
+		fmt.Fprintf(&sb, "Averages from HTTP tracing. Connect and TLS apply only to the %d requests that opened a new connection; "+
+			"the rest of TTFT after the first response byte is server queueing and prefill on servers that send headers early.\n\n", report.NewConnections)
+		fmt.Fprintf(&sb, "| Phase | Avg |\n")
+		fmt.Fprintf(&sb, "|-------|-----|\n")
+		fmt.Fprintf(&sb, "| Connect (DNS + TCP) | %.2f |\n", report.AvgConnectMs)
+		fmt.Fprintf(&sb, "| TLS Handshake | %.2f |\n", report.AvgTLSMs)
+		fmt.Fprintf(&sb, "| Request Write | %.2f |\n", report.AvgRequestWriteMs)
+		fmt.Fprintf(&sb, "| Server First Byte | %.2f |\n", report.AvgServerFirstByteMs)
+		fmt.Fprintf(&sb, "| TTFT (total) | %.2f |\n\n", report.AvgTTFTMs)
+	}
+
+	if !report.StreamingStats && report.Success > 0 {
+		fmt.Fprintf(&sb, "## Latency Outliers\n\n")
+		fmt.Fprintf(&sb, "Requests with latency beyond median ± 3·MAD.\n\n")
+		fmt.Fprintf(&sb, "| Metric | Value |\n")
+		fmt.Fprintf(&sb, "|--------|-------|\n")
+		fmt.Fprintf(&sb, "| MAD | %.2f ms |\n", report.LatencyMADMs)
+		fmt.Fprintf(&sb, "| IQR (P75 - P25) | %.2f ms |\n", report.LatencyIQRMs)
+		fmt.Fprintf(&sb, "| Outliers | %d |\n", report.OutlierCount)
+		if len(report.OutlierIDs) > 0 {
+			fmt.Fprintf(&sb, "| Top Outlier IDs | %s |\n", strings.Join(report.OutlierIDs, ", "))
+		}
+		fmt.Fprintf(&sb, "\n")
+	}
+
+	if len(report.PromptBuckets) > 0 {
+		fmt.Fprintf(&sb, "## Latency by Prompt Length\n\n")
+		fmt.Fprintf(&sb, "Requests grouped by prompt length in %s; TTFT and latency in ms over the successful requests of each bucket.\n\n", report.PromptLengthUnit)
+		fmt.Fprintf(&sb, "| Prompt Length | Requests | Success | Avg TTFT | P50 TTFT | P95 TTFT | Avg Latency | P50 Latency | P95 Latency |\n")
+		fmt.Fprintf(&sb, "|---------------|----------|---------|----------|----------|----------|-------------|-------------|-------------|\n")
+		for _, b := range report.PromptBuckets {
+			fmt.Fprintf(&sb, "| %d–%d | %d | %d | %.2f | %d | %d | %.2f | %d | %d |\n", b.MinLength, b.MaxLength, b.Requests, b.Success,
+				b.AvgTTFTMs, b.P50TTFTMs, b.P95TTFTMs, b.AvgLatencyMs, b.P50LatencyMs, b.P95LatencyMs)
+		}
+		fmt.Fprintf(&sb, "\n")
+	}
+
+	if w := report.WarmupReport; w != nil && w.Success > 0 {
+		fmt.Fprintf(&sb, "## Warmup vs Steady State (ms)\n\n")
+		fmt.Fprintf(&sb, "| Metric | Warmup | Steady State | Ratio |\n")
+		fmt.Fprintf(&sb, "|--------|--------|--------------|-------|\n")
+		fmt.Fprintf(&sb, "| Avg TTFT | %.2f | %.2f | %s |\n", w.AvgTTFTMs, report.AvgTTFTMs, ratioCell(w.AvgTTFTMs, report.AvgTTFTMs))
+		fmt.Fprintf(&sb, "| P50 TTFT | %d | %d | %s |\n", w.P50TTFTMs, report.P50TTFTMs, ratioCell(float64(w.P50TTFTMs), float64(report.P50TTFTMs)))
+		fmt.Fprintf(&sb, "| Avg Latency | %.2f | %.2f | %s |\n", w.AvgLatencyMs, report.AvgLatencyMs, ratioCell(w.AvgLatencyMs, report.AvgLatencyMs))
+		fmt.Fprintf(&sb, "| P50 Latency | %d | %d | %s |\n", w.P50LatencyMs, report.P50LatencyMs, ratioCell(float64(w.P50LatencyMs), float64(report.P50LatencyMs)))
+		fmt.Fprintf(&sb, "| Success | %d/%d | %d/%d | |\n\n", w.Success, w.TotalRequests, report.Success, report.TotalRequests)
+	}
+
+	if n := len(report.Timeseries); n > 1 {
+		fmt.Fprintf(&sb, "## Timeseries (1s windows)\n\n")
+		if n > maxTimeseriesRows {
+			fmt.Fprintf(&sb, "_First %d of %d windows; see `timeseries` in summary.json or the chart in report.html._\n\n", maxTimeseriesRows, n)
+		}
+		fmt.Fprintf(&sb, "| Second | RPS | Failures | P95 Latency (ms) |\n")
+		fmt.Fprintf(&sb, "|--------|-----|----------|------------------|\n")
+		for _, w := range report.Timeseries[:min(n, maxTimeseriesRows)] {
+			fmt.Fprintf(&sb, "| %d | %.0f | %d | %d |\n", w.Second, w.RPS, w.Failure, w.P95LatencyMs)
+		}
+		fmt.Fprintf(&sb, "\n")
+	}
+
+	if len(report.FinishReasonCounts) > 0 {
+		fmt.Fprintf(&sb, "## Finish Reasons\n\n")
+		fmt.Fprintf(&sb, "| Reason | Count | Share |\n")
+		fmt.Fprintf(&sb, "|--------|-------|-------|\n")
+		reasons := make([]string, 0, len(report.FinishReasonCounts))
+		for reason := range report.FinishReasonCounts {
+			reasons = append(reasons, reason)
+		}
+		sort.Slice(reasons, func(i, j int) bool {
+			return report.FinishReasonCounts[reasons[i]] > report.FinishReasonCounts[reasons[j]]
+		})
+		for _, reason := range reasons {
+			count := report.FinishReasonCounts[reason]
+			fmt.Fprintf(&sb, "| %s | %d | %.1f%% |\n", reason, count, float64(count)/float64(report.Success)*100)
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(report.HTTPStatusCounts) > 0 {
+		fmt.Fprintf(&sb, "## HTTP Errors\n\n")
+		fmt.Fprintf(&sb, "| Status | Count | Share |\n")
+		fmt.Fprintf(&sb, "|--------|-------|-------|\n")
+		codes := make([]int, 0, len(report.HTTPStatusCounts))
+		for code := range report.HTTPStatusCounts {
+			codes = append(codes, code)
+		}
+		sort.Ints(codes)
+		for _, code := range codes {
+			count := report.HTTPStatusCounts[code]
+			fmt.Fprintf(&sb, "| %d | %d | %.1f%% |\n", code, count, float64(count)/float64(report.TotalRequests)*100)
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(report.APIKeys) > 0 {
+		fmt.Fprintf(&sb, "## API Keys\n\n")
+		fmt.Fprintf(&sb, "| Key | Requests | Success Rate | Failures | HTTP 429 |\n")
+		fmt.Fprintf(&sb, "|-----|----------|--------------|----------|----------|\n")
+		for _, k := range report.APIKeys {
+			fmt.Fprintf(&sb, "| #%d | %d | %.2f%% | %d | %d |\n", k.Index, k.Requests, k.SuccessRate*100, k.Failures, k.RateLimited)
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(report.ErrorsTopN) > 0 {
+		fmt.Fprintf(&sb, "## Top Errors\n\n")
+		fmt.Fprintf(&sb, "| Count | Error |\n")
+		fmt.Fprintf(&sb, "|-------|-------|\n")
+		for _, e := range report.ErrorsTopN {
+			fmt.Fprintf(&sb, "| %d | %s |\n", e.Count, markdownCell(e.Key, 200))
+		}
+		sb.WriteString("\n")
+	}
+
+	return sb.String()
+}
+
+// ratioCell formats warmup/steady as "1.85x", or "-" when steady is zero.
+func ratioCell(warmup, steady float64) string {
+	if steady <= 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.2fx", warmup/steady)
+}
+
+// markdownCell makes s safe for a single table cell and truncates it to maxLen bytes.
+func markdownCell(s string, maxLen int) string {
+	s = strings.ReplaceAll(s, "\r", " ")
+	s = strings.ReplaceAll(s, "\n", " ")
+	s = strings.ReplaceAll(s, "|", "\\|")
+	return truncateString(s, maxLen)
+}
@@ -0,0 +1,51 @@
+package runner
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/result"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/stats"
+)
+
+// csvPercentiles are the rows of percentiles.csv.
+var csvPercentiles = []float64{1, 5, 10, 25, 50, 75, 90, 95, 99, 99.9}
+
+// writePercentileCSV writes percentiles.csv (-percentile-csv): one row per
+// percentile with the TTFT and latency at that point, computed from the full
+// distributions, for plotting with external tools.
+func writePercentileCSV(report *result.BenchmarkReport, path string) error {
+	ttfts := msToFloat(report.TTFTDistribution)
+	latencies := msToFloat(report.LatencyDistribution)
+
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create percentile CSV: %w", err)
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	w.Write([]string{"percentile", "ttft_ms", "latency_ms"})
+	for _, p := range csvPercentiles {
+		w.Write([]string{
+			strconv.FormatFloat(p, 'f', -1, 64),
+			strconv.FormatFloat(stats.PercentileFloat(ttfts, p), 'f', 2, 64),
+			strconv.FormatFloat(stats.PercentileFloat(latencies, p), 'f', 2, 64),
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("failed to write percentile CSV: %w", err)
+	}
+	return nil
+}
+
+func msToFloat(values []int64) []float64 {
+	out := make([]float64, len(values))
+	for i, v := range values {
+		out[i] = float64(v)
+	}
+	return out
+}
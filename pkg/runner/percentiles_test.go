@@ -0,0 +1,45 @@
+package runner
+
+import (
+	"encoding/csv"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/result"
+)
+
+func TestWritePercentileCSV(t *testing.T) {
+	report := &result.BenchmarkReport{
+		TTFTDistribution:    []int64{40, 10, 30, 20, 50},
+		LatencyDistribution: []int64{100, 200, 300, 400, 500},
+	}
+	path := filepath.Join(t.TempDir(), "percentiles.csv")
+	if err := writePercentileCSV(report, path); err != nil {
+		t.Fatalf("writePercentileCSV: %v", err)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	rows, err := csv.NewReader(file).ReadAll()
+	if err != nil {
+		t.Fatalf("invalid CSV: %v", err)
+	}
+	if len(rows) != len(csvPercentiles)+1 {
+		t.Fatalf("got %d rows, want a header and %d percentiles", len(rows), len(csvPercentiles))
+	}
+	want := map[string][]string{
+		"percentile": {"ttft_ms", "latency_ms"},
+		"50":         {"30.00", "300.00"},
+		"75":         {"40.00", "400.00"},
+		"99.9":       {"49.96", "499.60"},
+	}
+	for _, row := range rows {
+		if w, ok := want[row[0]]; ok && (row[1] != w[0] || row[2] != w[1]) {
+			t.Errorf("row %v, want %s,%s", row, w[0], w[1])
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package runner
+
+import (
+	"sort"
+	"unicode/utf8"
+
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/result"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/workload"
+)
+
+// firstPromptBucket is the upper bound of the shortest prompt-length bucket;
+// each following bucket doubles it.
+const firstPromptBucket = 128
+
+// promptLength returns the length of the prompt sent for input: characters
+// with -token-mode chars, otherwise tokens estimated with the built-in
+// tokenizer, so failed requests without usage are measured the same way.
+func (r *Runner) promptLength(input workload.WorkloadInput) int {
+	if r.cfg.TokenMode != "chars" {
+		return input.PromptTokens(r.cfg.SystemPrompt, r.cfg.ModelName)
+	}
+	n := 0
+	for _, msg := range input.ToMessagesWithSystem(r.cfg.SystemPrompt) {
+		n += utf8.RuneCountInString(msg.Content)
+	}
+	return n
+}
+
+// promptLengthUnit names the unit of promptLength.
+func (r *Runner) promptLengthUnit() string {
+	if r.cfg.TokenMode == "chars" {
+		return "chars"
+	}
+	return "tokens"
+}
+
+// promptBucket accumulates the requests of one prompt-length bucket.
+type promptBucket struct {
+	requests int
+	success  int
+	ttfts    durationSeries
+	latency  durationSeries
+}
+
+// promptBucketIndex returns the bucket of a prompt length: 0 for up to
+// firstPromptBucket, then one bucket per doubling.
+func promptBucketIndex(length int) int {
+	i := 0
+	for upper := firstPromptBucket; length > upper; upper *= 2 {
+		i++
+	}
+	return i
+}
+
+// promptBucketBounds returns the inclusive length range of bucket i.
+func promptBucketBounds(i int) (lo, hi int) {
+	hi = firstPromptBucket << i
+	if i == 0 {
+		return 0, hi
+	}
+	return hi/2 + 1, hi
+}
+
+func (a *aggregator) addToPromptBucket(res result.RequestResult) {
+	if res.PromptLength <= 0 {
+		return
+	}
+	i := promptBucketIndex(res.PromptLength)
+	b := a.promptBuckets[i]
+	if b == nil {
+		if a.promptBuckets == nil {
+			a.promptBuckets = make(map[int]*promptBucket)
+		}
+		b = &promptBucket{ttfts: a.newSeries(), latency: a.newSeries()}
+		a.promptBuckets[i] = b
+	}
+	b.requests++
+	if res.IsSuccess() {
+		b.success++
+		b.ttfts.Add(res.TTFT)
+		b.latency.Add(res.Latency)
+	}
+}
+
+// promptBucketStats returns the non-empty buckets from shortest to longest,
+// or nil when every prompt fell into one bucket and there is nothing to compare.
+func (a *aggregator) promptBucketStats() []result.PromptBucketStat {
+	if len(a.promptBuckets) < 2 {
+		return nil
+	}
+	indexes := make([]int, 0, len(a.promptBuckets))
+	for i := range a.promptBuckets {
+		indexes = append(indexes, i)
+	}
+	sort.Ints(indexes)
+
+	stats := make([]result.PromptBucketStat, 0, len(indexes))
+	for _, i := range indexes {
+		b := a.promptBuckets[i]
+		lo, hi := promptBucketBounds(i)
+		stat := result.PromptBucketStat{MinLength: lo, MaxLength: hi, Requests: b.requests, Success: b.success}
+		if b.success > 0 {
+			stat.AvgTTFTMs = b.ttfts.AverageMs()
+			stat.P50TTFTMs = b.ttfts.PercentileMs(50)
+			stat.P95TTFTMs = b.ttfts.PercentileMs(95)
+			stat.AvgLatencyMs = b.latency.AverageMs()
+			stat.P50LatencyMs = b.latency.PercentileMs(50)
+			stat.P95LatencyMs = b.latency.PercentileMs(95)
+		}
+		stats = append(stats, stat)
+	}
+	return stats
+}
@@ -0,0 +1,79 @@
+package runner
+
+import (
+	"testing"
+	"time"
+
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/config"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/result"
+)
+
+func TestPromptBucketIndex(t *testing.T) {
+	tests := []struct {
+		length int
+		want   int
+	}{
+		{1, 0},
+		{128, 0},
+		{129, 1},
+		{256, 1},
+		{257, 2},
+		{5000, 6}, // 4097–8192
+	}
+	for _, tt := range tests {
+		got := promptBucketIndex(tt.length)
+		if got != tt.want {
+			t.Errorf("promptBucketIndex(%d) = %d, want %d", tt.length, got, tt.want)
+		}
+		if lo, hi := promptBucketBounds(got); tt.length < lo || tt.length > hi {
+			t.Errorf("length %d outside its bucket %d–%d", tt.length, lo, hi)
+		}
+	}
+}
+
+func TestBuildReport_PromptBuckets(t *testing.T) {
+	ok := func(length int, latencyMs int) result.RequestResult {
+		return result.RequestResult{
+			Status:       result.StatusOK,
+			PromptLength: length,
+			TTFT:         time.Duration(latencyMs/10) * time.Millisecond,
+			Latency:      time.Duration(latencyMs) * time.Millisecond,
+		}
+	}
+	r := New(config.DefaultConfig(), &scriptedProvider{})
+
+	// Every prompt in one bucket: nothing to compare
+	agg := newAggregator(false)
+	agg.add(ok(50, 100))
+	agg.add(ok(60, 120))
+	if report := r.buildReport(agg, time.Second); report.PromptBuckets != nil {
+		t.Errorf("PromptBuckets = %+v, want none for a single bucket", report.PromptBuckets)
+	}
+
+	agg = newAggregator(false)
+	agg.add(ok(50, 100))
+	agg.add(ok(200, 300))
+	agg.add(ok(250, 500))
+	agg.add(result.RequestResult{Status: result.StatusHTTPError, PromptLength: 3000, Err: "HTTP 400: too long"})
+	report := r.buildReport(agg, time.Second)
+
+	want := []result.PromptBucketStat{
+		{MinLength: 0, MaxLength: 128, Requests: 1, Success: 1, AvgTTFTMs: 10, P50TTFTMs: 10, P95TTFTMs: 10, AvgLatencyMs: 100, P50LatencyMs: 100, P95LatencyMs: 100},
+		{MinLength: 129, MaxLength: 256, Requests: 2, Success: 2, AvgTTFTMs: 40, P50TTFTMs: 40, P95TTFTMs: 49, AvgLatencyMs: 400, P50LatencyMs: 400, P95LatencyMs: 490},
+		{MinLength: 2049, MaxLength: 4096, Requests: 1},
+	}
+	if len(report.PromptBuckets) != len(want) {
+		t.Fatalf("PromptBuckets = %+v, want %d buckets", report.PromptBuckets, len(want))
+	}
+	for i, b := range report.PromptBuckets {
+		if b != want[i] {
+			t.Errorf("bucket %d = %+v, want %+v", i, b, want[i])
+		}
+	}
+	if report.PromptLengthUnit != "tokens" {
+		t.Errorf("PromptLengthUnit = %q, want tokens", report.PromptLengthUnit)
+	}
+	if got := report.PromptLengthDistribution; len(got) != 3 || got[1] != 200 {
+		t.Errorf("PromptLengthDistribution = %v, want the successful requests' lengths in latency order", got)
+	}
+}
@@ -0,0 +1,178 @@
+package runner
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/config"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/provider"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/result"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/workload"
+)
+
+// resultRecord is one line of results.jsonl as written by resultsWriter.
+type resultRecord struct {
+	RequestID       string               `json:"request_id"`
+	Seq             int                  `json:"seq"`
+	Status          result.RequestStatus `json:"status"`
+	TTFTMs          int64                `json:"ttft_ms"`
+	LatencyMs       int64                `json:"latency_ms"`
+	DecodeMs        int64                `json:"decode_ms"`
+	TTLTMs          int64                `json:"ttlt_ms"`
+	InTokens        int                  `json:"in_tokens"`
+	PromptLength    int                  `json:"prompt_length"`
+	OutTokens       int                  `json:"out_tokens"`
+	OutChars        int                  `json:"out_chars"`
+	StartTS         time.Time            `json:"start_ts"`
+	FirstContentTS  time.Time            `json:"first_content_ts"`
+	EndTS           time.Time            `json:"end_ts"`
+	Provider        string               `json:"provider"`
+	Err             string               `json:"err"`
+	ResponseHash    string               `json:"response_hash"`
+	FinishReason    string               `json:"finish_reason"`
+	ValidJSON       *bool                `json:"valid_json"`
+	TokensEstimated bool                 `json:"tokens_estimated"`
+	ToolCallChars   int                  `json:"tool_call_chars"`
+	ThinkChars      int                  `json:"think_chars"`
+	ConnectMs       float64              `json:"connect_ms"`
+	TLSMs           float64              `json:"tls_ms"`
+	RequestWriteMs  float64              `json:"request_write_ms"`
+	FirstByteMs     float64              `json:"server_first_byte_ms"`
+	ConnReused      bool                 `json:"conn_reused"`
+	Attempts        int                  `json:"attempts"`
+	Choices         int                  `json:"choices"`
+	SSEViolations   int                  `json:"sse_violations"`
+	SSEFirstViol    string               `json:"sse_first_violation"`
+	AttemptLatMs    []float64            `json:"attempt_latencies_ms"`
+	AvgLogprob      *float64             `json:"avg_logprob"`
+	Scores          map[string]float64   `json:"scores"`
+	APIKeyIndex     int                  `json:"api_key_index"`
+}
+
+func (rec resultRecord) toResult() result.RequestResult {
+	res := result.RequestResult{
+		ID:                rec.RequestID,
+		Seq:               rec.Seq,
+		Status:            rec.Status,
+		TTFT:              time.Duration(rec.TTFTMs) * time.Millisecond,
+		Latency:           time.Duration(rec.LatencyMs) * time.Millisecond,
+		Decode:            time.Duration(rec.DecodeMs) * time.Millisecond,
+		TTLT:              time.Duration(rec.TTLTMs) * time.Millisecond,
+		InTokens:          rec.InTokens,
+		PromptLength:      rec.PromptLength,
+		OutTokens:         rec.OutTokens,
+		OutChars:          rec.OutChars,
+		Err:               rec.Err,
+		ResponseHash:      rec.ResponseHash,
+		FinishReason:      rec.FinishReason,
+		TokensEstimated:   rec.TokensEstimated,
+		ToolCallChars:     rec.ToolCallChars,
+		ThinkChars:        rec.ThinkChars,
+		Connect:           msDuration(rec.ConnectMs),
+		TLS:               msDuration(rec.TLSMs),
+		RequestWrite:      msDuration(rec.RequestWriteMs),
+		ServerFirstByte:   msDuration(rec.FirstByteMs),
+		ConnReused:        rec.ConnReused,
+		Attempts:          rec.Attempts,
+		Choices:           rec.Choices,
+		SSEViolations:     rec.SSEViolations,
+		SSEFirstViolation: rec.SSEFirstViol,
+		AttemptLatencies:  rec.AttemptLatMs,
+		AvgLogprob:        rec.AvgLogprob,
+		Scores:            rec.Scores,
+		APIKeyIndex:       rec.APIKeyIndex,
+		StartTime:         rec.StartTS,
+		FirstContentTime:  rec.FirstContentTS,
+		EndTime:           rec.EndTS,
+	}
+	if rec.ValidJSON != nil {
+		res.ValidJSON = *rec.ValidJSON
+	}
+	return res
+}
+
+// replayProvider stands in for the provider that produced a results file.
+type replayProvider struct {
+	name string
+}
+
+func (p *replayProvider) Name() string { return p.name }
+
+func (p *replayProvider) StreamChat(ctx context.Context, cfg *config.GlobalConfig, input workload.WorkloadInput) (<-chan provider.StreamEvent, error) {
+	return nil, fmt.Errorf("replay provider cannot send requests")
+}
+
+// Replay rebuilds the report from a results.jsonl file without contacting the
+// API and writes it to cfg.OutputDir. Timings are restored at millisecond
+// precision and raw frame samples are not available.
+func Replay(cfg *config.GlobalConfig, resultsPath string) (*result.BenchmarkReport, error) {
+	file, err := os.Open(resultsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open results file: %w", err)
+	}
+	defer file.Close()
+
+	agg := newAggregator(cfg.StreamingStats)
+	providerName := ""
+	var first, last time.Time
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 1024*1024), 1024*1024) // 1MB buffer
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var rec resultRecord
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			return nil, fmt.Errorf("failed to parse results line %d: %w", lineNum, err)
+		}
+		if providerName == "" {
+			providerName = rec.Provider
+		}
+		if rec.ValidJSON != nil {
+			cfg.JSONMode = true
+		}
+		if !rec.StartTS.IsZero() && (first.IsZero() || rec.StartTS.Before(first)) {
+			first = rec.StartTS
+		}
+		if rec.EndTS.After(last) {
+			last = rec.EndTS
+		}
+		agg.add(rec.toResult())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read results file: %w", err)
+	}
+	if agg.total == 0 {
+		return nil, fmt.Errorf("no results found in %s", resultsPath)
+	}
+
+	cfg.TotalRequests = agg.total
+	r := New(cfg, &replayProvider{name: providerName})
+
+	var wallTime time.Duration
+	if !first.IsZero() && last.After(first) {
+		wallTime = last.Sub(first)
+	}
+	report := r.buildReport(agg, wallTime)
+	if !first.IsZero() {
+		report.StartedAt = first.Format(time.RFC3339)
+	}
+
+	if err := os.MkdirAll(cfg.OutputDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+	if err := r.writeOutput(report); err != nil {
+		return nil, fmt.Errorf("failed to write output: %w", err)
+	}
+	return report, nil
+}
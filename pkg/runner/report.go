@@ -4,65 +4,478 @@ package runner
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"os"
 	"path/filepath"
+	"slices"
 	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/brianxiadong/llm-benchmark-kit/pkg/result"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/scorer"
 	"github.com/brianxiadong/llm-benchmark-kit/pkg/stats"
 )
 
-func (r *Runner) generateReport(results []result.RequestResult, wallTime time.Duration) *result.BenchmarkReport {
-	report := &result.BenchmarkReport{
-		Provider:      r.provider.Name(),
-		Model:         r.cfg.ModelName,
-		StartedAt:     time.Now().Format(time.RFC3339),
-		WallTimeMs:    wallTime.Milliseconds(),
-		TotalRequests: len(results),
-		TokenMode:     r.cfg.TokenMode,
-	}
-
-	// Separate successful and failed requests
-	var successResults []result.RequestResult
-	var ttfts []time.Duration
-	var latencies []time.Duration
-	var decodes []time.Duration
-	var totalTokens int
-	var totalInTokens int
-	var totalChars int
-	errorCounts := make(map[string]int)
-
-	for _, res := range results {
-		if res.IsSuccess() {
-			report.Success++
-			successResults = append(successResults, res)
-			ttfts = append(ttfts, res.TTFT)
-			latencies = append(latencies, res.Latency)
-			if res.Decode > 0 {
-				decodes = append(decodes, res.Decode)
-			}
-			totalTokens += res.OutTokens
-			totalInTokens += res.InTokens
-			totalChars += res.OutChars
+// durationSeries is a set of durations summarized as average and percentiles.
+type durationSeries interface {
+	Add(d time.Duration)
+	Count() int
+	AverageMs() float64
+	PercentileMs(p float64) int64
+}
+
+// exactDurations keeps every sample for exact percentiles and distributions.
+type exactDurations []time.Duration
+
+func (e *exactDurations) Add(d time.Duration)          { *e = append(*e, d) }
+func (e *exactDurations) Count() int                   { return len(*e) }
+func (e *exactDurations) AverageMs() float64           { return stats.AverageMs(*e) }
+func (e *exactDurations) PercentileMs(p float64) int64 { return stats.PercentileMs(*e, p) }
+
+// speedSeries is a set of per-request speeds summarized as means and percentiles.
+type speedSeries interface {
+	Add(v float64)
+	Count() int
+	Mean() float64
+	GeoMean() float64
+	Percentile(p float64) float64
+}
+
+// exactSpeeds keeps every sample for exact percentiles.
+type exactSpeeds []float64
+
+func (e *exactSpeeds) Add(v float64)                { *e = append(*e, v) }
+func (e *exactSpeeds) Count() int                   { return len(*e) }
+func (e *exactSpeeds) GeoMean() float64             { return stats.GeometricMean(*e) }
+func (e *exactSpeeds) Percentile(p float64) float64 { return stats.PercentileFloat(*e, p) }
+
+func (e *exactSpeeds) Mean() float64 {
+	if len(*e) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, v := range *e {
+		sum += v
+	}
+	return sum / float64(len(*e))
+}
 
-			// Capture first sample
-			if report.FirstContentRaw == "" && res.FirstContentRaw != "" {
-				report.FirstContentRaw = res.FirstContentRaw
+// streamingSpeeds estimates P50/P95/P99 without retaining samples. The means
+// are exact: only running sums are kept.
+type streamingSpeeds struct {
+	count         int
+	sum           float64
+	logSum        float64 // Sum of ln(v) over positive samples, for GeoMean
+	logCount      int
+	p50, p95, p99 *stats.StreamingPercentile
+}
+
+func newStreamingSpeeds() *streamingSpeeds {
+	return &streamingSpeeds{
+		p50: stats.NewStreamingPercentile(50),
+		p95: stats.NewStreamingPercentile(95),
+		p99: stats.NewStreamingPercentile(99),
+	}
+}
+
+func (s *streamingSpeeds) Add(v float64) {
+	s.count++
+	s.sum += v
+	if v > 0 {
+		s.logSum += math.Log(v)
+		s.logCount++
+	}
+	s.p50.Add(v)
+	s.p95.Add(v)
+	s.p99.Add(v)
+}
+
+func (s *streamingSpeeds) Count() int { return s.count }
+
+func (s *streamingSpeeds) Mean() float64 {
+	if s.count == 0 {
+		return 0
+	}
+	return s.sum / float64(s.count)
+}
+
+// GeoMean matches stats.GeometricMean: samples <= 0 are skipped.
+func (s *streamingSpeeds) GeoMean() float64 {
+	if s.logCount == 0 {
+		return 0
+	}
+	return math.Exp(s.logSum / float64(s.logCount))
+}
+
+// Percentile returns the estimate for 50, 95 or 99; other values return 0.
+func (s *streamingSpeeds) Percentile(p float64) float64 {
+	switch p {
+	case 50:
+		return s.p50.Value()
+	case 95:
+		return s.p95.Value()
+	case 99:
+		return s.p99.Value()
+	}
+	return 0
+}
+
+// aggregator accumulates request results into report statistics one at a time.
+// In streaming mode percentiles are estimated and no per-request samples are kept.
+type aggregator struct {
+	streaming bool
+
+	total      int
+	success    int
+	failure    int
+	partial    int
+	empty      int
+	ttfts      durationSeries
+	latency    durationSeries
+	latIDs     []string // Request IDs in latency sample order (exact mode only)
+	promptLens []int    // Prompt lengths in latency sample order (exact mode only)
+	decodes    durationSeries
+	ttlts      durationSeries
+	tails      durationSeries // Latency - TTLT
+	outToks    int
+	inToks     int
+	outChars   int
+
+	// Per-request decode speeds; the report picks one according to the token mode
+	tokenSpeeds speedSeries // completion tokens / decode seconds
+	charSpeeds  speedSeries // output chars / decode seconds
+
+	errorCounts   map[string]int
+	httpStatuses  map[int]int
+	finishReasons map[string]int
+	validJSON     int
+	logprobSum    float64 // Sum of per-response mean logprobs
+	logprobCount  int
+	minLogprob    float64
+	estimatedToks int
+	toolCallResps int
+	retried       int
+	retryAttempts int
+	sseViolations int
+	sseViolReqs   int
+	sseExample    string
+	scores        map[string]*scoreTally // Keyed by scorer name
+	keys          map[int]*result.APIKeyStat
+
+	// Network phases of successful traced requests
+	traced         int
+	newConns       int
+	connectSum     time.Duration
+	tlsSum         time.Duration
+	writeSum       time.Duration
+	firstByteSum   time.Duration
+	responseCounts map[string]int // Response hash -> occurrences
+	untracked      int            // Responses not counted because responseCounts was full (streaming mode)
+
+	windows       map[int64]*window     // Completion second (Unix) -> window
+	promptBuckets map[int]*promptBucket // Prompt-length bucket index -> bucket
+
+	firstContentRaw    string
+	middleFramesRaw    []string
+	middleFrameNumbers []int
+	finalFrameRaw      string
+}
+
+// window accumulates the results that completed within one second.
+type window struct {
+	success int
+	failure int
+	latency durationSeries
+}
+
+// scoreTally accumulates one scorer's ratings.
+type scoreTally struct {
+	sum    float64
+	passed int
+	count  int
+}
+
+func (a *aggregator) addKey(res result.RequestResult) {
+	s, ok := a.keys[res.APIKeyIndex]
+	if !ok {
+		if a.keys == nil {
+			a.keys = make(map[int]*result.APIKeyStat)
+		}
+		s = &result.APIKeyStat{Index: res.APIKeyIndex}
+		a.keys[res.APIKeyIndex] = s
+	}
+	s.Requests++
+	if !res.IsSuccess() {
+		s.Failures++
+		if httpStatusCode(res.Err) == 429 {
+			s.RateLimited++
+		}
+	}
+}
+
+func (a *aggregator) addScore(name string, score float64) {
+	t, ok := a.scores[name]
+	if !ok {
+		if a.scores == nil {
+			a.scores = make(map[string]*scoreTally)
+		}
+		t = &scoreTally{}
+		a.scores[name] = t
+	}
+	t.sum += score
+	t.count++
+	if score >= scorer.PassThreshold {
+		t.passed++
+	}
+}
+
+// maxTrackedResponses bounds the distinct responses counted in streaming mode.
+// Once reached, only responses already seen are counted, so a response that
+// repeats from early on (a cache hit or canned reply) is still caught.
+const maxTrackedResponses = 10000
+
+func (a *aggregator) countResponse(hash string) {
+	if _, ok := a.responseCounts[hash]; !ok && a.streaming && len(a.responseCounts) >= maxTrackedResponses {
+		a.untracked++
+		return
+	}
+	a.responseCounts[hash]++
+}
+
+func newAggregator(streaming bool) *aggregator {
+	a := &aggregator{
+		streaming:      streaming,
+		errorCounts:    make(map[string]int),
+		httpStatuses:   make(map[int]int),
+		finishReasons:  make(map[string]int),
+		responseCounts: make(map[string]int),
+		windows:        make(map[int64]*window),
+	}
+	if streaming {
+		a.ttfts = stats.NewStreamingDurations()
+		a.latency = stats.NewStreamingDurations()
+		a.decodes = stats.NewStreamingDurations()
+		a.ttlts = stats.NewStreamingDurations()
+		a.tails = stats.NewStreamingDurations()
+		a.tokenSpeeds = newStreamingSpeeds()
+		a.charSpeeds = newStreamingSpeeds()
+	} else {
+		a.ttfts = &exactDurations{}
+		a.latency = &exactDurations{}
+		a.decodes = &exactDurations{}
+		a.ttlts = &exactDurations{}
+		a.tails = &exactDurations{}
+		a.tokenSpeeds = &exactSpeeds{}
+		a.charSpeeds = &exactSpeeds{}
+	}
+	return a
+}
+
+func (a *aggregator) newSeries() durationSeries {
+	if a.streaming {
+		return stats.NewStreamingDurations()
+	}
+	return &exactDurations{}
+}
+
+func (a *aggregator) add(res result.RequestResult) {
+	a.total++
+	if res.APIKeyIndex > 0 {
+		a.addKey(res)
+	}
+	if res.Attempts > 1 {
+		a.retried++
+		a.retryAttempts += res.Attempts - 1
+	}
+	if res.SSEViolations > 0 {
+		a.sseViolations += res.SSEViolations
+		a.sseViolReqs++
+		if a.sseExample == "" {
+			a.sseExample = res.SSEFirstViolation
+		}
+	}
+	a.addToWindow(res)
+	a.addToPromptBucket(res)
+	if res.IsSuccess() {
+		a.success++
+		a.ttfts.Add(res.TTFT)
+		a.latency.Add(res.Latency)
+		if !a.streaming {
+			a.latIDs = append(a.latIDs, res.ID)
+			a.promptLens = append(a.promptLens, res.PromptLength)
+		}
+		if res.Decode > 0 {
+			a.decodes.Add(res.Decode)
+			if res.OutTokens > 0 {
+				a.tokenSpeeds.Add(float64(res.OutTokens) / res.Decode.Seconds())
 			}
-			if len(report.MiddleFramesRaw) == 0 && len(res.MiddleFramesRaw) > 0 {
-				report.MiddleFramesRaw = res.MiddleFramesRaw
+			if res.OutChars > 0 {
+				a.charSpeeds.Add(float64(res.OutChars) / res.Decode.Seconds())
+			}
+		}
+		if res.TTLT > 0 {
+			a.ttlts.Add(res.TTLT)
+			a.tails.Add(res.Latency - res.TTLT)
+		}
+		a.outToks += res.OutTokens
+		a.inToks += res.InTokens
+		a.outChars += res.OutChars
+		if res.ResponseHash != "" {
+			a.countResponse(res.ResponseHash)
+		}
+		reason := res.FinishReason
+		if reason == "" {
+			reason = "unknown"
+		}
+		a.finishReasons[reason]++
+		if res.ValidJSON {
+			a.validJSON++
+		}
+		for name, score := range res.Scores {
+			a.addScore(name, score)
+		}
+		if res.AvgLogprob != nil {
+			if a.logprobCount == 0 || *res.AvgLogprob < a.minLogprob {
+				a.minLogprob = *res.AvgLogprob
 			}
-			if report.FinalFrameRaw == "" && res.FinalFrameRaw != "" {
-				report.FinalFrameRaw = res.FinalFrameRaw
+			a.logprobSum += *res.AvgLogprob
+			a.logprobCount++
+		}
+		if res.TokensEstimated {
+			a.estimatedToks++
+		}
+		if res.ToolCallChars > 0 {
+			a.toolCallResps++
+		}
+		if res.ServerFirstByte > 0 {
+			a.traced++
+			a.writeSum += res.RequestWrite
+			a.firstByteSum += res.ServerFirstByte
+			if !res.ConnReused {
+				a.newConns++
+				a.connectSum += res.Connect
+				a.tlsSum += res.TLS
 			}
-		} else {
-			report.Failure++
-			errKey := string(res.Status)
-			if res.Err != "" {
-				errKey = fmt.Sprintf("%s: %s", res.Status, res.Err)
+		}
+
+		// Capture first sample
+		if a.firstContentRaw == "" && res.FirstContentRaw != "" {
+			a.firstContentRaw = res.FirstContentRaw
+		}
+		if len(a.middleFramesRaw) == 0 && len(res.MiddleFramesRaw) > 0 {
+			a.middleFramesRaw = res.MiddleFramesRaw
+			a.middleFrameNumbers = res.MiddleFrameNumbers
+		}
+		if a.finalFrameRaw == "" && res.FinalFrameRaw != "" {
+			a.finalFrameRaw = res.FinalFrameRaw
+		}
+	} else {
+		a.failure++
+		switch res.Status {
+		case result.StatusPartial:
+			a.partial++
+		case result.StatusEmpty:
+			a.empty++
+		}
+		errKey := string(res.Status)
+		if res.Err != "" {
+			errKey = fmt.Sprintf("%s: %s", res.Status, res.Err)
+		}
+		a.errorCounts[errKey]++
+		if code := httpStatusCode(res.Err); code > 0 {
+			a.httpStatuses[code]++
+		}
+	}
+}
+
+func (a *aggregator) addToWindow(res result.RequestResult) {
+	if res.EndTime.IsZero() {
+		return
+	}
+	key := res.EndTime.Unix()
+	w := a.windows[key]
+	if w == nil {
+		w = &window{latency: a.newSeries()}
+		a.windows[key] = w
+	}
+	if res.IsSuccess() {
+		w.success++
+		w.latency.Add(res.Latency)
+	} else {
+		w.failure++
+	}
+}
+
+// timeseries returns one entry per second from the first to the last
+// completion; seconds without completions are included as zeros.
+func (a *aggregator) timeseries() []result.WindowStat {
+	if len(a.windows) == 0 {
+		return nil
+	}
+	keys := make([]int64, 0, len(a.windows))
+	for key := range a.windows {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	first, last := keys[0], keys[len(keys)-1]
+	series := make([]result.WindowStat, 0, last-first+1)
+	for key := first; key <= last; key++ {
+		stat := result.WindowStat{Second: int(key - first)}
+		if w := a.windows[key]; w != nil {
+			stat.Success = w.success
+			stat.Failure = w.failure
+			stat.RPS = float64(w.success)
+			if w.latency.Count() > 0 {
+				stat.P95LatencyMs = w.latency.PercentileMs(95)
 			}
-			errorCounts[errKey]++
+		}
+		series = append(series, stat)
+	}
+	return series
+}
+
+func (r *Runner) buildReport(agg *aggregator, wallTime time.Duration) *result.BenchmarkReport {
+	report := &result.BenchmarkReport{
+		RunName:            r.cfg.RunName,
+		Provider:           r.provider.Name(),
+		Model:              r.cfg.ModelName,
+		StartedAt:          time.Now().Format(time.RFC3339),
+		WallTimeMs:         wallTime.Milliseconds(),
+		TotalRequests:      agg.total,
+		Success:            agg.success,
+		Failure:            agg.failure,
+		PartialCount:       agg.partial,
+		EmptyCount:         agg.empty,
+		TokenMode:          r.cfg.TokenMode,
+		KeepAliveDisabled:  r.cfg.NoKeepAlive,
+		CacheBusted:        r.cfg.BustCache,
+		StreamingStats:     agg.streaming,
+		FirstContentRaw:    agg.firstContentRaw,
+		MiddleFramesRaw:    agg.middleFramesRaw,
+		MiddleFrameNumbers: agg.middleFrameNumbers,
+		FinalFrameRaw:      agg.finalFrameRaw,
+	}
+	totalTokens, totalInTokens, totalChars := agg.outToks, agg.inToks, agg.outChars
+	report.TotalPromptTokens = totalInTokens
+	report.TotalCompletionTokens = totalTokens
+	report.EstimatedTokenCount = agg.estimatedToks
+	report.ToolCallResponses = agg.toolCallResps
+	result.ApplyCost(report, result.Pricing{InputPerMillion: r.cfg.PriceInput, OutputPerMillion: r.cfg.PriceOutput})
+	report.RetriedRequests = agg.retried
+	report.SSEViolations = agg.sseViolations
+	report.SSEViolationRequests = agg.sseViolReqs
+	report.SSEViolationExample = agg.sseExample
+	report.RetryAttempts = agg.retryAttempts
+	if agg.traced > 0 {
+		report.AvgRequestWriteMs = avgMs(agg.writeSum, agg.traced)
+		report.AvgServerFirstByteMs = avgMs(agg.firstByteSum, agg.traced)
+		report.NewConnections = agg.newConns
+		if agg.newConns > 0 {
+			report.AvgConnectMs = avgMs(agg.connectSum, agg.newConns)
+			report.AvgTLSMs = avgMs(agg.tlsSum, agg.newConns)
 		}
 	}
 
@@ -72,31 +485,50 @@ func (r *Runner) generateReport(results []result.RequestResult, wallTime time.Du
 	}
 
 	// Calculate statistics for successful requests
-	if len(successResults) > 0 {
+	if report.Success > 0 {
 		// TTFT statistics
-		report.AvgTTFTMs = stats.AverageMs(ttfts)
-		report.P50TTFTMs = stats.PercentileMs(ttfts, 50)
-		report.P95TTFTMs = stats.PercentileMs(ttfts, 95)
-		report.P99TTFTMs = stats.PercentileMs(ttfts, 99)
+		report.AvgTTFTMs = agg.ttfts.AverageMs()
+		report.P50TTFTMs = agg.ttfts.PercentileMs(50)
+		report.P95TTFTMs = agg.ttfts.PercentileMs(95)
+		report.P99TTFTMs = agg.ttfts.PercentileMs(99)
 
 		// Latency statistics
-		report.AvgLatencyMs = stats.AverageMs(latencies)
-		report.P50LatencyMs = stats.PercentileMs(latencies, 50)
-		report.P95LatencyMs = stats.PercentileMs(latencies, 95)
-		report.P99LatencyMs = stats.PercentileMs(latencies, 99)
+		report.AvgLatencyMs = agg.latency.AverageMs()
+		report.P50LatencyMs = agg.latency.PercentileMs(50)
+		report.P95LatencyMs = agg.latency.PercentileMs(95)
+		report.P99LatencyMs = agg.latency.PercentileMs(99)
 
 		// Decode statistics
-		if len(decodes) > 0 {
-			report.AvgDecodeMs = stats.AverageMs(decodes)
-			report.P50DecodeMs = stats.PercentileMs(decodes, 50)
-			report.P95DecodeMs = stats.PercentileMs(decodes, 95)
-			report.P99DecodeMs = stats.PercentileMs(decodes, 99)
-			report.DecodeDistribution = stats.DurationsToMs(decodes)
+		if agg.decodes.Count() > 0 {
+			report.AvgDecodeMs = agg.decodes.AverageMs()
+			report.P50DecodeMs = agg.decodes.PercentileMs(50)
+			report.P95DecodeMs = agg.decodes.PercentileMs(95)
+			report.P99DecodeMs = agg.decodes.PercentileMs(99)
+		}
+
+		// Time to last token and the stream tail after it
+		if agg.ttlts.Count() > 0 {
+			report.AvgTTLTMs = agg.ttlts.AverageMs()
+			report.P50TTLTMs = agg.ttlts.PercentileMs(50)
+			report.P95TTLTMs = agg.ttlts.PercentileMs(95)
+			report.P99TTLTMs = agg.ttlts.PercentileMs(99)
+			report.AvgStreamTailMs = agg.tails.AverageMs()
+			report.P95StreamTailMs = agg.tails.PercentileMs(95)
 		}
 
-		// Distributions for visualization
-		report.TTFTDistribution = stats.DurationsToMs(ttfts)
-		report.LatencyDistribution = stats.DurationsToMs(latencies)
+		// Distributions for visualization (not available in streaming mode)
+		if !agg.streaming {
+			latencies := *agg.latency.(*exactDurations)
+			report.LatencyMADMs = float64(stats.MAD(latencies).Microseconds()) / 1000.0
+			report.LatencyIQRMs = float64(stats.IQR(latencies).Microseconds()) / 1000.0
+			report.OutlierCount, report.OutlierIDs = latencyOutliers(latencies, agg.latIDs, 10)
+
+			report.TTFTDistribution = stats.DurationsToMs(*agg.ttfts.(*exactDurations))
+			report.LatencyDistribution = stats.DurationsToMs(*agg.latency.(*exactDurations))
+			if agg.decodes.Count() > 0 {
+				report.DecodeDistribution = stats.DurationsToMs(*agg.decodes.(*exactDurations))
+			}
+		}
 
 		// Prefill speed: input_tokens / avg_TTFT
 		if totalInTokens > 0 && report.AvgTTFTMs > 0 {
@@ -122,6 +554,22 @@ func (r *Runner) generateReport(results []result.RequestResult, wallTime time.Du
 				}
 			}
 		}
+
+		// Per-request decode speed percentiles, using the same unit as DecodeSpeed
+		var speeds speedSeries
+		switch {
+		case r.cfg.TokenMode == "usage" && totalTokens > 0:
+			speeds = agg.tokenSpeeds
+		case r.cfg.TokenMode == "usage" || r.cfg.TokenMode == "chars":
+			speeds = agg.charSpeeds
+		}
+		if speeds != nil && speeds.Count() > 0 {
+			report.MeanTokensPerSec = speeds.Mean()
+			report.GeoMeanTokensPerSec = speeds.GeoMean()
+			report.P50TokensPerSec = speeds.Percentile(50)
+			report.P95TokensPerSec = speeds.Percentile(95)
+			report.P99TokensPerSec = speeds.Percentile(99)
+		}
 	}
 
 	// Calculate throughput
@@ -152,12 +600,111 @@ func (r *Runner) generateReport(results []result.RequestResult, wallTime time.Du
 		}
 	}
 
+	report.Timeseries = agg.timeseries()
+	if buckets := agg.promptBucketStats(); buckets != nil {
+		report.PromptLengthUnit = r.promptLengthUnit()
+		report.PromptBuckets = buckets
+		if !agg.streaming {
+			report.PromptLengthDistribution = agg.promptLens
+		}
+	}
+
+	// Response diversity
+	report.DistinctResponses = len(agg.responseCounts)
+	report.DistinctResponsesCapped = agg.untracked > 0
+	for _, count := range agg.responseCounts {
+		if count > report.TopResponseCount {
+			report.TopResponseCount = count
+		}
+	}
+
 	// Error breakdown (top N)
-	report.ErrorsTopN = r.topNErrors(errorCounts, 10)
+	report.ErrorsTopN = r.topNErrors(agg.errorCounts, 10)
+	if len(agg.httpStatuses) > 0 {
+		report.HTTPStatusCounts = agg.httpStatuses
+	}
+	for _, s := range agg.keys {
+		s.SuccessRate = float64(s.Requests-s.Failures) / float64(s.Requests)
+		report.APIKeys = append(report.APIKeys, *s)
+	}
+	sort.Slice(report.APIKeys, func(i, j int) bool { return report.APIKeys[i].Index < report.APIKeys[j].Index })
+	if r.cfg.JSONMode && report.Success > 0 {
+		report.ValidJSONCount = agg.validJSON
+		rate := float64(agg.validJSON) / float64(report.Success)
+		report.ValidJSONRate = &rate
+	}
+	if len(agg.finishReasons) > 0 {
+		report.FinishReasonCounts = agg.finishReasons
+	}
+	if agg.logprobCount > 0 {
+		avg := agg.logprobSum / float64(agg.logprobCount)
+		minAvg := agg.minLogprob
+		report.AvgLogprob = &avg
+		report.MinAvgLogprob = &minAvg
+		report.LogprobResponses = agg.logprobCount
+	}
+	for name, t := range agg.scores {
+		report.Scores = append(report.Scores, result.ScoreStat{
+			Name:     name,
+			Mean:     t.sum / float64(t.count),
+			PassRate: float64(t.passed) / float64(t.count),
+			Scored:   t.count,
+		})
+	}
+	sort.Slice(report.Scores, func(i, j int) bool { return report.Scores[i].Name < report.Scores[j].Name })
 
 	return report
 }
 
+// latencyOutliers counts latencies beyond median ± 3·MAD and returns up to
+// maxIDs of their request IDs, furthest from the median first.
+func latencyOutliers(latencies []time.Duration, ids []string, maxIDs int) (int, []string) {
+	mad := stats.MAD(latencies)
+	if mad == 0 {
+		// More than half the samples are identical; any difference would count
+		return 0, nil
+	}
+	median := stats.Percentile(latencies, 50)
+
+	type outlier struct {
+		id        string
+		deviation time.Duration
+	}
+	var outliers []outlier
+	for i, d := range latencies {
+		if dev := (d - median).Abs(); dev > 3*mad {
+			outliers = append(outliers, outlier{id: ids[i], deviation: dev})
+		}
+	}
+	sort.SliceStable(outliers, func(i, j int) bool {
+		return outliers[i].deviation > outliers[j].deviation
+	})
+
+	var topIDs []string
+	for i := 0; i < len(outliers) && i < maxIDs; i++ {
+		topIDs = append(topIDs, outliers[i].id)
+	}
+	return len(outliers), topIDs
+}
+
+// httpStatusCode extracts the status code from provider errors of the form "HTTP 429: body".
+// It returns 0 if the error is not an HTTP status error.
+func httpStatusCode(errMsg string) int {
+	rest, ok := strings.CutPrefix(errMsg, "HTTP ")
+	if !ok {
+		return 0
+	}
+	codeStr, _, ok := strings.Cut(rest, ":")
+	if !ok {
+		return 0
+	}
+	code, err := strconv.Atoi(codeStr)
+	if err != nil {
+		return 0
+	}
+	return code
+}
+
 func (r *Runner) topNErrors(errorCounts map[string]int, n int) []result.ErrorStat {
 	var errors []result.ErrorStat
 	for key, count := range errorCounts {
@@ -174,63 +721,212 @@ func (r *Runner) topNErrors(errorCounts map[string]int, n int) []result.ErrorSta
 	return errors
 }
 
-func (r *Runner) writeOutput(results []result.RequestResult, report *result.BenchmarkReport) error {
-	// Create output directory
+// resultsWriter appends per-request records to results.jsonl as they complete.
+type resultsWriter struct {
+	path    string
+	f       *os.File
+	encoder *json.Encoder
+}
+
+func (r *Runner) newResultsWriter() (*resultsWriter, error) {
 	if err := os.MkdirAll(r.cfg.OutputDir, 0755); err != nil {
-		return fmt.Errorf("failed to create output directory: %w", err)
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
 	}
 
-	// Write results.jsonl
 	resultsPath := filepath.Join(r.cfg.OutputDir, "results.jsonl")
-	f, err := os.Create(resultsPath)
+	f, err := createOrAppend(resultsPath, r.cfg.Resume)
 	if err != nil {
-		return fmt.Errorf("failed to create results file: %w", err)
-	}
-	defer f.Close()
-
-	encoder := json.NewEncoder(f)
-	for _, res := range results {
-		// Convert to output format
-		output := map[string]interface{}{
-			"request_id":       res.ID,
-			"status":           res.Status,
-			"ttft_ms":          res.TTFT.Milliseconds(),
-			"latency_ms":       res.Latency.Milliseconds(),
-			"decode_ms":        res.Decode.Milliseconds(),
-			"in_tokens":        res.InTokens,
-			"out_tokens":       res.OutTokens,
-			"out_chars":        res.OutChars,
-			"start_ts":         res.StartTime.Format(time.RFC3339Nano),
-			"first_content_ts": res.FirstContentTime.Format(time.RFC3339Nano),
-			"end_ts":           res.EndTime.Format(time.RFC3339Nano),
-			"provider":         r.provider.Name(),
-		}
-		if res.Err != "" {
-			output["err"] = res.Err
+		return nil, fmt.Errorf("failed to create results file: %w", err)
+	}
+	return &resultsWriter{path: resultsPath, f: f, encoder: json.NewEncoder(f)}, nil
+}
+
+func (w *resultsWriter) write(res result.RequestResult, providerName string, jsonMode bool) error {
+	// Convert to output format
+	output := map[string]interface{}{
+		"request_id":       res.ID,
+		"status":           res.Status,
+		"ttft_ms":          res.TTFT.Milliseconds(),
+		"latency_ms":       res.Latency.Milliseconds(),
+		"decode_ms":        res.Decode.Milliseconds(),
+		"ttlt_ms":          res.TTLT.Milliseconds(),
+		"in_tokens":        res.InTokens,
+		"out_tokens":       res.OutTokens,
+		"out_chars":        res.OutChars,
+		"start_ts":         res.StartTime.Format(time.RFC3339Nano),
+		"first_content_ts": res.FirstContentTime.Format(time.RFC3339Nano),
+		"end_ts":           res.EndTime.Format(time.RFC3339Nano),
+		"provider":         providerName,
+	}
+	if res.Seq > 0 {
+		output["seq"] = res.Seq
+	}
+	if res.PromptLength > 0 {
+		output["prompt_length"] = res.PromptLength
+	}
+	if res.Err != "" {
+		output["err"] = res.Err
+	}
+	if res.ResponseHash != "" {
+		output["response_hash"] = res.ResponseHash
+	}
+	if res.FinishReason != "" {
+		output["finish_reason"] = res.FinishReason
+	}
+	if res.TokensEstimated {
+		output["tokens_estimated"] = true
+	}
+	if res.ThinkChars > 0 {
+		output["think_chars"] = res.ThinkChars
+	}
+	if res.ToolCallChars > 0 {
+		output["tool_call_chars"] = res.ToolCallChars
+	}
+	if res.Choices > 1 {
+		output["choices"] = res.Choices
+	}
+	if res.SSEViolations > 0 {
+		output["sse_violations"] = res.SSEViolations
+		output["sse_first_violation"] = res.SSEFirstViolation
+	}
+	if res.Attempts > 1 {
+		output["attempts"] = res.Attempts
+		output["attempt_latencies_ms"] = res.AttemptLatencies
+	}
+	if res.ServerFirstByte > 0 {
+		output["connect_ms"] = durationMs(res.Connect)
+		output["tls_ms"] = durationMs(res.TLS)
+		output["request_write_ms"] = durationMs(res.RequestWrite)
+		output["server_first_byte_ms"] = durationMs(res.ServerFirstByte)
+		output["conn_reused"] = res.ConnReused
+	}
+	if res.SampledRequest != nil {
+		output["request"] = res.SampledRequest
+		output["response_text"] = res.SampledResponse
+		if res.SampledLogprobs != nil {
+			output["logprobs"] = res.SampledLogprobs
 		}
-		if err := encoder.Encode(output); err != nil {
-			return fmt.Errorf("failed to write result: %w", err)
+	}
+	if jsonMode {
+		output["valid_json"] = res.ValidJSON
+	}
+	if res.AvgLogprob != nil {
+		output["avg_logprob"] = *res.AvgLogprob
+	}
+	if len(res.Scores) > 0 {
+		output["scores"] = res.Scores
+	}
+	if res.APIKeyIndex > 0 {
+		output["api_key_index"] = res.APIKeyIndex
+	}
+	if err := w.encoder.Encode(output); err != nil {
+		return fmt.Errorf("failed to write result: %w", err)
+	}
+	return nil
+}
+
+// sync flushes the results written so far to disk.
+func (w *resultsWriter) sync() error {
+	if err := w.f.Sync(); err != nil {
+		return fmt.Errorf("failed to sync results file: %w", err)
+	}
+	return nil
+}
+
+func (w *resultsWriter) Close() error {
+	return w.f.Close()
+}
+
+// createOrAppend creates path, or opens it for appending when resuming a run.
+func createOrAppend(path string, appendMode bool) (*os.File, error) {
+	if appendMode {
+		return os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+	}
+	return os.Create(path)
+}
+
+// OutputFormats lists the report formats accepted by -output-format.
+var OutputFormats = []string{"json", "md", "html"}
+
+// ParseOutputFormat parses a comma-separated format list such as "json,html".
+// "all" (or an empty string) selects every format.
+func ParseOutputFormat(spec string) (map[string]bool, error) {
+	selected := make(map[string]bool)
+	for _, f := range strings.Split(spec, ",") {
+		f = strings.ToLower(strings.TrimSpace(f))
+		switch {
+		case f == "" || f == "all":
+			for _, name := range OutputFormats {
+				selected[name] = true
+			}
+		case slices.Contains(OutputFormats, f):
+			selected[f] = true
+		default:
+			return nil, fmt.Errorf("unknown output format %q (valid: %s, all)", f, strings.Join(OutputFormats, ", "))
 		}
 	}
-	fmt.Printf("  - Results: %s\n", resultsPath)
+	return selected, nil
+}
 
-	// Write summary.json
-	summaryPath := filepath.Join(r.cfg.OutputDir, "summary.json")
-	summaryData, err := json.MarshalIndent(report, "", "  ")
+func (r *Runner) writeOutput(report *result.BenchmarkReport) error {
+	return r.writeReports(report, true)
+}
+
+// writeReports writes the report files selected by -output-format, listing
+// them on stdout when verbose.
+func (r *Runner) writeReports(report *result.BenchmarkReport, verbose bool) error {
+	formats, err := ParseOutputFormat(r.cfg.OutputFormat)
 	if err != nil {
-		return fmt.Errorf("failed to marshal summary: %w", err)
+		return err
 	}
-	if err := os.WriteFile(summaryPath, summaryData, 0644); err != nil {
-		return fmt.Errorf("failed to write summary: %w", err)
+
+	// Write summary.json
+	if formats["json"] {
+		summaryPath := filepath.Join(r.cfg.OutputDir, "summary.json")
+		summaryData, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal summary: %w", err)
+		}
+		if err := os.WriteFile(summaryPath, summaryData, 0644); err != nil {
+			return fmt.Errorf("failed to write summary: %w", err)
+		}
+		if verbose {
+			fmt.Fprintf(r.cfg.Stdout(), "  - Summary: %s\n", summaryPath)
+		}
+	}
+
+	// Write report.md
+	if formats["md"] {
+		mdPath := filepath.Join(r.cfg.OutputDir, "report.md")
+		if err := r.writeMarkdownReport(report, mdPath); err != nil {
+			return fmt.Errorf("failed to write markdown report: %w", err)
+		}
+		if verbose {
+			fmt.Fprintf(r.cfg.Stdout(), "  - Markdown: %s\n", mdPath)
+		}
 	}
-	fmt.Printf("  - Summary: %s\n", summaryPath)
 
 	// Write report.html
-	reportPath := filepath.Join(r.cfg.OutputDir, "report.html")
-	if err := r.writeHTMLReport(report, reportPath); err != nil {
-		return fmt.Errorf("failed to write HTML report: %w", err)
+	if formats["html"] {
+		reportPath := filepath.Join(r.cfg.OutputDir, "report.html")
+		if err := r.writeHTMLReport(report, reportPath); err != nil {
+			return fmt.Errorf("failed to write HTML report: %w", err)
+		}
+		if verbose {
+			fmt.Fprintf(r.cfg.Stdout(), "  - Report:  %s\n", reportPath)
+		}
+	}
+
+	// Write percentiles.csv
+	if r.cfg.PercentileCSV {
+		csvPath := filepath.Join(r.cfg.OutputDir, "percentiles.csv")
+		if err := writePercentileCSV(report, csvPath); err != nil {
+			return err
+		}
+		if verbose {
+			fmt.Fprintf(r.cfg.Stdout(), "  - Percentiles: %s\n", csvPath)
+		}
 	}
-	fmt.Printf("  - Report:  %s\n", reportPath)
 
 	return nil
 }
@@ -0,0 +1,53 @@
+package runner
+
+import (
+	"time"
+
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/result"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/workload"
+)
+
+// executeWithRetries retries failed attempts up to cfg.Retries times with
+// exponential backoff. The result describes the final attempt: by default its
+// TTFT and latency exclude earlier attempts and backoff sleeps, so retries do
+// not inflate percentiles. With CountRetryLatency they span from the first
+// attempt's start, as a client would experience them.
+func (r *Runner) executeWithRetries(input workload.WorkloadInput) result.RequestResult {
+	res := r.executeAttempt(input)
+	firstStart := res.StartTime
+	latencies := []float64{durationMs(res.Latency)}
+
+	backoff := time.Duration(r.cfg.RetryBackoffMs) * time.Millisecond
+	for retry := 0; retry < r.cfg.Retries && retryable(res); retry++ {
+		time.Sleep(backoff)
+		backoff *= 2
+		res = r.executeAttempt(input)
+		latencies = append(latencies, durationMs(res.Latency))
+	}
+
+	if len(latencies) > 1 {
+		res.Attempts = len(latencies)
+		res.AttemptLatencies = latencies
+		if r.cfg.CountRetryLatency {
+			offset := res.StartTime.Sub(firstStart)
+			res.StartTime = firstStart
+			res.Latency += offset
+			if res.TTFT > 0 {
+				res.TTFT += offset
+			}
+		}
+	}
+	return res
+}
+
+// retryable reports whether a failed attempt is worth repeating: nothing was
+// streamed yet, and the error is not one that would recur on a repeat (4xx
+// other than 408 and 429, or a response over -max-response-chars).
+func retryable(res result.RequestResult) bool {
+	switch res.Status {
+	case result.StatusOK, result.StatusPartial, result.StatusTooLarge:
+		return false
+	}
+	code := httpStatusCode(res.Err)
+	return code == 0 || code >= 500 || code == 408 || code == 429
+}
@@ -0,0 +1,128 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/config"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/provider"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/result"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/workload"
+)
+
+// flakyProvider fails its first failures calls with an HTTP 503 after
+// failDelay, then streams a short reply after replyDelay.
+type flakyProvider struct {
+	failures   int
+	failDelay  time.Duration
+	replyDelay time.Duration
+	calls      int
+}
+
+func (p *flakyProvider) Name() string { return "flaky" }
+
+func (p *flakyProvider) StreamChat(ctx context.Context, cfg *config.GlobalConfig, input workload.WorkloadInput) (<-chan provider.StreamEvent, error) {
+	p.calls++
+	if p.calls <= p.failures {
+		time.Sleep(p.failDelay)
+		return nil, fmt.Errorf("HTTP 503: overloaded")
+	}
+	time.Sleep(p.replyDelay)
+	events := make(chan provider.StreamEvent, 2)
+	events <- provider.StreamEvent{Type: provider.EventContent, Text: "hello"}
+	events <- provider.StreamEvent{Type: provider.EventEnd, FinishReason: "stop"}
+	close(events)
+	return events, nil
+}
+
+func TestExecuteRequest_RetryLatency(t *testing.T) {
+	const (
+		failDelay  = 20 * time.Millisecond
+		replyDelay = 10 * time.Millisecond
+		backoff    = 100 * time.Millisecond
+	)
+
+	tests := []struct {
+		name         string
+		countRetries bool
+		minLatency   time.Duration
+		maxLatency   time.Duration
+	}{
+		// Final attempt only: no failed attempt or backoff sleep
+		{"final attempt", false, replyDelay, failDelay + backoff},
+		// First attempt start to end: 20ms fail + 100ms backoff + 10ms reply
+		{"count retry latency", true, failDelay + backoff + replyDelay, time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			cfg.Retries = 2
+			cfg.RetryBackoffMs = int(backoff / time.Millisecond)
+			cfg.CountRetryLatency = tt.countRetries
+			p := &flakyProvider{failures: 1, failDelay: failDelay, replyDelay: replyDelay}
+			r := New(cfg, p)
+
+			res := r.executeRequest(workload.NewSimpleWorkload("req-1", "hi", 8))
+			if res.Status != result.StatusOK {
+				t.Fatalf("Status = %q (%s), want ok", res.Status, res.Err)
+			}
+			if p.calls != 2 || res.Attempts != 2 {
+				t.Fatalf("calls = %d, Attempts = %d, want 2", p.calls, res.Attempts)
+			}
+			if len(res.AttemptLatencies) != 2 {
+				t.Fatalf("AttemptLatencies = %v, want 2 entries", res.AttemptLatencies)
+			}
+			if res.AttemptLatencies[0] < durationMs(failDelay) || res.AttemptLatencies[0] >= durationMs(failDelay+backoff) {
+				t.Errorf("first attempt latency = %.1fms, want the failed attempt alone (~%v)", res.AttemptLatencies[0], failDelay)
+			}
+			if res.Latency < tt.minLatency || res.Latency >= tt.maxLatency {
+				t.Errorf("Latency = %v, want in [%v, %v)", res.Latency, tt.minLatency, tt.maxLatency)
+			}
+			if res.TTFT > res.Latency {
+				t.Errorf("TTFT = %v exceeds Latency = %v", res.TTFT, res.Latency)
+			}
+		})
+	}
+}
+
+func TestExecuteRequest_RetriesDisabled(t *testing.T) {
+	cfg := config.DefaultConfig()
+	p := &flakyProvider{failures: 1}
+	res := New(cfg, p).executeRequest(workload.NewSimpleWorkload("req-1", "hi", 8))
+	if res.Status != result.StatusHTTPError || p.calls != 1 {
+		t.Fatalf("Status = %q, calls = %d, want one failed attempt", res.Status, p.calls)
+	}
+	if res.Attempts != 0 || res.AttemptLatencies != nil {
+		t.Errorf("Attempts = %d, AttemptLatencies = %v, want unset", res.Attempts, res.AttemptLatencies)
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		status result.RequestStatus
+		err    string
+		want   bool
+	}{
+		{result.StatusOK, "", false},
+		{result.StatusPartial, "stream reset", false},
+		{result.StatusHTTPError, "HTTP 503: overloaded", true},
+		{result.StatusHTTPError, "HTTP 500: internal", true},
+		{result.StatusHTTPError, "HTTP 429: rate limited", true},
+		{result.StatusHTTPError, "HTTP 408: timeout", true},
+		{result.StatusHTTPError, "HTTP 400: bad request", false},
+		{result.StatusHTTPError, "HTTP 401: unauthorized", false},
+		{result.StatusHTTPError, "dial tcp: connection refused", true},
+		{result.StatusTimeout, "request timeout", true},
+		{result.StatusTooLarge, "response exceeded 100 chars", false},
+	}
+
+	for _, tt := range tests {
+		res := result.RequestResult{Status: tt.status, Err: tt.err}
+		if got := retryable(res); got != tt.want {
+			t.Errorf("retryable(%s, %q) = %v, want %v", tt.status, tt.err, got, tt.want)
+		}
+	}
+}
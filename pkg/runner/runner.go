@@ -3,57 +3,319 @@ package runner
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"math"
+	"math/rand"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/brianxiadong/llm-benchmark-kit/pkg/config"
 	"github.com/brianxiadong/llm-benchmark-kit/pkg/provider"
 	"github.com/brianxiadong/llm-benchmark-kit/pkg/result"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/scorer"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/tokenizer"
 	"github.com/brianxiadong/llm-benchmark-kit/pkg/workload"
 )
 
 // MaxSampleSize is the maximum size for raw data sampling.
 const MaxSampleSize = 64 * 1024 // 64KB
 
+// MaxMiddleFrames bounds -sample-middle-frames.
+const MaxMiddleFrames = 20
+
+// ProgressReporter receives live updates while the timed benchmark batch runs.
+type ProgressReporter interface {
+	Start(total int)
+	Record(res result.RequestResult)
+	Stop()
+}
+
 // Runner executes the benchmark.
 type Runner struct {
 	cfg      *config.GlobalConfig
 	provider provider.Provider
 	loader   *workload.Loader
+	progress ProgressReporter
+
+	baseMessages []workload.ChatMessage // Conversation from -messages-file prepended to every request
+	monitor      *clientMonitor         // Watches the dispatch loop of the measured batch (-self-monitor)
+	scorers      []scorer.Scorer        // Rate successful responses (-score)
+	keys         *keyPool               // Rotates -api-key across requests (nil = cfg.Token)
+	nonces       atomic.Int64           // Requests given a -bust-cache nonce so far
 }
 
 // New creates a new benchmark runner.
 func New(cfg *config.GlobalConfig, p provider.Provider) *Runner {
-	return &Runner{
+	r := &Runner{
 		cfg:      cfg,
 		provider: p,
 		loader:   workload.NewLoader(),
 	}
+	if len(cfg.APIKeys) > 0 {
+		r.keys = newKeyPool(cfg.APIKeys, cfg.PerKeyRPS)
+	}
+	return r
+}
+
+// SetProgress registers a reporter that receives results as they complete.
+func (r *Runner) SetProgress(p ProgressReporter) {
+	r.progress = p
+}
+
+// AddScorer attaches a scorer that rates every successful response.
+func (r *Runner) AddScorer(s scorer.Scorer) {
+	r.scorers = append(r.scorers, s)
 }
 
 // Run executes the benchmark and returns the report.
 func (r *Runner) Run() (*result.BenchmarkReport, error) {
+	if r.cfg.MessagesFile != "" {
+		messages, err := workload.LoadMessagesFile(r.cfg.MessagesFile)
+		if err != nil {
+			return nil, err
+		}
+		r.baseMessages = messages
+		fmt.Fprintf(r.cfg.Stdout(), "Using %d-message conversation from %s as the base of every request\n", len(messages), r.cfg.MessagesFile)
+	}
+
+	var readyWait time.Duration
+	if r.cfg.WaitReady {
+		var err error
+		if readyWait, err = r.waitReady(time.Duration(r.cfg.WaitReadySec) * time.Second); err != nil {
+			return nil, err
+		}
+	}
+
+	warmupCount := r.cfg.Warmup
+	if r.cfg.WarmupStablePct > 0 {
+		warmupCount = r.cfg.WarmupMax
+	}
+	totalNeeded := r.cfg.TotalRequests + warmupCount
+
 	// Load workloads
+	var source <-chan workload.WorkloadInput
+	sourceErr := func() error { return nil }
+	if r.cfg.StreamingStats && r.cfg.PromptTemplate == "" && r.cfg.WorkloadFile != "" {
+		// Read the file lazily so memory stays flat for huge datasets
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		var err error
+		source, sourceErr, err = r.streamWorkloads(ctx, totalNeeded)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load workloads: %w", err)
+		}
+	} else {
+		workloads, err := r.loadWorkloads(totalNeeded)
+		if err != nil {
+			return nil, err
+		}
+		source = feed(workloads[:totalNeeded])
+	}
+
+	// Run warmup; its results are kept apart to show the cold-start penalty
+	var warmupReport *result.BenchmarkReport
+	if r.cfg.WarmupStablePct > 0 {
+		warmupReport = r.warmupUntilStable(source)
+	} else if r.cfg.Warmup > 0 {
+		fmt.Fprintf(r.cfg.Stdout(), "Running %d warmup requests with %d concurrency...\n", r.cfg.Warmup, r.cfg.Concurrency)
+		warmupAgg := newAggregator(r.cfg.StreamingStats)
+		warmupStart := time.Now()
+		r.runBatch(take(source, r.cfg.Warmup, nil), nil, warmupAgg.add)
+		warmupReport = r.buildReport(warmupAgg, time.Since(warmupStart))
+	}
+
+	// Results are written to results.jsonl as they complete
+	rw, err := r.newResultsWriter()
+	if err != nil {
+		return nil, err
+	}
+	defer rw.Close()
+	var tw *transcriptWriter
+	if r.cfg.RecordAll {
+		if tw, err = r.newTranscriptWriter(); err != nil {
+			return nil, err
+		}
+		defer tw.Close()
+		fmt.Fprintln(r.cfg.Stdout(), "Recording every prompt and response to transcript.jsonl (-record-all); expect roughly prompt + response size per request")
+	}
+
+	// Results of an interrupted run are reloaded and only the missing requests are sent
+	agg := newAggregator(r.cfg.StreamingStats)
+	resume := &resumeState{done: map[int]bool{}}
+	if r.cfg.Resume {
+		if resume, err = r.loadResume(agg); err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(r.cfg.Stdout(), "Resuming run in %s: %d of %d requests already completed\n", r.cfg.OutputDir, len(resume.done), r.cfg.TotalRequests)
+	}
+	completed := len(resume.done)
+	if err := r.writeCheckpoint(completed, resume.elapsed); err != nil {
+		return nil, err
+	}
+
+	// Run benchmark
+	fmt.Fprintf(r.cfg.Stdout(), "Running %d benchmark requests with %d concurrency...\n", r.cfg.TotalRequests-completed, r.cfg.Concurrency)
+	if r.cfg.StreamingStats {
+		fmt.Fprintln(r.cfg.Stdout(), "Using streaming statistics (estimated percentiles, bounded memory)")
+	}
+	if r.progress != nil {
+		r.progress.Start(r.cfg.TotalRequests - completed)
+	}
+	var writeErr error
+	var abortReason string
+	consecutiveFailures := 0
+	stop := make(chan struct{})
+	if r.cfg.SelfMonitor {
+		r.monitor = newClientMonitor(r.cfg.RPS)
+	}
+	startTime := time.Now()
+	lastCheckpoint := startTime
+	// Snapshots read the aggregator while results are still being added
+	var aggMu sync.Mutex
+	stopSnapshots := func() {}
+	if r.cfg.SnapshotSec > 0 {
+		stopSnapshots = r.startSnapshots(func() *result.BenchmarkReport {
+			aggMu.Lock()
+			defer aggMu.Unlock()
+			report := r.buildReport(agg, resume.elapsed+time.Since(startTime))
+			report.WarmupReport = warmupReport
+			report.ReadyWaitMs = readyWait.Milliseconds()
+			return report
+		})
+	}
+	r.runBatch(sequence(source, r.cfg.TotalRequests, resume.done, stop), stop, func(res result.RequestResult) {
+		aggMu.Lock()
+		agg.add(res)
+		aggMu.Unlock()
+		completed++
+		if res.Status == result.StatusOK {
+			consecutiveFailures = 0
+		} else {
+			consecutiveFailures++
+			if r.cfg.AbortAfter > 0 && consecutiveFailures == r.cfg.AbortAfter && abortReason == "" {
+				abortReason = fmt.Sprintf("%d consecutive failures (last error: %s)", consecutiveFailures, res.Err)
+				close(stop)
+			}
+		}
+		if writeErr == nil {
+			writeErr = rw.write(res, r.provider.Name(), r.cfg.JSONMode)
+		}
+		if writeErr == nil && tw != nil {
+			writeErr = tw.write(res)
+		}
+		if writeErr == nil && r.cfg.CheckpointSec > 0 && time.Since(lastCheckpoint) >= time.Duration(r.cfg.CheckpointSec)*time.Second {
+			// Flush results to disk first so the checkpoint never claims more than was saved
+			if writeErr = rw.sync(); writeErr == nil {
+				writeErr = r.writeCheckpoint(completed, resume.elapsed+time.Since(startTime))
+			}
+			lastCheckpoint = time.Now()
+		}
+		if r.progress != nil {
+			r.progress.Record(res)
+		}
+	})
+	stopSnapshots()
+	sessionTime := time.Since(startTime)
+	wallTime := resume.elapsed + sessionTime
+	if r.progress != nil {
+		r.progress.Stop()
+	}
+	if writeErr == nil {
+		if writeErr = rw.sync(); writeErr == nil {
+			writeErr = r.writeCheckpoint(completed, wallTime)
+		}
+	}
+	if writeErr != nil {
+		return nil, fmt.Errorf("failed to write output: %w", writeErr)
+	}
+	if err := sourceErr(); err != nil {
+		return nil, fmt.Errorf("failed to load workloads: %w", err)
+	}
+	fmt.Fprintf(r.cfg.Stdout(), "  - Results: %s\n", rw.path)
+	if tw != nil {
+		fmt.Fprintf(r.cfg.Stdout(), "  - Transcript: %s\n", tw.path)
+	}
+	if abortReason != "" {
+		fmt.Fprintf(r.cfg.Stdout(), "Run aborted after %s\n", abortReason)
+	}
+
+	// Generate report
+	report := r.buildReport(agg, wallTime)
+	report.Aborted = abortReason != ""
+	report.AbortReason = abortReason
+	report.WarmupReport = warmupReport
+	report.ReadyWaitMs = readyWait.Milliseconds()
+	if r.monitor != nil {
+		report.ClientStats = r.monitor.stats(sessionTime)
+		report.ClientSaturated = report.ClientStats.Saturated
+		r.monitor = nil
+	}
+
+	// Write output files
+	if err := r.writeOutput(report); err != nil {
+		return nil, fmt.Errorf("failed to write output: %w", err)
+	}
+
+	return report, nil
+}
+
+// waitReady sends a 1-token request every second until one succeeds or the
+// timeout passes, and returns how long it waited.
+func (r *Runner) waitReady(timeout time.Duration) (time.Duration, error) {
+	fmt.Fprintf(r.cfg.Stdout(), "Waiting up to %s for the endpoint to become ready...\n", timeout)
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		res := r.executeRequest(workload.NewSimpleWorkload("ready-check", "Hi", 1))
+		if res.IsSuccess() {
+			waited := time.Since(start)
+			fmt.Fprintf(r.cfg.Stdout(), "Endpoint ready after %s (%d attempts)\n", waited.Round(time.Millisecond), attempt)
+			return waited, nil
+		}
+		if time.Since(start)+time.Second > timeout {
+			return 0, fmt.Errorf("endpoint not ready after %s: %s", timeout, res.Err)
+		}
+		time.Sleep(time.Second)
+	}
+}
+
+// loadWorkloads loads all workloads into memory, repeating them if there are
+// fewer than n.
+func (r *Runner) loadWorkloads(n int) ([]workload.WorkloadInput, error) {
 	var workloads []workload.WorkloadInput
 	var err error
 
-	if r.cfg.WorkloadFile != "" {
+	if r.cfg.PromptTemplate != "" {
+		workloads, err = r.loadTemplateWorkloads()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load workloads: %w", err)
+		}
+		fmt.Fprintf(r.cfg.Stdout(), "Expanded prompt template into %d workloads\n", len(workloads))
+	} else if r.cfg.WorkloadFile != "" {
 		workloads, err = r.loader.LoadFromFile(r.cfg.WorkloadFile, r.cfg.MaxTokens)
 		if err != nil {
 			return nil, fmt.Errorf("failed to load workloads: %w", err)
 		}
 	} else {
-		workloads = r.loader.GenerateDefault(r.cfg.TotalRequests+r.cfg.Warmup, r.cfg.MaxTokens)
+		workloads = r.loader.GenerateDefault(n, r.cfg.MaxTokens)
+	}
+	if len(workloads) == 0 {
+		return nil, fmt.Errorf("failed to load workloads: no prompts found")
 	}
 
-	totalNeeded := r.cfg.TotalRequests + r.cfg.Warmup
-	if len(workloads) < totalNeeded {
+	if len(workloads) < n {
 		// Repeat workloads if not enough
 		original := workloads
-		for len(workloads) < totalNeeded {
+		for len(workloads) < n {
 			for _, w := range original {
-				if len(workloads) >= totalNeeded {
+				if len(workloads) >= n {
 					break
 				}
 				newWorkload := w
@@ -62,35 +324,142 @@ func (r *Runner) Run() (*result.BenchmarkReport, error) {
 			}
 		}
 	}
+	return workloads, nil
+}
 
-	// Run warmup
-	if r.cfg.Warmup > 0 {
-		fmt.Printf("Running %d warmup requests...\n", r.cfg.Warmup)
-		warmupWorkloads := workloads[:r.cfg.Warmup]
-		r.runBatch(warmupWorkloads, false)
-		workloads = workloads[r.cfg.Warmup:]
+// streamWorkloads yields n workloads from the workload file without loading it
+// into memory. If the file holds fewer than n, it is re-opened and repeated.
+// A parse or read error closes the channel early and is then returned by the
+// error function.
+func (r *Runner) streamWorkloads(ctx context.Context, n int) (<-chan workload.WorkloadInput, func() error, error) {
+	src, err := r.loader.Stream(ctx, r.cfg.WorkloadFile, r.cfg.MaxTokens)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Read the first workload up front, so an empty or unreadable file fails
+	// before any request is sent
+	first, ok := <-src.C
+	if !ok {
+		if err := src.Err(); err != nil {
+			return nil, nil, fmt.Errorf("no prompts found: %w", err)
+		}
+		return nil, nil, fmt.Errorf("no prompts found")
 	}
+	fmt.Fprintf(r.cfg.Stdout(), "Streaming workloads from %s\n", r.cfg.WorkloadFile)
 
-	// Run benchmark
-	fmt.Printf("Running %d benchmark requests with %d concurrency...\n", r.cfg.TotalRequests, r.cfg.Concurrency)
-	startTime := time.Now()
-	results := r.runBatch(workloads[:r.cfg.TotalRequests], true)
-	wallTime := time.Since(startTime)
+	out := make(chan workload.WorkloadInput)
+	errc := make(chan error, 1)
+	go func() {
+		defer close(out)
+		w, ok := first, true
+		sent := 0
+		for pass := 0; ok; pass++ {
+			for ; ok; w, ok = <-src.C {
+				if sent >= n {
+					return
+				}
+				if pass > 0 {
+					w.ID = fmt.Sprintf("req-%d", sent+1)
+				}
+				select {
+				case out <- w:
+				case <-ctx.Done():
+					return
+				}
+				sent++
+			}
+			if err := src.Err(); err != nil {
+				errc <- err
+				return
+			}
+			if sent >= n {
+				return
+			}
 
-	// Generate report
-	report := r.generateReport(results, wallTime)
+			// Re-open the file to repeat it
+			if src, err = r.loader.Stream(ctx, r.cfg.WorkloadFile, r.cfg.MaxTokens); err != nil {
+				errc <- err
+				return
+			}
+			w, ok = <-src.C
+		}
+		if err := src.Err(); err != nil {
+			errc <- err
+		}
+	}()
+	return out, func() error {
+		select {
+		case err := <-errc:
+			return err
+		default:
+			return nil
+		}
+	}, nil
+}
 
-	// Write output files
-	if err := r.writeOutput(results, report); err != nil {
-		return nil, fmt.Errorf("failed to write output: %w", err)
+// feed returns a closed, pre-filled channel holding the workloads.
+func feed(workloads []workload.WorkloadInput) <-chan workload.WorkloadInput {
+	ch := make(chan workload.WorkloadInput, len(workloads))
+	for _, w := range workloads {
+		ch <- w
 	}
+	close(ch)
+	return ch
+}
 
-	return report, nil
+// take forwards at most n workloads from src, leaving the rest for the next
+// caller. Like sequence it gives up once stop is closed.
+func take(src <-chan workload.WorkloadInput, n int, stop <-chan struct{}) <-chan workload.WorkloadInput {
+	out := make(chan workload.WorkloadInput)
+	go func() {
+		defer close(out)
+		for i := 0; i < n; i++ {
+			w, ok := <-src
+			if !ok {
+				return
+			}
+			select {
+			case out <- w:
+			case <-stop:
+				return
+			}
+		}
+	}()
+	return out
 }
 
-func (r *Runner) runBatch(workloads []workload.WorkloadInput, collect bool) []result.RequestResult {
-	jobs := make(chan workload.WorkloadInput, len(workloads))
-	results := make(chan result.RequestResult, len(workloads))
+// loadTemplateWorkloads expands the prompt template with variables from -vars-file or -vars.
+func (r *Runner) loadTemplateWorkloads() ([]workload.WorkloadInput, error) {
+	var varSets []map[string]string
+	var err error
+	if r.cfg.VarsFile != "" {
+		varSets, err = workload.LoadVarsFile(r.cfg.VarsFile)
+	} else {
+		varSets, err = workload.ParseVars(r.cfg.PromptVars)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(varSets) == 0 {
+		return nil, fmt.Errorf("no template variable sets found")
+	}
+	return r.loader.LoadFromTemplate(r.cfg.PromptTemplate, varSets, r.cfg.MaxTokens)
+}
+
+// runBatch executes the workloads until the channel is closed and passes each result to onResult as it
+// completes. onResult is called from a single goroutine; nil discards results.
+// Closing stop (may be nil) sends no further jobs; requests in flight still complete.
+func (r *Runner) runBatch(workloads <-chan workload.WorkloadInput, stop <-chan struct{}, onResult func(result.RequestResult)) {
+	// Buffers are sized by concurrency so memory does not grow with the request count
+	inFlight := r.cfg.Concurrency
+	var sem chan struct{}
+	if r.cfg.MaxInFlight > r.cfg.Concurrency {
+		// Workers overlap requests; the semaphore bounds the total in flight
+		inFlight = r.cfg.MaxInFlight
+		sem = make(chan struct{}, r.cfg.MaxInFlight)
+	}
+	jobs := make(chan workload.WorkloadInput, r.cfg.Concurrency)
+	results := make(chan result.RequestResult, inFlight)
 
 	// Start workers
 	var wg sync.WaitGroup
@@ -98,7 +467,11 @@ func (r *Runner) runBatch(workloads []workload.WorkloadInput, collect bool) []re
 		wg.Add(1)
 		go func() {
 			defer wg.Done()
-			r.worker(jobs, results)
+			if sem != nil {
+				r.asyncWorker(jobs, results, sem)
+			} else {
+				r.worker(jobs, results)
+			}
 		}()
 	}
 
@@ -112,13 +485,32 @@ func (r *Runner) runBatch(workloads []workload.WorkloadInput, collect bool) []re
 
 	// Send jobs
 	go func() {
-		for _, w := range workloads {
+		defer func() {
+			close(jobs)
+			// Unblock the producer when stopped early
+			for range workloads {
+			}
+		}()
+		for w := range workloads {
 			if ticker != nil {
-				<-ticker.C
+				select {
+				case <-ticker.C:
+				case <-stop:
+					return
+				}
+			}
+			if r.monitor != nil {
+				if !r.dispatchMonitored(jobs, w, stop) {
+					return
+				}
+				continue
+			}
+			select {
+			case jobs <- w:
+			case <-stop:
+				return
 			}
-			jobs <- w
 		}
-		close(jobs)
 	}()
 
 	// Wait for workers and close results
@@ -127,15 +519,31 @@ func (r *Runner) runBatch(workloads []workload.WorkloadInput, collect bool) []re
 		close(results)
 	}()
 
-	// Collect results
-	var collected []result.RequestResult
+	// Deliver results
 	for res := range results {
-		if collect {
-			collected = append(collected, res)
+		if onResult != nil {
+			onResult(res)
 		}
 	}
+}
 
-	return collected
+// dispatchMonitored hands w to a worker like runBatch does, recording for
+// -self-monitor whether it had to wait for one. It returns false if stopped.
+func (r *Runner) dispatchMonitored(jobs chan<- workload.WorkloadInput, w workload.WorkloadInput, stop <-chan struct{}) bool {
+	select {
+	case jobs <- w:
+		r.monitor.recordDispatch(0)
+		return true
+	default:
+	}
+	waitStart := time.Now()
+	select {
+	case jobs <- w:
+		r.monitor.recordDispatch(time.Since(waitStart))
+		return true
+	case <-stop:
+		return false
+	}
 }
 
 func (r *Runner) worker(jobs <-chan workload.WorkloadInput, results chan<- result.RequestResult) {
@@ -145,21 +553,92 @@ func (r *Runner) worker(jobs <-chan workload.WorkloadInput, results chan<- resul
 	}
 }
 
+// asyncWorker launches each job without waiting for the previous one to
+// finish, like an event-loop client. sem caps requests in flight across all
+// workers; the worker returns once its own requests have completed.
+func (r *Runner) asyncWorker(jobs <-chan workload.WorkloadInput, results chan<- result.RequestResult, sem chan struct{}) {
+	var wg sync.WaitGroup
+	for job := range jobs {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(job workload.WorkloadInput) {
+			defer wg.Done()
+			res := r.executeRequest(job)
+			<-sem
+			results <- res
+		}(job)
+	}
+	wg.Wait()
+}
+
 func (r *Runner) executeRequest(input workload.WorkloadInput) result.RequestResult {
+	if r.baseMessages != nil {
+		// Explicit workloads add their prompt as the next turn; built-in prompts are dropped
+		appendOwn := r.cfg.WorkloadFile != "" || r.cfg.PromptTemplate != ""
+		input = input.WithBaseMessages(r.baseMessages, appendOwn)
+	}
+	if r.cfg.BustCache {
+		// The timestamp keeps nonces unique across runs against the same server
+		input = input.WithNonce(fmt.Sprintf("[nonce: %x-%d]", time.Now().UnixNano(), r.nonces.Add(1)))
+	}
+	var res result.RequestResult
+	if r.cfg.Retries > 0 {
+		res = r.executeWithRetries(input)
+	} else {
+		res = r.executeAttempt(input)
+	}
+	res.Seq = input.Seq
+	res.PromptLength = r.promptLength(input)
+	return res
+}
+
+// errFirstByteTimeout cancels a request that got no stream event within -first-byte-timeout.
+var errFirstByteTimeout = errors.New("first byte timeout")
+
+// executeAttempt sends one request and measures it.
+func (r *Runner) executeAttempt(input workload.WorkloadInput) result.RequestResult {
+	// Waiting for a key's rate limit is not part of the request
+	base := context.Background()
+	keyIndex := 0
+	if r.keys != nil {
+		i := r.keys.acquire()
+		base = provider.WithAPIKey(base, r.cfg.APIKeys[i])
+		keyIndex = i + 1
+	}
+
 	res := result.RequestResult{
-		ID:        input.ID,
-		StartTime: time.Now(),
+		ID:          input.ID,
+		StartTime:   time.Now(),
+		APIKeyIndex: keyIndex,
 	}
 
 	// Create context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(r.cfg.TimeoutSec)*time.Second)
+	ctx, cancel := context.WithTimeout(base, time.Duration(r.cfg.TimeoutSec)*time.Second)
 	defer cancel()
 
-	// Execute streaming request
-	events, err := r.provider.StreamChat(ctx, r.cfg, input)
+	// A server that accepted the request but never answers fails after
+	// -first-byte-timeout; once the stream has started, only -timeout applies
+	gotFirstByte := func() {}
+	if r.cfg.FirstByteTimeoutSec > 0 {
+		var cancelCause context.CancelCauseFunc
+		ctx, cancelCause = context.WithCancelCause(ctx)
+		defer cancelCause(nil)
+		timer := time.AfterFunc(time.Duration(r.cfg.FirstByteTimeoutSec)*time.Second, func() { cancelCause(errFirstByteTimeout) })
+		defer timer.Stop()
+		gotFirstByte = func() { timer.Stop() }
+	}
+
+	// Execute streaming request; response headers have arrived once StreamChat returns
+	var trace requestTrace
+	events, err := r.provider.StreamChat(trace.withTrace(ctx), r.cfg, input)
+	trace.apply(&res)
 	if err != nil {
 		res.Status = result.StatusHTTPError
 		res.Err = err.Error()
+		if errors.Is(context.Cause(ctx), errFirstByteTimeout) {
+			res.Status = result.StatusFirstByteTimeout
+			res.Err = fmt.Sprintf("no response within %ds (-first-byte-timeout)", r.cfg.FirstByteTimeoutSec)
+		}
 		res.EndTime = time.Now()
 		res.Latency = res.EndTime.Sub(res.StartTime)
 		return res
@@ -167,80 +646,278 @@ func (r *Runner) executeRequest(input workload.WorkloadInput) result.RequestResu
 
 	// Process events
 	var totalContent string
+	var visibleContent strings.Builder // Content without reasoning, for JSON validation, scoring and -record-all (first choice only)
+	choices := map[int]bool{}          // Completion indices seen, with -n
 	gotFirstContent := false
+	ttftChars := 0 // Non-whitespace chars seen so far, for -ttft-min-chars
+	var firstAnyContent time.Time
+	var lastOutput time.Time // Last content, reasoning or tool-call delta, for TTLT
+	markTTFT := func(text string) {
+		if gotFirstContent {
+			return
+		}
+		now := time.Now()
+		if firstAnyContent.IsZero() {
+			firstAnyContent = now
+		}
+		if r.cfg.TTFTMinChars > 0 {
+			ttftChars += nonSpaceCount(text)
+			if ttftChars < r.cfg.TTFTMinChars {
+				return
+			}
+		}
+		res.FirstContentTime = now
+		res.TTFT = now.Sub(res.StartTime)
+		gotFirstContent = true
+	}
 	var usage *provider.TokenUsage
 	contentFrameCount := 0
+	middleFrames := newFrameSampler(r.cfg.SampleMiddleFrames)
+	var logprobs []provider.TokenLogprob
+	var logprobSum float64
+	var thinkFilters map[int]*thinkFilter // Per completion, with -think-tag-filter
+	if r.cfg.ThinkTagFilter {
+		thinkFilters = map[int]*thinkFilter{}
+	}
 
 	for event := range events {
+		gotFirstByte()
+		switch event.Type {
+		case provider.EventContent, provider.EventReasoning, provider.EventToolCall:
+			if event.Text != "" || event.ToolName != "" {
+				lastOutput = time.Now()
+			}
+		}
+
 		switch event.Type {
 		case provider.EventContent:
-			if !gotFirstContent {
-				res.FirstContentTime = time.Now()
-				res.TTFT = res.FirstContentTime.Sub(res.StartTime)
-				gotFirstContent = true
+			text := event.Text
+			if thinkFilters != nil {
+				f := thinkFilters[event.Choice]
+				if f == nil {
+					f = &thinkFilter{}
+					thinkFilters[event.Choice] = f
+				}
+				var thought string
+				text, thought = f.feed(text)
+				res.ThinkChars += len(thought)
+				if thought != "" && firstAnyContent.IsZero() {
+					firstAnyContent = time.Now()
+				}
+			}
+			if thinkFilters == nil || text != "" {
+				markTTFT(text)
 			}
+			choices[event.Choice] = true
 
 			contentFrameCount++
 			// Capture first frame (frame 1)
 			if contentFrameCount == 1 {
 				res.FirstContentRaw = truncateString(event.Raw, MaxSampleSize)
 			}
-			// Capture middle frames (frames 2, 5, 10)
-			if contentFrameCount == 2 || contentFrameCount == 5 || contentFrameCount == 10 {
-				res.MiddleFramesRaw = append(res.MiddleFramesRaw, truncateString(event.Raw, MaxSampleSize))
-			}
+			middleFrames.add(contentFrameCount, event.Raw)
 
-			totalContent += event.Text
+			totalContent += text
+			for _, lp := range event.Logprobs {
+				logprobSum += lp.Logprob
+			}
+			logprobs = append(logprobs, event.Logprobs...)
+			if (r.cfg.JSONMode || r.cfg.RecordAll || len(r.scorers) > 0) && event.Choice == 0 {
+				visibleContent.WriteString(text)
+			}
 
 		case provider.EventReasoning:
 			// Reasoning tokens also count for TTFT (first response from server)
-			if !gotFirstContent {
-				res.FirstContentTime = time.Now()
-				res.TTFT = res.FirstContentTime.Sub(res.StartTime)
-				gotFirstContent = true
-			}
+			markTTFT(event.Text)
+			choices[event.Choice] = true
+			totalContent += event.Text
+
+		case provider.EventToolCall:
+			// The first tool-call fragment, usually just the name, is the first output;
+			// only the arguments JSON counts toward chars/tokens
+			markTTFT(event.ToolName + event.Text)
+			choices[event.Choice] = true
 			totalContent += event.Text
+			res.ToolCallChars += len(event.Text)
 
 		case provider.EventUsage:
 			usage = event.Usage
 
+		case provider.EventViolation:
+			if res.SSEViolations == 0 {
+				res.SSEFirstViolation = event.Err.Error()
+			}
+			res.SSEViolations++
+
 		case provider.EventEnd:
 			res.FinalFrameRaw = truncateString(event.Raw, MaxSampleSize)
+			if event.FinishReason != "" {
+				res.FinishReason = event.FinishReason
+			}
 
 		case provider.EventError:
 			res.Status = result.StatusParseError
+			var httpErr *provider.HTTPError
+			if errors.As(event.Err, &httpErr) {
+				// An error object sent in the stream of a 200 response
+				res.Status = result.StatusHTTPError
+			}
+			if gotFirstContent {
+				res.Status = result.StatusPartial
+			}
 			res.Err = event.Err.Error()
 		}
+
+		if r.cfg.MaxResponseChars > 0 && len(totalContent) > r.cfg.MaxResponseChars {
+			res.Status = result.StatusTooLarge
+			res.Err = fmt.Sprintf("response exceeded %d chars", r.cfg.MaxResponseChars)
+			cancel()
+			// Providers that send without watching ctx (aliyun, bedrock) still
+			// emit the read error of the closed body; drain so they can exit
+			for range events {
+			}
+			break
+		}
+	}
+
+	// Text held back as a possible partial <think> tag
+	for choice, f := range thinkFilters {
+		text, thought := f.flush()
+		res.ThinkChars += len(thought)
+		if text != "" {
+			markTTFT(text)
+			totalContent += text
+			if choice == 0 {
+				visibleContent.WriteString(text)
+			}
+		}
+	}
+
+	for _, f := range middleFrames.pick() {
+		res.MiddleFramesRaw = append(res.MiddleFramesRaw, f.raw)
+		res.MiddleFrameNumbers = append(res.MiddleFrameNumbers, f.number)
 	}
 
 	res.EndTime = time.Now()
 	res.Latency = res.EndTime.Sub(res.StartTime)
 
+	// Content arrived but never reached -ttft-min-chars, or was all <think>: fall back to the first delta
+	if !gotFirstContent && !firstAnyContent.IsZero() {
+		res.FirstContentTime = firstAnyContent
+		res.TTFT = firstAnyContent.Sub(res.StartTime)
+		gotFirstContent = true
+	}
+
 	if gotFirstContent {
 		res.Decode = res.EndTime.Sub(res.FirstContentTime)
 	}
+	if !lastOutput.IsZero() {
+		res.TTLT = lastOutput.Sub(res.StartTime)
+	}
 
 	res.OutChars = len(totalContent)
+	if len(choices) > 1 {
+		res.Choices = len(choices)
+	}
+	if totalContent != "" {
+		res.ResponseHash = hashContent(totalContent)
+	}
+	if len(logprobs) > 0 {
+		avg := logprobSum / float64(len(logprobs))
+		res.AvgLogprob = &avg
+		res.LogprobTokens = len(logprobs)
+	}
+	if r.cfg.SampleRate > 0 && rand.Float64() < r.cfg.SampleRate {
+		res.SampledRequest, _ = json.Marshal(input)
+		res.SampledResponse = totalContent
+		if len(logprobs) > 0 {
+			res.SampledLogprobs, _ = json.Marshal(logprobs)
+		}
+	}
+	if r.cfg.JSONMode {
+		res.ValidJSON = json.Valid([]byte(strings.TrimSpace(visibleContent.String())))
+	}
+	if r.cfg.RecordAll {
+		res.RecordedMessages, _ = json.Marshal(input.ToMessagesWithSystem(r.cfg.SystemPrompt))
+		res.RecordedResponse = visibleContent.String()
+	}
 	if usage != nil {
 		res.InTokens = usage.PromptTokens
 		res.OutTokens = usage.CompletionTokens
+	} else if r.cfg.TokenMode != "disabled" && totalContent != "" {
+		// Many servers ignore stream_options.include_usage; estimate instead of reporting 0.
+		// With -token-mode chars speeds stay in chars, but token totals are still reported
+		if r.cfg.CharsPerToken > 0 {
+			res.OutTokens = int(math.Ceil(float64(utf8.RuneCountInString(totalContent)) / r.cfg.CharsPerToken))
+		} else {
+			res.OutTokens = tokenizer.Count(totalContent, r.cfg.ModelName)
+		}
+		res.TokensEstimated = true
 	}
 
-	if res.Status == "" {
-		if ctx.Err() == context.DeadlineExceeded {
+	if errors.Is(context.Cause(ctx), errFirstByteTimeout) {
+		// Takes precedence over a parse error from the stream being cut off
+		res.Status = result.StatusFirstByteTimeout
+		res.Err = fmt.Sprintf("no response within %ds (-first-byte-timeout)", r.cfg.FirstByteTimeoutSec)
+	} else if res.Status == "" {
+		if ctx.Err() == context.DeadlineExceeded && gotFirstContent {
+			res.Status = result.StatusPartial
+			res.Err = "request timeout after partial content"
+		} else if ctx.Err() == context.DeadlineExceeded {
 			res.Status = result.StatusTimeout
 			res.Err = "request timeout"
 		} else if gotFirstContent {
 			res.Status = result.StatusOK
 		} else {
-			res.Status = result.StatusParseError
+			res.Status = result.StatusEmpty
 			res.Err = "no content received"
+			if res.FinishReason != "" {
+				res.Err += fmt.Sprintf(" (finish_reason=%s)", res.FinishReason)
+			}
 		}
 	}
+	if res.Status == result.StatusOK && len(r.scorers) > 0 {
+		res.Scores = r.score(input, visibleContent.String())
+	}
 
 	return res
 }
 
+// nonSpaceCount returns the number of non-whitespace characters in s.
+func nonSpaceCount(s string) int {
+	n := 0
+	for _, c := range s {
+		if !unicode.IsSpace(c) {
+			n++
+		}
+	}
+	return n
+}
+
+// hashContent returns a short hex SHA-256 digest used to compare responses.
+func hashContent(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:8])
+}
+
+// score rates response with every attached scorer. The prompt passed to the
+// scorers is the last user message of input.
+func (r *Runner) score(input workload.WorkloadInput, response string) map[string]float64 {
+	var prompt string
+	messages := input.ToMessages()
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == "user" {
+			prompt = messages[i].Content
+			break
+		}
+	}
+	scores := make(map[string]float64, len(r.scorers))
+	for _, s := range r.scorers {
+		scores[s.Name()] = s.Score(prompt, response)
+	}
+	return scores
+}
+
 func truncateString(s string, maxLen int) string {
 	if len(s) <= maxLen {
 		return s
@@ -0,0 +1,257 @@
+package runner
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/config"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/provider"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/result"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/workload"
+)
+
+// endlessProvider streams content until the request context is cancelled.
+type endlessProvider struct {
+	stopped chan struct{}
+}
+
+func (p *endlessProvider) Name() string { return "endless" }
+
+func (p *endlessProvider) StreamChat(ctx context.Context, cfg *config.GlobalConfig, input workload.WorkloadInput) (<-chan provider.StreamEvent, error) {
+	events := make(chan provider.StreamEvent)
+	go func() {
+		defer close(p.stopped)
+		defer close(events)
+		for {
+			select {
+			case events <- provider.StreamEvent{Type: provider.EventContent, Text: "0123456789"}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events, nil
+}
+
+func TestExecuteRequest_MaxResponseChars(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.MaxResponseChars = 95
+	cfg.Retries = 2
+	p := &endlessProvider{stopped: make(chan struct{})}
+
+	res := New(cfg, p).executeRequest(workload.NewSimpleWorkload("req-1", "hi", 8))
+	if res.Status != result.StatusTooLarge {
+		t.Fatalf("Status = %q (%s), want too_large", res.Status, res.Err)
+	}
+	if res.OutChars != 100 {
+		t.Errorf("OutChars = %d, want 100 (stopped at the first frame over the limit)", res.OutChars)
+	}
+	if res.Attempts != 0 {
+		t.Errorf("Attempts = %d, want no retries", res.Attempts)
+	}
+	<-p.stopped // The provider goroutine must exit once the request is aborted
+}
+
+// unwatchedProvider sends like the aliyun and bedrock parsers: without
+// watching ctx, ending with the read error once the request is cancelled.
+type unwatchedProvider struct {
+	stopped chan struct{}
+}
+
+func (p *unwatchedProvider) Name() string { return "unwatched" }
+
+func (p *unwatchedProvider) StreamChat(ctx context.Context, cfg *config.GlobalConfig, input workload.WorkloadInput) (<-chan provider.StreamEvent, error) {
+	events := make(chan provider.StreamEvent)
+	go func() {
+		defer close(p.stopped)
+		defer close(events)
+		for ctx.Err() == nil {
+			events <- provider.StreamEvent{Type: provider.EventContent, Text: "0123456789"}
+		}
+		events <- provider.StreamEvent{Type: provider.EventError, Err: ctx.Err()}
+	}()
+	return events, nil
+}
+
+func TestExecuteRequest_MaxResponseCharsDrainsStream(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.MaxResponseChars = 95
+	p := &unwatchedProvider{stopped: make(chan struct{})}
+
+	res := New(cfg, p).executeRequest(workload.NewSimpleWorkload("req-1", "hi", 8))
+	if res.Status != result.StatusTooLarge {
+		t.Fatalf("Status = %q (%s), want too_large", res.Status, res.Err)
+	}
+	select {
+	case <-p.stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("provider goroutine still blocked on send after the request was aborted")
+	}
+}
+
+// scriptedProvider streams a fixed list of content deltas.
+type scriptedProvider struct {
+	deltas   []string
+	endDelay time.Duration // Pause between the last delta and the end of the stream
+}
+
+func (p *scriptedProvider) Name() string { return "scripted" }
+
+func (p *scriptedProvider) StreamChat(ctx context.Context, cfg *config.GlobalConfig, input workload.WorkloadInput) (<-chan provider.StreamEvent, error) {
+	events := make(chan provider.StreamEvent, len(p.deltas)+1)
+	for _, d := range p.deltas {
+		events <- provider.StreamEvent{Type: provider.EventContent, Text: d}
+	}
+	go func() {
+		time.Sleep(p.endDelay)
+		events <- provider.StreamEvent{Type: provider.EventEnd, FinishReason: "stop"}
+		close(events)
+	}()
+	return events, nil
+}
+
+func TestExecuteRequest_ThinkTagFilter(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.ThinkTagFilter = true
+	cfg.JSONMode = true
+	p := &scriptedProvider{deltas: []string{"<think>", "Let me think", "</th", "ink>\n", `{"a": 1}`}}
+
+	res := New(cfg, p).executeRequest(workload.NewSimpleWorkload("req-1", "hi", 8))
+	if res.Status != result.StatusOK {
+		t.Fatalf("Status = %q (%s), want ok", res.Status, res.Err)
+	}
+	if res.OutChars != len("\n{\"a\": 1}") || res.ThinkChars != len("Let me think") {
+		t.Errorf("OutChars = %d, ThinkChars = %d; want the answer and the thinking lengths", res.OutChars, res.ThinkChars)
+	}
+	if !res.ValidJSON {
+		t.Error("ValidJSON = false, want the answer without the think block to parse")
+	}
+}
+
+func TestExecuteRequest_EstimatesTokensWithoutUsage(t *testing.T) {
+	for _, mode := range []string{"usage", "chars"} {
+		cfg := config.DefaultConfig()
+		cfg.TokenMode = mode
+		p := &scriptedProvider{deltas: []string{"tiktoken", " is great!"}}
+
+		res := New(cfg, p).executeRequest(workload.NewSimpleWorkload("req-1", "hi", 8))
+		if res.OutTokens != 6 || !res.TokensEstimated {
+			t.Errorf("token-mode %s: OutTokens = %d (estimated %v), want 6 estimated", mode, res.OutTokens, res.TokensEstimated)
+		}
+	}
+}
+
+func TestExecuteRequest_Empty(t *testing.T) {
+	res := New(config.DefaultConfig(), &scriptedProvider{}).executeRequest(workload.NewSimpleWorkload("req-1", "hi", 8))
+	if res.Status != result.StatusEmpty {
+		t.Errorf("Status = %q (%s), want empty", res.Status, res.Err)
+	}
+}
+
+func TestExecuteRequest_TTLT(t *testing.T) {
+	p := &scriptedProvider{deltas: []string{"a", "b"}, endDelay: 50 * time.Millisecond}
+	res := New(config.DefaultConfig(), p).executeRequest(workload.NewSimpleWorkload("req-1", "hi", 8))
+	if res.Status != result.StatusOK {
+		t.Fatalf("Status = %q (%s), want ok", res.Status, res.Err)
+	}
+	if res.TTLT <= 0 || res.Latency-res.TTLT < 50*time.Millisecond {
+		t.Errorf("TTLT = %v, Latency = %v; want the 50ms wait for the end of the stream between them", res.TTLT, res.Latency)
+	}
+}
+
+// silentProvider accepts every request and never sends anything.
+type silentProvider struct{}
+
+func (p *silentProvider) Name() string { return "silent" }
+
+func (p *silentProvider) StreamChat(ctx context.Context, cfg *config.GlobalConfig, input workload.WorkloadInput) (<-chan provider.StreamEvent, error) {
+	events := make(chan provider.StreamEvent)
+	context.AfterFunc(ctx, func() { close(events) })
+	return events, nil
+}
+
+func TestExecuteRequest_FirstByteTimeout(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.TimeoutSec = 30
+	cfg.FirstByteTimeoutSec = 1
+
+	res := New(cfg, &silentProvider{}).executeRequest(workload.NewSimpleWorkload("req-1", "hi", 8))
+	if res.Status != result.StatusFirstByteTimeout {
+		t.Fatalf("Status = %q (%s), want first_byte_timeout", res.Status, res.Err)
+	}
+	if res.Latency > 5*time.Second {
+		t.Errorf("Latency = %v, want the request to fail after -first-byte-timeout, not -timeout", res.Latency)
+	}
+}
+
+func TestAggregator_ResponseCountsBounded(t *testing.T) {
+	agg := newAggregator(true)
+	for i := 0; i < maxTrackedResponses+50; i++ {
+		agg.countResponse(fmt.Sprintf("unique-%d", i))
+		agg.countResponse("canned") // Repeats from the start, so it is always tracked
+	}
+	if len(agg.responseCounts) != maxTrackedResponses {
+		t.Errorf("tracked %d responses, want the cap %d", len(agg.responseCounts), maxTrackedResponses)
+	}
+	if got := agg.responseCounts["canned"]; got != maxTrackedResponses+50 {
+		t.Errorf("canned count = %d, want %d", got, maxTrackedResponses+50)
+	}
+	if agg.untracked == 0 {
+		t.Error("untracked = 0, want the responses beyond the cap counted")
+	}
+
+	exact := newAggregator(false)
+	for i := 0; i < maxTrackedResponses+50; i++ {
+		exact.countResponse(fmt.Sprintf("unique-%d", i))
+	}
+	if len(exact.responseCounts) != maxTrackedResponses+50 || exact.untracked != 0 {
+		t.Errorf("exact mode tracked %d (untracked %d), want every response", len(exact.responseCounts), exact.untracked)
+	}
+}
+
+func TestRun_StreamingWorkloads(t *testing.T) {
+	badLine := `{"conversations": [{"from": "robot", "value": "x"}]}`
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{"repeated", "one\ntwo\n", ""},
+		{"parse error", "one\ntwo\n" + badLine + "\n", "failed to parse line 3"},
+		{"every line bad", badLine + "\n" + badLine + "\n", "no prompts found"},
+		{"empty", "\n", "no prompts found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			cfg.TotalRequests = 5
+			cfg.Concurrency = 1
+			cfg.OutputDir = t.TempDir()
+			cfg.OutputFormat = "json"
+			cfg.StreamingStats = true
+			cfg.WorkloadFile = filepath.Join(t.TempDir(), "prompts.txt")
+			if err := os.WriteFile(cfg.WorkloadFile, []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			report, err := New(cfg, &scriptedProvider{deltas: []string{"ok"}}).Run()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Run: %v", err)
+				}
+				if report.Success != 5 {
+					t.Errorf("successes = %d, want 5", report.Success)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Run error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+package runner
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+	"runtime/metrics"
+	"sync"
+	"time"
+
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/result"
+)
+
+const (
+	schedLatencyMetric = "/sched/latencies:seconds"
+
+	// Thresholds of the client saturation heuristic
+	maxSchedLatencyP99 = 5 * time.Millisecond // Goroutines waiting this long to run means the process is CPU-starved
+	maxDispatchLagMin  = time.Second          // Falling this far behind the -rps schedule...
+	maxDispatchLagFrac = 0.1                  // ...or this share of the run, whichever is larger
+	busyWorkerShare    = 0.5                  // Share of dispatches that waited for a free worker
+)
+
+// clientMonitor watches the benchmark's own dispatch loop, so a run limited by
+// the client (too few workers, a lagging RPS ticker, a CPU-starved process) is
+// not mistaken for a slow server.
+type clientMonitor struct {
+	mu         sync.Mutex
+	start      time.Time
+	interval   time.Duration // Ideal gap between dispatches with -rps (0 = unlimited)
+	dispatched int
+	waited     int           // Dispatches that found every worker busy
+	waitTotal  time.Duration // Time spent waiting for a free worker
+	lag        time.Duration // How far the latest dispatch was behind the -rps schedule
+	maxLag     time.Duration
+	schedStart *metrics.Float64Histogram
+}
+
+func newClientMonitor(rps float64) *clientMonitor {
+	m := &clientMonitor{start: time.Now()}
+	if rps > 0 {
+		m.interval = time.Duration(float64(time.Second) / rps)
+	}
+	m.schedStart = readSchedLatencies()
+	return m
+}
+
+// recordDispatch records a job handed to a worker after waiting wait for one.
+func (m *clientMonitor) recordDispatch(wait time.Duration) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.dispatched++
+	if wait > 0 {
+		m.waited++
+		m.waitTotal += wait
+	}
+	if m.interval > 0 {
+		ideal := m.start.Add(time.Duration(m.dispatched) * m.interval)
+		m.lag = max(time.Since(ideal), 0)
+		m.maxLag = max(m.maxLag, m.lag)
+	}
+}
+
+// stats summarizes the run and applies the saturation heuristic.
+func (m *clientMonitor) stats(wallTime time.Duration) *result.ClientStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stats := &result.ClientStats{
+		GOMAXPROCS:       runtime.GOMAXPROCS(0),
+		DispatchLagMs:    durationMs(m.lag),
+		MaxDispatchLagMs: durationMs(m.maxLag),
+		WorkerWaitMs:     durationMs(m.waitTotal),
+	}
+	if m.dispatched > 0 {
+		stats.WorkerWaitRatio = float64(m.waited) / float64(m.dispatched)
+	}
+	if m.interval > 0 {
+		stats.TargetRPS = float64(time.Second) / float64(m.interval)
+	}
+	if wallTime > 0 {
+		stats.DispatchRPS = float64(m.dispatched) / wallTime.Seconds()
+	}
+	schedP99 := histogramPercentile(m.schedStart, readSchedLatencies(), 0.99)
+	stats.SchedLatencyP99Ms = durationMs(schedP99)
+
+	allowedLag := max(maxDispatchLagMin, time.Duration(float64(wallTime)*maxDispatchLagFrac))
+	switch {
+	case schedP99 > maxSchedLatencyP99:
+		stats.Saturated = true
+		stats.Reason = fmt.Sprintf("Go scheduler latency p99 is %.1f ms (GOMAXPROCS=%d): the benchmark process is CPU-starved, so timings include client delay",
+			stats.SchedLatencyP99Ms, stats.GOMAXPROCS)
+	case m.interval > 0 && m.lag > allowedLag && stats.WorkerWaitRatio >= busyWorkerShare:
+		stats.Saturated = true
+		stats.Reason = fmt.Sprintf("dispatch fell %.1f s behind the -rps schedule because every worker was busy for %.0f%% of requests: achieved RPS is capped by -concurrency/-max-in-flight",
+			m.lag.Seconds(), stats.WorkerWaitRatio*100)
+	case m.interval > 0 && m.lag > allowedLag:
+		stats.Saturated = true
+		stats.Reason = fmt.Sprintf("dispatch fell %.1f s behind the -rps schedule while workers were free: the benchmark tool cannot send at the requested rate",
+			m.lag.Seconds())
+	}
+	return stats
+}
+
+// readSchedLatencies reads the runtime's cumulative goroutine scheduling latency histogram.
+func readSchedLatencies() *metrics.Float64Histogram {
+	samples := []metrics.Sample{{Name: schedLatencyMetric}}
+	metrics.Read(samples)
+	if samples[0].Value.Kind() != metrics.KindFloat64Histogram {
+		return nil
+	}
+	return samples[0].Value.Float64Histogram()
+}
+
+// histogramPercentile returns the q-th percentile of the samples recorded
+// between the before and after snapshots of a cumulative histogram, using
+// each bucket's upper bound.
+func histogramPercentile(before, after *metrics.Float64Histogram, q float64) time.Duration {
+	if before == nil || after == nil || len(before.Counts) != len(after.Counts) {
+		return 0
+	}
+	var total uint64
+	for i := range after.Counts {
+		total += after.Counts[i] - before.Counts[i]
+	}
+	if total == 0 {
+		return 0
+	}
+	target := uint64(math.Ceil(q * float64(total)))
+	var seen uint64
+	for i := range after.Counts {
+		seen += after.Counts[i] - before.Counts[i]
+		if seen >= target {
+			upper := after.Buckets[i+1]
+			if math.IsInf(upper, 1) {
+				upper = after.Buckets[i]
+			}
+			return time.Duration(upper * float64(time.Second))
+		}
+	}
+	return 0
+}
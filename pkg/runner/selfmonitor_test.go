@@ -0,0 +1,74 @@
+package runner
+
+import (
+	"math"
+	"runtime/metrics"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHistogramPercentile(t *testing.T) {
+	buckets := []float64{0, 0.001, 0.01, 0.1, math.Inf(1)}
+	before := &metrics.Float64Histogram{Counts: []uint64{5, 5, 0, 0}, Buckets: buckets}
+	after := &metrics.Float64Histogram{Counts: []uint64{95, 14, 0, 1}, Buckets: buckets}
+
+	// The 100 new samples: 90 under 1ms, 9 under 10ms, 1 of 100ms or more
+	tests := []struct {
+		q    float64
+		want time.Duration
+	}{
+		{0.5, time.Millisecond},
+		{0.95, 10 * time.Millisecond},
+		{0.99, 10 * time.Millisecond},
+		{1, 100 * time.Millisecond}, // Unbounded bucket reports its lower bound
+	}
+	for _, tt := range tests {
+		if got := histogramPercentile(before, after, tt.q); got != tt.want {
+			t.Errorf("p%g = %v, want %v", tt.q*100, got, tt.want)
+		}
+	}
+	if got := histogramPercentile(after, after, 0.99); got != 0 {
+		t.Errorf("no new samples: got %v, want 0", got)
+	}
+}
+
+func TestClientMonitor_DispatchLag(t *testing.T) {
+	tests := []struct {
+		name      string
+		wait      time.Duration // Worker wait per dispatch
+		saturated bool
+		reason    string
+	}{
+		{"on schedule", 0, false, ""},
+		{"busy workers", 10 * time.Millisecond, true, "every worker was busy"},
+		{"idle workers", 0, true, "while workers were free"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newClientMonitor(100) // 10ms apart
+			dispatches := 100
+			if tt.saturated {
+				// A run that began 3s ago but only dispatched 100 requests (1s of schedule)
+				m.start = m.start.Add(-3 * time.Second)
+			} else {
+				m.start = m.start.Add(-time.Second)
+			}
+			for i := 0; i < dispatches; i++ {
+				m.recordDispatch(tt.wait)
+			}
+
+			stats := m.stats(3 * time.Second)
+			if stats.TargetRPS != 100 {
+				t.Errorf("TargetRPS = %v, want 100", stats.TargetRPS)
+			}
+			if stats.Saturated != tt.saturated {
+				t.Fatalf("Saturated = %v (%s), want %v", stats.Saturated, stats.Reason, tt.saturated)
+			}
+			if !strings.Contains(stats.Reason, tt.reason) {
+				t.Errorf("Reason = %q, want it to mention %q", stats.Reason, tt.reason)
+			}
+		})
+	}
+}
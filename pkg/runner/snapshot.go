@@ -0,0 +1,38 @@
+package runner
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/result"
+)
+
+// startSnapshots rewrites the report files every -snapshot-interval with the
+// report returned by build, so a long run can be watched while it is still
+// going. build must be safe to call concurrently with the running benchmark.
+// The returned function stops the snapshots and waits for one in progress.
+func (r *Runner) startSnapshots(build func() *result.BenchmarkReport) (stop func()) {
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		ticker := time.NewTicker(time.Duration(r.cfg.SnapshotSec) * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				report := build()
+				report.Snapshot = true
+				if err := r.writeReports(report, false); err != nil {
+					fmt.Fprintf(r.cfg.Stdout(), "Warning: failed to write report snapshot: %v\n", err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-finished
+	}
+}
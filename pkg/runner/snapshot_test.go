@@ -0,0 +1,48 @@
+package runner
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/config"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/result"
+)
+
+func TestStartSnapshots(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.OutputDir = t.TempDir()
+	cfg.OutputFormat = "json"
+	cfg.SnapshotSec = 1
+
+	stop := New(cfg, &scriptedProvider{}).startSnapshots(func() *result.BenchmarkReport {
+		return &result.BenchmarkReport{TotalRequests: 3}
+	})
+	path := filepath.Join(cfg.OutputDir, "summary.json")
+	deadline := time.Now().Add(3 * time.Second)
+	for {
+		if _, err := os.Stat(path); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			stop()
+			t.Fatal("no snapshot written within 3s")
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	stop()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report result.BenchmarkReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatal(err)
+	}
+	if !report.Snapshot || report.TotalRequests != 3 {
+		t.Errorf("snapshot = %v with %d requests, want true with 3", report.Snapshot, report.TotalRequests)
+	}
+}
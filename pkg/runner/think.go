@@ -0,0 +1,68 @@
+package runner
+
+import "strings"
+
+const (
+	thinkOpenTag  = "<think>"
+	thinkCloseTag = "</think>"
+)
+
+// thinkFilter splits streamed content into visible text and inline
+// <think>...</think> blocks (-think-tag-filter). A tag may be split across
+// deltas, so a trailing partial tag is held back until the next delta.
+type thinkFilter struct {
+	inThink bool
+	pending string
+}
+
+// feed returns the visible and thinking parts of the next delta; tags are dropped.
+func (f *thinkFilter) feed(text string) (visible, think string) {
+	s := f.pending + text
+	f.pending = ""
+	var vis, th strings.Builder
+	emit := func(part string) {
+		if f.inThink {
+			th.WriteString(part)
+		} else {
+			vis.WriteString(part)
+		}
+	}
+	for s != "" {
+		tag := thinkOpenTag
+		if f.inThink {
+			tag = thinkCloseTag
+		}
+		if i := strings.Index(s, tag); i >= 0 {
+			emit(s[:i])
+			s = s[i+len(tag):]
+			f.inThink = !f.inThink
+			continue
+		}
+		keep := partialTagLen(s, tag)
+		emit(s[:len(s)-keep])
+		f.pending = s[len(s)-keep:]
+		break
+	}
+	return vis.String(), th.String()
+}
+
+// flush returns text held back at the end of the stream. An unclosed block
+// stays thinking, as in summarizer's cleanResponse.
+func (f *thinkFilter) flush() (visible, think string) {
+	s := f.pending
+	f.pending = ""
+	if f.inThink {
+		return "", s
+	}
+	return s, ""
+}
+
+// partialTagLen returns the length of the longest suffix of s that is a proper prefix of tag.
+func partialTagLen(s, tag string) int {
+	for n := min(len(s), len(tag)-1); n > 0; n-- {
+		if strings.HasSuffix(s, tag[:n]) {
+			return n
+		}
+	}
+	return 0
+}
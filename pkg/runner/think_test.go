@@ -0,0 +1,40 @@
+package runner
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestThinkFilter(t *testing.T) {
+	tests := []struct {
+		name    string
+		deltas  []string
+		visible string
+		think   string
+	}{
+		{"no tags", []string{"Hello", " world"}, "Hello world", ""},
+		{"one delta", []string{"<think>plan</think>Answer"}, "Answer", "plan"},
+		{"split tags", []string{"<thi", "nk>pl", "an</th", "ink>Ans", "wer"}, "Answer", "plan"},
+		{"false partial", []string{"a <", "b"}, "a <b", ""},
+		{"unclosed", []string{"<think>still thinking <"}, "", "still thinking <"},
+		{"trailing partial", []string{"x</t"}, "x</t", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var f thinkFilter
+			var vis, th strings.Builder
+			for _, d := range tt.deltas {
+				v, k := f.feed(d)
+				vis.WriteString(v)
+				th.WriteString(k)
+			}
+			v, k := f.flush()
+			vis.WriteString(v)
+			th.WriteString(k)
+			if vis.String() != tt.visible || th.String() != tt.think {
+				t.Errorf("visible = %q, think = %q; want %q, %q", vis.String(), th.String(), tt.visible, tt.think)
+			}
+		})
+	}
+}
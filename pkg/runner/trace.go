@@ -0,0 +1,87 @@
+package runner
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/result"
+)
+
+// requestTrace records connection and request phases of one HTTP request, so
+// TTFT can be split into network time and server time.
+type requestTrace struct {
+	mu           sync.Mutex
+	connectStart time.Time // First DNS lookup or dial
+	connectDone  time.Time
+	tlsStart     time.Time
+	tlsDone      time.Time
+	gotConn      time.Time
+	wroteRequest time.Time
+	firstByte    time.Time
+	reused       bool
+}
+
+// withTrace returns a context that records the phases of requests made with it.
+func (t *requestTrace) withTrace(ctx context.Context) context.Context {
+	// Callbacks may run on transport goroutines, e.g. parallel dials
+	mark := func(ts *time.Time, first bool) {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if !first || ts.IsZero() {
+			*ts = time.Now()
+		}
+	}
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		DNSStart:          func(httptrace.DNSStartInfo) { mark(&t.connectStart, true) },
+		ConnectStart:      func(string, string) { mark(&t.connectStart, true) },
+		ConnectDone:       func(string, string, error) { mark(&t.connectDone, false) },
+		TLSHandshakeStart: func() { mark(&t.tlsStart, true) },
+		TLSHandshakeDone:  func(tls.ConnectionState, error) { mark(&t.tlsDone, false) },
+		GotConn: func(info httptrace.GotConnInfo) {
+			mark(&t.gotConn, false)
+			t.mu.Lock()
+			t.reused = info.Reused
+			t.mu.Unlock()
+		},
+		WroteRequest:         func(httptrace.WroteRequestInfo) { mark(&t.wroteRequest, false) },
+		GotFirstResponseByte: func() { mark(&t.firstByte, true) },
+	})
+}
+
+// apply stores the recorded phases in res. Phases that did not happen (for
+// example connect and TLS on a reused connection) stay zero.
+func (t *requestTrace) apply(res *result.RequestResult) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	res.ConnReused = t.reused
+	res.Connect = between(t.connectStart, t.connectDone)
+	res.TLS = between(t.tlsStart, t.tlsDone)
+	res.RequestWrite = between(t.gotConn, t.wroteRequest)
+	res.ServerFirstByte = between(t.wroteRequest, t.firstByte)
+}
+
+// between returns end - start, or 0 if either is unset.
+func between(start, end time.Time) time.Duration {
+	if start.IsZero() || end.IsZero() || end.Before(start) {
+		return 0
+	}
+	return end.Sub(start)
+}
+
+// durationMs returns d in milliseconds with microsecond precision.
+func durationMs(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000.0
+}
+
+// avgMs returns the mean of n durations summing to total, in milliseconds.
+func avgMs(total time.Duration, n int) float64 {
+	return durationMs(total) / float64(n)
+}
+
+// msDuration converts milliseconds (as written by durationMs) back to a duration.
+func msDuration(ms float64) time.Duration {
+	return time.Duration(ms * float64(time.Millisecond))
+}
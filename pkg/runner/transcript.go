@@ -0,0 +1,57 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/result"
+)
+
+// transcriptRecord is one line of transcript.jsonl (-record-all).
+type transcriptRecord struct {
+	ID           string               `json:"request_id"`
+	Status       result.RequestStatus `json:"status"`
+	Messages     json.RawMessage      `json:"messages"`
+	Response     string               `json:"response"`
+	FinishReason string               `json:"finish_reason,omitempty"`
+	Err          string               `json:"err,omitempty"`
+}
+
+// transcriptWriter writes the full exchange of every request to transcript.jsonl.
+type transcriptWriter struct {
+	path    string
+	f       *os.File
+	encoder *json.Encoder
+}
+
+func (r *Runner) newTranscriptWriter() (*transcriptWriter, error) {
+	path := filepath.Join(r.cfg.OutputDir, "transcript.jsonl")
+	f, err := createOrAppend(path, r.cfg.Resume)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create transcript file: %w", err)
+	}
+	enc := json.NewEncoder(f)
+	enc.SetEscapeHTML(false) // Keep responses byte-comparable with the model output
+	return &transcriptWriter{path: path, f: f, encoder: enc}, nil
+}
+
+func (w *transcriptWriter) write(res result.RequestResult) error {
+	rec := transcriptRecord{
+		ID:           res.ID,
+		Status:       res.Status,
+		Messages:     res.RecordedMessages,
+		Response:     res.RecordedResponse,
+		FinishReason: res.FinishReason,
+		Err:          res.Err,
+	}
+	if err := w.encoder.Encode(rec); err != nil {
+		return fmt.Errorf("failed to write transcript: %w", err)
+	}
+	return nil
+}
+
+func (w *transcriptWriter) Close() error {
+	return w.f.Close()
+}
@@ -0,0 +1,84 @@
+package runner
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/result"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/stats"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/workload"
+)
+
+// minWarmupWindow is the smallest window for -warmup-until-stable; fewer
+// requests give a P95 too noisy to compare.
+const minWarmupWindow = 20
+
+// warmupWindow returns the number of completed requests per stability window.
+func (r *Runner) warmupWindow() int {
+	if r.cfg.WarmupWindow > 0 {
+		return r.cfg.WarmupWindow
+	}
+	return max(minWarmupWindow, r.cfg.Concurrency)
+}
+
+// warmupUntilStable sends warmup requests from source at the benchmark's
+// concurrency until the P95 latency of a window of completed requests is
+// within -warmup-until-stable percent of the previous window, or -warmup-max
+// requests have been sent. Load is kept up across windows; requests still in
+// flight when the server is found stable finish as warmup.
+func (r *Runner) warmupUntilStable(source <-chan workload.WorkloadInput) *result.BenchmarkReport {
+	window := r.warmupWindow()
+	fmt.Fprintf(r.cfg.Stdout(), "Warming up until P95 latency is stable within %g%% (windows of %d requests, at most %d) with %d concurrency...\n",
+		r.cfg.WarmupStablePct, window, r.cfg.WarmupMax, r.cfg.Concurrency)
+
+	agg := newAggregator(r.cfg.StreamingStats)
+	var p95s []int64
+	var latencies []time.Duration // Successful requests of the current window
+	completed := 0
+	stable := false
+	stop := make(chan struct{})
+	start := time.Now()
+	r.runBatch(take(source, r.cfg.WarmupMax, stop), stop, func(res result.RequestResult) {
+		agg.add(res)
+		if stable {
+			return
+		}
+		completed++
+		if res.IsSuccess() {
+			latencies = append(latencies, res.Latency)
+		}
+		if completed%window != 0 {
+			return
+		}
+		p95 := stats.PercentileMs(latencies, 95)
+		latencies = latencies[:0]
+		p95s = append(p95s, p95)
+		if n := len(p95s); n > 1 && withinPct(p95s[n-2], p95, r.cfg.WarmupStablePct) {
+			stable = true
+			close(stop)
+		}
+	})
+
+	report := r.buildReport(agg, time.Since(start))
+	report.WarmupWindowP95Ms = p95s
+	report.WarmupStable = stable
+	if stable {
+		fmt.Fprintf(r.cfg.Stdout(), "P95 latency stable after %d warmup requests (window P95s: %v ms)\n", report.TotalRequests, p95s)
+	} else {
+		fmt.Fprintf(r.cfg.Stdout(), "⚠️  P95 latency did not stabilize within %d warmup requests (window P95s: %v ms); measuring anyway\n", report.TotalRequests, p95s)
+	}
+	return report
+}
+
+// withinPct reports whether cur differs from prev by at most pct percent of
+// prev. Windows without a successful request (P95 0) never match.
+func withinPct(prev, cur int64, pct float64) bool {
+	if prev <= 0 || cur <= 0 {
+		return false
+	}
+	diff := float64(cur - prev)
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff <= float64(prev)*pct/100
+}
@@ -0,0 +1,72 @@
+package runner
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/config"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/provider"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/workload"
+)
+
+// slowingProvider answers request k after k²/10 ms, so latency never levels off.
+type slowingProvider struct {
+	calls atomic.Int64
+}
+
+func (p *slowingProvider) Name() string { return "slowing" }
+
+func (p *slowingProvider) StreamChat(ctx context.Context, cfg *config.GlobalConfig, input workload.WorkloadInput) (<-chan provider.StreamEvent, error) {
+	k := p.calls.Add(1)
+	time.Sleep(time.Duration(k*k) * time.Millisecond / 10)
+	events := make(chan provider.StreamEvent, 2)
+	events <- provider.StreamEvent{Type: provider.EventContent, Text: "ok"}
+	events <- provider.StreamEvent{Type: provider.EventEnd, FinishReason: "stop"}
+	close(events)
+	return events, nil
+}
+
+func TestRunWarmupUntilStable(t *testing.T) {
+	tests := []struct {
+		name       string
+		p          provider.Provider
+		wantStable bool
+	}{
+		{"steady server", &scriptedProvider{deltas: []string{"ok"}, endDelay: 10 * time.Millisecond}, true},
+		{"slowing server", &slowingProvider{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.DefaultConfig()
+			cfg.TotalRequests = 3
+			cfg.Concurrency = 1
+			cfg.WarmupStablePct = 20
+			cfg.WarmupWindow = 5
+			cfg.WarmupMax = 25
+			cfg.OutputDir = t.TempDir()
+			cfg.OutputFormat = "json"
+
+			report, err := New(cfg, tt.p).Run()
+			if err != nil {
+				t.Fatalf("Run: %v", err)
+			}
+			w := report.WarmupReport
+			if w == nil {
+				t.Fatal("no warmup report")
+			}
+			if w.WarmupStable != tt.wantStable {
+				t.Errorf("warmup stable = %v (window P95s %v), want %v", w.WarmupStable, w.WarmupWindowP95Ms, tt.wantStable)
+			}
+			// Stable after two windows, plus requests already dispatched; otherwise all of -warmup-max
+			if tt.wantStable && (w.TotalRequests < 10 || w.TotalRequests >= 25) || !tt.wantStable && w.TotalRequests != 25 {
+				t.Errorf("%d warmup requests sent (window P95s %v)", w.TotalRequests, w.WarmupWindowP95Ms)
+			}
+			if report.TotalRequests != 3 {
+				t.Errorf("measured %d requests, want 3", report.TotalRequests)
+			}
+		})
+	}
+}
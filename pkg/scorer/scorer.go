@@ -0,0 +1,122 @@
+// Package scorer checks response quality, so a fast endpoint that returns
+// garbage is caught. A scorer rates one response from 0 (bad) to 1 (good).
+package scorer
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// PassThreshold is the score at or above which a response passes.
+const PassThreshold = 0.5
+
+// Scorer rates a response to prompt between 0 and 1.
+type Scorer interface {
+	// Name identifies the scorer in reports.
+	Name() string
+	// Score rates response; prompt is the last user message.
+	Score(prompt, response string) float64
+}
+
+// Regex scores 1 when the response matches a regular expression.
+type Regex struct {
+	re *regexp.Regexp
+}
+
+// NewRegex compiles pattern into a Regex scorer.
+func NewRegex(pattern string) (*Regex, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex %q: %w", pattern, err)
+	}
+	return &Regex{re: re}, nil
+}
+
+func (s *Regex) Name() string { return "regex:" + s.re.String() }
+
+func (s *Regex) Score(prompt, response string) float64 {
+	return boolScore(s.re.MatchString(response))
+}
+
+// LengthRange scores 1 when the response has between Min and Max characters
+// (runes, after trimming whitespace). Max 0 means no upper bound.
+type LengthRange struct {
+	Min, Max int
+}
+
+func (s LengthRange) Name() string {
+	if s.Max == 0 {
+		return fmt.Sprintf("length:%d-", s.Min)
+	}
+	return fmt.Sprintf("length:%d-%d", s.Min, s.Max)
+}
+
+func (s LengthRange) Score(prompt, response string) float64 {
+	n := utf8.RuneCountInString(strings.TrimSpace(response))
+	return boolScore(n >= s.Min && (s.Max == 0 || n <= s.Max))
+}
+
+// NonEmpty scores 1 when the response has any non-whitespace content.
+type NonEmpty struct{}
+
+func (NonEmpty) Name() string { return "nonempty" }
+
+func (NonEmpty) Score(prompt, response string) float64 {
+	return boolScore(strings.TrimSpace(response) != "")
+}
+
+// ValidJSON scores 1 when the response parses as JSON.
+type ValidJSON struct{}
+
+func (ValidJSON) Name() string { return "json" }
+
+func (ValidJSON) Score(prompt, response string) float64 {
+	return boolScore(json.Valid([]byte(strings.TrimSpace(response))))
+}
+
+// Parse creates a built-in scorer from a spec: "nonempty", "json",
+// "regex:<pattern>" or "length:<min>-<max>" (max may be omitted).
+func Parse(spec string) (Scorer, error) {
+	kind, arg, _ := strings.Cut(spec, ":")
+	switch kind {
+	case "nonempty":
+		return NonEmpty{}, nil
+	case "json":
+		return ValidJSON{}, nil
+	case "regex":
+		if arg == "" {
+			return nil, fmt.Errorf("regex scorer needs a pattern, e.g. regex:(?i)paris")
+		}
+		return NewRegex(arg)
+	case "length":
+		minStr, maxStr, ok := strings.Cut(arg, "-")
+		if !ok {
+			return nil, fmt.Errorf("length scorer needs a range, e.g. length:20-2000")
+		}
+		min, err := strconv.Atoi(strings.TrimSpace(minStr))
+		if err != nil || min < 0 {
+			return nil, fmt.Errorf("invalid length scorer minimum %q", minStr)
+		}
+		var max int
+		if strings.TrimSpace(maxStr) != "" {
+			max, err = strconv.Atoi(strings.TrimSpace(maxStr))
+			if err != nil || max < min {
+				return nil, fmt.Errorf("invalid length scorer maximum %q", maxStr)
+			}
+		}
+		return LengthRange{Min: min, Max: max}, nil
+	default:
+		return nil, fmt.Errorf("unknown scorer %q (use nonempty, json, regex:<pattern> or length:<min>-<max>)", spec)
+	}
+}
+
+func boolScore(ok bool) float64 {
+	if ok {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,41 @@
+package scorer
+
+import "testing"
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		spec     string
+		response string
+		want     float64
+		wantErr  bool
+	}{
+		{"nonempty", "  hi ", 1, false},
+		{"nonempty", " \n ", 0, false},
+		{"json", `{"a": 1}`, 1, false},
+		{"json", `{"a": `, 0, false},
+		{"regex:(?i)paris", "The capital is Paris.", 1, false},
+		{"regex:^\\d+$", "forty-two", 0, false},
+		{"length:3-5", " abcd ", 1, false},
+		{"length:3-5", "abcdef", 0, false},
+		{"length:2-", "你好", 1, false},
+		{"regex:", "", 0, true},
+		{"regex:(", "", 0, true},
+		{"length:5-3", "", 0, true},
+		{"length:abc", "", 0, true},
+		{"bleu", "", 0, true},
+	}
+
+	for _, tt := range tests {
+		s, err := Parse(tt.spec)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Parse(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if got := s.Score("prompt", tt.response); got != tt.want {
+			t.Errorf("%s.Score(%q) = %v, want %v", s.Name(), tt.response, got, tt.want)
+		}
+	}
+}
@@ -5,10 +5,19 @@ package sse
 import (
 	"bufio"
 	"bytes"
+	"errors"
+	"fmt"
 	"io"
 	"strings"
+	"unicode/utf8"
 )
 
+// DefaultMaxEventSize is the default limit on the size of one event.
+const DefaultMaxEventSize = 16 << 20
+
+// ErrEventTooLarge is returned by Next when an event exceeds MaxEventSize.
+var ErrEventTooLarge = errors.New("SSE event too large")
+
 // Event represents a single SSE event.
 type Event struct {
 	ID    string // Event ID (optional)
@@ -18,14 +27,28 @@ type Event struct {
 }
 
 // Parser parses SSE events from an io.Reader.
+//
+// Lines end in LF or CRLF and are reassembled from reads of any size, so
+// frames split mid-line or mid-character by the network parse the same.
+// Parsing is lenient: malformed input is skipped or repaired where possible.
+// Set OnViolation to be told about each protocol violation that was tolerated
+// (invalid UTF-8, lines without a known field, a stream that ends mid-event).
+//
+// MaxEventSize bounds the bytes read for one event (including its field
+// names), so a server that never ends a line or an event cannot exhaust
+// memory; 0 means no limit.
 type Parser struct {
 	reader *bufio.Reader
+
+	OnViolation  func(msg string)
+	MaxEventSize int
 }
 
-// NewParser creates a new SSE parser.
+// NewParser creates a new SSE parser with MaxEventSize set to DefaultMaxEventSize.
 func NewParser(r io.Reader) *Parser {
 	return &Parser{
-		reader: bufio.NewReader(r),
+		reader:       bufio.NewReader(r),
+		MaxEventSize: DefaultMaxEventSize,
 	}
 }
 
@@ -34,13 +57,20 @@ func NewParser(r io.Reader) *Parser {
 func (p *Parser) Next() (*Event, error) {
 	var event Event
 	var dataLines []string
+	size := 0
 
 	for {
-		line, err := p.reader.ReadString('\n')
+		line, err := p.readLine(size)
+		lineSize := len(line)
+		size += lineSize
 		if err != nil {
 			if err == io.EOF {
+				if line != "" {
+					p.violation("stream ended in the middle of a line")
+				}
 				// Handle any remaining data
 				if len(dataLines) > 0 {
+					p.violation("stream ended without a blank line after the last event")
 					event.Data = strings.Join(dataLines, "\n")
 					return &event, nil
 				}
@@ -48,6 +78,9 @@ func (p *Parser) Next() (*Event, error) {
 			}
 			return nil, err
 		}
+		if p.OnViolation != nil && !utf8.ValidString(line) {
+			p.violation("invalid UTF-8 in line")
+		}
 
 		// Remove trailing newline
 		line = strings.TrimSuffix(line, "\n")
@@ -59,11 +92,13 @@ func (p *Parser) Next() (*Event, error) {
 				event.Data = strings.Join(dataLines, "\n")
 				return &event, nil
 			}
+			size = 0
 			continue
 		}
 
-		// Comment line (keep-alive)
+		// Comment line (keep-alive); not kept, so not counted toward the event size
 		if strings.HasPrefix(line, ":") {
+			size -= lineSize
 			continue
 		}
 
@@ -84,6 +119,26 @@ func (p *Parser) Next() (*Event, error) {
 	}
 }
 
+// readLine reads up to and including the next newline, failing with
+// ErrEventTooLarge once used bytes of the current event plus the line exceed
+// MaxEventSize.
+func (p *Parser) readLine(used int) (string, error) {
+	if p.MaxEventSize <= 0 {
+		return p.reader.ReadString('\n')
+	}
+	var buf []byte
+	for {
+		chunk, err := p.reader.ReadSlice('\n')
+		if used+len(buf)+len(chunk) > p.MaxEventSize {
+			return "", fmt.Errorf("%w: exceeds %d bytes", ErrEventTooLarge, p.MaxEventSize)
+		}
+		buf = append(buf, chunk...)
+		if err != bufio.ErrBufferFull {
+			return string(buf), err
+		}
+	}
+}
+
 func (p *Parser) parseField(event *Event, dataLines *[]string, field, value string) {
 	switch field {
 	case "id":
@@ -94,7 +149,24 @@ func (p *Parser) parseField(event *Event, dataLines *[]string, field, value stri
 		*dataLines = append(*dataLines, value)
 	case "retry":
 		// Parse retry value (not commonly used)
+	default:
+		// Allowed by the spec, but from an LLM API usually a frame missing "data:"
+		p.violation("line without a known field: " + truncate(field, 40))
+	}
+}
+
+func (p *Parser) violation(msg string) {
+	if p.OnViolation != nil {
+		p.OnViolation(msg)
+	}
+}
+
+// truncate shortens s to at most n bytes for violation messages.
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
 	}
+	return s[:n] + "..."
 }
 
 // ReadEvents reads all events from the stream and returns them.
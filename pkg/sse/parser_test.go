@@ -1,8 +1,13 @@
 package sse
 
 import (
+	"errors"
+	"io"
+	"math/rand"
+	"reflect"
 	"strings"
 	"testing"
+	"testing/iotest"
 )
 
 func TestParser_BasicEvent(t *testing.T) {
@@ -151,3 +156,145 @@ func TestParseEventBlock(t *testing.T) {
 		t.Errorf("expected data 'hello\\nworld', got '%s'", event.Data)
 	}
 }
+
+func TestParser_Violations(t *testing.T) {
+	tests := []struct {
+		name       string
+		input      string
+		violations int
+	}{
+		{"well formed", "data: one\n\n: keep-alive\n\ndata: two\n\n", 0},
+		{"invalid utf-8", "data: \xff\xfe\n\n", 1},
+		{"missing data prefix", "{\"choices\":[]}\n\ndata: ok\n\n", 1},
+		{"no final boundary", "data: one\n\ndata: two\n", 1},
+		{"truncated last line", "data: one\n\ndata: tw", 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			parser := NewParser(strings.NewReader(tt.input))
+			parser.OnViolation = func(msg string) { got = append(got, msg) }
+			for {
+				if _, err := parser.Next(); err != nil {
+					break
+				}
+			}
+			if len(got) != tt.violations {
+				t.Errorf("violations = %q, want %d", got, tt.violations)
+			}
+		})
+	}
+}
+
+func TestParser_MaxEventSize(t *testing.T) {
+	keepAlive := strings.Repeat(": ping\n\n", 20)
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{"within limit", "data: " + strings.Repeat("x", 50) + "\n\n", false},
+		{"long line", "data: " + strings.Repeat("x", 200) + "\n\n", true},
+		{"many lines", strings.Repeat("data: "+strings.Repeat("x", 20)+"\n", 10) + "\n", true},
+		{"keep-alives are not counted", keepAlive + "data: ok\n\n", false},
+		{"unterminated line", "data: " + strings.Repeat("x", 200), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewParser(strings.NewReader(tt.input))
+			p.MaxEventSize = 100
+			_, err := p.Next()
+			if gotErr := errors.Is(err, ErrEventTooLarge); gotErr != tt.wantErr {
+				t.Errorf("Next() error = %v, want ErrEventTooLarge %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// chunkReader returns the data in pieces of random length (1-maxChunk bytes),
+// like reads from a network connection.
+type chunkReader struct {
+	data     string
+	rng      *rand.Rand
+	maxChunk int
+}
+
+func (r *chunkReader) Read(p []byte) (int, error) {
+	if r.data == "" {
+		return 0, io.EOF
+	}
+	n := min(1+r.rng.Intn(r.maxChunk), len(p), len(r.data))
+	copy(p, r.data[:n])
+	r.data = r.data[n:]
+	return n, nil
+}
+
+func TestParser_SplitReads(t *testing.T) {
+	large := strings.Repeat("x", 100_000)
+	input := ": keep-alive\r\n\r\n" +
+		"data: {\"a\":1}\n\n" +
+		"id: 7\r\nevent: delta\r\ndata: line1\r\ndata: line2\r\n\r\n" +
+		"data: 你好，世界 🌍\n\n" +
+		"data:no-space\n: comment inside an event\ndata:  two spaces\n\n" +
+		"data: " + large + "\n\n" +
+		"data: [DONE]\n\n"
+	want := []*Event{
+		{Data: `{"a":1}`},
+		{ID: "7", Event: "delta", Data: "line1\nline2"},
+		{Data: "你好，世界 🌍"},
+		{Data: "no-space\n two spaces"},
+		{Data: large},
+		{Data: "[DONE]"},
+	}
+
+	readers := []struct {
+		name string
+		r    func() io.Reader
+	}{
+		{"one byte", func() io.Reader { return iotest.OneByteReader(strings.NewReader(input)) }},
+		{"half", func() io.Reader { return iotest.HalfReader(strings.NewReader(input)) }},
+		{"data with EOF", func() io.Reader { return iotest.DataErrReader(strings.NewReader(input)) }},
+		{"random small chunks", func() io.Reader { return &chunkReader{data: input, rng: rand.New(rand.NewSource(1)), maxChunk: 7} }},
+		{"random large chunks", func() io.Reader { return &chunkReader{data: input, rng: rand.New(rand.NewSource(2)), maxChunk: 9000} }},
+	}
+	for _, rd := range readers {
+		for _, maxSize := range []int{0, DefaultMaxEventSize} {
+			parser := NewParser(rd.r())
+			parser.MaxEventSize = maxSize
+			var violations []string
+			parser.OnViolation = func(msg string) { violations = append(violations, msg) }
+			var got []*Event
+			for {
+				event, err := parser.Next()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					t.Fatalf("%s (max %d): Next() error = %v", rd.name, maxSize, err)
+				}
+				got = append(got, event)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("%s (max %d): got %d events, want %d reassembled in order", rd.name, maxSize, len(got), len(want))
+			}
+			if len(violations) > 0 {
+				t.Errorf("%s (max %d): violations %q for a well-formed stream", rd.name, maxSize, violations)
+			}
+		}
+	}
+}
+
+func TestParser_EverySplitPoint(t *testing.T) {
+	input := "data: a\r\ndata: b\r\n\r\n: ping\n\nevent: x\ndata: ü\n\n"
+	want := []*Event{{Data: "a\nb"}, {Event: "x", Data: "ü"}}
+	for i := 1; i < len(input); i++ {
+		// Two reads, split at byte i
+		r := io.MultiReader(strings.NewReader(input[:i]), strings.NewReader(input[i:]))
+		got, err := ReadEvents(r)
+		if err != nil || !reflect.DeepEqual(got, want) {
+			t.Errorf("split at %d (%q|%q): got %+v, %v", i, input[:i], input[i:], got, err)
+		}
+	}
+}
@@ -2,6 +2,7 @@
 package stats
 
 import (
+	"math"
 	"sort"
 	"time"
 )
@@ -34,6 +35,29 @@ func Percentile(durations []time.Duration, p float64) time.Duration {
 	return time.Duration(float64(sorted[lower])*(1-weight) + float64(sorted[upper])*weight)
 }
 
+// PercentileFloat calculates the p-th percentile of the given values
+// using the same linear interpolation as Percentile.
+func PercentileFloat(values []float64, p float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	index := (p / 100.0) * float64(len(sorted)-1)
+	lower := int(index)
+	upper := lower + 1
+
+	if upper >= len(sorted) {
+		return sorted[len(sorted)-1]
+	}
+
+	weight := index - float64(lower)
+	return sorted[lower]*(1-weight) + sorted[upper]*weight
+}
+
 // PercentileMs calculates the p-th percentile and returns milliseconds.
 func PercentileMs(durations []time.Duration, p float64) int64 {
 	return Percentile(durations, p).Milliseconds()
@@ -57,6 +81,27 @@ func AverageMs(durations []time.Duration) float64 {
 	return float64(Average(durations).Microseconds()) / 1000.0
 }
 
+// MAD calculates the median absolute deviation: the median of each
+// duration's distance from the median. Unlike the standard deviation it is
+// not inflated by a few extreme values.
+func MAD(durations []time.Duration) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+
+	median := Percentile(durations, 50)
+	deviations := make([]time.Duration, len(durations))
+	for i, d := range durations {
+		deviations[i] = (d - median).Abs()
+	}
+	return Percentile(deviations, 50)
+}
+
+// IQR calculates the interquartile range (P75 - P25).
+func IQR(durations []time.Duration) time.Duration {
+	return Percentile(durations, 75) - Percentile(durations, 25)
+}
+
 // Sum calculates the sum of the given integers.
 func Sum(values []int) int {
 	var sum int
@@ -74,3 +119,44 @@ func DurationsToMs(durations []time.Duration) []int64 {
 	}
 	return result
 }
+
+// MeanStdDev returns the mean and sample standard deviation of the values.
+// The standard deviation is 0 for fewer than two values.
+func MeanStdDev(values []float64) (mean, stddev float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+	if len(values) < 2 {
+		return mean, 0
+	}
+
+	var sq float64
+	for _, v := range values {
+		sq += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(sq / float64(len(values)-1))
+}
+
+// GeometricMean returns the geometric mean of the values, the appropriate
+// average for rates such as tokens/s because one very fast request cannot
+// dominate it. Values <= 0 have no logarithm and are skipped; the result is 0
+// if no positive value remains.
+func GeometricMean(values []float64) float64 {
+	var logSum float64
+	n := 0
+	for _, v := range values {
+		if v > 0 {
+			logSum += math.Log(v)
+			n++
+		}
+	}
+	if n == 0 {
+		return 0
+	}
+	return math.Exp(logSum / float64(n))
+}
@@ -1,6 +1,7 @@
 package stats
 
 import (
+	"math"
 	"testing"
 	"time"
 )
@@ -140,3 +141,106 @@ func TestDurationsToMs(t *testing.T) {
 		}
 	}
 }
+
+func TestMeanStdDev(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		mean   float64
+		stddev float64
+	}{
+		{name: "empty", values: nil, mean: 0, stddev: 0},
+		{name: "single", values: []float64{5}, mean: 5, stddev: 0},
+		{name: "sample stddev", values: []float64{2, 4, 4, 4, 5, 5, 7, 9}, mean: 5, stddev: 2.138089935299395},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mean, stddev := MeanStdDev(tt.values)
+			if mean != tt.mean {
+				t.Errorf("expected mean %f, got %f", tt.mean, mean)
+			}
+			if diff := stddev - tt.stddev; diff > 1e-9 || diff < -1e-9 {
+				t.Errorf("expected stddev %f, got %f", tt.stddev, stddev)
+			}
+		})
+	}
+}
+
+func TestPercentileFloat(t *testing.T) {
+	values := []float64{40, 10, 30, 20, 50}
+
+	tests := []struct {
+		p    float64
+		want float64
+	}{
+		{0, 10},
+		{50, 30},
+		{95, 48},
+		{100, 50},
+	}
+	for _, tt := range tests {
+		if got := PercentileFloat(values, tt.p); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("PercentileFloat(p=%v) = %v, want %v", tt.p, got, tt.want)
+		}
+	}
+	if got := PercentileFloat(nil, 50); got != 0 {
+		t.Errorf("PercentileFloat(nil) = %v, want 0", got)
+	}
+}
+
+func TestMADAndIQR(t *testing.T) {
+	ms := func(values ...int) []time.Duration {
+		durations := make([]time.Duration, len(values))
+		for i, v := range values {
+			durations[i] = time.Duration(v) * time.Millisecond
+		}
+		return durations
+	}
+
+	tests := []struct {
+		name   string
+		values []time.Duration
+		mad    time.Duration
+		iqr    time.Duration
+	}{
+		{"empty", nil, 0, 0},
+		{"single", ms(100), 0, 0},
+		// Median 30, deviations 20,10,0,10,970 -> MAD 10; the 1000ms spike does not inflate it
+		{"with spike", ms(10, 20, 30, 40, 1000), 10 * time.Millisecond, 20 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := MAD(tt.values); got != tt.mad {
+				t.Errorf("MAD = %v, want %v", got, tt.mad)
+			}
+			if got := IQR(tt.values); got != tt.iqr {
+				t.Errorf("IQR = %v, want %v", got, tt.iqr)
+			}
+		})
+	}
+}
+
+func TestGeometricMean(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   float64
+	}{
+		{"empty", nil, 0},
+		{"single", []float64{42}, 42},
+		{"rates", []float64{10, 40}, 20},
+		{"outlier", []float64{1, 1, 1000}, 10},
+		{"zeros skipped", []float64{0, 4, 9, 0}, 6},
+		{"only zeros", []float64{0, 0}, 0},
+		{"negative skipped", []float64{-5, 2, 8}, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := GeometricMean(tt.values); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("GeometricMean(%v) = %v, want %v", tt.values, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package stats
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// StreamingPercentile estimates a single percentile in constant memory using
+// the P² algorithm (Jain & Chlamtac, 1985). It is safe for concurrent use.
+type StreamingPercentile struct {
+	mu    sync.Mutex
+	p     float64    // Target quantile in [0, 1]
+	count int        // Number of observations
+	q     [5]float64 // Marker heights
+	n     [5]float64 // Actual marker positions (1-based)
+	np    [5]float64 // Desired marker positions
+	dn    [5]float64 // Desired position increments
+}
+
+// NewStreamingPercentile creates an estimator for the p-th percentile (0-100).
+func NewStreamingPercentile(p float64) *StreamingPercentile {
+	q := p / 100.0
+	return &StreamingPercentile{
+		p:  q,
+		np: [5]float64{1, 1 + 2*q, 1 + 4*q, 3 + 2*q, 5},
+		dn: [5]float64{0, q / 2, q, (1 + q) / 2, 1},
+	}
+}
+
+// Add records an observation.
+func (s *StreamingPercentile) Add(x float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Collect the first five observations verbatim
+	if s.count < 5 {
+		s.q[s.count] = x
+		s.count++
+		if s.count == 5 {
+			sort.Float64s(s.q[:])
+			for i := range s.n {
+				s.n[i] = float64(i + 1)
+			}
+		}
+		return
+	}
+	s.count++
+
+	// Find the cell k containing x, extending the extremes if needed
+	var k int
+	switch {
+	case x < s.q[0]:
+		s.q[0] = x
+		k = 0
+	case x >= s.q[4]:
+		s.q[4] = x
+		k = 3
+	default:
+		for k = 0; k < 3; k++ {
+			if x < s.q[k+1] {
+				break
+			}
+		}
+	}
+
+	for i := k + 1; i < 5; i++ {
+		s.n[i]++
+	}
+	for i := range s.np {
+		s.np[i] += s.dn[i]
+	}
+
+	// Adjust the three middle markers
+	for i := 1; i <= 3; i++ {
+		d := s.np[i] - s.n[i]
+		if (d >= 1 && s.n[i+1]-s.n[i] > 1) || (d <= -1 && s.n[i-1]-s.n[i] < -1) {
+			sign := 1.0
+			if d < 0 {
+				sign = -1.0
+			}
+			qp := s.parabolic(i, sign)
+			if s.q[i-1] < qp && qp < s.q[i+1] {
+				s.q[i] = qp
+			} else {
+				s.q[i] = s.linear(i, sign)
+			}
+			s.n[i] += sign
+		}
+	}
+}
+
+func (s *StreamingPercentile) parabolic(i int, d float64) float64 {
+	return s.q[i] + d/(s.n[i+1]-s.n[i-1])*
+		((s.n[i]-s.n[i-1]+d)*(s.q[i+1]-s.q[i])/(s.n[i+1]-s.n[i])+
+			(s.n[i+1]-s.n[i]-d)*(s.q[i]-s.q[i-1])/(s.n[i]-s.n[i-1]))
+}
+
+func (s *StreamingPercentile) linear(i int, d float64) float64 {
+	j := i + int(d)
+	return s.q[i] + d*(s.q[j]-s.q[i])/(s.n[j]-s.n[i])
+}
+
+// Value returns the current estimate. With fewer than five observations
+// the exact percentile is returned.
+func (s *StreamingPercentile) Value() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.count == 0 {
+		return 0
+	}
+	if s.count < 5 {
+		sorted := make([]float64, s.count)
+		copy(sorted, s.q[:s.count])
+		sort.Float64s(sorted)
+		index := s.p * float64(len(sorted)-1)
+		lower := int(index)
+		if lower+1 >= len(sorted) {
+			return sorted[len(sorted)-1]
+		}
+		weight := index - float64(lower)
+		return sorted[lower]*(1-weight) + sorted[lower+1]*weight
+	}
+	return s.q[2]
+}
+
+// Count returns the number of observations.
+func (s *StreamingPercentile) Count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.count
+}
+
+// StreamingDurations tracks the average and P50/P95/P99 of a duration series
+// without retaining individual samples. It is safe for concurrent use.
+type StreamingDurations struct {
+	mu    sync.Mutex
+	count int
+	sum   time.Duration
+	p50   *StreamingPercentile
+	p95   *StreamingPercentile
+	p99   *StreamingPercentile
+}
+
+// NewStreamingDurations creates an empty streaming duration tracker.
+func NewStreamingDurations() *StreamingDurations {
+	return &StreamingDurations{
+		p50: NewStreamingPercentile(50),
+		p95: NewStreamingPercentile(95),
+		p99: NewStreamingPercentile(99),
+	}
+}
+
+// Add records a duration.
+func (s *StreamingDurations) Add(d time.Duration) {
+	s.mu.Lock()
+	s.count++
+	s.sum += d
+	s.mu.Unlock()
+
+	ms := float64(d) / float64(time.Millisecond)
+	s.p50.Add(ms)
+	s.p95.Add(ms)
+	s.p99.Add(ms)
+}
+
+// Count returns the number of recorded durations.
+func (s *StreamingDurations) Count() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.count
+}
+
+// AverageMs returns the mean in milliseconds.
+func (s *StreamingDurations) AverageMs() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.count == 0 {
+		return 0
+	}
+	return float64((s.sum / time.Duration(s.count)).Microseconds()) / 1000.0
+}
+
+// PercentileMs returns the estimated percentile in milliseconds.
+// Only 50, 95 and 99 are tracked; other values return 0.
+func (s *StreamingDurations) PercentileMs(p float64) int64 {
+	switch p {
+	case 50:
+		return int64(s.p50.Value())
+	case 95:
+		return int64(s.p95.Value())
+	case 99:
+		return int64(s.p99.Value())
+	}
+	return 0
+}
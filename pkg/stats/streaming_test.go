@@ -0,0 +1,107 @@
+package stats
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+	"time"
+)
+
+func exactPercentile(values []float64, p float64) float64 {
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+	index := (p / 100.0) * float64(len(sorted)-1)
+	lower := int(index)
+	if lower+1 >= len(sorted) {
+		return sorted[len(sorted)-1]
+	}
+	weight := index - float64(lower)
+	return sorted[lower]*(1-weight) + sorted[lower+1]*weight
+}
+
+func TestStreamingPercentile_MatchesExact(t *testing.T) {
+	rng := rand.New(rand.NewSource(42))
+
+	tests := []struct {
+		name      string
+		generate  func() float64
+		tolerance float64 // Relative tolerance
+	}{
+		{
+			name:      "uniform",
+			generate:  func() float64 { return rng.Float64() * 1000 },
+			tolerance: 0.02,
+		},
+		{
+			name:      "normal",
+			generate:  func() float64 { return 500 + rng.NormFloat64()*50 },
+			tolerance: 0.02,
+		},
+		{
+			name:      "exponential (latency-like long tail)",
+			generate:  func() float64 { return 100 + rng.ExpFloat64()*200 },
+			tolerance: 0.05,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			values := make([]float64, 20000)
+			estimators := map[float64]*StreamingPercentile{
+				50: NewStreamingPercentile(50),
+				95: NewStreamingPercentile(95),
+				99: NewStreamingPercentile(99),
+			}
+			for i := range values {
+				values[i] = tt.generate()
+				for _, e := range estimators {
+					e.Add(values[i])
+				}
+			}
+
+			for p, e := range estimators {
+				exact := exactPercentile(values, p)
+				got := e.Value()
+				if rel := math.Abs(got-exact) / exact; rel > tt.tolerance {
+					t.Errorf("p%.0f = %.2f, exact %.2f (relative error %.3f > %.3f)", p, got, exact, rel, tt.tolerance)
+				}
+			}
+		})
+	}
+}
+
+func TestStreamingPercentile_FewSamples(t *testing.T) {
+	e := NewStreamingPercentile(50)
+	if e.Value() != 0 {
+		t.Errorf("expected 0 for empty estimator, got %f", e.Value())
+	}
+
+	for _, v := range []float64{30, 10, 20} {
+		e.Add(v)
+	}
+	if e.Value() != 20 {
+		t.Errorf("expected exact median 20, got %f", e.Value())
+	}
+	if e.Count() != 3 {
+		t.Errorf("expected count 3, got %d", e.Count())
+	}
+}
+
+func TestStreamingDurations(t *testing.T) {
+	s := NewStreamingDurations()
+	for i := 1; i <= 100; i++ {
+		s.Add(time.Duration(i) * time.Millisecond)
+	}
+
+	if s.Count() != 100 {
+		t.Errorf("expected count 100, got %d", s.Count())
+	}
+	if avg := s.AverageMs(); avg != 50.5 {
+		t.Errorf("expected average 50.5ms, got %f", avg)
+	}
+	if p50 := s.PercentileMs(50); p50 < 48 || p50 > 52 {
+		t.Errorf("expected p50 ~50ms, got %d", p50)
+	}
+}
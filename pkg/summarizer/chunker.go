@@ -4,14 +4,21 @@ package summarizer
 import (
 	"strings"
 	"unicode/utf8"
+
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/tokenizer"
 )
 
+// MinRechunkSize is the smallest chunk (in characters) that is still split in
+// half after a context overflow.
+const MinRechunkSize = 500
+
 // Chunker splits text into chunks of specified size.
 type Chunker struct {
-	MaxChunkSize int // Maximum characters per chunk
+	MaxChunkSize int              // Maximum size per chunk, in the unit of Measure
+	Measure      func(string) int // Size of a piece of text; characters when nil
 }
 
-// NewChunker creates a new Chunker with the specified max chunk size.
+// NewChunker creates a new Chunker with the specified max chunk size in characters.
 func NewChunker(maxChunkSize int) *Chunker {
 	if maxChunkSize <= 0 {
 		maxChunkSize = 8000
@@ -19,6 +26,24 @@ func NewChunker(maxChunkSize int) *Chunker {
 	return &Chunker{MaxChunkSize: maxChunkSize}
 }
 
+// NewTokenChunker creates a Chunker whose chunks hold at most maxTokens
+// tokens of model's encoding, so chunks fit a context budget regardless of
+// the transcript's language.
+func NewTokenChunker(maxTokens int, model string) *Chunker {
+	return &Chunker{
+		MaxChunkSize: maxTokens,
+		Measure:      func(s string) int { return tokenizer.Count(s, model) },
+	}
+}
+
+// Size returns the size of text in the chunker's unit.
+func (c *Chunker) Size(text string) int {
+	if c.Measure == nil {
+		return utf8.RuneCountInString(text)
+	}
+	return c.Measure(text)
+}
+
 // Split splits the text into chunks, preferring natural paragraph boundaries.
 func (c *Chunker) Split(text string) []string {
 	// Split by double newlines (paragraphs)
@@ -52,10 +77,11 @@ func (c *Chunker) combineIntochunks(paragraphs []string) []string {
 
 	var chunks []string
 	var currentChunk strings.Builder
+	currentLen := 0
+	sepLen := c.Size("\n\n")
 
 	for _, para := range paragraphs {
-		paraLen := utf8.RuneCountInString(para)
-		currentLen := utf8.RuneCountInString(currentChunk.String())
+		paraLen := c.Size(para)
 
 		// If single paragraph exceeds max size, split it further
 		if paraLen > c.MaxChunkSize {
@@ -63,6 +89,7 @@ func (c *Chunker) combineIntochunks(paragraphs []string) []string {
 			if currentLen > 0 {
 				chunks = append(chunks, currentChunk.String())
 				currentChunk.Reset()
+				currentLen = 0
 			}
 			// Split large paragraph by lines
 			chunks = append(chunks, c.splitLargeParagraph(para)...)
@@ -70,18 +97,21 @@ func (c *Chunker) combineIntochunks(paragraphs []string) []string {
 		}
 
 		// Check if adding this paragraph exceeds limit
-		if currentLen+paraLen+2 > c.MaxChunkSize { // +2 for \n\n separator
+		if currentLen+paraLen+sepLen > c.MaxChunkSize {
 			if currentLen > 0 {
 				chunks = append(chunks, currentChunk.String())
 				currentChunk.Reset()
+				currentLen = 0
 			}
 		}
 
 		// Add paragraph to current chunk
 		if currentChunk.Len() > 0 {
 			currentChunk.WriteString("\n\n")
+			currentLen += sepLen
 		}
 		currentChunk.WriteString(para)
+		currentLen += paraLen
 	}
 
 	// Don't forget the last chunk
@@ -98,32 +128,37 @@ func (c *Chunker) splitLargeParagraph(para string) []string {
 
 	var chunks []string
 	var currentChunk strings.Builder
+	currentLen := 0
+	sepLen := c.Size("\n")
 
 	for _, line := range lines {
-		lineLen := utf8.RuneCountInString(line)
-		currentLen := utf8.RuneCountInString(currentChunk.String())
+		lineLen := c.Size(line)
 
 		// If single line exceeds max, just add it as a chunk
 		if lineLen > c.MaxChunkSize {
 			if currentLen > 0 {
 				chunks = append(chunks, currentChunk.String())
 				currentChunk.Reset()
+				currentLen = 0
 			}
 			chunks = append(chunks, line)
 			continue
 		}
 
-		if currentLen+lineLen+1 > c.MaxChunkSize {
+		if currentLen+lineLen+sepLen > c.MaxChunkSize {
 			if currentLen > 0 {
 				chunks = append(chunks, currentChunk.String())
 				currentChunk.Reset()
+				currentLen = 0
 			}
 		}
 
 		if currentChunk.Len() > 0 {
 			currentChunk.WriteString("\n")
+			currentLen += sepLen
 		}
 		currentChunk.WriteString(line)
+		currentLen += lineLen
 	}
 
 	if currentChunk.Len() > 0 {
@@ -132,3 +167,22 @@ func (c *Chunker) splitLargeParagraph(para string) []string {
 
 	return chunks
 }
+
+// splitInHalf re-chunks text at half its length, preferring paragraph and line
+// boundaries. It returns nil when text is shorter than 2*MinRechunkSize.
+func splitInHalf(text string) []string {
+	length := utf8.RuneCountInString(text)
+	if length < 2*MinRechunkSize {
+		return nil
+	}
+
+	half := (length + 1) / 2
+	parts := NewChunker(half).Split(text)
+	if len(parts) >= 2 {
+		return []string{parts[0], strings.Join(parts[1:], "\n\n")}
+	}
+
+	// No usable boundary near the middle: cut at the midpoint
+	runes := []rune(text)
+	return []string{string(runes[:half]), string(runes[half:])}
+}
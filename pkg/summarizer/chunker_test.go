@@ -0,0 +1,30 @@
+package summarizer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/tokenizer"
+)
+
+func TestTokenChunker_StaysWithinBudget(t *testing.T) {
+	var paragraphs []string
+	for i := 0; i < 40; i++ {
+		paragraphs = append(paragraphs, "李工：接口重构已经完成了百分之八十，预计月底可以提测。\nWang: the frontend depends on the new API.")
+	}
+	text := strings.Join(paragraphs, "\n\n")
+
+	const budget = 200
+	chunks := NewTokenChunker(budget, "gpt-4").Split(text)
+	if len(chunks) < 2 {
+		t.Fatalf("got %d chunks, want the transcript split", len(chunks))
+	}
+	for i, chunk := range chunks {
+		if n := tokenizer.Count(chunk, "gpt-4"); n > budget {
+			t.Errorf("chunk %d has %d tokens, budget %d", i+1, n, budget)
+		}
+	}
+	if got := strings.Join(chunks, "\n\n"); got != text {
+		t.Error("chunks do not add up to the original text")
+	}
+}
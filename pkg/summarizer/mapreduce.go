@@ -0,0 +1,214 @@
+package summarizer
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// summarySeparator separates the chunk summaries merged by one reduce request.
+const summarySeparator = "\n\n---\n\n"
+
+// mapResult is the outcome of summarizing one chunk in the map phase. An
+// overflowing chunk is split in half, so it may yield several summaries.
+type mapResult struct {
+	summaries []string
+	metrics   []ChunkMetrics
+	rechunks  int
+}
+
+// runMapReduce summarizes every chunk on its own with up to
+// SummaryConcurrency requests in flight (map), then merges the chunk
+// summaries in chunk order, a group at a time and with the same concurrency,
+// until one summary is left (reduce). Unlike runRefine, the chunks do not wait
+// for each other, so long transcripts finish much sooner.
+func (s *Summarizer) runMapReduce(chunks []string, metrics *SummaryMetrics, intermediateDir string) (string, error) {
+	workers := s.cfg.SummaryConcurrency
+	metrics.Strategy = "map_reduce"
+	metrics.Concurrency = workers
+	fmt.Fprintf(s.cfg.Stdout(), "Summarizing %d chunks with %d parallel requests (map-reduce)\n", len(chunks), workers)
+
+	// Map: results are stored by chunk index so the merge order never depends on timing
+	mapStart := time.Now()
+	results := make([]mapResult, len(chunks))
+	err := parallel(len(chunks), workers, func(i int) error {
+		var stream io.Writer
+		if len(chunks) == 1 && s.stream != nil {
+			stream = s.stream
+			fmt.Fprintf(s.cfg.Stdout(), "  Streaming final summary:\n\n")
+		}
+		res, err := s.mapChunk(chunks[i], i+1, stream)
+		if err != nil {
+			return fmt.Errorf("failed to process chunk %d: %w", i+1, err)
+		}
+		results[i] = res
+		fmt.Fprintf(s.cfg.Stdout(), "  ✓ Chunk %d/%d summarized\n", i+1, len(chunks))
+		if !s.cfg.NoIntermediate {
+			last := res.metrics[len(res.metrics)-1]
+			if _, err := s.saveIntermediate(intermediateDir, strings.Join(res.summaries, summarySeparator), last); err != nil {
+				fmt.Fprintf(s.cfg.Stdout(), "  Warning: failed to save intermediate result: %v\n", err)
+			}
+		}
+		return nil
+	})
+	metrics.MapTime = time.Since(mapStart)
+	if err != nil {
+		return "", err
+	}
+
+	var summaries []string
+	for _, res := range results {
+		summaries = append(summaries, res.summaries...)
+		metrics.RechunkCount += res.rechunks
+		for _, m := range res.metrics {
+			s.addChunkMetrics(metrics, m)
+		}
+	}
+	metrics.TotalChunks = len(chunks)
+
+	// Reduce: merge neighbouring summaries until one is left
+	reduceStart := time.Now()
+	next := len(chunks) + 1 // Index of the next request in the metrics
+	for level := 1; len(summaries) > 1; level++ {
+		groups := groupSummaries(summaries, s.chunker)
+		final := len(groups) == 1
+		fmt.Fprintf(s.cfg.Stdout(), "Merging %d summaries in %d groups (reduce round %d)...\n", len(summaries), len(groups), level)
+		if final && s.stream != nil {
+			fmt.Fprintf(s.cfg.Stdout(), "  Streaming final summary:\n\n")
+		}
+
+		merged := make([]string, len(groups))
+		groupMetrics := make([]*ChunkMetrics, len(groups))
+		err := parallel(len(groups), workers, func(g int) error {
+			group := groups[g]
+			if len(group) == 1 {
+				// A leftover summary moves on to the next round unchanged
+				merged[g] = group[0]
+				return nil
+			}
+			var stream io.Writer
+			if final {
+				stream = s.stream
+			}
+			sysPrompt, userPrompt := BuildPrompt(group[0], strings.Join(group[1:], summarySeparator), s.meetingTime)
+			response, m, err := s.chat(sysPrompt, userPrompt, next+g, stream)
+			if err != nil {
+				return fmt.Errorf("failed to merge summaries (reduce round %d): %w", level, err)
+			}
+			m.Phase = "reduce"
+			merged[g] = s.cleanResponse(response)
+			groupMetrics[g] = &m
+			return nil
+		})
+		if err != nil {
+			metrics.ReduceTime = time.Since(reduceStart)
+			return "", err
+		}
+		for _, m := range groupMetrics {
+			if m != nil {
+				s.addChunkMetrics(metrics, *m)
+			}
+		}
+		next += len(groups)
+		summaries = merged
+	}
+	metrics.ReduceTime = time.Since(reduceStart)
+
+	if wall := metrics.MapTime + metrics.ReduceTime; wall > 0 {
+		metrics.Speedup = metrics.TotalProcessingTime.Seconds() / wall.Seconds()
+	}
+	fmt.Fprintf(s.cfg.Stdout(), "Map %.2fs + reduce %.2fs for %.2fs of requests: %.2fx faster than sequential\n",
+		metrics.MapTime.Seconds(), metrics.ReduceTime.Seconds(), metrics.TotalProcessingTime.Seconds(), metrics.Speedup)
+	return summaries[0], nil
+}
+
+// mapChunk summarizes one chunk on its own. On a context overflow the chunk
+// is split in half and each half is summarized in turn.
+func (s *Summarizer) mapChunk(chunk string, index int, stream io.Writer) (mapResult, error) {
+	sysPrompt, userPrompt := BuildPrompt("", chunk, s.meetingTime)
+	response, m, err := s.chat(sysPrompt, userPrompt, index, stream)
+	if err != nil {
+		halves := splitInHalf(chunk)
+		if !IsContextOverflow(err) || halves == nil {
+			return mapResult{}, err
+		}
+		fmt.Fprintf(s.cfg.Stdout(), "  ⚠️  Token overflow at chunk %d, re-chunking it into %d + %d chars and retrying\n",
+			index, utf8.RuneCountInString(halves[0]), utf8.RuneCountInString(halves[1]))
+		res := mapResult{rechunks: 1}
+		for _, half := range halves {
+			part, err := s.mapChunk(half, index, stream)
+			if err != nil {
+				return mapResult{}, err
+			}
+			res.summaries = append(res.summaries, part.summaries...)
+			res.metrics = append(res.metrics, part.metrics...)
+			res.rechunks += part.rechunks
+		}
+		return res, nil
+	}
+	m.Phase = "map"
+	return mapResult{summaries: []string{s.cleanResponse(response)}, metrics: []ChunkMetrics{m}}, nil
+}
+
+// addChunkMetrics records one request in the totals.
+func (s *Summarizer) addChunkMetrics(metrics *SummaryMetrics, m ChunkMetrics) {
+	metrics.ChunkMetrics = append(metrics.ChunkMetrics, m)
+	metrics.TotalPromptTokens += m.PromptTokens
+	metrics.TotalCompletionTokens += m.CompletionTokens
+	metrics.TotalTokens += m.TotalTokens
+	metrics.TotalProcessingTime += m.ProcessingTime
+}
+
+// groupSummaries splits summaries, in order, into groups of at least two
+// whose combined size stays within the chunker's limit where possible. Only the last
+// group may hold a single summary, so every round shrinks the list.
+func groupSummaries(summaries []string, chunker *Chunker) [][]string {
+	var groups [][]string
+	var group []string
+	size := 0
+	for _, summary := range summaries {
+		n := chunker.Size(summary)
+		if len(group) >= 2 && size+n > chunker.MaxChunkSize {
+			groups = append(groups, group)
+			group, size = nil, 0
+		}
+		group = append(group, summary)
+		size += n
+	}
+	if len(group) > 0 {
+		groups = append(groups, group)
+	}
+	return groups
+}
+
+// parallel calls fn for 0..n-1 with at most workers calls running at once and
+// returns the first error. All calls run even if one fails.
+func parallel(n, workers int, fn func(i int) error) error {
+	if workers < 1 {
+		workers = 1
+	}
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var firstErr error
+	for i := 0; i < n; i++ {
+		sem <- struct{}{}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			if err := fn(i); err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+			}
+		}(i)
+	}
+	wg.Wait()
+	return firstErr
+}
@@ -0,0 +1,66 @@
+package summarizer
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/config"
+)
+
+func TestRunMapReduce_KeepsChunkOrder(t *testing.T) {
+	// The fake model echoes the [X] markers of its prompt in order. Earlier
+	// chunks answer more slowly, so requests finish in reverse order.
+	marker := regexp.MustCompile(`\[[A-Z]\]`)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ChatRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		markers := marker.FindAllString(req.Messages[1].Content, -1)
+		if len(markers) == 1 {
+			time.Sleep(time.Duration('F'-markers[0][1]) * 10 * time.Millisecond)
+		}
+		content := strings.Join(markers, "") + strings.Repeat("。", 8)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{{"message": map[string]string{"content": content}, "finish_reason": "stop"}},
+			"usage":   map[string]int{"prompt_tokens": 10, "completion_tokens": 5, "total_tokens": 15},
+		})
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	transcript := filepath.Join(dir, "meeting.txt")
+	paragraphs := []string{"[A] 第一段会议内容", "[B] 第二段会议内容", "[C] 第三段会议内容", "[D] 第四段会议内容", "[E] 第五段会议内容"}
+	if err := os.WriteFile(transcript, []byte(strings.Join(paragraphs, "\n\n")), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.URL = server.URL
+	cfg.ModelName = "test"
+	cfg.NoIntermediate = true
+	cfg.SummaryConcurrency = 5
+	summary, metrics, err := NewSummarizer(cfg, 20, "2026-01-01 10:00").RunWithMetrics(transcript, dir)
+	if err != nil {
+		t.Fatalf("RunWithMetrics: %v", err)
+	}
+
+	if got := marker.FindAllString(summary, -1); strings.Join(got, "") != "[A][B][C][D][E]" {
+		t.Errorf("summary markers = %v, want chunk order A..E", got)
+	}
+	if metrics.TotalChunks != 5 || metrics.Strategy != "map_reduce" {
+		t.Errorf("TotalChunks = %d, Strategy = %q, want 5 chunks with map_reduce", metrics.TotalChunks, metrics.Strategy)
+	}
+	// 11-char summaries with a 20-char limit merge in pairs over three rounds: 2 + 1 + 1 reduce requests
+	if len(metrics.ChunkMetrics) != 9 {
+		t.Errorf("%d requests recorded, want 5 map + 4 reduce", len(metrics.ChunkMetrics))
+	}
+	if metrics.Speedup <= 1 {
+		t.Errorf("Speedup = %.2f, want > 1 for parallel map requests", metrics.Speedup)
+	}
+}
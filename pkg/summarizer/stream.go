@@ -0,0 +1,104 @@
+package summarizer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/sse"
+)
+
+// streamChunk is one chunk of a streaming chat completion.
+type streamChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content          *string `json:"content"`
+			Reasoning        *string `json:"reasoning"`
+			ReasoningContent *string `json:"reasoning_content"`
+		} `json:"delta"`
+		FinishReason *string `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *ChatUsage `json:"usage"`
+}
+
+// readStream reads an SSE chat completion from r, copying content deltas to w
+// as they arrive, and fills resp as if the response had not been streamed.
+// Content, reasoning and reasoning_content stay nil when no such delta arrived.
+func readStream(r io.Reader, w io.Writer, resp *ChatResponse) error {
+	var content, reasoning, reasoningContent strings.Builder
+	var gotContent, gotReasoning, gotReasoningContent bool
+	var finishReason string
+
+	parser := sse.NewParser(r)
+	for {
+		event, err := parser.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read stream: %w", err)
+		}
+		data := strings.TrimSpace(event.Data)
+		if data == "" {
+			continue
+		}
+		if data == "[DONE]" {
+			break
+		}
+
+		var chunk streamChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return fmt.Errorf("failed to parse stream chunk: %w", err)
+		}
+		if chunk.Usage != nil {
+			resp.Usage = *chunk.Usage
+			if resp.Usage.TotalTokens == 0 {
+				resp.Usage.TotalTokens = resp.Usage.PromptTokens + resp.Usage.CompletionTokens
+			}
+		}
+		if len(chunk.Choices) == 0 {
+			continue
+		}
+		choice := chunk.Choices[0]
+		if d := choice.Delta.Content; d != nil {
+			gotContent = true
+			content.WriteString(*d)
+			io.WriteString(w, *d)
+		}
+		if d := choice.Delta.Reasoning; d != nil {
+			gotReasoning = true
+			reasoning.WriteString(*d)
+		}
+		if d := choice.Delta.ReasoningContent; d != nil {
+			gotReasoningContent = true
+			reasoningContent.WriteString(*d)
+		}
+		if choice.FinishReason != nil && *choice.FinishReason != "" {
+			finishReason = *choice.FinishReason
+		}
+	}
+
+	if gotContent {
+		// End the streamed text so later output starts on its own line
+		io.WriteString(w, "\n")
+	}
+
+	var choice ChatChoice
+	choice.FinishReason = finishReason
+	if gotContent {
+		choice.Message.Content = stringPtr(content.String())
+	}
+	if gotReasoning {
+		choice.Message.Reasoning = stringPtr(reasoning.String())
+	}
+	if gotReasoningContent {
+		choice.Message.ReasoningContent = stringPtr(reasoningContent.String())
+	}
+	resp.Choices = []ChatChoice{choice}
+	return nil
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
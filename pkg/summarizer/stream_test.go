@@ -0,0 +1,43 @@
+package summarizer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadStream(t *testing.T) {
+	body := strings.Join([]string{
+		`data: {"choices":[{"delta":{"reasoning_content":"thinking"}}]}`,
+		`data: {"choices":[{"delta":{"content":"## 会议"}}]}`,
+		`data: {"choices":[{"delta":{"content":"纪要"},"finish_reason":"stop"}]}`,
+		`data: {"choices":[],"usage":{"prompt_tokens":7,"completion_tokens":3}}`,
+		`data: [DONE]`,
+		``,
+	}, "\n\n")
+
+	var out strings.Builder
+	var resp ChatResponse
+	if err := readStream(strings.NewReader(body), &out, &resp); err != nil {
+		t.Fatalf("readStream: %v", err)
+	}
+
+	if got := out.String(); got != "## 会议纪要\n" {
+		t.Errorf("streamed %q, want %q", got, "## 会议纪要\n")
+	}
+	msg := resp.Choices[0].Message
+	if msg.Content == nil || *msg.Content != "## 会议纪要" {
+		t.Errorf("content = %v, want %q", msg.Content, "## 会议纪要")
+	}
+	if msg.ReasoningContent == nil || *msg.ReasoningContent != "thinking" {
+		t.Errorf("reasoning_content = %v, want %q", msg.ReasoningContent, "thinking")
+	}
+	if msg.Reasoning != nil {
+		t.Errorf("reasoning = %q, want nil", *msg.Reasoning)
+	}
+	if resp.Choices[0].FinishReason != "stop" {
+		t.Errorf("finish_reason = %q, want stop", resp.Choices[0].FinishReason)
+	}
+	if resp.Usage.TotalTokens != 10 {
+		t.Errorf("total_tokens = %d, want 10", resp.Usage.TotalTokens)
+	}
+}
@@ -4,9 +4,8 @@ package summarizer
 import (
 	"bytes"
 	"context"
-	"crypto/tls"
-	"crypto/x509"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -14,8 +13,11 @@ import (
 	"path/filepath"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/brianxiadong/llm-benchmark-kit/pkg/config"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/httpclient"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/provider"
 	"github.com/brianxiadong/llm-benchmark-kit/pkg/workload"
 )
 
@@ -28,10 +30,22 @@ type ChunkMetrics struct {
 	ProcessingTime   time.Duration `json:"processing_time"`
 	StartTime        time.Time     `json:"start_time"`
 	EndTime          time.Time     `json:"end_time"`
-	Overflowed       bool          `json:"overflowed"`               // Whether this chunk caused overflow
-	OverflowError    string        `json:"overflow_error,omitempty"` // Error message if overflowed
+	Overflowed       bool          `json:"overflowed"`                // Whether this chunk caused overflow
+	OverflowError    string        `json:"overflow_error,omitempty"`  // Error message if overflowed
+	Truncated        bool          `json:"truncated"`                 // Whether the response hit max_tokens (finish_reason=length)
+	ExtendAttempts   int           `json:"extend_attempts,omitempty"` // Number of retries with a larger max_tokens
+	ExtendSucceeded  bool          `json:"extend_succeeded"`          // Whether a retry produced a complete response
+	MaxTokens        int           `json:"max_tokens"`                // max_tokens used for the accepted response
+	Phase            string        `json:"phase,omitempty"`           // "map" or "reduce" in map-reduce mode
 }
 
+const (
+	// defaultChunkMaxTokens allows longer responses for thinking models that need reasoning + output.
+	defaultChunkMaxTokens = 16384
+	// maxChunkMaxTokens caps how far auto-extend doubles max_tokens.
+	maxChunkMaxTokens = 65536
+)
+
 // SummaryMetrics holds overall performance metrics for the summarization.
 type SummaryMetrics struct {
 	ModelName             string         `json:"model_name"`
@@ -48,6 +62,19 @@ type SummaryMetrics struct {
 	OverflowDetected      bool           `json:"overflow_detected"`            // Whether overflow was detected
 	OverflowAtChunk       int            `json:"overflow_at_chunk,omitempty"`  // Chunk number where overflow occurred
 	OverflowAtTokens      int            `json:"overflow_at_tokens,omitempty"` // Total tokens when overflow occurred
+	RechunkCount          int            `json:"rechunk_count,omitempty"`      // Overflowing chunks that were split in half and retried
+
+	Strategy    string        `json:"strategy"`              // "refine" (rolling summary) or "map_reduce"
+	Concurrency int           `json:"concurrency,omitempty"` // Parallel requests of the map and reduce phases (map_reduce only)
+	MapTime     time.Duration `json:"map_time,omitempty"`    // Wall time of the map phase
+	ReduceTime  time.Duration `json:"reduce_time,omitempty"` // Wall time of the reduce phase
+	Speedup     float64       `json:"speedup,omitempty"`     // Sum of request times / wall time of both phases (gain over sequential requests)
+}
+
+// IsContextOverflow reports whether err is a request rejected because the
+// prompt exceeds the model's context window, as classified by the provider.
+func IsContextOverflow(err error) bool {
+	return errors.Is(err, provider.ErrContextOverflow)
 }
 
 // Summarizer handles meeting transcript summarization.
@@ -55,46 +82,74 @@ type Summarizer struct {
 	cfg         *config.GlobalConfig
 	chunker     *Chunker
 	meetingTime string
+	stream      io.Writer // Receives the final chunk's summary as it streams (nil = no streaming)
 }
 
-// NewSummarizer creates a new Summarizer.
+// NewSummarizer creates a new Summarizer. Chunks hold at most chunkSize
+// characters, or cfg.ChunkTokens tokens when that is set.
 func NewSummarizer(cfg *config.GlobalConfig, chunkSize int, meetingTime string) *Summarizer {
+	chunker := NewChunker(chunkSize)
+	if cfg.ChunkTokens > 0 {
+		chunker = NewTokenChunker(cfg.ChunkTokens, cfg.ModelName)
+	}
 	return &Summarizer{
 		cfg:         cfg,
-		chunker:     NewChunker(chunkSize),
+		chunker:     chunker,
 		meetingTime: meetingTime,
 	}
 }
 
+// SetStreamWriter makes the final chunk use a streaming request and copies its
+// content to w as it arrives, so the user can watch the summary form.
+func (s *Summarizer) SetStreamWriter(w io.Writer) {
+	s.stream = w
+}
+
 // ChatRequest represents the OpenAI chat completion request.
 type ChatRequest struct {
-	Model     string                 `json:"model"`
-	Messages  []workload.ChatMessage `json:"messages"`
-	MaxTokens int                    `json:"max_tokens,omitempty"`
-	Stream    bool                   `json:"stream"`
+	Model         string                 `json:"model"`
+	Messages      []workload.ChatMessage `json:"messages"`
+	MaxTokens     int                    `json:"max_tokens,omitempty"`
+	Temperature   *float64               `json:"temperature,omitempty"`
+	TopP          *float64               `json:"top_p,omitempty"`
+	Seed          *int                   `json:"seed,omitempty"`
+	Stop          []string               `json:"stop,omitempty"`
+	Stream        bool                   `json:"stream"`
+	StreamOptions *StreamOptions         `json:"stream_options,omitempty"`
+}
+
+// StreamOptions asks the server to send token usage in the last stream chunk.
+type StreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
 }
 
 // ChatResponse represents the OpenAI chat completion response.
 type ChatResponse struct {
-	ID      string `json:"id"`
-	Object  string `json:"object"`
-	Created int64  `json:"created"`
-	Model   string `json:"model"`
-	Choices []struct {
-		Index   int `json:"index"`
-		Message struct {
-			Role             string  `json:"role"`
-			Content          *string `json:"content"`
-			Reasoning        *string `json:"reasoning"`
-			ReasoningContent *string `json:"reasoning_content"`
-		} `json:"message"`
-		FinishReason string `json:"finish_reason"`
-	} `json:"choices"`
-	Usage struct {
-		PromptTokens     int `json:"prompt_tokens"`
-		CompletionTokens int `json:"completion_tokens"`
-		TotalTokens      int `json:"total_tokens"`
-	} `json:"usage"`
+	ID      string       `json:"id"`
+	Object  string       `json:"object"`
+	Created int64        `json:"created"`
+	Model   string       `json:"model"`
+	Choices []ChatChoice `json:"choices"`
+	Usage   ChatUsage    `json:"usage"`
+}
+
+// ChatChoice is one choice of a chat completion response.
+type ChatChoice struct {
+	Index   int `json:"index"`
+	Message struct {
+		Role             string  `json:"role"`
+		Content          *string `json:"content"`
+		Reasoning        *string `json:"reasoning"`
+		ReasoningContent *string `json:"reasoning_content"`
+	} `json:"message"`
+	FinishReason string `json:"finish_reason"`
+}
+
+// ChatUsage is the token usage of a chat completion.
+type ChatUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
 }
 
 // Run processes the transcript file and generates a meeting summary.
@@ -113,9 +168,9 @@ func (s *Summarizer) RunWithMetrics(transcriptFile, outputDir string) (string, *
 	}
 
 	// Read the transcript file
-	content, err := os.ReadFile(transcriptFile)
+	content, err := ReadTranscript(transcriptFile, s.cfg.TranscriptEncoding)
 	if err != nil {
-		return "", nil, fmt.Errorf("failed to read transcript file: %w", err)
+		return "", nil, err
 	}
 
 	// Create output directory
@@ -125,31 +180,83 @@ func (s *Summarizer) RunWithMetrics(transcriptFile, outputDir string) (string, *
 
 	// Create intermediate results directory
 	intermediateDir := filepath.Join(outputDir, "intermediate")
-	if err := os.MkdirAll(intermediateDir, 0755); err != nil {
-		return "", nil, fmt.Errorf("failed to create intermediate directory: %w", err)
+	if !s.cfg.NoIntermediate {
+		if err := os.MkdirAll(intermediateDir, 0755); err != nil {
+			return "", nil, fmt.Errorf("failed to create intermediate directory: %w", err)
+		}
 	}
 
 	// Split into chunks
-	chunks := s.chunker.Split(string(content))
-	fmt.Printf("Transcript split into %d chunks\n", len(chunks))
+	chunks := s.chunker.Split(content)
+	fmt.Fprintf(s.cfg.Stdout(), "Transcript split into %d chunks\n", len(chunks))
 	metrics.TotalChunks = len(chunks)
 
-	// Process each chunk iteratively
 	var currentSummary string
-	for i, chunk := range chunks {
-		fmt.Printf("Processing chunk %d/%d...\n", i+1, len(chunks))
+	metrics.Strategy = "refine"
+	if s.cfg.SummaryConcurrency > 0 {
+		currentSummary, err = s.runMapReduce(chunks, metrics, intermediateDir)
+	} else {
+		currentSummary, err = s.runRefine(chunks, metrics, intermediateDir)
+	}
+	if err != nil {
+		return "", metrics, err
+	}
+
+	// Finalize metrics
+	metrics.EndTime = time.Now()
+	if metrics.TotalChunks > 0 {
+		metrics.AverageTimePerChunk = metrics.TotalProcessingTime / time.Duration(metrics.TotalChunks)
+	}
+	if metrics.TotalProcessingTime.Seconds() > 0 {
+		metrics.TokensPerSecond = float64(metrics.TotalCompletionTokens) / metrics.TotalProcessingTime.Seconds()
+	}
+
+	// Save final summary
+	finalPath := filepath.Join(outputDir, "meeting_summary.md")
+	if err := os.WriteFile(finalPath, []byte(currentSummary), 0644); err != nil {
+		return "", metrics, fmt.Errorf("failed to save final summary: %w", err)
+	}
+	fmt.Fprintf(s.cfg.Stdout(), "\n✅ Final summary saved to: %s\n", finalPath)
+
+	// Generate and save performance report
+	if err := s.savePerformanceReport(metrics, outputDir); err != nil {
+		fmt.Fprintf(s.cfg.Stdout(), "  Warning: failed to save performance report: %v\n", err)
+	}
+
+	return currentSummary, metrics, nil
+}
+
+// runRefine summarizes the chunks one after another, each request merging
+// the next chunk into the summary so far. An overflowing chunk is split in
+// half and retried; if it cannot be split, the last summary is kept.
+func (s *Summarizer) runRefine(chunks []string, metrics *SummaryMetrics, intermediateDir string) (string, error) {
+	var currentSummary string
+	for i := 0; i < len(chunks); i++ {
+		chunk := chunks[i]
+		fmt.Fprintf(s.cfg.Stdout(), "Processing chunk %d/%d...\n", i+1, len(chunks))
 
 		// Build the prompt
 		sysPrompt, userPrompt := BuildPrompt(currentSummary, chunk, s.meetingTime)
 
-		// Call the LLM and collect metrics
-		response, chunkMetrics, err := s.chat(sysPrompt, userPrompt, i+1)
+		// Call the LLM and collect metrics; the final chunk streams to the terminal if requested
+		var stream io.Writer
+		if i == len(chunks)-1 && s.stream != nil {
+			stream = s.stream
+			fmt.Fprintf(s.cfg.Stdout(), "  Streaming final summary:\n\n")
+		}
+		response, chunkMetrics, err := s.chat(sysPrompt, userPrompt, i+1, stream)
 		if err != nil {
-			// Check if it's an overflow error
-			if strings.Contains(strings.ToLower(err.Error()), "maximum context length") ||
-				strings.Contains(strings.ToLower(err.Error()), "context_length_exceeded") ||
-				strings.Contains(strings.ToLower(err.Error()), "token limit") ||
-				strings.Contains(strings.ToLower(err.Error()), "too many tokens") {
+			// On overflow, retry the chunk as two halves before giving up
+			if IsContextOverflow(err) {
+				if halves := splitInHalf(chunk); halves != nil {
+					metrics.RechunkCount++
+					fmt.Fprintf(s.cfg.Stdout(), "  ⚠️  Token overflow at chunk %d/%d, re-chunking it into %d + %d chars and retrying\n",
+						i+1, len(chunks), utf8.RuneCountInString(halves[0]), utf8.RuneCountInString(halves[1]))
+					chunks = append(chunks[:i], append(halves, chunks[i+1:]...)...)
+					i--
+					continue
+				}
+
 				// Mark overflow in metrics
 				chunkMetrics.Overflowed = true
 				chunkMetrics.OverflowError = err.Error()
@@ -158,20 +265,20 @@ func (s *Summarizer) RunWithMetrics(transcriptFile, outputDir string) (string, *
 				metrics.OverflowAtChunk = i + 1
 				metrics.OverflowAtTokens = metrics.TotalTokens
 
-				fmt.Printf("  ⚠️  Token overflow detected at chunk %d/%d (total tokens so far: %d)\n",
+				fmt.Fprintf(s.cfg.Stdout(), "  ⚠️  Token overflow detected at chunk %d/%d (total tokens so far: %d)\n",
 					i+1, len(chunks), metrics.TotalTokens)
-				fmt.Printf("  Error: %s\n", err.Error())
+				fmt.Fprintf(s.cfg.Stdout(), "  Error: %s\n", err.Error())
 
 				// Use the current summary as the final result
 				if currentSummary == "" {
-					return "", metrics, fmt.Errorf("overflow on first chunk, cannot continue: %w", err)
+					return "", fmt.Errorf("overflow on first chunk, cannot continue: %w", err)
 				}
 
-				fmt.Printf("  Using last successful summary as final result\n")
+				fmt.Fprintf(s.cfg.Stdout(), "  Using last successful summary as final result\n")
 				break
 			}
 			// Other errors - fail immediately
-			return "", metrics, fmt.Errorf("failed to process chunk %d: %w", i+1, err)
+			return "", fmt.Errorf("failed to process chunk %d: %w", i+1, err)
 		}
 
 		// Update metrics
@@ -183,46 +290,130 @@ func (s *Summarizer) RunWithMetrics(transcriptFile, outputDir string) (string, *
 
 		currentSummary = s.cleanResponse(response)
 
+		if s.cfg.NoIntermediate {
+			fmt.Fprintf(s.cfg.Stdout(), "  ✓ Chunk %d/%d processed (tokens: %d, time: %.2fs)\n",
+				i+1, len(chunks), chunkMetrics.TotalTokens, chunkMetrics.ProcessingTime.Seconds())
+			continue
+		}
+
 		// Save intermediate result
-		intermediatePath := filepath.Join(intermediateDir, fmt.Sprintf("chunk_%02d.md", i+1))
-		if err := os.WriteFile(intermediatePath, []byte(currentSummary), 0644); err != nil {
-			fmt.Printf("  Warning: failed to save intermediate result: %v\n", err)
+		intermediatePath, err := s.saveIntermediate(intermediateDir, currentSummary, chunkMetrics)
+		if err != nil {
+			fmt.Fprintf(s.cfg.Stdout(), "  Warning: failed to save intermediate result: %v\n", err)
 		}
 
-		fmt.Printf("  ✓ Chunk %d/%d processed (tokens: %d, time: %.2fs), saved to %s\n",
+		fmt.Fprintf(s.cfg.Stdout(), "  ✓ Chunk %d/%d processed (tokens: %d, time: %.2fs), saved to %s\n",
 			i+1, len(chunks), chunkMetrics.TotalTokens, chunkMetrics.ProcessingTime.Seconds(), intermediatePath)
 	}
 
-	// Finalize metrics
-	metrics.EndTime = time.Now()
-	if len(chunks) > 0 {
-		metrics.AverageTimePerChunk = metrics.TotalProcessingTime / time.Duration(len(chunks))
+	metrics.TotalChunks = len(chunks)
+	return currentSummary, nil
+}
+
+// IntermediateChunk is the structured form of an intermediate summary,
+// written when IntermediateFormat is "json".
+type IntermediateChunk struct {
+	ChunkIndex int          `json:"chunk_index"`
+	Summary    string       `json:"summary"`
+	Metrics    ChunkMetrics `json:"metrics"`
+}
+
+// saveIntermediate writes the running summary after a chunk as
+// chunk_NN.md, or chunk_NN.json with the chunk's token metrics.
+func (s *Summarizer) saveIntermediate(dir, summary string, metrics ChunkMetrics) (string, error) {
+	if s.cfg.IntermediateFormat == "json" {
+		path := filepath.Join(dir, fmt.Sprintf("chunk_%02d.json", metrics.ChunkIndex))
+		data, err := json.MarshalIndent(IntermediateChunk{
+			ChunkIndex: metrics.ChunkIndex,
+			Summary:    summary,
+			Metrics:    metrics,
+		}, "", "  ")
+		if err != nil {
+			return path, err
+		}
+		return path, os.WriteFile(path, data, 0644)
 	}
-	if metrics.TotalProcessingTime.Seconds() > 0 {
-		metrics.TokensPerSecond = float64(metrics.TotalCompletionTokens) / metrics.TotalProcessingTime.Seconds()
+
+	path := filepath.Join(dir, fmt.Sprintf("chunk_%02d.md", metrics.ChunkIndex))
+	return path, os.WriteFile(path, []byte(summary), 0644)
+}
+
+// chat sends a chat request to the LLM and returns content with metrics. The
+// request streams, copying content to stream as it arrives, when stream is not nil.
+// If the response was cut off by max_tokens and SummaryAutoExtend is enabled, the
+// request is repeated with doubled max_tokens until it completes or the cap is reached.
+// Attempts are then buffered, and only the accepted one is written to stream.
+func (s *Summarizer) chat(sysPrompt, userPrompt string, chunkIndex int, stream io.Writer) (string, ChunkMetrics, error) {
+	buffered := stream != nil && s.cfg.SummaryAutoExtend
+	var accepted []byte
+	if buffered {
+		defer func() { stream.Write(accepted) }()
+	}
+	attempt := func(maxTokens int) (string, string, ChunkMetrics, error) {
+		if !buffered {
+			return s.chatOnce(sysPrompt, userPrompt, chunkIndex, maxTokens, stream)
+		}
+		var buf bytes.Buffer
+		content, finishReason, metrics, err := s.chatOnce(sysPrompt, userPrompt, chunkIndex, maxTokens, &buf)
+		if err == nil {
+			accepted = buf.Bytes()
+		}
+		return content, finishReason, metrics, err
 	}
 
-	// Save final summary
-	finalPath := filepath.Join(outputDir, "meeting_summary.md")
-	if err := os.WriteFile(finalPath, []byte(currentSummary), 0644); err != nil {
-		return "", metrics, fmt.Errorf("failed to save final summary: %w", err)
+	maxTokens := defaultChunkMaxTokens
+	content, finishReason, metrics, err := attempt(maxTokens)
+	if err != nil || finishReason != "length" {
+		return content, metrics, err
 	}
-	fmt.Printf("\n✅ Final summary saved to: %s\n", finalPath)
 
-	// Generate and save performance report
-	if err := s.savePerformanceReport(metrics, outputDir); err != nil {
-		fmt.Printf("  Warning: failed to save performance report: %v\n", err)
+	metrics.Truncated = true
+	fmt.Fprintf(s.cfg.Stdout(), "  ⚠️  Chunk %d response truncated at max_tokens=%d (finish_reason=length)\n", chunkIndex, maxTokens)
+	if !s.cfg.SummaryAutoExtend {
+		return content, metrics, nil
 	}
 
-	return currentSummary, metrics, nil
+	startTime := metrics.StartTime
+	attempts := 0
+	for maxTokens < maxChunkMaxTokens {
+		maxTokens *= 2
+		if maxTokens > maxChunkMaxTokens {
+			maxTokens = maxChunkMaxTokens
+		}
+		attempts++
+		fmt.Fprintf(s.cfg.Stdout(), "  🔁 Retrying chunk %d with max_tokens=%d\n", chunkIndex, maxTokens)
+
+		retryContent, retryFinish, retryMetrics, retryErr := attempt(maxTokens)
+		if retryErr != nil {
+			fmt.Fprintf(s.cfg.Stdout(), "  ⚠️  Retry failed, keeping truncated response: %v\n", retryErr)
+			break
+		}
+
+		content, metrics = retryContent, retryMetrics
+		metrics.Truncated = true
+		if retryFinish != "length" {
+			metrics.ExtendSucceeded = true
+			break
+		}
+	}
+
+	metrics.ExtendAttempts = attempts
+	metrics.StartTime = startTime
+	metrics.ProcessingTime = metrics.EndTime.Sub(startTime)
+	if !metrics.ExtendSucceeded {
+		fmt.Fprintf(s.cfg.Stdout(), "  ⚠️  Chunk %d still truncated after %d retries, using truncated response\n", chunkIndex, attempts)
+	}
+	return content, metrics, nil
 }
 
-// chat sends a non-streaming chat request to the LLM and returns content with metrics.
-func (s *Summarizer) chat(sysPrompt, userPrompt string, chunkIndex int) (string, ChunkMetrics, error) {
+// chatOnce performs a single chat request with the given max_tokens and also
+// returns the finish_reason of the first choice.
+func (s *Summarizer) chatOnce(sysPrompt, userPrompt string, chunkIndex, maxTokens int, stream io.Writer) (string, string, ChunkMetrics, error) {
 	startTime := time.Now()
 	metrics := ChunkMetrics{
 		ChunkIndex: chunkIndex,
 		StartTime:  startTime,
+		MaxTokens:  maxTokens,
 	}
 
 	messages := []workload.ChatMessage{
@@ -231,29 +422,36 @@ func (s *Summarizer) chat(sysPrompt, userPrompt string, chunkIndex int) (string,
 	}
 
 	reqBody := ChatRequest{
-		Model:     s.cfg.ModelName,
-		Messages:  messages,
-		MaxTokens: 16384, // Allow longer responses for thinking models that need reasoning + output
-		Stream:    false,
+		Model:       s.cfg.ModelName,
+		Messages:    messages,
+		MaxTokens:   maxTokens,
+		Temperature: s.cfg.Temperature,
+		TopP:        s.cfg.TopP,
+		Seed:        s.cfg.Seed,
+		Stop:        s.cfg.Stop,
+		Stream:      stream != nil,
+	}
+	if stream != nil {
+		reqBody.StreamOptions = &StreamOptions{IncludeUsage: true}
 	}
 
-	jsonBody, err := json.Marshal(reqBody)
+	jsonBody, err := provider.MarshalBody(s.cfg, reqBody)
 	if err != nil {
-		return "", metrics, fmt.Errorf("failed to marshal request: %w", err)
+		return "", "", metrics, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
 	// Verbose logging: request
 	if s.cfg.Verbose {
-		fmt.Println("\n" + strings.Repeat("=", 80))
-		fmt.Println("[VERBOSE] LLM REQUEST")
-		fmt.Println(strings.Repeat("-", 80))
-		fmt.Printf("URL: %s\n", s.cfg.URL)
-		fmt.Printf("Model: %s\n", s.cfg.ModelName)
-		fmt.Println("\n[System Prompt]:")
-		fmt.Println(sysPrompt)
-		fmt.Println("\n[User Prompt]:")
-		fmt.Println(userPrompt)
-		fmt.Println(strings.Repeat("=", 80))
+		fmt.Fprintln(s.cfg.Stdout(), "\n"+strings.Repeat("=", 80))
+		fmt.Fprintln(s.cfg.Stdout(), "[VERBOSE] LLM REQUEST")
+		fmt.Fprintln(s.cfg.Stdout(), strings.Repeat("-", 80))
+		fmt.Fprintf(s.cfg.Stdout(), "URL: %s\n", s.cfg.URL)
+		fmt.Fprintf(s.cfg.Stdout(), "Model: %s\n", s.cfg.ModelName)
+		fmt.Fprintln(s.cfg.Stdout(), "\n[System Prompt]:")
+		fmt.Fprintln(s.cfg.Stdout(), sysPrompt)
+		fmt.Fprintln(s.cfg.Stdout(), "\n[User Prompt]:")
+		fmt.Fprintln(s.cfg.Stdout(), userPrompt)
+		fmt.Fprintln(s.cfg.Stdout(), strings.Repeat("=", 80))
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(s.cfg.TimeoutSec)*time.Second)
@@ -261,7 +459,7 @@ func (s *Summarizer) chat(sysPrompt, userPrompt string, chunkIndex int) (string,
 
 	req, err := http.NewRequestWithContext(ctx, "POST", s.cfg.URL, bytes.NewReader(jsonBody))
 	if err != nil {
-		return "", metrics, fmt.Errorf("failed to create request: %w", err)
+		return "", "", metrics, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/json")
@@ -269,29 +467,38 @@ func (s *Summarizer) chat(sysPrompt, userPrompt string, chunkIndex int) (string,
 		req.Header.Set("Authorization", "Bearer "+s.cfg.Token)
 	}
 
-	client := s.createClient()
+	client := httpclient.New(s.cfg)
 	resp, err := client.Do(req)
 	if err != nil {
-		return "", metrics, fmt.Errorf("request failed: %w", err)
+		return "", "", metrics, fmt.Errorf("request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", metrics, fmt.Errorf("failed to read response: %w", err)
-	}
+	var chatResp ChatResponse
+	if stream != nil && resp.StatusCode == http.StatusOK {
+		if err := readStream(resp.Body, stream, &chatResp); err != nil {
+			return "", "", metrics, err
+		}
+	} else {
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return "", "", metrics, fmt.Errorf("failed to read response: %w", err)
+		}
 
-	if resp.StatusCode != http.StatusOK {
-		return "", metrics, fmt.Errorf("HTTP %d: %s", resp.StatusCode, string(body))
-	}
+		if resp.StatusCode != http.StatusOK {
+			return "", "", metrics, provider.StatusError(resp.StatusCode, body)
+		}
+		if err := provider.BodyError(body); err != nil {
+			return "", "", metrics, err
+		}
 
-	var chatResp ChatResponse
-	if err := json.Unmarshal(body, &chatResp); err != nil {
-		return "", metrics, fmt.Errorf("failed to parse response: %w", err)
+		if err := json.Unmarshal(body, &chatResp); err != nil {
+			return "", "", metrics, fmt.Errorf("failed to parse response: %w", err)
+		}
 	}
 
 	if len(chatResp.Choices) == 0 {
-		return "", metrics, fmt.Errorf("no response choices")
+		return "", "", metrics, fmt.Errorf("no response choices")
 	}
 
 	// Update metrics with timing and token usage
@@ -314,32 +521,32 @@ func (s *Summarizer) chat(sysPrompt, userPrompt string, chunkIndex int) (string,
 		hasReasoning := (msg.ReasoningContent != nil && *msg.ReasoningContent != "") ||
 			(msg.Reasoning != nil && *msg.Reasoning != "")
 		if hasReasoning {
-			fmt.Printf("  ⚠️  思考模型在推理阶段耗尽了 max_tokens（completion_tokens=%d）\n", chatResp.Usage.CompletionTokens)
-			fmt.Printf("  模型返回了 reasoning 但 content 为空，请增大 max_tokens 参数\n")
+			fmt.Fprintf(s.cfg.Stdout(), "  ⚠️  思考模型在推理阶段耗尽了 max_tokens（completion_tokens=%d）\n", chatResp.Usage.CompletionTokens)
+			fmt.Fprintf(s.cfg.Stdout(), "  模型返回了 reasoning 但 content 为空，请增大 max_tokens 参数\n")
 		} else {
-			fmt.Printf("  ⚠️  Warning: LLM returned empty content for chunk %d (completion_tokens=%d)\n",
+			fmt.Fprintf(s.cfg.Stdout(), "  ⚠️  Warning: LLM returned empty content for chunk %d (completion_tokens=%d)\n",
 				chunkIndex, chatResp.Usage.CompletionTokens)
 		}
 	}
 
 	// Verbose logging: response
 	if s.cfg.Verbose {
-		fmt.Println("\n" + strings.Repeat("=", 80))
-		fmt.Println("[VERBOSE] LLM RESPONSE")
-		fmt.Println(strings.Repeat("-", 80))
-		fmt.Printf("Status: %d\n", resp.StatusCode)
-		fmt.Printf("Tokens: prompt=%d, completion=%d, total=%d\n",
+		fmt.Fprintln(s.cfg.Stdout(), "\n"+strings.Repeat("=", 80))
+		fmt.Fprintln(s.cfg.Stdout(), "[VERBOSE] LLM RESPONSE")
+		fmt.Fprintln(s.cfg.Stdout(), strings.Repeat("-", 80))
+		fmt.Fprintf(s.cfg.Stdout(), "Status: %d\n", resp.StatusCode)
+		fmt.Fprintf(s.cfg.Stdout(), "Tokens: prompt=%d, completion=%d, total=%d\n",
 			chatResp.Usage.PromptTokens, chatResp.Usage.CompletionTokens, chatResp.Usage.TotalTokens)
-		fmt.Printf("Processing time: %.2fs\n", metrics.ProcessingTime.Seconds())
-		fmt.Printf("Content is nil: %v\n", msg.Content == nil)
-		fmt.Printf("Reasoning is nil: %v\n", msg.Reasoning == nil)
-		fmt.Printf("ReasoningContent is nil: %v\n", msg.ReasoningContent == nil)
-		fmt.Println("\n[Content]:")
-		fmt.Println(content)
-		fmt.Println(strings.Repeat("=", 80))
+		fmt.Fprintf(s.cfg.Stdout(), "Processing time: %.2fs\n", metrics.ProcessingTime.Seconds())
+		fmt.Fprintf(s.cfg.Stdout(), "Content is nil: %v\n", msg.Content == nil)
+		fmt.Fprintf(s.cfg.Stdout(), "Reasoning is nil: %v\n", msg.Reasoning == nil)
+		fmt.Fprintf(s.cfg.Stdout(), "ReasoningContent is nil: %v\n", msg.ReasoningContent == nil)
+		fmt.Fprintln(s.cfg.Stdout(), "\n[Content]:")
+		fmt.Fprintln(s.cfg.Stdout(), content)
+		fmt.Fprintln(s.cfg.Stdout(), strings.Repeat("=", 80))
 	}
 
-	return content, metrics, nil
+	return content, chatResp.Choices[0].FinishReason, metrics, nil
 }
 
 // savePerformanceReport generates and saves a performance report to the output directory.
@@ -366,11 +573,20 @@ func (s *Summarizer) savePerformanceReport(metrics *SummaryMetrics, outputDir st
 	if metrics.OverflowDetected {
 		sb.WriteString(fmt.Sprintf("| 成功处理分片数 | %d |\n", len(metrics.ChunkMetrics)))
 	}
+	if metrics.RechunkCount > 0 {
+		sb.WriteString(fmt.Sprintf("| 溢出后重新分片次数 | %d |\n", metrics.RechunkCount))
+	}
 	sb.WriteString(fmt.Sprintf("| 总 Prompt Tokens | %d |\n", metrics.TotalPromptTokens))
 	sb.WriteString(fmt.Sprintf("| 总 Completion Tokens | %d |\n", metrics.TotalCompletionTokens))
 	sb.WriteString(fmt.Sprintf("| 总 Tokens | %d |\n", metrics.TotalTokens))
 	sb.WriteString(fmt.Sprintf("| 总处理时间 | %.2f 秒 |\n", metrics.TotalProcessingTime.Seconds()))
 	sb.WriteString(fmt.Sprintf("| 平均每分片耗时 | %.2f 秒 |\n", metrics.AverageTimePerChunk.Seconds()))
+	if metrics.Strategy == "map_reduce" {
+		sb.WriteString(fmt.Sprintf("| 总结策略 | map-reduce（并发 %d） |\n", metrics.Concurrency))
+		sb.WriteString(fmt.Sprintf("| Map 阶段耗时 | %.2f 秒 |\n", metrics.MapTime.Seconds()))
+		sb.WriteString(fmt.Sprintf("| Reduce 阶段耗时 | %.2f 秒 |\n", metrics.ReduceTime.Seconds()))
+		sb.WriteString(fmt.Sprintf("| 相对顺序执行加速比 | %.2fx |\n", metrics.Speedup))
+	}
 	sb.WriteString(fmt.Sprintf("| Token 生成速度 | %.2f tokens/秒 |\n", metrics.TokensPerSecond))
 	sb.WriteString(fmt.Sprintf("| 开始时间 | %s |\n", metrics.StartTime.Format("2006-01-02 15:04:05")))
 	sb.WriteString(fmt.Sprintf("| 结束时间 | %s |\n", metrics.EndTime.Format("2006-01-02 15:04:05")))
@@ -382,9 +598,17 @@ func (s *Summarizer) savePerformanceReport(metrics *SummaryMetrics, outputDir st
 		status := "✓"
 		if chunk.Overflowed {
 			status = "⚠️ 溢出"
+		} else if chunk.ExtendSucceeded {
+			status = fmt.Sprintf("✓ 扩展重试 (max_tokens=%d)", chunk.MaxTokens)
+		} else if chunk.Truncated {
+			status = "⚠️ 截断"
 		}
-		sb.WriteString(fmt.Sprintf("| %d | %d | %d | %d | %.2f | %s |\n",
-			chunk.ChunkIndex,
+		label := fmt.Sprintf("%d", chunk.ChunkIndex)
+		if chunk.Phase == "reduce" {
+			label += " (合并)"
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %d | %d | %d | %.2f | %s |\n",
+			label,
 			chunk.PromptTokens,
 			chunk.CompletionTokens,
 			chunk.TotalTokens,
@@ -407,7 +631,7 @@ func (s *Summarizer) savePerformanceReport(metrics *SummaryMetrics, outputDir st
 	if err := os.WriteFile(reportPath, []byte(sb.String()), 0644); err != nil {
 		return fmt.Errorf("failed to save performance report: %w", err)
 	}
-	fmt.Printf("📊 Performance report saved to: %s\n", reportPath)
+	fmt.Fprintf(s.cfg.Stdout(), "📊 Performance report saved to: %s\n", reportPath)
 
 	// Also save as JSON for programmatic access
 	jsonPath := filepath.Join(outputDir, "performance_metrics.json")
@@ -418,31 +642,11 @@ func (s *Summarizer) savePerformanceReport(metrics *SummaryMetrics, outputDir st
 	if err := os.WriteFile(jsonPath, jsonData, 0644); err != nil {
 		return fmt.Errorf("failed to save metrics JSON: %w", err)
 	}
-	fmt.Printf("📊 Performance metrics (JSON) saved to: %s\n", jsonPath)
+	fmt.Fprintf(s.cfg.Stdout(), "📊 Performance metrics (JSON) saved to: %s\n", jsonPath)
 
 	return nil
 }
 
-func (s *Summarizer) createClient() *http.Client {
-	transport := &http.Transport{}
-
-	if s.cfg.InsecureTLS {
-		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
-	} else if s.cfg.CACertPath != "" {
-		caCert, err := os.ReadFile(s.cfg.CACertPath)
-		if err == nil {
-			caCertPool := x509.NewCertPool()
-			caCertPool.AppendCertsFromPEM(caCert)
-			transport.TLSClientConfig = &tls.Config{RootCAs: caCertPool}
-		}
-	}
-
-	return &http.Client{
-		Transport: transport,
-		Timeout:   time.Duration(s.cfg.TimeoutSec) * time.Second,
-	}
-}
-
 // cleanResponse removes <think> tags and other unwanted artifacts from the response.
 func (s *Summarizer) cleanResponse(response string) string {
 	// Remove <think>...</think> blocks including the tags
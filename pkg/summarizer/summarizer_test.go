@@ -0,0 +1,131 @@
+package summarizer
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/config"
+)
+
+func TestRunWithMetrics_RechunksOnOverflow(t *testing.T) {
+	// The server rejects prompts over 800 transcript chars with a Chinese-language error
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ChatRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if strings.Count(req.Messages[1].Content, "甲") > 800 {
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write([]byte(`{"code":"InvalidParameter","message":"输入超过最大长度限制"}`))
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"choices": []map[string]interface{}{{"message": map[string]string{"content": "会议摘要。"}, "finish_reason": "stop"}},
+			"usage":   map[string]int{"prompt_tokens": 10, "completion_tokens": 5, "total_tokens": 15},
+		})
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	transcript := filepath.Join(dir, "meeting.txt")
+	if err := os.WriteFile(transcript, []byte(strings.Repeat("甲", 1200)), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.URL = server.URL
+	cfg.ModelName = "test"
+	cfg.NoIntermediate = true
+	_, metrics, err := NewSummarizer(cfg, 2000, "2026-01-01 10:00").RunWithMetrics(transcript, dir)
+	if err != nil {
+		t.Fatalf("RunWithMetrics: %v", err)
+	}
+	if metrics.RechunkCount != 1 {
+		t.Errorf("RechunkCount = %d, want the overflowing chunk split once", metrics.RechunkCount)
+	}
+}
+
+func TestChat_AutoExtend(t *testing.T) {
+	tests := []struct {
+		name          string
+		completeAt    int  // Smallest max_tokens that gets a complete reply (0 = never)
+		failRetries   bool // Retries get HTTP 500
+		wantTokens    []int
+		wantContent   string
+		wantSucceeded bool
+	}{
+		{
+			name:          "completes after doubling",
+			completeAt:    4 * defaultChunkMaxTokens,
+			wantTokens:    []int{defaultChunkMaxTokens, 2 * defaultChunkMaxTokens, 4 * defaultChunkMaxTokens},
+			wantContent:   "summary at 65536",
+			wantSucceeded: true,
+		},
+		{
+			name:        "stops at the cap",
+			wantTokens:  []int{defaultChunkMaxTokens, 2 * defaultChunkMaxTokens, maxChunkMaxTokens},
+			wantContent: "summary at 65536",
+		},
+		{
+			name:        "failed retry keeps the truncated reply",
+			failRetries: true,
+			wantTokens:  []int{defaultChunkMaxTokens, 2 * defaultChunkMaxTokens},
+			wantContent: "summary at 16384",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotTokens []int
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var req ChatRequest
+				json.NewDecoder(r.Body).Decode(&req)
+				gotTokens = append(gotTokens, req.MaxTokens)
+				if tt.failRetries && len(gotTokens) > 1 {
+					http.Error(w, "overloaded", http.StatusInternalServerError)
+					return
+				}
+				finish := "length"
+				if tt.completeAt > 0 && req.MaxTokens >= tt.completeAt {
+					finish = "stop"
+				}
+				chunk, _ := json.Marshal(map[string]interface{}{
+					"choices": []map[string]interface{}{{
+						"delta":         map[string]string{"content": fmt.Sprintf("summary at %d", req.MaxTokens)},
+						"finish_reason": finish,
+					}},
+				})
+				fmt.Fprintf(w, "data: %s\n\ndata: [DONE]\n\n", chunk)
+			}))
+			defer server.Close()
+
+			cfg := config.DefaultConfig()
+			cfg.URL = server.URL
+			cfg.ModelName = "test"
+			cfg.SummaryAutoExtend = true
+			var stream strings.Builder
+			content, metrics, err := NewSummarizer(cfg, 2000, "").chat("sys", "user", 1, &stream)
+			if err != nil {
+				t.Fatalf("chat: %v", err)
+			}
+
+			if fmt.Sprint(gotTokens) != fmt.Sprint(tt.wantTokens) {
+				t.Errorf("max_tokens sent = %v, want %v", gotTokens, tt.wantTokens)
+			}
+			if content != tt.wantContent {
+				t.Errorf("content = %q, want %q", content, tt.wantContent)
+			}
+			if stream.String() != tt.wantContent+"\n" {
+				t.Errorf("streamed %q, want only the accepted reply %q", stream.String(), tt.wantContent+"\n")
+			}
+			if !metrics.Truncated || metrics.ExtendSucceeded != tt.wantSucceeded || metrics.ExtendAttempts != len(tt.wantTokens)-1 {
+				t.Errorf("metrics: truncated=%v succeeded=%v attempts=%d, want true, %v, %d",
+					metrics.Truncated, metrics.ExtendSucceeded, metrics.ExtendAttempts, tt.wantSucceeded, len(tt.wantTokens)-1)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package summarizer
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"os"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding"
+	"golang.org/x/text/encoding/simplifiedchinese"
+)
+
+// Transcript encodings accepted by ReadTranscript.
+const (
+	EncodingAuto    = "auto" // UTF-8, UTF-16 when the file starts with a UTF-16 BOM, else GB18030 if it decodes cleanly
+	EncodingUTF8    = "utf-8"
+	EncodingUTF16LE = "utf-16le"
+	EncodingUTF16BE = "utf-16be"
+	EncodingGBK     = "gbk"
+	EncodingGB18030 = "gb18030"
+)
+
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+// ReadTranscript reads a transcript file as UTF-8 text, removing any byte
+// order mark. Files exported by Windows tools often start with a BOM, which
+// would otherwise end up in the first chunk and prompt.
+func ReadTranscript(path, encoding string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read transcript file: %w", err)
+	}
+	text, err := DecodeTranscript(data, encoding)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode transcript %s: %w", path, err)
+	}
+	return text, nil
+}
+
+// DecodeTranscript converts data in the given encoding to UTF-8 without a BOM.
+// Input that does not decode cleanly is rejected instead of being chunked,
+// since miscounted runes would produce broken chunks and prompts.
+//
+// In auto mode a file that is not valid UTF-8 is tried as GB18030, a superset
+// of GBK and the usual encoding of Chinese transcripts exported on Windows.
+func DecodeTranscript(data []byte, encoding string) (string, error) {
+	switch strings.ToLower(encoding) {
+	case "", EncodingAuto:
+		switch {
+		case bytes.HasPrefix(data, bomUTF16LE):
+			return decodeUTF16(data[2:], binary.LittleEndian)
+		case bytes.HasPrefix(data, bomUTF16BE):
+			return decodeUTF16(data[2:], binary.BigEndian)
+		}
+		text, err := decodeUTF8(data)
+		if err != nil {
+			if gbText, gbErr := decodeChinese(data, simplifiedchinese.GB18030, EncodingGB18030); gbErr == nil {
+				return gbText, nil
+			}
+		}
+		return text, err
+	case EncodingUTF8, "utf8":
+		return decodeUTF8(data)
+	case EncodingUTF16LE:
+		return decodeUTF16(bytes.TrimPrefix(data, bomUTF16LE), binary.LittleEndian)
+	case EncodingUTF16BE:
+		return decodeUTF16(bytes.TrimPrefix(data, bomUTF16BE), binary.BigEndian)
+	case EncodingGBK:
+		return decodeChinese(data, simplifiedchinese.GBK, EncodingGBK)
+	case EncodingGB18030:
+		return decodeChinese(data, simplifiedchinese.GB18030, EncodingGB18030)
+	default:
+		return "", fmt.Errorf("unsupported transcript encoding %q (use %s, %s, %s, %s, %s or %s)",
+			encoding, EncodingAuto, EncodingUTF8, EncodingUTF16LE, EncodingUTF16BE, EncodingGBK, EncodingGB18030)
+	}
+}
+
+func decodeUTF8(data []byte) (string, error) {
+	data = bytes.TrimPrefix(data, bomUTF8)
+	if !utf8.Valid(data) {
+		offset := 0
+		for offset < len(data) {
+			r, size := utf8.DecodeRune(data[offset:])
+			if r == utf8.RuneError && size <= 1 {
+				break
+			}
+			offset += size
+		}
+		return "", fmt.Errorf("invalid UTF-8 at byte %d, and not valid GB18030 either; pass -transcript-encoding if you know the encoding", offset)
+	}
+	return string(data), nil
+}
+
+// decodeChinese decodes GBK or GB18030. The decoders substitute U+FFFD for
+// invalid byte sequences, so its presence marks input in another encoding.
+func decodeChinese(data []byte, enc encoding.Encoding, name string) (string, error) {
+	out, err := enc.NewDecoder().Bytes(data)
+	if err != nil {
+		return "", fmt.Errorf("invalid %s: %w", name, err)
+	}
+	if i := bytes.IndexRune(out, utf8.RuneError); i >= 0 {
+		return "", fmt.Errorf("invalid %s: undecodable bytes near character %d", name, utf8.RuneCount(out[:i]))
+	}
+	return string(out), nil
+}
+
+func decodeUTF16(data []byte, order binary.ByteOrder) (string, error) {
+	if len(data)%2 != 0 {
+		return "", fmt.Errorf("invalid UTF-16: odd number of bytes (%d)", len(data))
+	}
+	units := make([]uint16, len(data)/2)
+	for i := range units {
+		units[i] = order.Uint16(data[2*i:])
+	}
+	return string(utf16.Decode(units)), nil
+}
@@ -0,0 +1,58 @@
+package summarizer
+
+import (
+	"os"
+	"testing"
+)
+
+func TestDecodeTranscript(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		encoding string
+		want     string
+		wantErr  bool
+	}{
+		{"plain utf-8", []byte("会议记录"), EncodingAuto, "会议记录", false},
+		{"utf-8 bom", append([]byte{0xEF, 0xBB, 0xBF}, "会议"...), EncodingAuto, "会议", false},
+		{"utf-8 bom explicit", append([]byte{0xEF, 0xBB, 0xBF}, "hi"...), EncodingUTF8, "hi", false},
+		{"utf-16le bom", []byte{0xFF, 0xFE, 0x1A, 0x4F, 0xAE, 0x8B}, EncodingAuto, "会议", false},
+		{"utf-16be bom", []byte{0xFE, 0xFF, 0x4F, 0x1A, 0x8B, 0xAE}, "", "会议", false},
+		{"utf-16le no bom", []byte{'h', 0, 'i', 0}, EncodingUTF16LE, "hi", false},
+		{"gbk auto", []byte{0xBB, 0xE1, 0xD2, 0xE9}, EncodingAuto, "会议", false}, // "会议" in GBK
+		{"gbk explicit", []byte{0xBB, 0xE1, 0xD2, 0xE9}, EncodingGBK, "会议", false},
+		{"gb18030 four-byte", []byte{0x95, 0x32, 0x82, 0x36}, EncodingGB18030, "\U00020000", false}, // Outside GBK
+		{"invalid gbk", []byte{0xBB, 0xE1, 0xFF}, EncodingGBK, "", true},
+		{"neither utf-8 nor gb18030", []byte{'h', 0xFF}, EncodingAuto, "", true},
+		{"odd utf-16", []byte{0xFF, 0xFE, 'h'}, EncodingAuto, "", true},
+		{"unknown encoding", []byte("hi"), "latin-1", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeTranscript(tt.data, tt.encoding)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadTranscript_GBKFixture(t *testing.T) {
+	want, err := os.ReadFile("testdata/meeting_utf8.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, encoding := range []string{EncodingGBK, EncodingGB18030, EncodingAuto} {
+		got, err := ReadTranscript("testdata/meeting_gbk.txt", encoding)
+		if err != nil {
+			t.Fatalf("%s: %v", encoding, err)
+		}
+		if got != string(want) {
+			t.Errorf("%s: decoded transcript differs from the UTF-8 original:\n%s", encoding, got)
+		}
+	}
+}
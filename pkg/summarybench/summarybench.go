@@ -4,7 +4,7 @@ package summarybench
 import (
 	"bytes"
 	"context"
-	"crypto/tls"
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -14,6 +14,7 @@ import (
 	"os"
 	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
@@ -21,6 +22,10 @@ import (
 
 	"github.com/brianxiadong/llm-benchmark-kit/pkg/config"
 	"github.com/brianxiadong/llm-benchmark-kit/pkg/embedded"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/httpclient"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/provider"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/stats"
+	"github.com/brianxiadong/llm-benchmark-kit/pkg/summarizer"
 	"github.com/brianxiadong/llm-benchmark-kit/pkg/workload"
 )
 
@@ -37,6 +42,8 @@ type RequestResult struct {
 	TotalTokens      int       `json:"total_tokens"`
 	TokensPerSecond  float64   `json:"tokens_per_second"`
 	Error            string    `json:"error,omitempty"`
+	Empty            bool      `json:"empty,omitempty"`   // HTTP 200 without any content or reasoning (a failure)
+	Retries          int       `json:"retries,omitempty"` // Failed attempts before this one (with -retries)
 }
 
 // BenchmarkStats holds aggregated statistics.
@@ -44,6 +51,7 @@ type BenchmarkStats struct {
 	TotalRequests    int     `json:"total_requests"`
 	SuccessCount     int     `json:"success_count"`
 	FailureCount     int     `json:"failure_count"`
+	EmptyCount       int     `json:"empty_count"` // Failures that returned no content (subset of FailureCount)
 	SuccessRate      float64 `json:"success_rate"`
 	TotalDurationSec float64 `json:"total_duration_sec"`
 	RPS              float64 `json:"requests_per_second"`
@@ -56,11 +64,13 @@ type BenchmarkStats struct {
 	LatencyMax float64 `json:"latency_max_ms"`
 
 	ThroughputAvg float64 `json:"throughput_avg"`
-	ThroughputP50 float64 `json:"throughput_p50"`
-	ThroughputP95 float64 `json:"throughput_p95"`
-	ThroughputP99 float64 `json:"throughput_p99"`
-	ThroughputMin float64 `json:"throughput_min"`
-	ThroughputMax float64 `json:"throughput_max"`
+	// ThroughputGeoMean is less skewed than the arithmetic mean by a few very fast requests
+	ThroughputGeoMean float64 `json:"throughput_geomean"`
+	ThroughputP50     float64 `json:"throughput_p50"`
+	ThroughputP95     float64 `json:"throughput_p95"`
+	ThroughputP99     float64 `json:"throughput_p99"`
+	ThroughputMin     float64 `json:"throughput_min"`
+	ThroughputMax     float64 `json:"throughput_max"`
 
 	TotalPromptTokens     int     `json:"total_prompt_tokens"`
 	TotalCompletionTokens int     `json:"total_completion_tokens"`
@@ -68,6 +78,9 @@ type BenchmarkStats struct {
 	AvgCompletionTokens   float64 `json:"avg_completion_tokens"`
 
 	OverallTokensPerSecond float64 `json:"overall_tokens_per_second"`
+
+	RetriedRequests int `json:"retried_requests,omitempty"` // Requests that needed at least one retry
+	TotalRetries    int `json:"total_retries,omitempty"`
 }
 
 // BenchmarkReport holds the complete benchmark report.
@@ -85,10 +98,14 @@ type BenchmarkReport struct {
 
 // ChatRequest represents the OpenAI chat completion request.
 type ChatRequest struct {
-	Model     string                 `json:"model"`
-	Messages  []workload.ChatMessage `json:"messages"`
-	MaxTokens int                    `json:"max_tokens,omitempty"`
-	Stream    bool                   `json:"stream"`
+	Model       string                 `json:"model"`
+	Messages    []workload.ChatMessage `json:"messages"`
+	MaxTokens   int                    `json:"max_tokens,omitempty"`
+	Temperature *float64               `json:"temperature,omitempty"`
+	TopP        *float64               `json:"top_p,omitempty"`
+	Seed        *int                   `json:"seed,omitempty"`
+	Stop        []string               `json:"stop,omitempty"`
+	Stream      bool                   `json:"stream"`
 }
 
 // ChatResponse represents the OpenAI chat completion response.
@@ -132,22 +149,24 @@ func NewBenchmark(cfg *config.GlobalConfig, concurrency, requests, chunkSize int
 
 // Run executes the concurrent summary benchmark.
 func (b *Benchmark) Run(transcriptFile, outputDir string) (*BenchmarkReport, error) {
-	var content []byte
-	var err error
-
 	if transcriptFile == "" {
-		content = embedded.GetTranscriptSample()
+		content := embedded.GetTranscriptSample()
 		if len(content) == 0 {
 			return nil, fmt.Errorf("no embedded transcript available")
 		}
-		fmt.Println("   Using embedded transcript sample")
+		fmt.Fprintln(b.cfg.Stdout(), "   Using embedded transcript sample")
+		transcript, err := summarizer.DecodeTranscript(content, summarizer.EncodingAuto)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode embedded transcript: %w", err)
+		}
+		b.transcript = transcript
 	} else {
-		content, err = os.ReadFile(transcriptFile)
+		transcript, err := summarizer.ReadTranscript(transcriptFile, b.cfg.TranscriptEncoding)
 		if err != nil {
-			return nil, fmt.Errorf("failed to read transcript: %w", err)
+			return nil, err
 		}
+		b.transcript = transcript
 	}
-	b.transcript = string(content)
 
 	if err := os.MkdirAll(outputDir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create output directory: %w", err)
@@ -174,19 +193,19 @@ func (b *Benchmark) Run(transcriptFile, outputDir string) (*BenchmarkReport, err
 	var completed int64
 	var wg sync.WaitGroup
 
-	fmt.Printf("\n")
-	fmt.Printf("   ┌─────────────────────────────────────────────────────────────────────────┐\n")
-	fmt.Printf("   │  会议纪要并发压测                                                        │\n")
-	fmt.Printf("   ├─────────────────────────────────────────────────────────────────────────┤\n")
-	fmt.Printf("   │  并发数: %-5d  总请求数: %-5d  分块大小: %-6d                        │\n", b.concurrency, b.requests, b.chunkSize)
-	fmt.Printf("   └─────────────────────────────────────────────────────────────────────────┘\n")
-	fmt.Printf("\n")
+	fmt.Fprintf(b.cfg.Stdout(), "\n")
+	fmt.Fprintf(b.cfg.Stdout(), "   ┌─────────────────────────────────────────────────────────────────────────┐\n")
+	fmt.Fprintf(b.cfg.Stdout(), "   │  会议纪要并发压测                                                        │\n")
+	fmt.Fprintf(b.cfg.Stdout(), "   ├─────────────────────────────────────────────────────────────────────────┤\n")
+	fmt.Fprintf(b.cfg.Stdout(), "   │  并发数: %-5d  总请求数: %-5d  分块大小: %-6d                        │\n", b.concurrency, b.requests, b.chunkSize)
+	fmt.Fprintf(b.cfg.Stdout(), "   └─────────────────────────────────────────────────────────────────────────┘\n")
+	fmt.Fprintf(b.cfg.Stdout(), "\n")
 
 	for i := 0; i < b.concurrency; i++ {
 		wg.Add(1)
 		go func(workerID int) {
 			defer wg.Done()
-			client := b.createClient()
+			client := httpclient.New(b.cfg)
 
 			for reqID := range workCh {
 				result := b.executeRequest(client, reqID)
@@ -197,9 +216,13 @@ func (b *Benchmark) Run(transcriptFile, outputDir string) (*BenchmarkReport, err
 				if !result.Success {
 					status = "❌"
 				}
-				fmt.Printf("   %s [%3d/%3d] Worker-%02d | Latency: %8.0fms | Tokens: %5d | %.1f tok/s\n",
+				retries := ""
+				if result.Retries > 0 {
+					retries = fmt.Sprintf(" | Retries: %d", result.Retries)
+				}
+				fmt.Fprintf(b.cfg.Stdout(), "   %s [%3d/%3d] Worker-%02d | Latency: %8.0fms | Tokens: %5d | %.1f tok/s%s\n",
 					status, current, b.requests, workerID,
-					result.LatencyMs, result.CompletionTokens, result.TokensPerSecond)
+					result.LatencyMs, result.CompletionTokens, result.TokensPerSecond, retries)
 			}
 		}(i)
 	}
@@ -230,7 +253,50 @@ func (b *Benchmark) Run(transcriptFile, outputDir string) (*BenchmarkReport, err
 	return report, nil
 }
 
+// executeRequest sends request reqID, retrying transient failures up to
+// cfg.Retries times with exponential backoff, like the benchmark runner. The
+// latency is the final attempt's own unless CountRetryLatency is set.
 func (b *Benchmark) executeRequest(client *http.Client, reqID int) RequestResult {
+	result := b.executeAttempt(client, reqID)
+	firstStart := result.StartTime
+
+	backoff := time.Duration(b.cfg.RetryBackoffMs) * time.Millisecond
+	for retry := 0; retry < b.cfg.Retries && retryable(result); retry++ {
+		time.Sleep(backoff)
+		backoff *= 2
+		result = b.executeAttempt(client, reqID)
+		result.Retries = retry + 1
+	}
+
+	if result.Retries > 0 && b.cfg.CountRetryLatency {
+		result.StartTime = firstStart
+		result.LatencyMs = float64(result.EndTime.Sub(firstStart).Milliseconds())
+		if result.Success && result.LatencyMs > 0 {
+			result.TokensPerSecond = float64(result.CompletionTokens) / (result.LatencyMs / 1000.0)
+		}
+	}
+	return result
+}
+
+// retryable reports whether a failed attempt may succeed on a repeat: a
+// connection or read error, or HTTP 5xx, 408 or 429. Empty or unparsable
+// responses are not retried, as the same request would likely fail again.
+func retryable(result RequestResult) bool {
+	if result.Success {
+		return false
+	}
+	if strings.HasPrefix(result.Error, "request failed:") || strings.HasPrefix(result.Error, "read error:") {
+		return true
+	}
+	var code int
+	if _, err := fmt.Sscanf(result.Error, "HTTP %d:", &code); err != nil {
+		return false
+	}
+	return code >= 500 || code == 408 || code == 429
+}
+
+// executeAttempt sends request reqID once.
+func (b *Benchmark) executeAttempt(client *http.Client, reqID int) RequestResult {
 	result := RequestResult{
 		ID:        reqID,
 		StartTime: time.Now(),
@@ -252,13 +318,17 @@ func (b *Benchmark) executeRequest(client *http.Client, reqID int) RequestResult
 	}
 
 	reqBody := ChatRequest{
-		Model:     b.cfg.ModelName,
-		Messages:  messages,
-		MaxTokens: 8192, // Allow enough tokens for thinking models (reasoning + output)
-		Stream:    false,
+		Model:       b.cfg.ModelName,
+		Messages:    messages,
+		MaxTokens:   8192, // Allow enough tokens for thinking models (reasoning + output)
+		Temperature: b.cfg.Temperature,
+		TopP:        b.cfg.TopP,
+		Seed:        b.cfg.Seed,
+		Stop:        b.cfg.Stop,
+		Stream:      false,
 	}
 
-	jsonBody, err := json.Marshal(reqBody)
+	jsonBody, err := provider.MarshalBody(b.cfg, reqBody)
 	if err != nil {
 		result.Error = fmt.Sprintf("marshal error: %v", err)
 		result.EndTime = time.Now()
@@ -303,7 +373,7 @@ func (b *Benchmark) executeRequest(client *http.Client, reqID int) RequestResult
 	result.LatencyMs = float64(result.EndTime.Sub(result.StartTime).Milliseconds())
 
 	if resp.StatusCode != http.StatusOK {
-		result.Error = fmt.Sprintf("HTTP %d: %s", resp.StatusCode, string(body))
+		result.Error = provider.StatusError(resp.StatusCode, body).Error()
 		return result
 	}
 
@@ -335,7 +405,9 @@ func (b *Benchmark) executeRequest(client *http.Client, reqID int) RequestResult
 		if hasReasoning {
 			result.Error = fmt.Sprintf("thinking model exhausted max_tokens during reasoning (completion_tokens=%d), increase max_tokens", chatResp.Usage.CompletionTokens)
 		} else {
-			result.Error = fmt.Sprintf("empty content (completion_tokens=%d)", chatResp.Usage.CompletionTokens)
+			result.Empty = true
+			result.Error = fmt.Sprintf("empty content (completion_tokens=%d, finish_reason=%s)",
+				chatResp.Usage.CompletionTokens, chatResp.Choices[0].FinishReason)
 		}
 	}
 
@@ -389,6 +461,13 @@ func (b *Benchmark) calculateStats(results []RequestResult, totalDuration time.D
 			stats.TotalCompletionTokens += r.CompletionTokens
 		} else {
 			stats.FailureCount++
+			if r.Empty {
+				stats.EmptyCount++
+			}
+		}
+		if r.Retries > 0 {
+			stats.RetriedRequests++
+			stats.TotalRetries += r.Retries
 		}
 	}
 
@@ -412,6 +491,7 @@ func (b *Benchmark) calculateStats(results []RequestResult, totalDuration time.D
 		stats.ThroughputMin = throughputs[0]
 		stats.ThroughputMax = throughputs[len(throughputs)-1]
 		stats.ThroughputAvg = avg(throughputs)
+		stats.ThroughputGeoMean = geoMean(throughputs)
 		stats.ThroughputP50 = percentile(throughputs, 50)
 		stats.ThroughputP95 = percentile(throughputs, 95)
 		stats.ThroughputP99 = percentile(throughputs, 99)
@@ -440,6 +520,11 @@ func avg(values []float64) float64 {
 	return sum / float64(len(values))
 }
 
+// geoMean is the geometric mean of values; calculateStats shadows the stats package.
+func geoMean(values []float64) float64 {
+	return stats.GeometricMean(values)
+}
+
 func percentile(sorted []float64, p float64) float64 {
 	if len(sorted) == 0 {
 		return 0
@@ -453,23 +538,6 @@ func percentile(sorted []float64, p float64) float64 {
 	return sorted[lower] + (sorted[upper]-sorted[lower])*(index-float64(lower))
 }
 
-func (b *Benchmark) createClient() *http.Client {
-	transport := &http.Transport{
-		MaxIdleConns:        100,
-		MaxIdleConnsPerHost: 100,
-		IdleConnTimeout:     90 * time.Second,
-	}
-
-	if b.cfg.InsecureTLS {
-		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
-	}
-
-	return &http.Client{
-		Transport: transport,
-		Timeout:   time.Duration(b.cfg.TimeoutSec) * time.Second,
-	}
-}
-
 func (b *Benchmark) saveReport(report *BenchmarkReport, outputDir string) error {
 	jsonPath := filepath.Join(outputDir, "summary_bench_report.json")
 	jsonData, err := json.MarshalIndent(report, "", "  ")
@@ -486,13 +554,47 @@ func (b *Benchmark) saveReport(report *BenchmarkReport, outputDir string) error
 		return err
 	}
 
-	fmt.Printf("\n   📄 Reports saved:\n")
-	fmt.Printf("      - %s\n", jsonPath)
-	fmt.Printf("      - %s\n", mdPath)
+	csvPath := filepath.Join(outputDir, "summary_bench_results.csv")
+	if err := writeResultsCSV(report.Results, csvPath); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(b.cfg.Stdout(), "\n   📄 Reports saved:\n")
+	fmt.Fprintf(b.cfg.Stdout(), "      - %s\n", jsonPath)
+	fmt.Fprintf(b.cfg.Stdout(), "      - %s\n", mdPath)
+	fmt.Fprintf(b.cfg.Stdout(), "      - %s\n", csvPath)
 
 	return nil
 }
 
+// writeResultsCSV writes one row per request for spreadsheet analysis.
+func writeResultsCSV(results []RequestResult, path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	w := csv.NewWriter(file)
+	w.Write([]string{"id", "success", "latency_ms", "prompt_tokens", "completion_tokens", "tokens_per_sec", "error"})
+	for _, r := range results {
+		w.Write([]string{
+			strconv.Itoa(r.ID),
+			strconv.FormatBool(r.Success),
+			strconv.FormatFloat(r.LatencyMs, 'f', 2, 64),
+			strconv.Itoa(r.PromptTokens),
+			strconv.Itoa(r.CompletionTokens),
+			strconv.FormatFloat(r.TokensPerSecond, 'f', 2, 64),
+			r.Error,
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	return file.Close()
+}
+
 func (b *Benchmark) generateMarkdown(report *BenchmarkReport) string {
 	s := report.Stats
 	md := fmt.Sprintf(`# 会议纪要并发压测报告
@@ -517,8 +619,10 @@ func (b *Benchmark) generateMarkdown(report *BenchmarkReport) string {
 |------|-----|
 | 成功请求 | %d |
 | 失败请求 | %d |
+| 空响应 | %d |
 | 成功率 | %.1f%% |
 | RPS | %.2f |
+| 重试请求 | %d (共 %d 次重试) |
 
 ### 延迟统计 (ms)
 
@@ -536,6 +640,7 @@ func (b *Benchmark) generateMarkdown(report *BenchmarkReport) string {
 | 指标 | 值 |
 |------|-----|
 | 平均 | %.1f |
+| 几何平均 | %.1f |
 | P50 | %.1f |
 | P95 | %.1f |
 | P99 | %.1f |
@@ -566,8 +671,11 @@ func (b *Benchmark) generateMarkdown(report *BenchmarkReport) string {
 		s.TotalDurationSec,
 		s.SuccessCount,
 		s.FailureCount,
+		s.EmptyCount,
 		s.SuccessRate,
 		s.RPS,
+		s.RetriedRequests,
+		s.TotalRetries,
 		s.LatencyAvg,
 		s.LatencyP50,
 		s.LatencyP95,
@@ -575,6 +683,7 @@ func (b *Benchmark) generateMarkdown(report *BenchmarkReport) string {
 		s.LatencyMin,
 		s.LatencyMax,
 		s.ThroughputAvg,
+		s.ThroughputGeoMean,
 		s.ThroughputP50,
 		s.ThroughputP95,
 		s.ThroughputP99,
@@ -589,7 +698,9 @@ func (b *Benchmark) generateMarkdown(report *BenchmarkReport) string {
 
 	for _, r := range report.Results {
 		status := "✅"
-		if !r.Success {
+		if r.Empty {
+			status = "❌ 空"
+		} else if !r.Success {
 			status = "❌"
 		}
 		md += fmt.Sprintf("| %d | %s | %.0f | %d | %d | %.1f |\n",
@@ -602,22 +713,29 @@ func (b *Benchmark) generateMarkdown(report *BenchmarkReport) string {
 func (b *Benchmark) printSummary(report *BenchmarkReport) {
 	s := report.Stats
 
-	fmt.Printf("\n")
-	fmt.Printf("   ┌─────────────────────────────────────────────────────────────────────────┐\n")
-	fmt.Printf("   │  📊 压测结果汇总                                                         │\n")
-	fmt.Printf("   ├─────────────────────────────────────────────────────────────────────────┤\n")
-	fmt.Printf("   │  %-20s │ %-48s │\n", "成功率", fmt.Sprintf("%.1f%% (%d/%d)", s.SuccessRate, s.SuccessCount, s.TotalRequests))
-	fmt.Printf("   │  %-20s │ %-48s │\n", "总耗时", fmt.Sprintf("%.2f 秒", s.TotalDurationSec))
-	fmt.Printf("   │  %-20s │ %-48s │\n", "RPS", fmt.Sprintf("%.2f req/s", s.RPS))
-	fmt.Printf("   ├─────────────────────────────────────────────────────────────────────────┤\n")
-	fmt.Printf("   │  延迟 (ms)           │ Avg: %-8.0f P50: %-8.0f P95: %-8.0f P99: %-6.0f │\n",
+	fmt.Fprintf(b.cfg.Stdout(), "\n")
+	fmt.Fprintf(b.cfg.Stdout(), "   ┌─────────────────────────────────────────────────────────────────────────┐\n")
+	fmt.Fprintf(b.cfg.Stdout(), "   │  📊 压测结果汇总                                                         │\n")
+	fmt.Fprintf(b.cfg.Stdout(), "   ├─────────────────────────────────────────────────────────────────────────┤\n")
+	fmt.Fprintf(b.cfg.Stdout(), "   │  %-20s │ %-48s │\n", "成功率", fmt.Sprintf("%.1f%% (%d/%d)", s.SuccessRate, s.SuccessCount, s.TotalRequests))
+	fmt.Fprintf(b.cfg.Stdout(), "   │  %-20s │ %-48s │\n", "总耗时", fmt.Sprintf("%.2f 秒", s.TotalDurationSec))
+	fmt.Fprintf(b.cfg.Stdout(), "   │  %-20s │ %-48s │\n", "RPS", fmt.Sprintf("%.2f req/s", s.RPS))
+	if s.EmptyCount > 0 {
+		fmt.Fprintf(b.cfg.Stdout(), "   │  %-20s │ %-48s │\n", "空响应", fmt.Sprintf("%d 个请求 (无内容)", s.EmptyCount))
+	}
+	if s.RetriedRequests > 0 {
+		fmt.Fprintf(b.cfg.Stdout(), "   │  %-20s │ %-48s │\n", "重试", fmt.Sprintf("%d 个请求, 共 %d 次", s.RetriedRequests, s.TotalRetries))
+	}
+	fmt.Fprintf(b.cfg.Stdout(), "   ├─────────────────────────────────────────────────────────────────────────┤\n")
+	fmt.Fprintf(b.cfg.Stdout(), "   │  延迟 (ms)           │ Avg: %-8.0f P50: %-8.0f P95: %-8.0f P99: %-6.0f │\n",
 		s.LatencyAvg, s.LatencyP50, s.LatencyP95, s.LatencyP99)
-	fmt.Printf("   │  吞吐 (tok/s)        │ Avg: %-8.1f P50: %-8.1f P95: %-8.1f P99: %-6.1f │\n",
+	fmt.Fprintf(b.cfg.Stdout(), "   │  吞吐 (tok/s)        │ Avg: %-8.1f P50: %-8.1f P95: %-8.1f P99: %-6.1f │\n",
 		s.ThroughputAvg, s.ThroughputP50, s.ThroughputP95, s.ThroughputP99)
-	fmt.Printf("   ├─────────────────────────────────────────────────────────────────────────┤\n")
-	fmt.Printf("   │  总输出 Tokens: %-10d      整体吞吐: %-10.1f tokens/s           │\n",
+	fmt.Fprintf(b.cfg.Stdout(), "   │  %-20s │ %-48s │\n", "吞吐几何平均", fmt.Sprintf("%.1f tok/s", s.ThroughputGeoMean))
+	fmt.Fprintf(b.cfg.Stdout(), "   ├─────────────────────────────────────────────────────────────────────────┤\n")
+	fmt.Fprintf(b.cfg.Stdout(), "   │  总输出 Tokens: %-10d      整体吞吐: %-10.1f tokens/s           │\n",
 		s.TotalCompletionTokens, s.OverallTokensPerSecond)
-	fmt.Printf("   └─────────────────────────────────────────────────────────────────────────┘\n")
+	fmt.Fprintf(b.cfg.Stdout(), "   └─────────────────────────────────────────────────────────────────────────┘\n")
 }
 
 // cleanThinkTags removes <think>...</think> blocks and code block markers from response text.
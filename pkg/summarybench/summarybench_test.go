@@ -0,0 +1,26 @@
+package summarybench
+
+import "testing"
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		result RequestResult
+		want   bool
+	}{
+		{RequestResult{Success: true}, false},
+		{RequestResult{Error: "HTTP 429: rate limited"}, true},
+		{RequestResult{Error: "HTTP 503: overloaded"}, true},
+		{RequestResult{Error: "HTTP 408: timeout"}, true},
+		{RequestResult{Error: "HTTP 400: bad request"}, false},
+		{RequestResult{Error: "request failed: context deadline exceeded"}, true},
+		{RequestResult{Error: "read error: unexpected EOF"}, true},
+		{RequestResult{Error: "empty content (completion_tokens=0)"}, false},
+		{RequestResult{Error: "parse error: invalid character"}, false},
+	}
+
+	for _, tt := range tests {
+		if got := retryable(tt.result); got != tt.want {
+			t.Errorf("retryable(%q) = %v, want %v", tt.result.Error, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+// Package tokenizer counts tokens with the tiktoken encodings used by
+// OpenAI-compatible models (cl100k_base and o200k_base).
+//
+// Counts come from real byte-pair encoding with the encodings' merge tables,
+// which are embedded in the binary, so no network access is needed. Servers
+// running other model families use other vocabularies; for them the count is
+// an estimate, but still far closer than a fixed chars-per-token ratio,
+// especially for CJK text and numbers.
+package tokenizer
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/pkoukk/tiktoken-go"
+	tiktokenloader "github.com/pkoukk/tiktoken-go-loader"
+)
+
+// Encoding names.
+const (
+	CL100K = "cl100k_base"
+	O200K  = "o200k_base"
+)
+
+func init() {
+	// Read merge tables from the embedded copies instead of downloading them
+	tiktoken.SetBpeLoader(tiktokenloader.NewOfflineLoader())
+}
+
+// o200kPrefixes are model name prefixes that use o200k_base.
+var o200kPrefixes = []string{"gpt-4o", "gpt-4.1", "gpt-4.5", "gpt-5", "chatgpt-4o", "o1", "o3", "o4"}
+
+// EncodingForModel returns the encoding used by model, defaulting to cl100k_base.
+func EncodingForModel(model string) string {
+	m := strings.ToLower(model)
+	if i := strings.LastIndex(m, "/"); i >= 0 {
+		m = m[i+1:] // Strip organization prefixes such as "openai/"
+	}
+	for _, p := range o200kPrefixes {
+		if strings.HasPrefix(m, p) {
+			return O200K
+		}
+	}
+	return CL100K
+}
+
+// Encoders are built on first use; loading a merge table takes a moment and
+// tens of megabytes, so only the encodings actually counted with are loaded.
+var encoders = map[string]func() (*tiktoken.Tiktoken, error){
+	CL100K: sync.OnceValues(func() (*tiktoken.Tiktoken, error) { return tiktoken.GetEncoding(CL100K) }),
+	O200K:  sync.OnceValues(func() (*tiktoken.Tiktoken, error) { return tiktoken.GetEncoding(O200K) }),
+}
+
+// Count returns the number of tokens in text for model's encoding. Special
+// tokens such as <|endoftext|> are counted as ordinary text.
+func Count(text, model string) int {
+	if text == "" {
+		return 0
+	}
+	enc := EncodingForModel(model)
+	tk, err := encoders[enc]()
+	if err != nil {
+		// The tables are compiled into the binary, so this is a build problem
+		panic(fmt.Sprintf("tokenizer: failed to load embedded %s merge table: %v", enc, err))
+	}
+	return len(tk.EncodeOrdinary(text))
+}
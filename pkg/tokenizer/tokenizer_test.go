@@ -0,0 +1,66 @@
+package tokenizer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestEncodingForModel(t *testing.T) {
+	tests := []struct {
+		model string
+		want  string
+	}{
+		{"gpt-4o-mini", O200K},
+		{"openai/o3-mini", O200K},
+		{"gpt-4-turbo", CL100K},
+		{"gpt-3.5-turbo", CL100K},
+		{"Qwen2.5-72B-Instruct", CL100K},
+		{"", CL100K},
+	}
+
+	for _, tt := range tests {
+		if got := EncodingForModel(tt.model); got != tt.want {
+			t.Errorf("EncodingForModel(%q) = %q, want %q", tt.model, got, tt.want)
+		}
+	}
+}
+
+func TestCount(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		model string
+		want  int
+	}{
+		{"empty", "", "", 0},
+		{"words", "Hello world", "", 2},
+		{"contraction", "I don't know.", "", 5},
+		{"contraction o200k", "I don't know.", "gpt-4o", 4},
+		{"digit groups", "1234567", "", 3},
+		{"chinese cl100k", "你好世界", "gpt-4", 5},
+		{"chinese o200k", "你好世界", "gpt-4o", 2},
+		{"cookbook cl100k", "tiktoken is great!", "gpt-4", 6}, // OpenAI cookbook example
+		{"cookbook o200k", "tiktoken is great!", "gpt-4o", 6},
+		{"special token as text", "<|endoftext|>", "", 7},
+		{"extra spaces", "a  b", "", 3},
+		{"punctuation run", "Wait...", "", 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Count(tt.text, tt.model); got != tt.want {
+				t.Errorf("Count(%q) = %d, want %d", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCount_EnglishRatio(t *testing.T) {
+	// English prose averages roughly 4 characters per token
+	text := strings.Repeat("The benchmark measures how quickly the model answers each request, including the time to first token. ", 20)
+	got := Count(text, "")
+	ratio := float64(len(text)) / float64(got)
+	if ratio < 3.5 || ratio > 6 {
+		t.Errorf("chars per token = %.2f (%d tokens for %d chars), want 3.5-6", ratio, got, len(text))
+	}
+}
@@ -3,6 +3,7 @@ package workload
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
 	"fmt"
 	"os"
@@ -17,10 +18,23 @@ func NewLoader() *Loader {
 	return &Loader{}
 }
 
+// ShareGPTTurn is a single turn in a ShareGPT-format conversation.
+type ShareGPTTurn struct {
+	From  string `json:"from"`
+	Value string `json:"value"`
+}
+
+// ShareGPTRecord is a ShareGPT-format dataset row.
+type ShareGPTRecord struct {
+	ID            string         `json:"id"`
+	Conversations []ShareGPTTurn `json:"conversations"`
+}
+
 // LoadFromFile loads workloads from a file.
 // Supports:
 // - Plain text (one prompt per line)
 // - JSONL (one JSON object per line with prompt/messages fields)
+// - ShareGPT JSONL (one {"conversations":[{"from":...,"value":...}]} object per line)
 func (l *Loader) LoadFromFile(path string, maxTokens int) ([]WorkloadInput, error) {
 	file, err := os.Open(path)
 	if err != nil {
@@ -54,9 +68,97 @@ func (l *Loader) LoadFromFile(path string, maxTokens int) ([]WorkloadInput, erro
 	return workloads, nil
 }
 
+// LoadMessagesFile loads a fixed conversation from a JSON array of
+// {"role": ..., "content": ...} objects.
+func LoadMessagesFile(path string) ([]ChatMessage, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read messages file: %w", err)
+	}
+	var messages []ChatMessage
+	if err := json.Unmarshal(data, &messages); err != nil {
+		return nil, fmt.Errorf("failed to parse messages file: %w", err)
+	}
+	if len(messages) == 0 {
+		return nil, fmt.Errorf("messages file %s contains no messages", path)
+	}
+	for i, msg := range messages {
+		switch msg.Role {
+		case "system", "user", "assistant":
+		default:
+			return nil, fmt.Errorf("message %d has invalid role %q (want system, user or assistant)", i+1, msg.Role)
+		}
+	}
+	return messages, nil
+}
+
+// WorkloadStream delivers the workloads of a file read by Loader.Stream.
+type WorkloadStream struct {
+	C   <-chan WorkloadInput // Closed at end of file, on the first error, or when ctx is done
+	err error
+}
+
+// Err returns the parse or read error that closed C early, if any. It must
+// only be called once C is closed.
+func (s *WorkloadStream) Err() error {
+	return s.err
+}
+
+// Stream reads workloads from a file lazily, in the same formats as
+// LoadFromFile, so memory use does not depend on the file size. Like
+// LoadFromFile it stops at the first line that fails to parse; the error is
+// reported by Err.
+func (l *Loader) Stream(ctx context.Context, path string, maxTokens int) (*WorkloadStream, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open workload file: %w", err)
+	}
+
+	out := make(chan WorkloadInput)
+	s := &WorkloadStream{C: out}
+	go func() {
+		defer close(out)
+		defer file.Close()
+
+		scanner := bufio.NewScanner(file)
+		scanner.Buffer(make([]byte, 1024*1024), 1024*1024) // 1MB buffer
+
+		id := 0
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+
+			id++
+			workload, err := l.parseLine(line, id, maxTokens)
+			if err != nil {
+				s.err = fmt.Errorf("failed to parse line %d: %w", id, err)
+				return
+			}
+			select {
+			case out <- workload:
+			case <-ctx.Done():
+				return
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			s.err = fmt.Errorf("failed to read workload file: %w", err)
+		}
+	}()
+	return s, nil
+}
+
 func (l *Loader) parseLine(line string, id int, maxTokens int) (WorkloadInput, error) {
 	// Try to parse as JSON first
 	if strings.HasPrefix(line, "{") {
+		if strings.Contains(line, `"conversations"`) {
+			var record ShareGPTRecord
+			if err := json.Unmarshal([]byte(line), &record); err == nil && len(record.Conversations) > 0 {
+				return parseShareGPT(record, id, maxTokens)
+			}
+		}
+
 		var input WorkloadInput
 		if err := json.Unmarshal([]byte(line), &input); err == nil {
 			if input.ID == "" {
@@ -73,6 +175,41 @@ func (l *Loader) parseLine(line string, id int, maxTokens int) (WorkloadInput, e
 	return NewSimpleWorkload(fmt.Sprintf("req-%d", id), line, maxTokens), nil
 }
 
+// parseShareGPT converts a ShareGPT conversation into chat messages.
+// Everything up to and including the last human turn becomes the prompt context;
+// the trailing model answer is dropped so the benchmarked model generates it.
+func parseShareGPT(record ShareGPTRecord, id int, maxTokens int) (WorkloadInput, error) {
+	var messages []ChatMessage
+	lastUser := -1
+	for _, turn := range record.Conversations {
+		var role string
+		switch strings.ToLower(turn.From) {
+		case "human", "user":
+			role = "user"
+		case "gpt", "assistant", "chatgpt", "bard", "bing":
+			role = "assistant"
+		case "system":
+			role = "system"
+		default:
+			return WorkloadInput{}, fmt.Errorf("unknown sharegpt speaker %q", turn.From)
+		}
+		messages = append(messages, ChatMessage{Role: role, Content: turn.Value})
+		if role == "user" {
+			lastUser = len(messages) - 1
+		}
+	}
+
+	if lastUser < 0 {
+		return WorkloadInput{}, fmt.Errorf("sharegpt conversation has no human turn")
+	}
+
+	workloadID := record.ID
+	if workloadID == "" {
+		workloadID = fmt.Sprintf("req-%d", id)
+	}
+	return NewChatWorkload(workloadID, messages[:lastUser+1], maxTokens), nil
+}
+
 // GenerateDefault generates a default workload for testing.
 func (l *Loader) GenerateDefault(count, maxTokens int) []WorkloadInput {
 	prompts := []string{
@@ -0,0 +1,122 @@
+package workload
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// ParseVars parses a variable spec like "topic=AI|ML,n=50|100" into variable sets.
+// Values separated by "|" are alternatives; the result is the cartesian product,
+// so the example above yields four sets.
+func ParseVars(spec string) ([]map[string]string, error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return []map[string]string{{}}, nil
+	}
+
+	type variable struct {
+		name   string
+		values []string
+	}
+	var vars []variable
+	for _, pair := range strings.Split(spec, ",") {
+		name, value, ok := strings.Cut(pair, "=")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid variable %q, expected name=value", pair)
+		}
+		vars = append(vars, variable{name: name, values: strings.Split(value, "|")})
+	}
+
+	sets := []map[string]string{{}}
+	for _, v := range vars {
+		var next []map[string]string
+		for _, set := range sets {
+			for _, value := range v.values {
+				combined := make(map[string]string, len(set)+1)
+				for k, val := range set {
+					combined[k] = val
+				}
+				combined[v.name] = value
+				next = append(next, combined)
+			}
+		}
+		sets = next
+	}
+	return sets, nil
+}
+
+// LoadVarsFile loads variable sets from a JSONL file, one JSON object per line.
+func LoadVarsFile(path string) ([]map[string]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open vars file: %w", err)
+	}
+	defer file.Close()
+
+	var sets []map[string]string
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 1024*1024), 1024*1024) // 1MB buffer
+
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var raw map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &raw); err != nil {
+			return nil, fmt.Errorf("failed to parse vars line %d: %w", lineNum, err)
+		}
+		set := make(map[string]string, len(raw))
+		for k, v := range raw {
+			set[k] = fmt.Sprint(v)
+		}
+		sets = append(sets, set)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read vars file: %w", err)
+	}
+	return sets, nil
+}
+
+// LoadFromTemplate renders a text/template prompt (e.g. "Summarize {{.topic}} in {{.n}} words")
+// once per variable set and returns one workload per rendered prompt.
+func (l *Loader) LoadFromTemplate(tmpl string, varSets []map[string]string, maxTokens int) ([]WorkloadInput, error) {
+	t, err := template.New("prompt").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse prompt template: %w", err)
+	}
+
+	workloads := make([]WorkloadInput, 0, len(varSets))
+	for i, vars := range varSets {
+		var sb strings.Builder
+		if err := t.Execute(&sb, vars); err != nil {
+			return nil, fmt.Errorf("failed to render prompt template with vars %s: %w", formatVars(vars), err)
+		}
+		workloads = append(workloads, NewSimpleWorkload(fmt.Sprintf("tmpl-%d", i+1), sb.String(), maxTokens))
+	}
+	return workloads, nil
+}
+
+func formatVars(vars map[string]string) string {
+	keys := make([]string, 0, len(vars))
+	for k := range vars {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = k + "=" + vars[k]
+	}
+	return strings.Join(pairs, ",")
+}
@@ -0,0 +1,84 @@
+package workload
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseVars(t *testing.T) {
+	tests := []struct {
+		name     string
+		spec     string
+		expected int
+		wantErr  bool
+	}{
+		{name: "empty", spec: "", expected: 1},
+		{name: "single values", spec: "topic=AI,n=50", expected: 1},
+		{name: "alternatives", spec: "topic=AI|ML,n=50|100|200", expected: 6},
+		{name: "missing equals", spec: "topic", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sets, err := ParseVars(tt.spec)
+			if tt.wantErr {
+				if err == nil {
+					t.Error("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(sets) != tt.expected {
+				t.Errorf("expected %d sets, got %d", tt.expected, len(sets))
+			}
+		})
+	}
+}
+
+func TestLoadFromTemplate(t *testing.T) {
+	sets, _ := ParseVars("topic=AI|ML,n=50")
+	loader := NewLoader()
+
+	workloads, err := loader.LoadFromTemplate("Summarize {{.topic}} in {{.n}} words", sets, 64)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(workloads) != 2 {
+		t.Fatalf("expected 2 workloads, got %d", len(workloads))
+	}
+	if workloads[0].Prompt != "Summarize AI in 50 words" {
+		t.Errorf("unexpected prompt: %q", workloads[0].Prompt)
+	}
+	if workloads[1].ID != "tmpl-2" || workloads[1].MaxTokens != 64 {
+		t.Errorf("unexpected workload: %+v", workloads[1])
+	}
+
+	if _, err := loader.LoadFromTemplate("Hello {{.missing}}", sets, 64); err == nil {
+		t.Error("expected error for missing variable")
+	}
+}
+
+func TestLoadVarsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "vars.jsonl")
+	content := `{"topic": "AI", "n": 50}
+
+{"topic": "ML", "n": 100}
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	sets, err := LoadVarsFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sets) != 2 {
+		t.Fatalf("expected 2 sets, got %d", len(sets))
+	}
+	if sets[1]["topic"] != "ML" || sets[1]["n"] != "100" {
+		t.Errorf("unexpected set: %v", sets[1])
+	}
+}
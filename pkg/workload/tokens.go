@@ -0,0 +1,71 @@
+package workload
+
+import "github.com/brianxiadong/llm-benchmark-kit/pkg/tokenizer"
+
+// Chat formatting overhead of the OpenAI chat format: every message is wrapped
+// in role and separator tokens, and the reply is primed with a few more.
+const (
+	tokensPerMessage = 3
+	tokensPerReply   = 3
+)
+
+// PromptTokens estimates the prompt tokens of the workload as sent with
+// systemPrompt, including the chat formatting overhead.
+func (w *WorkloadInput) PromptTokens(systemPrompt, model string) int {
+	messages := w.ToMessagesWithSystem(systemPrompt)
+	if len(messages) == 0 {
+		return 0
+	}
+	n := tokensPerReply
+	for _, msg := range messages {
+		n += tokensPerMessage + tokenizer.Count(msg.Role, model) + tokenizer.Count(msg.Content, model)
+	}
+	return n
+}
+
+// TokenEstimate summarizes the estimated prompt tokens of a set of workloads.
+type TokenEstimate struct {
+	Prompts     int    `json:"prompts"`
+	TotalTokens int    `json:"total_tokens"`
+	MaxTokens   int    `json:"max_tokens"` // Largest single prompt
+	MaxID       string `json:"max_id"`     // ID of the largest prompt
+	MaxOutput   int    `json:"max_output"` // Sum of the workloads' max_tokens (upper bound on completion tokens)
+	Encoding    string `json:"encoding"`   // Tokenizer encoding used for the estimate
+}
+
+// AvgTokens returns the mean prompt tokens per workload.
+func (e TokenEstimate) AvgTokens() float64 {
+	if e.Prompts == 0 {
+		return 0
+	}
+	return float64(e.TotalTokens) / float64(e.Prompts)
+}
+
+// EstimateTokens estimates the prompt tokens of sending n requests cycled
+// through workloads, as the benchmark does when n exceeds the workload count.
+// n <= 0 counts each workload once.
+func EstimateTokens(workloads []WorkloadInput, n int, systemPrompt, model string) TokenEstimate {
+	est := TokenEstimate{Encoding: tokenizer.EncodingForModel(model)}
+	if len(workloads) == 0 {
+		return est
+	}
+	if n <= 0 {
+		n = len(workloads)
+	}
+
+	counts := make([]int, len(workloads))
+	for i := range workloads {
+		counts[i] = workloads[i].PromptTokens(systemPrompt, model)
+	}
+	for i := 0; i < n; i++ {
+		w, tokens := workloads[i%len(workloads)], counts[i%len(workloads)]
+		est.Prompts++
+		est.TotalTokens += tokens
+		est.MaxOutput += w.MaxTokens
+		if tokens > est.MaxTokens {
+			est.MaxTokens = tokens
+			est.MaxID = w.ID
+		}
+	}
+	return est
+}
@@ -13,6 +13,10 @@ type WorkloadInput struct {
 	Prompt    string        `json:"prompt,omitempty"`
 	Messages  []ChatMessage `json:"messages,omitempty"`
 	MaxTokens int           `json:"max_tokens,omitempty"`
+
+	// Seq is the 1-based position of the request in a benchmark run, set by
+	// the runner (0 for warmup and other requests outside the run)
+	Seq int `json:"-"`
 }
 
 // NewSimpleWorkload creates a WorkloadInput with a simple prompt.
@@ -47,3 +51,49 @@ func (w *WorkloadInput) ToMessages() []ChatMessage {
 	}
 	return nil
 }
+
+// WithBaseMessages returns a copy of the workload that sends the base
+// conversation, followed by the workload's own messages when appendOwn is set.
+func (w WorkloadInput) WithBaseMessages(base []ChatMessage, appendOwn bool) WorkloadInput {
+	messages := append([]ChatMessage{}, base...)
+	if appendOwn {
+		messages = append(messages, w.ToMessages()...)
+	}
+	w.Prompt = ""
+	w.Messages = messages
+	return w
+}
+
+// WithNonce returns a copy of the workload with nonce appended to its last
+// user message, so a server that caches responses by prompt cannot serve it
+// from cache. The shared prompt prefix is unchanged.
+func (w WorkloadInput) WithNonce(nonce string) WorkloadInput {
+	if len(w.Messages) == 0 {
+		w.Prompt += "\n\n" + nonce
+		return w
+	}
+	messages := append([]ChatMessage{}, w.Messages...)
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == "user" {
+			messages[i].Content += "\n\n" + nonce
+			break
+		}
+	}
+	w.Messages = messages
+	return w
+}
+
+// ToMessagesWithSystem is like ToMessages but prepends a system message with the
+// given prompt when the workload does not already contain one.
+func (w *WorkloadInput) ToMessagesWithSystem(systemPrompt string) []ChatMessage {
+	messages := w.ToMessages()
+	if systemPrompt == "" || len(messages) == 0 {
+		return messages
+	}
+	for _, msg := range messages {
+		if msg.Role == "system" {
+			return messages
+		}
+	}
+	return append([]ChatMessage{{Role: "system", Content: systemPrompt}}, messages...)
+}
@@ -1,8 +1,10 @@
 package workload
 
 import (
+	"context"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -73,6 +75,37 @@ func TestWorkloadInput_ToMessages(t *testing.T) {
 	})
 }
 
+func TestWorkloadInput_ToMessagesWithSystem(t *testing.T) {
+	t.Run("prepends system prompt", func(t *testing.T) {
+		w := WorkloadInput{Prompt: "Hello"}
+		result := w.ToMessagesWithSystem("You are helpful.")
+		if len(result) != 2 {
+			t.Fatalf("expected 2 messages, got %d", len(result))
+		}
+		if result[0].Role != "system" || result[0].Content != "You are helpful." {
+			t.Errorf("unexpected system message: %+v", result[0])
+		}
+	})
+
+	t.Run("keeps existing system message", func(t *testing.T) {
+		w := WorkloadInput{Messages: []ChatMessage{
+			{Role: "system", Content: "Original"},
+			{Role: "user", Content: "Hello"},
+		}}
+		result := w.ToMessagesWithSystem("Override")
+		if len(result) != 2 || result[0].Content != "Original" {
+			t.Errorf("expected existing system message to be kept, got %+v", result)
+		}
+	})
+
+	t.Run("empty system prompt", func(t *testing.T) {
+		w := WorkloadInput{Prompt: "Hello"}
+		if result := w.ToMessagesWithSystem(""); len(result) != 1 {
+			t.Errorf("expected 1 message, got %d", len(result))
+		}
+	})
+}
+
 func TestLoader_LoadFromFile_PlainText(t *testing.T) {
 	// Create temp file
 	dir := t.TempDir()
@@ -154,3 +187,196 @@ func TestLoader_GenerateDefault(t *testing.T) {
 		}
 	}
 }
+
+func TestLoadFromFile_ShareGPT(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "sharegpt.jsonl")
+	content := `{"id":"conv-1","conversations":[{"from":"system","value":"Be brief."},{"from":"human","value":"Hi"},{"from":"gpt","value":"Hello!"},{"from":"human","value":"What is Go?"},{"from":"gpt","value":"A language."}]}
+{"conversations":[{"from":"human","value":"Only question"}]}
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	loader := NewLoader()
+	workloads, err := loader.LoadFromFile(path, 128)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(workloads) != 2 {
+		t.Fatalf("expected 2 workloads, got %d", len(workloads))
+	}
+
+	w := workloads[0]
+	if w.ID != "conv-1" {
+		t.Errorf("expected ID 'conv-1', got '%s'", w.ID)
+	}
+	if len(w.Messages) != 4 {
+		t.Fatalf("expected 4 messages (trailing answer dropped), got %d", len(w.Messages))
+	}
+	if w.Messages[2].Role != "assistant" || w.Messages[3].Role != "user" || w.Messages[3].Content != "What is Go?" {
+		t.Errorf("unexpected messages: %+v", w.Messages)
+	}
+
+	if workloads[1].ID != "req-2" || len(workloads[1].Messages) != 1 {
+		t.Errorf("unexpected second workload: %+v", workloads[1])
+	}
+}
+
+func TestLoader_Stream(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "prompts.jsonl")
+	content := "What is AI?\n\n{\"id\": \"custom-id\", \"prompt\": \"World\"}\nHello"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	s, err := NewLoader().Stream(context.Background(), path, 256)
+	if err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+	var ids []string
+	for w := range s.C {
+		ids = append(ids, w.ID)
+	}
+	if err := s.Err(); err != nil {
+		t.Errorf("Err = %v, want nil", err)
+	}
+	want := []string{"req-1", "custom-id", "req-3"}
+	if strings.Join(ids, ",") != strings.Join(want, ",") {
+		t.Errorf("ids = %v, want %v", ids, want)
+	}
+
+	if _, err := NewLoader().Stream(context.Background(), filepath.Join(dir, "missing"), 256); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
+
+func TestLoader_StreamErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		ids     string
+		wantErr string
+	}{
+		{
+			name:    "parse error",
+			content: "Hello\n{\"conversations\": [{\"from\": \"robot\", \"value\": \"x\"}]}\nWorld",
+			ids:     "req-1",
+			wantErr: "failed to parse line 2",
+		},
+		{
+			name:    "line too long",
+			content: "Hello\n" + strings.Repeat("x", 2*1024*1024),
+			ids:     "req-1",
+			wantErr: "failed to read workload file",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "prompts.jsonl")
+			if err := os.WriteFile(path, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("failed to create test file: %v", err)
+			}
+
+			s, err := NewLoader().Stream(context.Background(), path, 256)
+			if err != nil {
+				t.Fatalf("Stream failed: %v", err)
+			}
+			var ids []string
+			for w := range s.C {
+				ids = append(ids, w.ID)
+			}
+			if strings.Join(ids, ",") != tt.ids {
+				t.Errorf("ids = %v, want %s", ids, tt.ids)
+			}
+			if err := s.Err(); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Err = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadMessagesFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "messages.json")
+	content := `[{"role": "system", "content": "Be brief."}, {"role": "user", "content": "Hi"}, {"role": "assistant", "content": "Hello!"}]`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to create test file: %v", err)
+	}
+
+	base, err := LoadMessagesFile(path)
+	if err != nil {
+		t.Fatalf("LoadMessagesFile failed: %v", err)
+	}
+	if len(base) != 3 {
+		t.Fatalf("expected 3 messages, got %d", len(base))
+	}
+
+	w := WorkloadInput{ID: "req-1", Prompt: "And you?"}.WithBaseMessages(base, true)
+	if len(w.Messages) != 4 || w.Messages[3].Role != "user" || w.Messages[3].Content != "And you?" || w.Prompt != "" {
+		t.Errorf("unexpected appended workload: %+v", w)
+	}
+	if w = (WorkloadInput{Prompt: "ignored"}).WithBaseMessages(base, false); len(w.Messages) != 3 {
+		t.Errorf("expected base conversation only, got %+v", w.Messages)
+	}
+
+	if err := os.WriteFile(path, []byte(`[{"role": "bot", "content": "x"}]`), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	if _, err := LoadMessagesFile(path); err == nil {
+		t.Error("expected error for invalid role")
+	}
+}
+
+func TestWorkloadInput_WithNonce(t *testing.T) {
+	w := NewSimpleWorkload("req-1", "Hello", 8).WithNonce("[n1]")
+	if w.Prompt != "Hello\n\n[n1]" {
+		t.Errorf("Prompt = %q, want the nonce appended", w.Prompt)
+	}
+
+	messages := []ChatMessage{
+		{Role: "system", Content: "Be brief"},
+		{Role: "user", Content: "Hi"},
+		{Role: "assistant", Content: "Hello"},
+		{Role: "user", Content: "And you?"},
+	}
+	w = NewChatWorkload("req-2", messages, 8).WithNonce("[n2]")
+	if w.Messages[3].Content != "And you?\n\n[n2]" || w.Messages[1].Content != "Hi" {
+		t.Errorf("Messages = %+v, want the nonce on the last user message only", w.Messages)
+	}
+	if messages[3].Content != "And you?" {
+		t.Error("WithNonce modified the original messages")
+	}
+}
+
+func TestEstimateTokens(t *testing.T) {
+	workloads := []WorkloadInput{
+		NewSimpleWorkload("short", "Hello world", 100),
+		NewChatWorkload("chat", []ChatMessage{
+			{Role: "system", Content: "You are helpful."},
+			{Role: "user", Content: "Summarize the benchmark results in one sentence."},
+		}, 200),
+	}
+
+	short := workloads[0].PromptTokens("", "")
+	chat := workloads[1].PromptTokens("", "")
+	if short <= 2 || chat <= short {
+		t.Fatalf("PromptTokens = %d and %d, want the chat workload larger and both above the content", short, chat)
+	}
+	if got := workloads[0].PromptTokens("Be brief.", ""); got <= short {
+		t.Errorf("PromptTokens with system prompt = %d, want more than %d", got, short)
+	}
+
+	// Five requests cycle through the two workloads: short, chat, short, chat, short
+	est := EstimateTokens(workloads, 5, "", "")
+	if est.Prompts != 5 || est.TotalTokens != 3*short+2*chat {
+		t.Errorf("Prompts = %d, TotalTokens = %d; want 5 and %d", est.Prompts, est.TotalTokens, 3*short+2*chat)
+	}
+	if est.MaxTokens != chat || est.MaxID != "chat" {
+		t.Errorf("MaxTokens = %d (%s), want %d (chat)", est.MaxTokens, est.MaxID, chat)
+	}
+	if est.MaxOutput != 3*100+2*200 {
+		t.Errorf("MaxOutput = %d, want 700", est.MaxOutput)
+	}
+}